@@ -1,49 +1,52 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	db "github.com/sssaang/simplebank/db/sqlc"
 	"github.com/sssaang/simplebank/token"
 )
 
 
+// createAccountRequest opens a checking account unless type says otherwise, an owner has one account of each type
+// per currency
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Type string `json:"type" binding:"omitempty,oneof=checking savings"`
 }
 
 func (server *Server) createAccount(ctx *gin.Context) {
 	var req createAccountRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
+	if !requireVerifiedEmail(ctx) {
+		return
+	}
+
+	accountType := req.Type
+	if len(accountType) == 0 {
+		accountType = db.ACCOUNT_CHECKING
+	}
+
 	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
 	arg := db.CreateAccountParams{
 		Owner: authPayload.Username,
 		Currency: req.Currency,
 		Balance: 0,
+		Type: accountType,
 	}
 
 	account, err := server.store.CreateAccount(ctx, arg)
 	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "foreign_key_violation", "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return 
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return 
 	}
 
-	ctx.JSON(http.StatusCreated, account)
+	ctx.JSON(http.StatusCreated, newAccountResponse(account))
 }
 
 type getAccountRequest struct {
@@ -53,30 +56,27 @@ type getAccountRequest struct {
 func (server *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	account, err := server.store.GetAccount(ctx, req.ID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return 
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return 
 	}
 	
-	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
-	if account.Owner != authPayload.Username {
-		err := errors.New("the user has no access to the account")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+	if !server.authorizeAccount(ctx, account, accountReaders) {
 		return
 	}
 
+	heldAmount, err := server.store.GetHeldAmount(ctx, account.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAvailableAccountResponse(account, heldAmount))
 }
 
 type listAccountsRequest struct {
@@ -87,22 +87,43 @@ type listAccountsRequest struct {
 func (server *Server) listAccounts(ctx *gin.Context) {
 	var req listAccountsRequest
 	if err := ctx.ShouldBindQuery(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
 	arg := db.ListAccountsParams{
-		Owner: authPayload.Username,
+		Username: authPayload.Username,
 		Limit: req.PageSize,
 		Offset: (req.PageID - 1) * req.PageSize,
 	}
 
 	accounts, err := server.store.ListAccounts(ctx, arg)
 	if err != nil { 
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return 
 	}
 
-	ctx.JSON(http.StatusOK, accounts)
+	ids := make([]int64, len(accounts))
+	for i, account := range accounts {
+		ids[i] = account.ID
+	}
+
+	heldAmounts, err := server.store.ListHeldAmounts(ctx, ids)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	held := make(map[int64]int64, len(heldAmounts))
+	for _, row := range heldAmounts {
+		held[row.AccountID] = row.HeldAmount
+	}
+
+	res := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		res[i] = newAvailableAccountResponse(account, held[account.ID])
+	}
+
+	ctx.JSON(http.StatusOK, res)
 }
\ No newline at end of file
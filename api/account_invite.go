@@ -0,0 +1,212 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/token"
+)
+
+type accountInviteResponse struct {
+	AccountID int64 `json:"account_id"`
+	Username string `json:"username"`
+	Role string `json:"role"`
+	InvitedBy string `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAccountInviteResponse(invite db.AccountInvite) accountInviteResponse {
+	return accountInviteResponse{
+		AccountID: invite.AccountID,
+		Username: invite.Username,
+		Role: invite.Role,
+		InvitedBy: invite.InvitedBy,
+		CreatedAt: invite.CreatedAt,
+	}
+}
+
+func newAccountInvitesResponse(invites []db.AccountInvite) []accountInviteResponse {
+	res := make([]accountInviteResponse, len(invites))
+	for i, invite := range invites {
+		res[i] = newAccountInviteResponse(invite)
+	}
+	return res
+}
+
+type inviteAccountMemberRequest struct {
+	Username string `json:"username" binding:"required,email"`
+	Role string `json:"role" binding:"required,oneof=owner spender viewer"`
+}
+
+// inviteAccountMember invites another user to share the account, only owners invite. The user gets no access
+// until it accepts, so an account cannot be shared with someone who never asked for it.
+func (server *Server) inviteAccountMember(ctx *gin.Context) {
+	var uri accountMembersURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req inviteAccountMemberRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountOwners) {
+		return
+	}
+
+	_, err = server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username: req.Username,
+	})
+	if err == nil {
+		abortWithError(ctx, newApiError(http.StatusConflict, ERROR_CODE_ALREADY_EXISTS, "the user already is a member of the account"))
+		return
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		abortWithError(ctx, err)
+		return
+	}
+
+	// an unknown user is an invalid reference, a user who already is invited a conflict
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	invite, err := server.store.CreateAccountInvite(ctx, db.CreateAccountInviteParams{
+		AccountID: account.ID,
+		Username: req.Username,
+		Role: req.Role,
+		InvitedBy: authPayload.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newAccountInviteResponse(invite))
+}
+
+// listAccountInvites shows the invites to the account which are not answered yet, to its owners
+func (server *Server) listAccountInvites(ctx *gin.Context) {
+	var uri accountMembersURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountOwners) {
+		return
+	}
+
+	invites, err := server.store.ListAccountInvites(ctx, account.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountInvitesResponse(invites))
+}
+
+// cancelAccountInvite withdraws an invite before it is answered, only owners withdraw
+func (server *Server) cancelAccountInvite(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountOwners) {
+		return
+	}
+
+	invite, err := server.store.DeleteAccountInvite(ctx, db.DeleteAccountInviteParams{
+		AccountID: account.ID,
+		Username: uri.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountInviteResponse(invite))
+}
+
+// listUserAccountInvites shows the authenticated user the invites it has not answered yet
+func (server *Server) listUserAccountInvites(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	invites, err := server.store.ListUserAccountInvites(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountInvitesResponse(invites))
+}
+
+type accountInviteURI struct {
+	AccountID int64 `uri:"account_id" binding:"required,min=1"`
+}
+
+// acceptAccountInvite makes the authenticated user a member of the account in the role it was invited to
+func (server *Server) acceptAccountInvite(ctx *gin.Context) {
+	var uri accountInviteURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	member, err := server.store.AcceptAccountInviteTx(ctx, db.AcceptAccountInviteTxParams{
+		AccountID: uri.AccountID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountMemberResponse(member))
+}
+
+// declineAccountInvite turns an invite of the authenticated user down
+func (server *Server) declineAccountInvite(ctx *gin.Context) {
+	var uri accountInviteURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	invite, err := server.store.DeleteAccountInvite(ctx, db.DeleteAccountInviteParams{
+		AccountID: uri.AccountID,
+		Username: authPayload.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountInviteResponse(invite))
+}
@@ -0,0 +1,381 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInviteAccountMemberAPI(t *testing.T) {
+	holder, _ := randomUser(t)
+	spender, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(holder.Username)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"username": invitee.Username, "role": db.ACCOUNT_ROLE_SPENDER},
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, invitee.Username, "")
+
+				arg := db.CreateAccountInviteParams{
+					AccountID: account.ID,
+					Username: invitee.Username,
+					Role: db.ACCOUNT_ROLE_SPENDER,
+					InvitedBy: holder.Username,
+				}
+				store.EXPECT().
+				CreateAccountInvite(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.AccountInvite{AccountID: arg.AccountID, Username: arg.Username, Role: arg.Role, InvitedBy: arg.InvitedBy}, nil)
+				// the invitee gets no access until it accepts
+				store.EXPECT().
+				CreateAccountMember(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, invitee.Username, got["username"])
+				require.Equal(t, db.ACCOUNT_ROLE_SPENDER, got["role"])
+				require.Equal(t, holder.Username, got["invited_by"])
+			},
+		},
+		{
+			name: "Spender Cannot Invite",
+			body: gin.H{"username": invitee.Username, "role": db.ACCOUNT_ROLE_VIEWER},
+			username: spender.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, spender.Username, db.ACCOUNT_ROLE_SPENDER)
+				store.EXPECT().
+				CreateAccountInvite(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Already A Member",
+			body: gin.H{"username": spender.Username, "role": db.ACCOUNT_ROLE_VIEWER},
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, spender.Username, db.ACCOUNT_ROLE_SPENDER)
+				store.EXPECT().
+				CreateAccountInvite(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_ALREADY_EXISTS)
+			},
+		},
+		{
+			name: "Already Invited",
+			body: gin.H{"username": invitee.Username, "role": db.ACCOUNT_ROLE_VIEWER},
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, invitee.Username, "")
+				store.EXPECT().
+				CreateAccountInvite(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountInvite{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_ALREADY_EXISTS)
+			},
+		},
+		{
+			name: "Unknown User",
+			body: gin.H{"username": "nobody@example.com", "role": db.ACCOUNT_ROLE_VIEWER},
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, "nobody@example.com", "")
+				store.EXPECT().
+				CreateAccountInvite(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountInvite{}, &pq.Error{Code: "23503"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_INVALID_REFERENCE)
+			},
+		},
+		{
+			name: "Invalid Role",
+			body: gin.H{"username": invitee.Username, "role": "admin"},
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, fmt.Sprintf("/accounts/%d/members", account.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestAnswerAccountInviteAPI(t *testing.T) {
+	holder, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(holder.Username)
+	invite := db.AccountInvite{AccountID: account.ID, Username: invitee.Username, Role: db.ACCOUNT_ROLE_VIEWER, InvitedBy: holder.Username}
+
+	testCases := []struct {
+		name string
+		answer string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Accept",
+			answer: "accept",
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				AcceptAccountInviteTx(gomock.Any(), gomock.Eq(db.AcceptAccountInviteTxParams{AccountID: account.ID, Username: invitee.Username})).
+				Times(1).
+				Return(db.AccountMember{
+					AccountID: account.ID,
+					Username: invitee.Username,
+					Role: invite.Role,
+					InvitedBy: sql.NullString{String: holder.Username, Valid: true},
+				}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountMemberResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, invitee.Username, got.Username)
+				require.Equal(t, db.ACCOUNT_ROLE_VIEWER, got.Role)
+				require.Equal(t, holder.Username, got.InvitedBy)
+			},
+		},
+		{
+			name: "Accept Not Invited",
+			answer: "accept",
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				AcceptAccountInviteTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+		{
+			name: "Decline",
+			answer: "decline",
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				DeleteAccountInvite(gomock.Any(), gomock.Eq(db.DeleteAccountInviteParams{AccountID: account.ID, Username: invitee.Username})).
+				Times(1).
+				Return(invite, nil)
+				store.EXPECT().
+				AcceptAccountInviteTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got accountInviteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, invitee.Username, got.Username)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/account_invites/%d/%s", account.ID, tc.answer)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, invitee.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListAccountInvitesAPI(t *testing.T) {
+	holder, _ := randomUser(t)
+	viewer, _ := randomUser(t)
+	invitee, _ := randomUser(t)
+	account := randomAccount(holder.Username)
+	invites := []db.AccountInvite{
+		{AccountID: account.ID, Username: invitee.Username, Role: db.ACCOUNT_ROLE_SPENDER, InvitedBy: holder.Username},
+	}
+
+	testCases := []struct {
+		name string
+		username string
+		url string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Account Owner",
+			username: holder.Username,
+			url: fmt.Sprintf("/accounts/%d/invites", account.ID),
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				ListAccountInvites(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(invites, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []accountInviteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 1)
+				require.Equal(t, invitee.Username, got[0].Username)
+			},
+		},
+		{
+			name: "Account Viewer",
+			username: viewer.Username,
+			url: fmt.Sprintf("/accounts/%d/invites", account.ID),
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, viewer.Username, db.ACCOUNT_ROLE_VIEWER)
+				store.EXPECT().
+				ListAccountInvites(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Invitee",
+			username: invitee.Username,
+			url: "/account_invites",
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ListUserAccountInvites(gomock.Any(), gomock.Eq(invitee.Username)).
+				Times(1).
+				Return(invites, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []accountInviteResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 1)
+				require.Equal(t, account.ID, got[0].AccountID)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, tc.url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/token"
+)
+
+var (
+	// accountReaders see the account, its holds, statements and members
+	accountReaders = []string{db.ACCOUNT_ROLE_OWNER, db.ACCOUNT_ROLE_SPENDER, db.ACCOUNT_ROLE_VIEWER}
+	// accountSpenders transfer from the account
+	accountSpenders = []string{db.ACCOUNT_ROLE_OWNER, db.ACCOUNT_ROLE_SPENDER}
+	// accountOwners manage the members of the account
+	accountOwners = []string{db.ACCOUNT_ROLE_OWNER}
+)
+
+// accountRole is the role of the user on the account, the holder is an owner without looking the membership up
+func (server *Server) accountRole(ctx *gin.Context, account db.Account, username string) (string, error) {
+	if account.Owner == username {
+		return db.ACCOUNT_ROLE_OWNER, nil
+	}
+
+	member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username: username,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, "the user has no access to the account")
+	}
+	return member.Role, err
+}
+
+// authorizeAccount aborts the request unless the authenticated user is a member of the account in one of the roles
+func (server *Server) authorizeAccount(ctx *gin.Context, account db.Account, roles []string) bool {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	role, err := server.accountRole(ctx, account, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return false
+	}
+
+	for _, allowed := range roles {
+		if role == allowed {
+			return true
+		}
+	}
+
+	abortWithError(ctx, newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, fmt.Sprintf("the %s role does not allow it on the account", role)))
+	return false
+}
+
+// isAccountMember tells whether the user holds the account or is a member of it in any role
+func (server *Server) isAccountMember(ctx *gin.Context, accountID int64, username string) (bool, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		return false, err
+	}
+
+	if account.Owner == username {
+		return true, nil
+	}
+
+	_, err = server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+		AccountID: account.ID,
+		Username: username,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+type accountMemberResponse struct {
+	AccountID int64 `json:"account_id"`
+	Username string `json:"username"`
+	Role string `json:"role"`
+	InvitedBy string `json:"invited_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAccountMemberResponse(member db.AccountMember) accountMemberResponse {
+	return accountMemberResponse{
+		AccountID: member.AccountID,
+		Username: member.Username,
+		Role: member.Role,
+		InvitedBy: member.InvitedBy.String,
+		CreatedAt: member.CreatedAt,
+	}
+}
+
+type accountMembersURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listAccountMembers shows who shares the account, to any of its members
+func (server *Server) listAccountMembers(ctx *gin.Context) {
+	var uri accountMembersURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountReaders) {
+		return
+	}
+
+	members, err := server.store.ListAccountMembers(ctx, account.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]accountMemberResponse, len(members))
+	for i, member := range members {
+		res[i] = newAccountMemberResponse(member)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type accountMemberURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+	Username string `uri:"username" binding:"required"`
+}
+
+// removeAccountMember takes a member off the account. Owners remove anyone but the holder, the other members
+// can only leave.
+func (server *Server) removeAccountMember(ctx *gin.Context) {
+	var uri accountMemberURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	roles := accountOwners
+	if uri.Username == authPayload.Username {
+		roles = accountReaders
+	}
+
+	if !server.authorizeAccount(ctx, account, roles) {
+		return
+	}
+
+	if uri.Username == account.Owner {
+		abortWithError(ctx, newApiError(http.StatusConflict, ERROR_CODE_CONFLICT, "the holder of the account cannot be removed"))
+		return
+	}
+
+	member, err := server.store.DeleteAccountMember(ctx, db.DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username: uri.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountMemberResponse(member))
+}
@@ -0,0 +1,242 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAccountRole makes the user a member of the account in the role, an empty role makes it a stranger
+func stubAccountRole(store *testdb.MockStore, account db.Account, username string, role string) {
+	member := db.AccountMember{AccountID: account.ID, Username: username, Role: role}
+	var err error
+	if len(role) == 0 {
+		member, err = db.AccountMember{}, sql.ErrNoRows
+	}
+
+	store.EXPECT().
+	GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: username})).
+	Times(1).
+	Return(member, err)
+}
+
+func TestListAccountMembersAPI(t *testing.T) {
+	holder, _ := randomUser(t)
+	viewer, _ := randomUser(t)
+	account := randomAccount(holder.Username)
+	members := []db.AccountMember{
+		{AccountID: account.ID, Username: holder.Username, Role: db.ACCOUNT_ROLE_OWNER},
+		{AccountID: account.ID, Username: viewer.Username, Role: db.ACCOUNT_ROLE_VIEWER, InvitedBy: sql.NullString{String: holder.Username, Valid: true}},
+	}
+
+	testCases := []struct {
+		name string
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Viewer",
+			username: viewer.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, viewer.Username, db.ACCOUNT_ROLE_VIEWER)
+				store.EXPECT().
+				ListAccountMembers(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(members, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 2)
+				require.NotContains(t, got[0], "invited_by")
+				require.Equal(t, db.ACCOUNT_ROLE_VIEWER, got[1]["role"])
+				require.Equal(t, holder.Username, got[1]["invited_by"])
+			},
+		},
+		{
+			name: "Not A Member",
+			username: "other@example.com",
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, "other@example.com", "")
+				store.EXPECT().
+				ListAccountMembers(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/members", account.ID), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRemoveAccountMemberAPI(t *testing.T) {
+	holder, _ := randomUser(t)
+	spender, _ := randomUser(t)
+	viewer, _ := randomUser(t)
+	account := randomAccount(holder.Username)
+
+	testCases := []struct {
+		name string
+		member string
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Owner Removes",
+			member: spender.Username,
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: spender.Username})).
+				Times(1).
+				Return(db.AccountMember{AccountID: account.ID, Username: spender.Username, Role: db.ACCOUNT_ROLE_SPENDER}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Member Leaves",
+			member: viewer.Username,
+			username: viewer.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, viewer.Username, db.ACCOUNT_ROLE_VIEWER)
+				store.EXPECT().
+				DeleteAccountMember(gomock.Any(), gomock.Eq(db.DeleteAccountMemberParams{AccountID: account.ID, Username: viewer.Username})).
+				Times(1).
+				Return(db.AccountMember{AccountID: account.ID, Username: viewer.Username, Role: db.ACCOUNT_ROLE_VIEWER}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Spender Removes Another",
+			member: viewer.Username,
+			username: spender.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, spender.Username, db.ACCOUNT_ROLE_SPENDER)
+				store.EXPECT().
+				DeleteAccountMember(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Holder",
+			member: holder.Username,
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				DeleteAccountMember(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_CONFLICT)
+			},
+		},
+		{
+			name: "Not A Member",
+			member: "nobody@example.com",
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				DeleteAccountMember(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			path := fmt.Sprintf("/accounts/%d/members/%s", account.ID, url.PathEscape(tc.member))
+			request, err := http.NewRequest(http.MethodDelete, path, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
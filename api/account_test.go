@@ -45,6 +45,7 @@ func TestCreateAccountAPI(t *testing.T) {
 				arg := db.CreateAccountParams{
 					Owner: account.Owner,
 					Currency: account.Currency,
+					Type: db.ACCOUNT_CHECKING,
 				}
 				store.EXPECT().
 				CreateAccount(gomock.Any(), gomock.Eq(arg)).
@@ -56,6 +57,56 @@ func TestCreateAccountAPI(t *testing.T) {
 				requireBodyMatchAccount(t, recorder.Body, account)
 			},
 		},
+		{
+			name: "Savings Account",
+			body: gin.H{
+				"currency": account.Currency,
+				"type": db.ACCOUNT_SAVINGS,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				savings := account
+				savings.Type = db.ACCOUNT_SAVINGS
+
+				arg := db.CreateAccountParams{
+					Owner: account.Owner,
+					Currency: account.Currency,
+					Type: db.ACCOUNT_SAVINGS,
+				}
+				store.EXPECT().
+				CreateAccount(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(savings, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.ACCOUNT_SAVINGS, got["type"])
+			},
+		},
+		{
+			name: "Invalid Type",
+			body: gin.H{
+				"currency": account.Currency,
+				"type": "brokerage",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
 		{
 			name: "Invalid Currency",
 			body: gin.H {
@@ -95,6 +146,30 @@ func TestCreateAccountAPI(t *testing.T) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "Unverified Email",
+			body: gin.H{
+				"owner": account.Owner,
+				"currency": account.Currency,
+				"balance": account.Balance,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				store.EXPECT().
+				CreateAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
 		{
 			name: "No Authorization",
 			body: gin.H {
@@ -122,6 +197,7 @@ func TestCreateAccountAPI(t *testing.T) {
 
 			store := testdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			stubAuthUsers(store)
 
 			server := NewTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -163,10 +239,35 @@ func TestGetAccountAPI(t *testing.T) {
 					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
 					Times(1).
 					Return(account, nil)
+					store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(int64(0), nil)
+				},
+				checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+					require.Equal(t, http.StatusOK, recorder.Code)
+					requireBodyMatchAvailableAccount(t, recorder.Body, account, 0)
+				},
+			},
+			{
+				name: "Active Holds",
+				accountID: account.ID,
+				setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+					addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+				},
+				buildStubs: func(store *testdb.MockStore) {
+					store.EXPECT().
+					GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account, nil)
+					store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Eq(account.ID)).
+					Times(1).
+					Return(account.Balance/2, nil)
 				},
 				checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 					require.Equal(t, http.StatusOK, recorder.Code)
-					requireBodyMatchAccount(t, recorder.Body, account)
+					requireBodyMatchAvailableAccount(t, recorder.Body, account, account.Balance/2)
 				},
 			},
 			{
@@ -226,9 +327,16 @@ func TestGetAccountAPI(t *testing.T) {
 					store.EXPECT().
 					GetAccount(gomock.Any(), gomock.Any()).
 					Times(1)
+					store.EXPECT().
+					GetAccountMember(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(db.AccountMember{}, sql.ErrNoRows)
+					store.EXPECT().
+					GetHeldAmount(gomock.Any(), gomock.Any()).
+					Times(0)
 				},
 				checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
-					require.Equal(t, http.StatusUnauthorized, recorder.Code)
+					requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
 				},
 			},
 			{
@@ -256,6 +364,7 @@ func TestGetAccountAPI(t *testing.T) {
 			
 			store := testdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			stubAuthUsers(store)
 
 			server := NewTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -277,6 +386,7 @@ func randomAccount(username string) db.Account {
 		Owner: username,
 		Balance: util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Type: db.ACCOUNT_CHECKING,
 	}
 }
 
@@ -284,8 +394,16 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	data, err := ioutil.ReadAll(body)
 	require.NoError(t, err)
 
-	var gotAccount db.Account
-	err = json.Unmarshal(data, &gotAccount)
+	expected, err := json.Marshal(newAccountResponse(account))
 	require.NoError(t, err)
-	require.Equal(t, account, gotAccount)
-}
\ No newline at end of file
+	require.JSONEq(t, string(expected), string(data))
+}
+
+func requireBodyMatchAvailableAccount(t *testing.T, body *bytes.Buffer, account db.Account, heldAmount int64) {
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+
+	expected, err := json.Marshal(newAvailableAccountResponse(account, heldAmount))
+	require.NoError(t, err)
+	require.JSONEq(t, string(expected), string(data))
+}
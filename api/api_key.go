@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+)
+
+const (
+	API_KEY_BYTES = 32
+	// API_KEY_PREFIX makes leaked keys easy to recognize by secret scanners
+	API_KEY_PREFIX = "sbk_"
+)
+
+type apiKeyResponse struct {
+	ID int64 `json:"id"`
+	Name string `json:"name"`
+	Scopes []string `json:"scopes"`
+	IsRevoked bool `json:"is_revoked"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newApiKeyResponse(apiKey db.ApiKey) apiKeyResponse {
+	return apiKeyResponse{
+		ID: apiKey.ID,
+		Name: apiKey.Name,
+		Scopes: apiKey.Scopes,
+		IsRevoked: apiKey.IsRevoked,
+		CreatedAt: apiKey.CreatedAt,
+	}
+}
+
+// newApiKeyPayload lets handlers treat a request authenticated with an API key like one with a token
+func newApiKeyPayload(apiKey db.ApiKey) *token.Payload {
+	return &token.Payload{
+		Username: apiKey.Username,
+		Scopes: apiKey.Scopes,
+		IssuedAt: apiKey.CreatedAt,
+	}
+}
+
+type createApiKeyRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,scope"`
+}
+
+type createApiKeyResponse struct {
+	// Key is only returned once, the server keeps its hash
+	Key string `json:"key"`
+	ApiKey apiKeyResponse `json:"api_key"`
+}
+
+func (server *Server) createApiKey(ctx *gin.Context) {
+	var req createApiKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	// a key can never grant more than the credentials used to create it
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	for _, scope := range req.Scopes {
+		if !authPayload.HasScope(scope) {
+			message := fmt.Sprintf("cannot grant the %s scope", scope)
+			abortWithError(ctx, newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, message))
+			return
+		}
+	}
+
+	secret, err := util.RandomSecret(API_KEY_BYTES)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	key := API_KEY_PREFIX + secret
+
+	arg := db.CreateApiKeyParams{
+		Username: authPayload.Username,
+		Name: req.Name,
+		KeyHash: util.HashSecret(key),
+		Scopes: req.Scopes,
+	}
+
+	apiKey, err := server.store.CreateApiKey(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := createApiKeyResponse{
+		Key: key,
+		ApiKey: newApiKeyResponse(apiKey),
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (server *Server) listApiKeys(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+
+	apiKeys, err := server.store.ListApiKeysByUser(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]apiKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		res[i] = newApiKeyResponse(apiKey)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type revokeApiKeyRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+func (server *Server) revokeApiKey(ctx *gin.Context) {
+	var req revokeApiKeyRequest
+	if err := ctx.ShouldBindUri(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	arg := db.RevokeApiKeyParams{
+		ID: req.ID,
+		Username: authPayload.Username,
+	}
+
+	apiKey, err := server.store.RevokeApiKey(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newApiKeyResponse(apiKey))
+}
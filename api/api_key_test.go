@@ -0,0 +1,289 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+func randomApiKey(username string, scopes []string) db.ApiKey {
+	return db.ApiKey{
+		ID: util.RandomInt(1, 1000),
+		Username: username,
+		Name: util.RandomString(8),
+		KeyHash: util.HashSecret(util.RandomString(32)),
+		Scopes: scopes,
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestCreateApiKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	scopes := []string{token.SCOPE_ACCOUNTS_READ, token.SCOPE_TRANSFERS_WRITE}
+	apiKey := randomApiKey(user.Username, scopes)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"name": apiKey.Name, "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateApiKey(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ interface{}, arg db.CreateApiKeyParams) (db.ApiKey, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.Equal(t, apiKey.Name, arg.Name)
+					require.Equal(t, scopes, arg.Scopes)
+					require.NotEmpty(t, arg.KeyHash)
+					apiKey.KeyHash = arg.KeyHash
+					return apiKey, nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var got createApiKeyResponse
+				err = json.Unmarshal(data, &got)
+				require.NoError(t, err)
+				require.True(t, strings.HasPrefix(got.Key, API_KEY_PREFIX))
+				require.Equal(t, apiKey.KeyHash, util.HashSecret(got.Key))
+				require.Equal(t, apiKey.ID, got.ApiKey.ID)
+				require.Equal(t, scopes, got.ApiKey.Scopes)
+				require.NotContains(t, string(data), apiKey.KeyHash)
+			},
+		},
+		{
+			name: "Scope Not Held",
+			body: gin.H{"name": apiKey.Name, "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				held := []string{token.SCOPE_API_KEYS_WRITE, token.SCOPE_ACCOUNTS_READ}
+				addScopedAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, held, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateApiKey(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Unknown Scope",
+			body: gin.H{"name": apiKey.Name, "scopes": []string{"everything"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateApiKey(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "No Scopes",
+			body: gin.H{"name": apiKey.Name, "scopes": []string{}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateApiKey(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			body: gin.H{"name": apiKey.Name, "scopes": scopes},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateApiKey(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ApiKey{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/api_keys", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListApiKeysAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	apiKeys := []db.ApiKey{
+		randomApiKey(user.Username, []string{token.SCOPE_ACCOUNTS_READ}),
+		randomApiKey(user.Username, []string{token.SCOPE_TRANSFERS_READ}),
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	ListApiKeysByUser(gomock.Any(), gomock.Eq(user.Username)).
+	Times(1).
+	Return(apiKeys, nil)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/api_keys", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []apiKeyResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		require.Equal(t, apiKey.ID, got[i].ID)
+		require.Equal(t, apiKey.Scopes, got[i].Scopes)
+	}
+	require.NotContains(t, recorder.Body.String(), apiKeys[0].KeyHash)
+}
+
+func TestRevokeApiKeyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	apiKey := randomApiKey(user.Username, []string{token.SCOPE_ACCOUNTS_READ})
+
+	testCases := []struct {
+		name string
+		id int64
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			id: apiKey.ID,
+			buildStubs: func(store *testdb.MockStore) {
+				revokedKey := apiKey
+				revokedKey.IsRevoked = true
+
+				arg := db.RevokeApiKeyParams{ID: apiKey.ID, Username: user.Username}
+				store.EXPECT().
+				RevokeApiKey(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(revokedKey, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got apiKeyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.True(t, got.IsRevoked)
+			},
+		},
+		{
+			name: "Not Found",
+			id: apiKey.ID,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				RevokeApiKey(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ApiKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusNotFound, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid ID",
+			id: 0,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				RevokeApiKey(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/api_keys/%d", tc.id)
+			request, err := http.NewRequest(http.MethodDelete, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,239 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/sssaang/simplebank/token"
+)
+
+const (
+	BATCH_ITEM_COMPLETED = "completed"
+	BATCH_ITEM_HELD = "held"
+	BATCH_ITEM_FAILED = "failed"
+)
+
+type batchTransferItem struct {
+	ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
+	Amount string `json:"amount" binding:"required,money"`
+}
+
+type batchTransferRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+	Currency string `json:"currency" binding:"required,currency"`
+	// BestEffort makes the transfers which can be made and reports the others as failed,
+	// by default the batch is atomic and the first failure rejects it as a whole
+	BestEffort bool `json:"best_effort"`
+	Transfers []batchTransferItem `json:"transfers" binding:"required,min=1,max=500,dive"`
+}
+
+type batchTransferItemResponse struct {
+	Index int `json:"index"`
+	ToAccountID int64 `json:"to_account_id"`
+	Amount util.Money `json:"amount"`
+	Status string `json:"status"`
+	Transfer *transferResponse `json:"transfer,omitempty"`
+	// HeldTransfer is set when the fraud screening held the transfer for review
+	HeldTransfer *heldTransferResponse `json:"held_transfer,omitempty"`
+	Error *ApiError `json:"error,omitempty"`
+}
+
+type batchTransferResponse struct {
+	FromAccount accountResponse `json:"from_account"`
+	Completed int `json:"completed"`
+	Held int `json:"held"`
+	Failed int `json:"failed"`
+	Transfers []batchTransferItemResponse `json:"transfers"`
+}
+
+func newBatchTransferResponse(arg db.BatchTransferTxParams, result db.BatchTransferTxResult) batchTransferResponse {
+	res := batchTransferResponse{
+		FromAccount: newAccountResponse(result.FromAccount),
+		Completed: result.Completed,
+		Held: result.Held,
+		Failed: len(result.Items) - result.Completed - result.Held,
+		Transfers: make([]batchTransferItemResponse, len(result.Items)),
+	}
+
+	for i, item := range result.Items {
+		res.Transfers[i] = batchTransferItemResponse{
+			Index: i,
+			ToAccountID: arg.Items[i].ToAccountID,
+			Amount: util.NewMoney(arg.Items[i].Amount, arg.Currency),
+			Status: BATCH_ITEM_COMPLETED,
+		}
+
+		if item.Err != nil {
+			res.Transfers[i].Status = BATCH_ITEM_FAILED
+			res.Transfers[i].Error = toApiError(item.Err)
+			continue
+		}
+
+		if item.Held {
+			held := newHeldTransferResponse(item.HeldTransfer)
+			res.Transfers[i].Status = BATCH_ITEM_HELD
+			res.Transfers[i].HeldTransfer = &held
+			continue
+		}
+
+		transfer := newTransferResponse(item.Transfer, arg.Currency)
+		res.Transfers[i].Transfer = &transfer
+	}
+
+	return res
+}
+
+func (server *Server) makeBatchTransfer(ctx *gin.Context) {
+	var req batchTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if !requireVerifiedEmail(ctx) {
+		return
+	}
+
+	arg := db.BatchTransferTxParams{
+		FromAccountID: req.FromAccountID,
+		Currency: req.Currency,
+		Items: make([]db.BatchTransferItem, len(req.Transfers)),
+		BestEffort: req.BestEffort,
+	}
+
+	for i, item := range req.Transfers {
+		amount, err := parseAmount(fmt.Sprintf("transfers[%d].amount", i), item.Amount, req.Currency)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		arg.Items[i] = db.BatchTransferItem{
+			ToAccountID: item.ToAccountID,
+			Amount: amount.Amount(),
+		}
+	}
+
+	fromAccount, isValid := server.validAccount(ctx, req.FromAccountID, req.Currency)
+	if !isValid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, accountSpenders) {
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	arg.RequestedBy = authPayload.Username
+
+	// the items refused by the screening are left out of the batch and reported as failed
+	batch := arg
+	batch.Items = nil
+	indexes := []int{}
+	refused := make(map[int]error)
+	batched := []risk.Transfer{}
+
+	for i := range arg.Items {
+		reasons, refusal, err := server.screenBatchItem(ctx, fromAccount, arg.Items[i], &batched)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		if refusal != nil {
+			if !req.BestEffort {
+				abortWithError(ctx, &db.BatchItemError{Index: i, Err: refusal})
+				return
+			}
+			refused[i] = refusal
+			continue
+		}
+
+		arg.Items[i].HoldReasons = reasons
+		batch.Items = append(batch.Items, arg.Items[i])
+		indexes = append(indexes, i)
+	}
+
+	result, err := server.store.BatchTransferTx(ctx, batch)
+	if err != nil {
+		var itemErr *db.BatchItemError
+		if errors.As(err, &itemErr) {
+			err = &db.BatchItemError{Index: indexes[itemErr.Index], Err: itemErr.Err}
+		}
+		abortWithError(ctx, err)
+		return
+	}
+
+	items := make([]db.BatchTransferItemResult, len(arg.Items))
+	for i, item := range result.Items {
+		items[indexes[i]] = item
+	}
+	for i, err := range refused {
+		items[i].Err = err
+	}
+	result.Items = items
+
+	ctx.JSON(http.StatusOK, newBatchTransferResponse(arg, result))
+}
+
+// screenBatchItem screens an item as a separate transfer, against the transfers made before the batch and the items
+// of the batch screened before it, which are all made or held unless refused. An item that is not refused is added to them.
+// It returns the reasons to hold an item flagged for review, or why the item is refused.
+// An unknown recipient is not screened, the batch reports it as not found.
+func (server *Server) screenBatchItem(ctx *gin.Context, fromAccount db.Account, item db.BatchTransferItem, batched *[]risk.Transfer) (reasons []string, refusal error, err error) {
+	toAccount, err := server.store.GetAccount(ctx, item.ToAccountID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// the items to the same recipient add up, so that splitting a large transfer does not avoid the cooling-off
+	amount := item.Amount
+	for _, earlier := range *batched {
+		if earlier.ToAccount.ID == toAccount.ID {
+			amount += earlier.Amount
+		}
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	err = server.coolingOff.Check(ctx, authPayload.Username, toAccount, amount)
+	var coolingOffErr *risk.CoolingOffError
+	if errors.As(err, &coolingOffErr) {
+		return nil, coolingOffErr, nil
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	transfer := risk.Transfer{
+		FromAccount: fromAccount,
+		ToAccount: toAccount,
+		Amount: item.Amount,
+		RequestedBy: authPayload.Username,
+		Batched: *batched,
+	}
+	assessment, err := server.riskEngine.Screen(ctx, transfer)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if assessment.Outcome == risk.OUTCOME_DENY {
+		return nil, errTransferDenied, nil
+	}
+
+	transfer.Batched = nil
+	*batched = append(*batched, transfer)
+
+	if assessment.Outcome == risk.OUTCOME_REVIEW {
+		return assessment.Reasons(), nil, nil
+	}
+	return nil, nil, nil
+}
@@ -0,0 +1,648 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+// batchTransferResult reads the response back, amounts stay strings since util.Money is write-only
+type batchTransferResult struct {
+	Completed int `json:"completed"`
+	Held int `json:"held"`
+	Failed int `json:"failed"`
+	Transfers []struct {
+		ToAccountID int64 `json:"to_account_id"`
+		Amount string `json:"amount"`
+		Status string `json:"status"`
+		Transfer *struct {
+			ID int64 `json:"id"`
+		} `json:"transfer"`
+		HeldTransfer *struct {
+			ID int64 `json:"id"`
+		} `json:"held_transfer"`
+		Error *ApiError `json:"error"`
+	} `json:"transfers"`
+}
+
+func TestMakeBatchTransferAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+	recipient1 := randomAccount("recipient1")
+	recipient1.Currency = util.USD
+	recipient2 := randomAccount("recipient2")
+	recipient2.Currency = util.USD
+
+	transfers := []gin.H{
+		{"to_account_id": recipient1.ID, "amount": "10"},
+		{"to_account_id": recipient2.ID, "amount": "2.50"},
+	}
+
+	arg := db.BatchTransferTxParams{
+		FromAccountID: account.ID,
+		Currency: util.USD,
+		Items: []db.BatchTransferItem{
+			{ToAccountID: recipient1.ID, Amount: 1000},
+			{ToAccountID: recipient2.ID, Amount: 250},
+		},
+		RequestedBy: user.Username,
+	}
+
+	bestEffortArg := arg
+	bestEffortArg.BestEffort = true
+
+	// stubRecipients lets every item be screened, the rules themselves are stubbed by each case
+	stubRecipients := func(store *testdb.MockStore) {
+		for _, recipient := range []db.Account{recipient1, recipient2} {
+			store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(recipient.ID)).
+			AnyTimes().
+			Return(recipient, nil)
+		}
+	}
+
+	// recipient2Rule flags the transfers to recipient2 only
+	recipient2Rule := func(action string) db.FraudRule {
+		return db.FraudRule{
+			ID: 1,
+			Name: "repeated to recipient2",
+			Kind: risk.RULE_REPEATED_TRANSFER,
+			Action: action,
+			MaxCount: sql.NullInt32{Int32: 1, Valid: true},
+			WindowSeconds: sql.NullInt32{Int32: 3600, Valid: true},
+		}
+	}
+	stubRecipient2Rule := func(store *testdb.MockStore, action string) {
+		store.EXPECT().
+		ListEnabledFraudRules(gomock.Any()).
+		Times(2).
+		Return([]db.FraudRule{recipient2Rule(action)}, nil)
+		store.EXPECT().
+		CountRepeatedTransfers(gomock.Any(), gomock.Any()).
+		Times(2).
+		DoAndReturn(func(_ interface{}, arg db.CountRepeatedTransfersParams) (int64, error) {
+			if arg.ToAccountID == recipient2.ID {
+				return 1, nil
+			}
+			return 0, nil
+		})
+	}
+
+	transfer1 := db.Transfer{ID: 1, FromAccountID: account.ID, ToAccountID: recipient1.ID, Amount: 1000, CreatedAt: time.Now()}
+
+	tooMany := make([]gin.H, 501)
+	for i := range tooMany {
+		tooMany[i] = gin.H{"to_account_id": recipient1.ID, "amount": "1"}
+	}
+
+	testCases := []struct {
+		name string
+		body gin.H
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Atomic",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubFraudRules(store)
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.BatchTransferTxResult{
+					FromAccount: account,
+					Items: []db.BatchTransferItemResult{
+						{Transfer: transfer1},
+						{Transfer: db.Transfer{ID: 2, FromAccountID: account.ID, ToAccountID: recipient2.ID, Amount: 250}},
+					},
+					Completed: 2,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res batchTransferResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 2, res.Completed)
+				require.Equal(t, 0, res.Failed)
+				require.Len(t, res.Transfers, 2)
+				require.Equal(t, BATCH_ITEM_COMPLETED, res.Transfers[1].Status)
+				require.Equal(t, int64(2), res.Transfers[1].Transfer.ID)
+				require.Equal(t, "2.50", res.Transfers[1].Amount)
+			},
+		},
+		{
+			name: "Best Effort",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "best_effort": true, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubFraudRules(store)
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(bestEffortArg)).
+				Times(1).
+				Return(db.BatchTransferTxResult{
+					FromAccount: account,
+					Items: []db.BatchTransferItemResult{
+						{Transfer: transfer1},
+						{Err: db.ErrInsufficientFunds},
+					},
+					Completed: 1,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res batchTransferResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 1, res.Completed)
+				require.Equal(t, 1, res.Failed)
+				require.Equal(t, BATCH_ITEM_COMPLETED, res.Transfers[0].Status)
+				require.Equal(t, BATCH_ITEM_FAILED, res.Transfers[1].Status)
+				require.Nil(t, res.Transfers[1].Transfer)
+				require.Equal(t, ERROR_CODE_INSUFFICIENT_FUNDS, res.Transfers[1].Error.Code)
+				require.Equal(t, recipient2.ID, res.Transfers[1].ToAccountID)
+			},
+		},
+		{
+			name: "Atomic Failure",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubFraudRules(store)
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.BatchTransferTxResult{}, &db.BatchItemError{Index: 1, Err: db.ErrCurrencyMismatch})
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_CURRENCY_MISMATCH)
+				require.Contains(t, apiErr.Message, "transfer 1 of the batch")
+			},
+		},
+		{
+			name: "Denied Atomic",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubRecipient2Rule(store, risk.OUTCOME_DENY)
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_TRANSFER_DENIED)
+				require.Contains(t, apiErr.Message, "transfer 1 of the batch")
+				require.NotContains(t, apiErr.Message, recipient2Rule(risk.OUTCOME_DENY).Name)
+			},
+		},
+		{
+			name: "Denied Best Effort",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "best_effort": true, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubRecipient2Rule(store, risk.OUTCOME_DENY)
+
+				// only the first item reaches the batch
+				batch := bestEffortArg
+				batch.Items = bestEffortArg.Items[:1]
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(batch)).
+				Times(1).
+				Return(db.BatchTransferTxResult{
+					FromAccount: account,
+					Items: []db.BatchTransferItemResult{{Transfer: transfer1}},
+					Completed: 1,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res batchTransferResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 1, res.Completed)
+				require.Equal(t, 1, res.Failed)
+				require.Len(t, res.Transfers, 2)
+				require.Equal(t, BATCH_ITEM_COMPLETED, res.Transfers[0].Status)
+				require.Equal(t, BATCH_ITEM_FAILED, res.Transfers[1].Status)
+				require.Equal(t, ERROR_CODE_TRANSFER_DENIED, res.Transfers[1].Error.Code)
+				require.Equal(t, recipient2.ID, res.Transfers[1].ToAccountID)
+			},
+		},
+		{
+			name: "Cooling Off Best Effort",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "best_effort": true, "transfers": []gin.H{
+				{"to_account_id": recipient1.ID, "amount": "10"},
+				{"to_account_id": recipient2.ID, "amount": "1000"},
+			}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubFraudRules(store)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Eq(db.HasTransferredToParams{Owner: user.Username, ToAccountID: recipient2.ID})).
+				Times(1).
+				Return(false, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Payee{}, sql.ErrNoRows)
+
+				// the large first transfer to recipient2 never reaches the batch
+				batch := bestEffortArg
+				batch.Items = bestEffortArg.Items[:1]
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(batch)).
+				Times(1).
+				Return(db.BatchTransferTxResult{
+					FromAccount: account,
+					Items: []db.BatchTransferItemResult{{Transfer: transfer1}},
+					Completed: 1,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res batchTransferResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 1, res.Completed)
+				require.Equal(t, 1, res.Failed)
+				require.Equal(t, BATCH_ITEM_FAILED, res.Transfers[1].Status)
+				require.Equal(t, ERROR_CODE_PAYEE_COOLING_OFF, res.Transfers[1].Error.Code)
+			},
+		},
+		{
+			name: "Held For Review",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubRecipient2Rule(store, risk.OUTCOME_REVIEW)
+
+				batch := arg
+				batch.Items = []db.BatchTransferItem{
+					arg.Items[0],
+					{ToAccountID: recipient2.ID, Amount: 250, HoldReasons: []string{recipient2Rule(risk.OUTCOME_REVIEW).Name}},
+				}
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(batch)).
+				Times(1).
+				Return(db.BatchTransferTxResult{
+					FromAccount: account,
+					Items: []db.BatchTransferItemResult{
+						{Transfer: transfer1},
+						{HeldTransfer: db.HeldTransfer{ID: 7, FromAccountID: account.ID, ToAccountID: recipient2.ID, Amount: 250, Currency: util.USD, Status: "pending"}, Held: true},
+					},
+					Completed: 1,
+					Held: 1,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res batchTransferResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 1, res.Completed)
+				require.Equal(t, 1, res.Held)
+				require.Equal(t, 0, res.Failed)
+				require.Equal(t, BATCH_ITEM_HELD, res.Transfers[1].Status)
+				require.Nil(t, res.Transfers[1].Transfer)
+				require.Equal(t, int64(7), res.Transfers[1].HeldTransfer.ID)
+			},
+		},
+		{
+			name: "Velocity Within The Batch",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "best_effort": true, "transfers": []gin.H{
+				{"to_account_id": recipient1.ID, "amount": "1"},
+				{"to_account_id": recipient1.ID, "amount": "2"},
+				{"to_account_id": recipient2.ID, "amount": "3"},
+				{"to_account_id": recipient2.ID, "amount": "4"},
+			}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+
+				// no transfer was made before the batch, its own items trip the rule
+				busyAccount := db.FraudRule{
+					ID: 1,
+					Name: "busy account",
+					Kind: risk.RULE_ACCOUNT_VELOCITY,
+					Action: risk.OUTCOME_REVIEW,
+					MaxCount: sql.NullInt32{Int32: 2, Valid: true},
+					WindowSeconds: sql.NullInt32{Int32: 3600, Valid: true},
+				}
+				store.EXPECT().
+				ListEnabledFraudRules(gomock.Any()).
+				Times(4).
+				Return([]db.FraudRule{busyAccount}, nil)
+				store.EXPECT().
+				CountTransfersFromAccountSince(gomock.Any(), gomock.Any()).
+				Times(4).
+				Return(int64(0), nil)
+
+				batch := bestEffortArg
+				batch.Items = []db.BatchTransferItem{
+					{ToAccountID: recipient1.ID, Amount: 100},
+					{ToAccountID: recipient1.ID, Amount: 200},
+					{ToAccountID: recipient2.ID, Amount: 300, HoldReasons: []string{busyAccount.Name}},
+					{ToAccountID: recipient2.ID, Amount: 400, HoldReasons: []string{busyAccount.Name}},
+				}
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(batch)).
+				Times(1).
+				Return(db.BatchTransferTxResult{
+					FromAccount: account,
+					Items: []db.BatchTransferItemResult{
+						{Transfer: db.Transfer{ID: 1}},
+						{Transfer: db.Transfer{ID: 2}},
+						{HeldTransfer: db.HeldTransfer{ID: 3}, Held: true},
+						{HeldTransfer: db.HeldTransfer{ID: 4}, Held: true},
+					},
+					Completed: 2,
+					Held: 2,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res batchTransferResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 2, res.Completed)
+				require.Equal(t, 2, res.Held)
+			},
+		},
+		{
+			name: "Cooling Off Split Within The Batch",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "best_effort": true, "transfers": []gin.H{
+				{"to_account_id": recipient2.ID, "amount": "600"},
+				{"to_account_id": recipient2.ID, "amount": "600"},
+			}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubRecipients(store)
+				stubFraudRules(store)
+				// each item is below the cooling-off amount, together they are not
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Eq(db.HasTransferredToParams{Owner: user.Username, ToAccountID: recipient2.ID})).
+				Times(1).
+				Return(false, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Payee{}, sql.ErrNoRows)
+
+				batch := bestEffortArg
+				batch.Items = []db.BatchTransferItem{{ToAccountID: recipient2.ID, Amount: 60000}}
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Eq(batch)).
+				Times(1).
+				Return(db.BatchTransferTxResult{
+					FromAccount: account,
+					Items: []db.BatchTransferItemResult{{Transfer: db.Transfer{ID: 1}}},
+					Completed: 1,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var res batchTransferResult
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, 1, res.Completed)
+				require.Equal(t, 1, res.Failed)
+				require.Equal(t, ERROR_CODE_PAYEE_COOLING_OFF, res.Transfers[1].Error.Code)
+			},
+		},
+		{
+			name: "Invalid Item",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": []gin.H{
+				{"to_account_id": recipient1.ID, "amount": "10"},
+				{"amount": "10"},
+			}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "transfers[1].to_account_id", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Invalid Item Amount",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": []gin.H{
+				{"to_account_id": recipient1.ID, "amount": "10"},
+				{"to_account_id": recipient2.ID, "amount": "0.001"},
+			}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "transfers[1].amount", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Too Many Transfers",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": tooMany},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "transfers", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Currency Mismatch",
+			body: gin.H{"from_account_id": account.ID, "currency": util.EUR, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_CURRENCY_MISMATCH)
+			},
+		},
+		{
+			name: "Unauthorized User",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Read Only Scope",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addScopedAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "No Authorization",
+			body: gin.H{"from_account_id": account.ID, "currency": util.USD, "transfers": transfers},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				BatchTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfers/batch", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
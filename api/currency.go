@@ -0,0 +1,131 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+type currencyResponse struct {
+	Code string `json:"code"`
+	MinorUnits int `json:"minor_units"`
+	IsEnabled bool `json:"is_enabled"`
+}
+
+func newCurrencyResponse(currency util.Currency) currencyResponse {
+	return currencyResponse{
+		Code: currency.Code,
+		MinorUnits: currency.MinorUnits,
+		IsEnabled: currency.IsEnabled,
+	}
+}
+
+// listCurrencies tells clients which currencies accounts and transfers can use, it is served from the registry
+func (server *Server) listCurrencies(ctx *gin.Context) {
+	res := []currencyResponse{}
+	for _, currency := range util.Currencies.All() {
+		if currency.IsEnabled {
+			res = append(res, newCurrencyResponse(currency))
+		}
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type adminCurrencyResponse struct {
+	currencyResponse
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAdminCurrencyResponse(currency db.Currency) adminCurrencyResponse {
+	return adminCurrencyResponse{
+		currencyResponse: newCurrencyResponse(db.NewRegistryCurrency(currency)),
+		CreatedAt: currency.CreatedAt,
+	}
+}
+
+// adminListCurrencies lists every currency of the database, including the disabled ones
+func (server *Server) adminListCurrencies(ctx *gin.Context) {
+	currencies, err := server.store.ListCurrencies(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]adminCurrencyResponse, len(currencies))
+	for i, currency := range currencies {
+		res[i] = newAdminCurrencyResponse(currency)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type createCurrencyRequest struct {
+	Code string `json:"code" binding:"required,len=3,alpha,uppercase"`
+	MinorUnits *int32 `json:"minor_units" binding:"required,min=0,max=4"`
+	IsEnabled bool `json:"is_enabled"`
+}
+
+func (server *Server) createCurrency(ctx *gin.Context) {
+	var req createCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	arg := db.CreateCurrencyParams{
+		Code: req.Code,
+		MinorUnits: *req.MinorUnits,
+		IsEnabled: req.IsEnabled,
+	}
+
+	currency, err := server.store.CreateCurrency(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	// the other instances pick the currency up on their next refresh
+	util.Currencies.Put(db.NewRegistryCurrency(currency))
+	ctx.JSON(http.StatusCreated, newAdminCurrencyResponse(currency))
+}
+
+type updateCurrencyURI struct {
+	Code string `uri:"code" binding:"required,len=3"`
+}
+
+// updateCurrencyRequest cannot change the minor units, the amounts already stored depend on them
+type updateCurrencyRequest struct {
+	IsEnabled *bool `json:"is_enabled" binding:"required"`
+}
+
+func (server *Server) updateCurrency(ctx *gin.Context) {
+	var uri updateCurrencyURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req updateCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	arg := db.UpdateCurrencyParams{
+		Code: uri.Code,
+		IsEnabled: *req.IsEnabled,
+	}
+
+	currency, err := server.store.UpdateCurrency(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	util.Currencies.Put(db.NewRegistryCurrency(currency))
+	ctx.JSON(http.StatusOK, newAdminCurrencyResponse(currency))
+}
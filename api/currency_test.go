@@ -0,0 +1,249 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+// randomAdmin returns a user with the admin role, which must be loaded with stubAdminUser
+func randomAdmin(t *testing.T) db.User {
+	admin, _ := randomUser(t)
+	admin.Role = util.ROLE_ADMIN
+	admin.IsEmailVerified = true
+	return admin
+}
+
+func stubAdminUser(store *testdb.MockStore, admin db.User) {
+	store.EXPECT().
+	GetUser(gomock.Any(), gomock.Eq(admin.Username)).
+	AnyTimes().
+	Return(admin, nil)
+}
+
+// restoreCurrencies puts back the currencies of the registry once the test changed them
+func restoreCurrencies(t *testing.T) {
+	currencies := util.Currencies.All()
+	t.Cleanup(func() {
+		util.Currencies.Load(currencies)
+	})
+}
+
+func TestListCurrenciesAPI(t *testing.T) {
+	restoreCurrencies(t)
+	util.Currencies.Load([]util.Currency{
+		{Code: util.USD, MinorUnits: 2, IsEnabled: true},
+		{Code: util.EUR, MinorUnits: 2, IsEnabled: false},
+	})
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewTestServer(t, testdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/currencies", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []currencyResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, []currencyResponse{{Code: util.USD, MinorUnits: 2, IsEnabled: true}}, got)
+}
+
+func TestCreateCurrencyAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+	currency := db.Currency{Code: "JPY", MinorUnits: 0, IsEnabled: true, CreatedAt: time.Now()}
+
+	testCases := []struct {
+		name string
+		body gin.H
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"code": currency.Code, "minor_units": currency.MinorUnits, "is_enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.CreateCurrencyParams{Code: currency.Code, MinorUnits: currency.MinorUnits, IsEnabled: true}
+				store.EXPECT().
+				CreateCurrency(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(currency, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got adminCurrencyResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, currency.Code, got.Code)
+
+				// the registry serves the new currency right away
+				require.True(t, util.IsSupportedCurrency(currency.Code))
+			},
+		},
+		{
+			name: "Not Admin",
+			body: gin.H{"code": currency.Code, "minor_units": currency.MinorUnits, "is_enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateCurrency(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Admin Scope Not Held",
+			body: gin.H{"code": currency.Code, "minor_units": currency.MinorUnits, "is_enabled": true},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addScopedAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, token.READ_ONLY_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateCurrency(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Invalid Code",
+			body: gin.H{"code": "jp", "minor_units": 0},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateCurrency(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Missing Minor Units",
+			body: gin.H{"code": currency.Code},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateCurrency(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Duplicate",
+			body: gin.H{"code": util.USD, "minor_units": 2},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateCurrency(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Currency{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_ALREADY_EXISTS)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			restoreCurrencies(t)
+
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/currencies", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateCurrencyAPI(t *testing.T) {
+	restoreCurrencies(t)
+
+	admin := randomAdmin(t)
+	disabled := db.Currency{Code: util.EUR, MinorUnits: 2, IsEnabled: false, CreatedAt: time.Now()}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	UpdateCurrency(gomock.Any(), gomock.Eq(db.UpdateCurrencyParams{Code: util.EUR, IsEnabled: false})).
+	Times(1).
+	Return(disabled, nil)
+	stubAdminUser(store, admin)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/admin/currencies/%s", util.EUR), bytes.NewBufferString(`{"is_enabled": false}`))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	// new accounts can no longer be opened in the currency
+	require.False(t, util.IsSupportedCurrency(util.EUR))
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodPost, "/account", bytes.NewBufferString(`{"currency": "EUR"}`))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+}
@@ -0,0 +1,246 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+)
+
+// stable error codes, clients must rely on them rather than on the messages
+const (
+	ERROR_CODE_INVALID_REQUEST = "invalid_request"
+	ERROR_CODE_VALIDATION_FAILED = "validation_failed"
+	ERROR_CODE_UNAUTHENTICATED = "unauthenticated"
+	ERROR_CODE_PERMISSION_DENIED = "permission_denied"
+	ERROR_CODE_EMAIL_NOT_VERIFIED = "email_not_verified"
+	ERROR_CODE_NOT_FOUND = "not_found"
+	ERROR_CODE_ALREADY_EXISTS = "already_exists"
+	ERROR_CODE_INVALID_REFERENCE = "invalid_reference"
+	ERROR_CODE_CONFLICT = "conflict"
+	ERROR_CODE_INSUFFICIENT_FUNDS = "insufficient_funds"
+	ERROR_CODE_CURRENCY_MISMATCH = "currency_mismatch"
+	ERROR_CODE_NO_EXCHANGE_RATE = "no_exchange_rate"
+	ERROR_CODE_AMOUNT_TOO_SMALL = "amount_too_small"
+	ERROR_CODE_TRANSFER_DENIED = "transfer_denied"
+	ERROR_CODE_LIMIT_EXCEEDED = "limit_exceeded"
+	ERROR_CODE_PAYEE_COOLING_OFF = "payee_cooling_off"
+	ERROR_CODE_NO_RECIPIENT_ACCOUNT = "no_recipient_account"
+	ERROR_CODE_INTERNAL = "internal"
+)
+
+const (
+	PROBLEM_CONTENT_TYPE = "application/problem+json"
+	PROBLEM_TYPE_PREFIX = "urn:simplebank:error:"
+)
+
+// ApiError is the body of every error response, a RFC 7807 problem details object
+// extended with a machine-readable code and the id of the request
+type ApiError struct {
+	Type string `json:"type"`
+	Title string `json:"title"`
+	Status int `json:"status"`
+	Code string `json:"code"`
+	Message string `json:"message"`
+	Details []ErrorDetail `json:"details,omitempty"`
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// ErrorDetail points at a field of the request which failed validation
+type ErrorDetail struct {
+	Field string `json:"field"`
+	Rule string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (apiErr *ApiError) Error() string {
+	return apiErr.Message
+}
+
+func newApiError(status int, code string, message string) *ApiError {
+	return &ApiError{
+		Type: PROBLEM_TYPE_PREFIX + code,
+		Title: http.StatusText(status),
+		Status: status,
+		Code: code,
+		Message: message,
+	}
+}
+
+var (
+	errInternal = newApiError(http.StatusInternalServerError, ERROR_CODE_INTERNAL, "internal server error")
+	errNotFound = newApiError(http.StatusNotFound, ERROR_CODE_NOT_FOUND, "the resource does not exist")
+	errAlreadyExists = newApiError(http.StatusConflict, ERROR_CODE_ALREADY_EXISTS, "the resource already exists")
+	errInvalidReference = newApiError(http.StatusUnprocessableEntity, ERROR_CODE_INVALID_REFERENCE, "a resource referenced by the request does not exist")
+	errInsufficientFunds = newApiError(http.StatusUnprocessableEntity, ERROR_CODE_INSUFFICIENT_FUNDS, "the balance of the account is insufficient")
+	// errTransferDenied does not tell which rule denied the transfer, so that the rules cannot be probed
+	errTransferDenied = newApiError(http.StatusForbidden, ERROR_CODE_TRANSFER_DENIED, "the transfer was denied by the fraud screening")
+)
+
+// toApiError maps the errors of the handlers and of the store to the errors clients see.
+// This is the only place where store errors are translated, unknown errors become internal errors
+// so that driver messages never reach the clients.
+func toApiError(err error) *ApiError {
+	// the batch is checked first, its item errors may wrap api errors
+	var itemErr *db.BatchItemError
+	if errors.As(err, &itemErr) {
+		problem := *toApiError(itemErr.Err)
+		problem.Message = fmt.Sprintf("transfer %d of the batch failed: %s", itemErr.Index, problem.Message)
+		return &problem
+	}
+
+	var apiErr *ApiError
+	if errors.As(err, &apiErr) {
+		return apiErr
+	}
+
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return errNotFound
+	case errors.Is(err, db.ErrInsufficientFunds):
+		return errInsufficientFunds
+	case errors.Is(err, db.ErrCurrencyMismatch):
+		return newApiError(http.StatusBadRequest, ERROR_CODE_CURRENCY_MISMATCH, db.ErrCurrencyMismatch.Error())
+	case errors.Is(err, db.ErrSameAccount):
+		return newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, db.ErrSameAccount.Error())
+	case errors.Is(err, db.ErrHoldNotActive):
+		return newApiError(http.StatusConflict, ERROR_CODE_CONFLICT, db.ErrHoldNotActive.Error())
+	case errors.Is(err, db.ErrPaymentRequestNotPending):
+		return newApiError(http.StatusConflict, ERROR_CODE_CONFLICT, db.ErrPaymentRequestNotPending.Error())
+	case errors.Is(err, db.ErrCaptureExceedsHold):
+		return newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, db.ErrCaptureExceedsHold.Error())
+	case errors.Is(err, db.ErrHeldTransferReviewed):
+		return newApiError(http.StatusConflict, ERROR_CODE_CONFLICT, db.ErrHeldTransferReviewed.Error())
+	case errors.Is(err, db.ErrAccountClosed):
+		return newApiError(http.StatusConflict, ERROR_CODE_CONFLICT, db.ErrAccountClosed.Error())
+	case errors.Is(err, db.ErrAccountsNotEmpty):
+		return newApiError(http.StatusConflict, ERROR_CODE_CONFLICT, db.ErrAccountsNotEmpty.Error())
+	case errors.Is(err, db.ErrNoExchangeRate):
+		return newApiError(http.StatusUnprocessableEntity, ERROR_CODE_NO_EXCHANGE_RATE, db.ErrNoExchangeRate.Error())
+	case errors.Is(err, db.ErrConversionTooSmall):
+		return newApiError(http.StatusUnprocessableEntity, ERROR_CODE_AMOUNT_TOO_SMALL, db.ErrConversionTooSmall.Error())
+	}
+
+	var limitErr *db.TransferLimitError
+	if errors.As(err, &limitErr) {
+		remaining := util.NewMoney(limitErr.Remaining, limitErr.Currency)
+		message := fmt.Sprintf("%s, %s %s remaining", limitErr.Error(), remaining, limitErr.Currency)
+		if limitErr.Limit == db.LIMIT_PER_TRANSACTION {
+			message = fmt.Sprintf("%s of %s %s", limitErr.Error(), remaining, limitErr.Currency)
+		}
+		return newApiError(http.StatusUnprocessableEntity, ERROR_CODE_LIMIT_EXCEEDED, message)
+	}
+
+	var coolingOffErr *risk.CoolingOffError
+	if errors.As(err, &coolingOffErr) {
+		return newApiError(http.StatusUnprocessableEntity, ERROR_CODE_PAYEE_COOLING_OFF, coolingOffErr.Error())
+	}
+
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) {
+		switch pqErr.Code.Name() {
+		case "unique_violation":
+			return errAlreadyExists
+		case "foreign_key_violation":
+			return errInvalidReference
+		}
+	}
+
+	return errInternal
+}
+
+// bindingError describes why the request could not be bound, with a detail per invalid field
+func bindingError(err error) *ApiError {
+	var validationErrs validator.ValidationErrors
+	if errors.As(err, &validationErrs) {
+		apiErr := newApiError(http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED, "the request has invalid fields")
+		for _, fieldErr := range validationErrs {
+			apiErr.Details = append(apiErr.Details, ErrorDetail{
+				Field: fieldPath(fieldErr),
+				Rule: fieldErr.Tag(),
+				Message: validationMessage(fieldErr),
+			})
+		}
+		return apiErr
+	}
+
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &typeErr) {
+		return fieldError(typeErr.Field, "type", fmt.Sprintf("must be a %s", typeErr.Type.Kind()))
+	}
+
+	if errors.Is(err, io.EOF) {
+		return newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, "the request body is empty")
+	}
+
+	return newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, err.Error())
+}
+
+// fieldPath names the field from the request root, "transfers[2].amount", so that the items of lists can be told apart
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return fieldErr.Field()
+}
+
+// fieldError reports a single invalid field, for the checks which cannot be expressed as binding tags
+func fieldError(field string, rule string, message string) *ApiError {
+	apiErr := newApiError(http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED, "the request has invalid fields")
+	apiErr.Details = []ErrorDetail{{Field: field, Rule: rule, Message: message}}
+	return apiErr
+}
+
+func validationMessage(fieldErr validator.FieldError) string {
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min":
+		return fmt.Sprintf("must be at least %s", fieldErr.Param())
+	case "max":
+		return fmt.Sprintf("must be at most %s", fieldErr.Param())
+	case "len":
+		return fmt.Sprintf("must have a length of %s", fieldErr.Param())
+	case "eq":
+		return fmt.Sprintf("must be %s", fieldErr.Param())
+	case "oneof":
+		return fmt.Sprintf("must be one of %s", strings.Join(strings.Fields(fieldErr.Param()), ", "))
+	case "datetime":
+		return fmt.Sprintf("must be formatted as %s", fieldErr.Param())
+	case "email":
+		return "must be a valid email address"
+	case "url":
+		return "must be a valid url"
+	case "currency":
+		return "is not a supported currency"
+	case "scope", "oauth_scope":
+		return "is not a supported scope"
+	}
+	return fmt.Sprintf("does not satisfy the %s rule", fieldErr.Tag())
+}
+
+// abortWithError writes err as problem details and stops the handler chain.
+// The cause of internal errors is only logged, along with the request id returned to the client.
+func abortWithError(ctx *gin.Context, err error) {
+	problem := *toApiError(err)
+	problem.RequestID = ctx.GetString(REQUEST_ID)
+
+	if problem.Status >= http.StatusInternalServerError {
+		log.Printf("request %s failed: %v", problem.RequestID, err)
+	}
+
+	ctx.Header("Content-Type", PROBLEM_CONTENT_TYPE)
+	ctx.AbortWithStatusJSON(problem.Status, problem)
+}
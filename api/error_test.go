@@ -0,0 +1,188 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/stretchr/testify/require"
+)
+
+// requireApiError checks that the response is problem details with the status and the code
+func requireApiError(t *testing.T, recorder *httptest.ResponseRecorder, status int, code string) ApiError {
+	require.Equal(t, status, recorder.Code)
+	require.Equal(t, PROBLEM_CONTENT_TYPE, recorder.Header().Get("Content-Type"))
+
+	var apiErr ApiError
+	err := json.Unmarshal(recorder.Body.Bytes(), &apiErr)
+	require.NoError(t, err)
+	require.Equal(t, status, apiErr.Status)
+	require.Equal(t, code, apiErr.Code)
+	require.Equal(t, PROBLEM_TYPE_PREFIX+code, apiErr.Type)
+	require.NotEmpty(t, apiErr.Message)
+	require.Equal(t, recorder.Header().Get(REQUEST_ID_HEADER), apiErr.RequestID)
+
+	return apiErr
+}
+
+func TestToApiError(t *testing.T) {
+	testCases := []struct {
+		name string
+		err error
+		status int
+		code string
+	}{
+		{
+			name: "No Rows",
+			err: sql.ErrNoRows,
+			status: http.StatusNotFound,
+			code: ERROR_CODE_NOT_FOUND,
+		},
+		{
+			name: "Wrapped No Rows",
+			err: fmt.Errorf("cannot get account: %w", sql.ErrNoRows),
+			status: http.StatusNotFound,
+			code: ERROR_CODE_NOT_FOUND,
+		},
+		{
+			name: "Unique Violation",
+			err: &pq.Error{Code: "23505"},
+			status: http.StatusConflict,
+			code: ERROR_CODE_ALREADY_EXISTS,
+		},
+		{
+			name: "Foreign Key Violation",
+			err: &pq.Error{Code: "23503"},
+			status: http.StatusUnprocessableEntity,
+			code: ERROR_CODE_INVALID_REFERENCE,
+		},
+		{
+			name: "Insufficient Funds",
+			err: db.ErrInsufficientFunds,
+			status: http.StatusUnprocessableEntity,
+			code: ERROR_CODE_INSUFFICIENT_FUNDS,
+		},
+		{
+			name: "Accounts Not Empty",
+			err: db.ErrAccountsNotEmpty,
+			status: http.StatusConflict,
+			code: ERROR_CODE_CONFLICT,
+		},
+		{
+			name: "Api Error",
+			err: errEmailNotVerified,
+			status: http.StatusForbidden,
+			code: ERROR_CODE_EMAIL_NOT_VERIFIED,
+		},
+		{
+			name: "Unknown",
+			err: &pq.Error{Code: "08006", Message: "connection failure"},
+			status: http.StatusInternalServerError,
+			code: ERROR_CODE_INTERNAL,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			apiErr := toApiError(tc.err)
+			require.Equal(t, tc.status, apiErr.Status)
+			require.Equal(t, tc.code, apiErr.Code)
+			require.Equal(t, http.StatusText(tc.status), apiErr.Title)
+		})
+	}
+}
+
+func TestInternalErrorIsNotLeaked(t *testing.T) {
+	apiErr := toApiError(errors.New("pq: password authentication failed for user \"root\""))
+	require.Equal(t, ERROR_CODE_INTERNAL, apiErr.Code)
+	require.NotContains(t, apiErr.Message, "pq")
+}
+
+func TestBindingErrorAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().CreateUserTx(gomock.Any(), gomock.Any()).Times(0)
+
+	server := NewTestServer(t, store)
+
+	testCases := []struct {
+		name string
+		body string
+		checkResponse func(apiErr ApiError)
+	}{
+		{
+			name: "Invalid Fields",
+			body: `{"username": "user@example.com", "password": "short", "full_name": "User", "email": "not an email"}`,
+			checkResponse: func(apiErr ApiError) {
+				require.ElementsMatch(t, []ErrorDetail{
+					{Field: "password", Rule: "min", Message: "must be at least 8"},
+					{Field: "email", Rule: "email", Message: "must be a valid email address"},
+				}, apiErr.Details)
+			},
+		},
+		{
+			name: "Invalid Type",
+			body: `{"username": 1}`,
+			checkResponse: func(apiErr ApiError) {
+				require.Len(t, apiErr.Details, 1)
+				require.Equal(t, "username", apiErr.Details[0].Field)
+				require.Equal(t, "type", apiErr.Details[0].Rule)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/user", bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+			request.Header.Set(REQUEST_ID_HEADER, "test-request-id")
+
+			server.router.ServeHTTP(recorder, request)
+			apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			require.Equal(t, "test-request-id", apiErr.RequestID)
+			tc.checkResponse(apiErr)
+		})
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	router := gin.New()
+	router.Use(requestIDMiddleware())
+	router.GET("/", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, ctx.GetString(REQUEST_ID))
+	})
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, "/", nil)
+	require.NoError(t, err)
+
+	router.ServeHTTP(recorder, request)
+	generated := recorder.Header().Get(REQUEST_ID_HEADER)
+	require.NotEmpty(t, generated)
+	require.Equal(t, generated, recorder.Body.String())
+
+	recorder = httptest.NewRecorder()
+	request.Header.Set(REQUEST_ID_HEADER, "upstream-id")
+
+	router.ServeHTTP(recorder, request)
+	require.Equal(t, "upstream-id", recorder.Header().Get(REQUEST_ID_HEADER))
+	require.Equal(t, "upstream-id", recorder.Body.String())
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+// listExchangeRates lets clients preview the rates applied to the conversions within wallets
+func (server *Server) listExchangeRates(ctx *gin.Context) {
+	rates, err := server.store.ListExchangeRates(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+type setExchangeRateURI struct {
+	FromCurrency string `uri:"from_currency" binding:"required,currency"`
+	ToCurrency string `uri:"to_currency" binding:"required,currency"`
+}
+
+type setExchangeRateRequest struct {
+	// Rate is the price of one major unit of from_currency in major units of to_currency, "0.9215"
+	Rate string `json:"rate" binding:"required"`
+}
+
+// setExchangeRate creates or replaces a rate, conversions already made keep the rate they applied
+func (server *Server) setExchangeRate(ctx *gin.Context) {
+	var uri setExchangeRateURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req setExchangeRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if uri.FromCurrency == uri.ToCurrency {
+		abortWithError(ctx, fieldError("to_currency", "nefield", "must differ from from_currency"))
+		return
+	}
+
+	if _, err := util.ParseRate(req.Rate); err != nil {
+		abortWithError(ctx, fieldError("rate", "rate", err.Error()))
+		return
+	}
+
+	arg := db.UpsertExchangeRateParams{
+		FromCurrency: uri.FromCurrency,
+		ToCurrency: uri.ToCurrency,
+		Rate: req.Rate,
+	}
+
+	rate, err := server.store.UpsertExchangeRate(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rate)
+}
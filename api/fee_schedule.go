@@ -0,0 +1,227 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+type feeTierResponse struct {
+	MinAmount util.Money `json:"min_amount"`
+	Flat util.Money `json:"flat"`
+	Rate string `json:"rate,omitempty"`
+}
+
+type feeScheduleResponse struct {
+	TransferType string `json:"transfer_type"`
+	Currency string `json:"currency"`
+	Tiers []feeTierResponse `json:"tiers"`
+	MinFee *util.Money `json:"min_fee,omitempty"`
+	MaxFee *util.Money `json:"max_fee,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newFeeScheduleResponse(schedule db.FeeSchedule) (feeScheduleResponse, error) {
+	var tiers []util.FeeTier
+	if err := json.Unmarshal(schedule.Tiers, &tiers); err != nil {
+		return feeScheduleResponse{}, err
+	}
+
+	res := feeScheduleResponse{
+		TransferType: schedule.TransferType,
+		Currency: schedule.Currency,
+		Tiers: make([]feeTierResponse, len(tiers)),
+		MinFee: optionalMoney(schedule.MinFee, schedule.Currency),
+		MaxFee: optionalMoney(schedule.MaxFee, schedule.Currency),
+		UpdatedAt: schedule.UpdatedAt,
+	}
+
+	for i, tier := range tiers {
+		res.Tiers[i] = feeTierResponse{
+			MinAmount: util.NewMoney(tier.MinAmount, schedule.Currency),
+			Flat: util.NewMoney(tier.Flat, schedule.Currency),
+			Rate: tier.Rate,
+		}
+	}
+
+	return res, nil
+}
+
+// listFeeSchedules shows the fees of every type of transfer, a type and currency without schedule is free
+func (server *Server) listFeeSchedules(ctx *gin.Context) {
+	schedules, err := server.store.ListFeeSchedules(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]feeScheduleResponse, len(schedules))
+	for i, schedule := range schedules {
+		res[i], err = newFeeScheduleResponse(schedule)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type feeScheduleURI struct {
+	TransferType string `uri:"transfer_type" binding:"required,oneof=transfer batch capture"`
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+type feeTierRequest struct {
+	MinAmount string `json:"min_amount" binding:"required,money"`
+	Flat string `json:"flat" binding:"omitempty,money"`
+	// Rate is the fraction of the amount charged on top of the flat fee, "0.015" for 1.5%
+	Rate string `json:"rate"`
+}
+
+// feeScheduleRequest replaces the whole schedule, the first tier starts at 0 and every next one at a higher amount
+type feeScheduleRequest struct {
+	Tiers []feeTierRequest `json:"tiers" binding:"required,min=1,max=20,dive"`
+	MinFee string `json:"min_fee" binding:"omitempty,money"`
+	MaxFee string `json:"max_fee" binding:"omitempty,money"`
+}
+
+// parseFee reads an amount of a fee schedule, which unlike the amount of a transfer may be 0
+func parseFee(field string, value string, currency string) (int64, error) {
+	if len(value) == 0 {
+		return 0, nil
+	}
+
+	amount, err := util.ParseMoney(value, currency)
+	if err != nil {
+		return 0, fieldError(field, "money", err.Error())
+	}
+	return amount.Amount(), nil
+}
+
+// parseFeeSchedule reads the schedule in the currency and checks that its tiers cover every amount once
+func parseFeeSchedule(req feeScheduleRequest, uri feeScheduleURI) (db.UpsertFeeScheduleParams, error) {
+	arg := db.UpsertFeeScheduleParams{
+		TransferType: uri.TransferType,
+		Currency: uri.Currency,
+	}
+
+	tiers := make([]util.FeeTier, len(req.Tiers))
+	for i, tier := range req.Tiers {
+		minAmount, err := parseFee(fmt.Sprintf("tiers[%d].min_amount", i), tier.MinAmount, uri.Currency)
+		if err != nil {
+			return arg, err
+		}
+
+		flat, err := parseFee(fmt.Sprintf("tiers[%d].flat", i), tier.Flat, uri.Currency)
+		if err != nil {
+			return arg, err
+		}
+
+		if len(tier.Rate) > 0 {
+			if _, err := util.ParseFeeRate(tier.Rate); err != nil {
+				return arg, fieldError(fmt.Sprintf("tiers[%d].rate", i), "rate", err.Error())
+			}
+		}
+
+		tiers[i] = util.FeeTier{MinAmount: minAmount, Flat: flat, Rate: tier.Rate}
+	}
+
+	if err := util.ValidateFeeTiers(tiers); err != nil {
+		return arg, fieldError("tiers", "tiers", err.Error())
+	}
+
+	for _, field := range []struct {
+		name string
+		value string
+		amount *sql.NullInt64
+	}{
+		{"min_fee", req.MinFee, &arg.MinFee},
+		{"max_fee", req.MaxFee, &arg.MaxFee},
+	} {
+		if len(field.value) == 0 {
+			continue
+		}
+
+		amount, err := parseFee(field.name, field.value, uri.Currency)
+		if err != nil {
+			return arg, err
+		}
+		*field.amount = sql.NullInt64{Int64: amount, Valid: true}
+	}
+
+	if arg.MinFee.Valid && arg.MaxFee.Valid && arg.MaxFee.Int64 < arg.MinFee.Int64 {
+		return arg, fieldError("max_fee", "gtefield", "cannot be lower than min_fee")
+	}
+
+	var err error
+	arg.Tiers, err = json.Marshal(tiers)
+	return arg, err
+}
+
+// setFeeSchedule replaces the fees of a type of transfer in a currency, from the next transfer on
+func (server *Server) setFeeSchedule(ctx *gin.Context) {
+	var uri feeScheduleURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req feeScheduleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	arg, err := parseFeeSchedule(req, uri)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	schedule, err := server.store.UpsertFeeSchedule(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res, err := newFeeScheduleResponse(schedule)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// deleteFeeSchedule makes a type of transfer in a currency free again
+func (server *Server) deleteFeeSchedule(ctx *gin.Context) {
+	var uri feeScheduleURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	schedule, err := server.store.DeleteFeeSchedule(ctx, db.DeleteFeeScheduleParams{
+		TransferType: uri.TransferType,
+		Currency: uri.Currency,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res, err := newFeeScheduleResponse(schedule)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,244 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListFeeSchedulesAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	ListFeeSchedules(gomock.Any()).
+	Times(1).
+	Return([]db.FeeSchedule{{
+		TransferType: db.TRANSFER_TYPE_TRANSFER,
+		Currency: util.USD,
+		Tiers: json.RawMessage(`[{"min_amount":0,"flat":25},{"min_amount":10000,"flat":0,"rate":"0.01"}]`),
+		MaxFee: sql.NullInt64{Int64: 500, Valid: true},
+	}}, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/fee_schedules", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, []interface{}{
+		map[string]interface{}{"min_amount": "0.00", "flat": "0.25"},
+		map[string]interface{}{"min_amount": "100.00", "flat": "0.00", "rate": "0.01"},
+	}, got[0]["tiers"])
+	require.NotContains(t, got[0], "min_fee")
+	require.Equal(t, "5.00", got[0]["max_fee"])
+}
+
+func TestSetFeeScheduleAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+
+	validTiers := []gin.H{
+		{"min_amount": "0", "flat": "0.25"},
+		{"min_amount": "100", "rate": "0.01"},
+	}
+
+	testCases := []struct {
+		name string
+		path string
+		body gin.H
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			path: "/admin/fee_schedules/transfer/USD",
+			body: gin.H{"tiers": validTiers, "min_fee": "0.10", "max_fee": "5"},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.UpsertFeeScheduleParams{
+					TransferType: db.TRANSFER_TYPE_TRANSFER,
+					Currency: util.USD,
+					Tiers: json.RawMessage(`[{"min_amount":0,"flat":25},{"min_amount":10000,"flat":0,"rate":"0.01"}]`),
+					MinFee: sql.NullInt64{Int64: 10, Valid: true},
+					MaxFee: sql.NullInt64{Int64: 500, Valid: true},
+				}
+				store.EXPECT().
+				UpsertFeeSchedule(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.FeeSchedule{
+					TransferType: arg.TransferType,
+					Currency: arg.Currency,
+					Tiers: arg.Tiers,
+					MinFee: arg.MinFee,
+					MaxFee: arg.MaxFee,
+				}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.TRANSFER_TYPE_TRANSFER, got["transfer_type"])
+				require.Len(t, got["tiers"], 2)
+				require.Equal(t, "0.10", got["min_fee"])
+			},
+		},
+		{
+			name: "First Tier Above Zero",
+			path: "/admin/fee_schedules/batch/USD",
+			body: gin.H{"tiers": []gin.H{{"min_amount": "1", "flat": "0.25"}}},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertFeeSchedule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Tiers Out Of Order",
+			path: "/admin/fee_schedules/batch/USD",
+			body: gin.H{"tiers": []gin.H{{"min_amount": "0"}, {"min_amount": "100"}, {"min_amount": "50"}}},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertFeeSchedule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Rate Above One",
+			path: "/admin/fee_schedules/capture/USD",
+			body: gin.H{"tiers": []gin.H{{"min_amount": "0", "rate": "1.5"}}},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertFeeSchedule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Max Below Min",
+			path: "/admin/fee_schedules/transfer/USD",
+			body: gin.H{"tiers": validTiers, "min_fee": "5", "max_fee": "1"},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertFeeSchedule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Invalid Transfer Type",
+			path: "/admin/fee_schedules/conversion/USD",
+			body: gin.H{"tiers": validTiers},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertFeeSchedule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Not Admin",
+			path: "/admin/fee_schedules/transfer/USD",
+			body: gin.H{"tiers": validTiers},
+			username: user.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertFeeSchedule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, tc.path, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestDeleteFeeScheduleAPI(t *testing.T) {
+	admin := randomAdmin(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	arg := db.DeleteFeeScheduleParams{TransferType: db.TRANSFER_TYPE_BATCH, Currency: util.EUR}
+	store.EXPECT().
+	DeleteFeeSchedule(gomock.Any(), gomock.Eq(arg)).
+	Times(1).
+	Return(db.FeeSchedule{}, sql.ErrNoRows)
+	stubAdminUser(store, admin)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodDelete, "/admin/fee_schedules/batch/EUR", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+}
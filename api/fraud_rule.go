@@ -0,0 +1,179 @@
+package api
+
+import (
+	"database/sql"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+)
+
+type fraudRuleResponse struct {
+	ID int64 `json:"id"`
+	Name string `json:"name"`
+	Kind string `json:"kind"`
+	Action string `json:"action"`
+	Currency string `json:"currency,omitempty"`
+	MinAmount *util.Money `json:"min_amount,omitempty"`
+	MaxCount int32 `json:"max_count,omitempty"`
+	WindowSeconds int32 `json:"window_seconds,omitempty"`
+	IsEnabled bool `json:"is_enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newFraudRuleResponse(rule db.FraudRule) fraudRuleResponse {
+	res := fraudRuleResponse{
+		ID: rule.ID,
+		Name: rule.Name,
+		Kind: rule.Kind,
+		Action: rule.Action,
+		Currency: rule.Currency.String,
+		MaxCount: rule.MaxCount.Int32,
+		WindowSeconds: rule.WindowSeconds.Int32,
+		IsEnabled: rule.IsEnabled,
+		CreatedAt: rule.CreatedAt,
+		UpdatedAt: rule.UpdatedAt,
+	}
+	if rule.MinAmount.Valid {
+		minAmount := util.NewMoney(rule.MinAmount.Int64, rule.Currency.String)
+		res.MinAmount = &minAmount
+	}
+	return res
+}
+
+func (server *Server) listFraudRules(ctx *gin.Context) {
+	rules, err := server.store.ListFraudRules(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]fraudRuleResponse, len(rules))
+	for i, rule := range rules {
+		res[i] = newFraudRuleResponse(rule)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// fraudRuleRequest describes a whole rule, min_amount and currency go together and restrict any kind of rule
+// to the large transfers of the currency, the kinds which count transfers need max_count and window_seconds,
+// a window of at most 31 days
+type fraudRuleRequest struct {
+	Name string `json:"name" binding:"required,max=100"`
+	Kind string `json:"kind" binding:"required,oneof=amount account_velocity user_velocity new_recipient repeated_transfer"`
+	Action string `json:"action" binding:"required,oneof=review deny"`
+	Currency string `json:"currency" binding:"omitempty,currency"`
+	MinAmount string `json:"min_amount" binding:"omitempty,money"`
+	MaxCount int32 `json:"max_count" binding:"omitempty,min=1"`
+	WindowSeconds int32 `json:"window_seconds" binding:"omitempty,min=1,max=2678400"`
+	IsEnabled *bool `json:"is_enabled" binding:"required"`
+}
+
+// fraudRuleParams checks that the fields of the request make sense for its kind
+func fraudRuleParams(req fraudRuleRequest) (db.CreateFraudRuleParams, error) {
+	arg := db.CreateFraudRuleParams{
+		Name: req.Name,
+		Kind: req.Kind,
+		Action: req.Action,
+		IsEnabled: *req.IsEnabled,
+	}
+
+	if (len(req.Currency) == 0) != (len(req.MinAmount) == 0) {
+		return arg, fieldError("min_amount", "required_with", "must be set along with currency")
+	}
+	if req.Kind == risk.RULE_AMOUNT && len(req.MinAmount) == 0 {
+		return arg, fieldError("min_amount", "required", "is required for amount rules")
+	}
+
+	if len(req.MinAmount) > 0 {
+		minAmount, err := parseAmount("min_amount", req.MinAmount, req.Currency)
+		if err != nil {
+			return arg, err
+		}
+		arg.Currency = sql.NullString{String: req.Currency, Valid: true}
+		arg.MinAmount = sql.NullInt64{Int64: minAmount.Amount(), Valid: true}
+	}
+
+	hasWindow := req.MaxCount > 0 && req.WindowSeconds > 0
+	switch {
+	case risk.HasWindow(req.Kind) && !hasWindow:
+		return arg, fieldError("max_count", "required", "max_count and window_seconds are required for rules which count transfers")
+	case !risk.HasWindow(req.Kind) && (req.MaxCount > 0 || req.WindowSeconds > 0):
+		return arg, fieldError("max_count", "excluded", "max_count and window_seconds only apply to rules which count transfers")
+	case hasWindow:
+		arg.MaxCount = sql.NullInt32{Int32: req.MaxCount, Valid: true}
+		arg.WindowSeconds = sql.NullInt32{Int32: req.WindowSeconds, Valid: true}
+	}
+
+	return arg, nil
+}
+
+func (server *Server) createFraudRule(ctx *gin.Context) {
+	var req fraudRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	arg, err := fraudRuleParams(req)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rule, err := server.store.CreateFraudRule(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newFraudRuleResponse(rule))
+}
+
+type fraudRuleURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// updateFraudRule replaces a rule, it applies to the transfers screened from then on
+func (server *Server) updateFraudRule(ctx *gin.Context) {
+	var uri fraudRuleURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req fraudRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	params, err := fraudRuleParams(req)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	rule, err := server.store.UpdateFraudRule(ctx, db.UpdateFraudRuleParams{
+		ID: uri.ID,
+		Name: params.Name,
+		Kind: params.Kind,
+		Action: params.Action,
+		Currency: params.Currency,
+		MinAmount: params.MinAmount,
+		MaxCount: params.MaxCount,
+		WindowSeconds: params.WindowSeconds,
+		IsEnabled: params.IsEnabled,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newFraudRuleResponse(rule))
+}
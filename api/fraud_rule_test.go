@@ -0,0 +1,322 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateFraudRuleAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name": "large usd",
+				"kind": risk.RULE_AMOUNT,
+				"action": risk.OUTCOME_REVIEW,
+				"currency": util.USD,
+				"min_amount": "5000.00",
+				"is_enabled": true,
+			},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.CreateFraudRuleParams{
+					Name: "large usd",
+					Kind: risk.RULE_AMOUNT,
+					Action: risk.OUTCOME_REVIEW,
+					Currency: sql.NullString{String: util.USD, Valid: true},
+					MinAmount: sql.NullInt64{Int64: 500000, Valid: true},
+					IsEnabled: true,
+				}
+				store.EXPECT().
+				CreateFraudRule(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.FraudRule{
+					ID: 1,
+					Name: arg.Name,
+					Kind: arg.Kind,
+					Action: arg.Action,
+					Currency: arg.Currency,
+					MinAmount: arg.MinAmount,
+					IsEnabled: true,
+				}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, "5000.00", got["min_amount"])
+				require.NotContains(t, got, "max_count")
+			},
+		},
+		{
+			name: "Velocity Without Window",
+			body: gin.H{
+				"name": "busy account",
+				"kind": risk.RULE_ACCOUNT_VELOCITY,
+				"action": risk.OUTCOME_REVIEW,
+				"max_count": 10,
+				"is_enabled": true,
+			},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateFraudRule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "max_count", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Window On Amount Rule",
+			body: gin.H{
+				"name": "large usd",
+				"kind": risk.RULE_AMOUNT,
+				"action": risk.OUTCOME_DENY,
+				"currency": util.USD,
+				"min_amount": "5000.00",
+				"max_count": 1,
+				"window_seconds": 60,
+				"is_enabled": true,
+			},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateFraudRule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Min Amount Without Currency",
+			body: gin.H{
+				"name": "large",
+				"kind": risk.RULE_NEW_RECIPIENT,
+				"action": risk.OUTCOME_REVIEW,
+				"min_amount": "100.00",
+				"is_enabled": true,
+			},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateFraudRule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "min_amount", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Invalid Action",
+			body: gin.H{
+				"name": "new recipient",
+				"kind": risk.RULE_NEW_RECIPIENT,
+				"action": risk.OUTCOME_ALLOW,
+				"is_enabled": true,
+			},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateFraudRule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Not Admin",
+			body: gin.H{
+				"name": "new recipient",
+				"kind": risk.RULE_NEW_RECIPIENT,
+				"action": risk.OUTCOME_REVIEW,
+				"is_enabled": true,
+			},
+			username: user.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateFraudRule(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/admin/fraud_rules", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdateFraudRuleAPI(t *testing.T) {
+	admin := randomAdmin(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	arg := db.UpdateFraudRuleParams{
+		ID: 3,
+		Name: "busy account",
+		Kind: risk.RULE_ACCOUNT_VELOCITY,
+		Action: risk.OUTCOME_DENY,
+		MaxCount: sql.NullInt32{Int32: 20, Valid: true},
+		WindowSeconds: sql.NullInt32{Int32: 3600, Valid: true},
+		IsEnabled: false,
+	}
+	store.EXPECT().
+	UpdateFraudRule(gomock.Any(), gomock.Eq(arg)).
+	Times(1).
+	Return(db.FraudRule{
+		ID: arg.ID,
+		Name: arg.Name,
+		Kind: arg.Kind,
+		Action: arg.Action,
+		MaxCount: arg.MaxCount,
+		WindowSeconds: arg.WindowSeconds,
+	}, nil)
+	stubAdminUser(store, admin)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"name": arg.Name,
+		"kind": arg.Kind,
+		"action": arg.Action,
+		"max_count": 20,
+		"window_seconds": 3600,
+		"is_enabled": false,
+	})
+	require.NoError(t, err)
+
+	url := fmt.Sprintf("/admin/fraud_rules/%d", arg.ID)
+	request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got fraudRuleResponse
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.False(t, got.IsEnabled)
+	require.Equal(t, int32(20), got.MaxCount)
+}
+
+func TestUpdateFraudRuleAPINotFound(t *testing.T) {
+	admin := randomAdmin(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	UpdateFraudRule(gomock.Any(), gomock.Any()).
+	Times(1).
+	Return(db.FraudRule{}, sql.ErrNoRows)
+	stubAdminUser(store, admin)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{
+		"name": "new recipient",
+		"kind": risk.RULE_NEW_RECIPIENT,
+		"action": risk.OUTCOME_REVIEW,
+		"is_enabled": true,
+	})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPut, "/admin/fraud_rules/99", bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+}
+
+func TestListFraudRulesAPI(t *testing.T) {
+	admin := randomAdmin(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	ListFraudRules(gomock.Any()).
+	Times(1).
+	Return([]db.FraudRule{
+		amountRule("large usd", risk.OUTCOME_REVIEW, util.USD),
+		{ID: 2, Name: "new recipient", Kind: risk.RULE_NEW_RECIPIENT, Action: risk.OUTCOME_REVIEW, IsEnabled: true},
+	}, nil)
+	stubAdminUser(store, admin)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/admin/fraud_rules", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, risk.RULE_NEW_RECIPIENT, got[1]["kind"])
+}
@@ -0,0 +1,161 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/sssaang/simplebank/token"
+)
+
+// heldTransferResponse is what the user sees of a transfer held for review, without the rules which held it
+type heldTransferResponse struct {
+	ID int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	// ToAccountID is omitted when the transfer was sent to a user, whose account the sender does not learn
+	ToAccountID int64 `json:"to_account_id,omitempty"`
+	Amount util.Money `json:"amount"`
+	Currency string `json:"currency"`
+	Status string `json:"status"`
+	Description string `json:"description"`
+	Reference string `json:"reference"`
+	Metadata map[string]string `json:"metadata"`
+	// PaymentRequestID is the payment request the transfer pays, accepted once the transfer is approved
+	PaymentRequestID int64 `json:"payment_request_id,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newHeldTransferResponse(held db.HeldTransfer) heldTransferResponse {
+	return heldTransferResponse{
+		ID: held.ID,
+		FromAccountID: held.FromAccountID,
+		ToAccountID: held.ToAccountID,
+		Amount: util.NewMoney(held.Amount, held.Currency),
+		Currency: held.Currency,
+		Status: held.Status,
+		Description: held.Description,
+		Reference: held.Reference,
+		Metadata: unmarshalMetadata(held.Metadata),
+		PaymentRequestID: held.PaymentRequestID.Int64,
+		CreatedAt: held.CreatedAt,
+	}
+}
+
+type adminHeldTransferResponse struct {
+	heldTransferResponse
+	RequestedBy string `json:"requested_by"`
+	Reasons []string `json:"reasons"`
+	ReviewedBy string `json:"reviewed_by,omitempty"`
+	ReviewedAt *time.Time `json:"reviewed_at,omitempty"`
+	TransferID int64 `json:"transfer_id,omitempty"`
+}
+
+func newAdminHeldTransferResponse(held db.HeldTransfer) adminHeldTransferResponse {
+	res := adminHeldTransferResponse{
+		heldTransferResponse: newHeldTransferResponse(held),
+		RequestedBy: held.RequestedBy,
+		Reasons: held.Reasons,
+		ReviewedBy: held.ReviewedBy.String,
+		TransferID: held.TransferID.Int64,
+	}
+	if held.ReviewedAt.Valid {
+		res.ReviewedAt = &held.ReviewedAt.Time
+	}
+	return res
+}
+
+// holdTransfer queues a transfer the fraud rules flagged for review, it is only made once staff approve it
+func (server *Server) holdTransfer(ctx *gin.Context, currency string, arg db.TransferTxParams, assessment risk.Assessment) (db.HeldTransfer, error) {
+	return server.store.CreateHeldTransfer(ctx, db.CreateHeldTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		Currency: currency,
+		RequestedBy: arg.RequestedBy,
+		RecipientHidden: arg.RecipientHidden,
+		Reasons: assessment.Reasons(),
+		Description: arg.Description,
+		Reference: arg.Reference,
+		Metadata: arg.Metadata,
+	})
+}
+
+type listHeldTransfersRequest struct {
+	Status string `form:"status" binding:"required,oneof=pending approved rejected"`
+	PageID int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=50"`
+}
+
+// listHeldTransfers is the review queue of the staff, oldest first
+func (server *Server) listHeldTransfers(ctx *gin.Context) {
+	var req listHeldTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	held, err := server.store.ListHeldTransfers(ctx, db.ListHeldTransfersParams{
+		Status: req.Status,
+		Limit: req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]adminHeldTransferResponse, len(held))
+	for i, transfer := range held {
+		res[i] = newAdminHeldTransferResponse(transfer)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type heldTransferURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+type reviewHeldTransferResponse struct {
+	HeldTransfer adminHeldTransferResponse `json:"held_transfer"`
+	Transfer *transferTxResponse `json:"transfer,omitempty"`
+}
+
+func (server *Server) approveHeldTransfer(ctx *gin.Context) {
+	server.reviewHeldTransfer(ctx, true)
+}
+
+func (server *Server) rejectHeldTransfer(ctx *gin.Context) {
+	server.reviewHeldTransfer(ctx, false)
+}
+
+func (server *Server) reviewHeldTransfer(ctx *gin.Context, approve bool) {
+	var uri heldTransferURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+
+	result, err := server.store.ReviewHeldTransferTx(ctx, db.ReviewHeldTransferTxParams{
+		ID: uri.ID,
+		Reviewer: authPayload.Username,
+		Approve: approve,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := reviewHeldTransferResponse{HeldTransfer: newAdminHeldTransferResponse(result.HeldTransfer)}
+	if result.Transfer != nil {
+		transfer := newTransferTxResponse(*result.Transfer)
+		res.Transfer = &transfer
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
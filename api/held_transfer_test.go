@@ -0,0 +1,275 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomHeldTransfer(username string) db.HeldTransfer {
+	return db.HeldTransfer{
+		ID: util.RandomInt(1, 1000),
+		FromAccountID: util.RandomInt(1, 1000),
+		ToAccountID: util.RandomInt(1001, 2000),
+		Amount: util.RandomMoney(),
+		Currency: util.USD,
+		RequestedBy: username,
+		Reasons: []string{"new recipient"},
+		Status: db.HELD_TRANSFER_PENDING,
+		CreatedAt: time.Now(),
+	}
+}
+
+func TestListHeldTransfersAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+	held := randomHeldTransfer(user.Username)
+
+	testCases := []struct {
+		name string
+		query string
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: "status=pending&page_id=1&page_size=5",
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.ListHeldTransfersParams{Status: db.HELD_TRANSFER_PENDING, Limit: 5, Offset: 0}
+				store.EXPECT().
+				ListHeldTransfers(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return([]db.HeldTransfer{held}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 1)
+				require.Equal(t, user.Username, got[0]["requested_by"])
+				require.Equal(t, []interface{}{"new recipient"}, got[0]["reasons"])
+				require.NotContains(t, got[0], "reviewed_at")
+			},
+		},
+		{
+			name: "Invalid Status",
+			query: "status=done&page_id=1&page_size=5",
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ListHeldTransfers(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Not Admin",
+			query: "status=pending&page_id=1&page_size=5",
+			username: user.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ListHeldTransfers(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/admin/held_transfers?"+tc.query, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestReviewHeldTransferAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+	held := randomHeldTransfer(user.Username)
+
+	reviewed := held
+	reviewed.ReviewedBy = sql.NullString{String: admin.Username, Valid: true}
+	reviewed.ReviewedAt = sql.NullTime{Time: time.Now(), Valid: true}
+
+	testCases := []struct {
+		name string
+		action string
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Approve",
+			action: "approve",
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				approved := reviewed
+				approved.Status = db.HELD_TRANSFER_APPROVED
+				approved.TransferID = sql.NullInt64{Int64: 42, Valid: true}
+
+				arg := db.ReviewHeldTransferTxParams{ID: held.ID, Reviewer: admin.Username, Approve: true}
+				store.EXPECT().
+				ReviewHeldTransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.ReviewHeldTransferTxResult{
+					HeldTransfer: approved,
+					Transfer: &db.TransferTxResult{
+						Transfer: db.Transfer{ID: 42, FromAccountID: held.FromAccountID, ToAccountID: held.ToAccountID, Amount: held.Amount},
+						FromAccount: db.Account{ID: held.FromAccountID, Currency: util.USD},
+						ToAccount: db.Account{ID: held.ToAccountID, Currency: util.USD},
+					},
+				}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					HeldTransfer gin.H `json:"held_transfer"`
+					Transfer gin.H `json:"transfer"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.HELD_TRANSFER_APPROVED, got.HeldTransfer["status"])
+				require.Equal(t, float64(42), got.HeldTransfer["transfer_id"])
+				require.Equal(t, admin.Username, got.HeldTransfer["reviewed_by"])
+				require.NotNil(t, got.Transfer)
+			},
+		},
+		{
+			name: "Reject",
+			action: "reject",
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				rejected := reviewed
+				rejected.Status = db.HELD_TRANSFER_REJECTED
+
+				arg := db.ReviewHeldTransferTxParams{ID: held.ID, Reviewer: admin.Username, Approve: false}
+				store.EXPECT().
+				ReviewHeldTransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.ReviewHeldTransferTxResult{HeldTransfer: rejected}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotContains(t, got, "transfer")
+			},
+		},
+		{
+			name: "Already Reviewed",
+			action: "approve",
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ReviewHeldTransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ReviewHeldTransferTxResult{}, db.ErrHeldTransferReviewed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_CONFLICT)
+			},
+		},
+		{
+			name: "Insufficient Funds",
+			action: "approve",
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ReviewHeldTransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ReviewHeldTransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_INSUFFICIENT_FUNDS)
+			},
+		},
+		{
+			name: "Not Found",
+			action: "reject",
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ReviewHeldTransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ReviewHeldTransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+		{
+			name: "Not Admin",
+			action: "approve",
+			username: user.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ReviewHeldTransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/admin/held_transfers/%d/%s", held.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,68 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+type holdResponse struct {
+	ID int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	Amount util.Money `json:"amount"`
+	Status string `json:"status"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newHoldResponse(hold db.Hold, currency string) holdResponse {
+	return holdResponse{
+		ID: hold.ID,
+		AccountID: hold.AccountID,
+		ToAccountID: hold.ToAccountID,
+		Amount: util.NewMoney(hold.Amount, currency),
+		Status: hold.Status,
+		ExpiresAt: hold.ExpiresAt,
+		CreatedAt: hold.CreatedAt,
+	}
+}
+
+type accountHoldsURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listAccountHolds shows the pending debits which make the available balance lower than the balance
+func (server *Server) listAccountHolds(ctx *gin.Context) {
+	var uri accountHoldsURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountReaders) {
+		return
+	}
+
+	holds, err := server.store.ListActiveHolds(ctx, account.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]holdResponse, len(holds))
+	for i, hold := range holds {
+		res[i] = newHoldResponse(hold, account.Currency)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
@@ -0,0 +1,93 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/statement"
+)
+
+type interestRateResponse struct {
+	Currency string `json:"currency"`
+	// EffectiveFrom is the first UTC day the rate applies, until the next rate of the currency
+	EffectiveFrom string `json:"effective_from"`
+	AnnualRate string `json:"annual_rate"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newInterestRateResponse(rate db.InterestRate) interestRateResponse {
+	return interestRateResponse{
+		Currency: rate.Currency,
+		EffectiveFrom: rate.EffectiveFrom.Format(statement.DATE_LAYOUT),
+		AnnualRate: rate.AnnualRate,
+		CreatedAt: rate.CreatedAt,
+	}
+}
+
+// listInterestRates shows the schedule savings accounts earn interest by, past rates included
+func (server *Server) listInterestRates(ctx *gin.Context) {
+	rates, err := server.store.ListInterestRates(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]interestRateResponse, len(rates))
+	for i, rate := range rates {
+		res[i] = newInterestRateResponse(rate)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type setInterestRateURI struct {
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+type setInterestRateRequest struct {
+	// AnnualRate is the fraction of the balance paid over a year, "0.025" for 2.5%
+	AnnualRate string `json:"annual_rate" binding:"required"`
+	EffectiveFrom string `json:"effective_from" binding:"required,datetime=2006-01-02"`
+}
+
+// setInterestRate schedules a rate of a currency from a day on, the days already accrued keep the rate they had
+func (server *Server) setInterestRate(ctx *gin.Context) {
+	var uri setInterestRateURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req setInterestRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if _, err := util.ParseAnnualRate(req.AnnualRate); err != nil {
+		abortWithError(ctx, fieldError("annual_rate", "rate", err.Error()))
+		return
+	}
+
+	// the binding has already checked the layout
+	effectiveFrom, _ := time.Parse(statement.DATE_LAYOUT, req.EffectiveFrom)
+	if effectiveFrom.Before(time.Now().UTC().Truncate(24 * time.Hour)) {
+		abortWithError(ctx, fieldError("effective_from", "min", "must not be before today"))
+		return
+	}
+
+	rate, err := server.store.UpsertInterestRate(ctx, db.UpsertInterestRateParams{
+		Currency: uri.Currency,
+		EffectiveFrom: effectiveFrom,
+		AnnualRate: req.AnnualRate,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newInterestRateResponse(rate))
+}
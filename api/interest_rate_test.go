@@ -0,0 +1,166 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/statement"
+	"github.com/stretchr/testify/require"
+)
+
+func TestListInterestRatesAPI(t *testing.T) {
+	effectiveFrom := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	ListInterestRates(gomock.Any()).
+	Times(1).
+	Return([]db.InterestRate{{Currency: util.USD, EffectiveFrom: effectiveFrom, AnnualRate: "0.025"}}, nil)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/interest_rates", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+	require.Equal(t, "2026-01-01", got[0]["effective_from"])
+	require.Equal(t, "0.025", got[0]["annual_rate"])
+}
+
+func TestSetInterestRateAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+	tomorrow := time.Now().UTC().Truncate(24 * time.Hour).AddDate(0, 0, 1)
+	yesterday := tomorrow.AddDate(0, 0, -2)
+
+	testCases := []struct {
+		name string
+		currency string
+		body gin.H
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			currency: util.USD,
+			body: gin.H{"annual_rate": "0.025", "effective_from": tomorrow.Format(statement.DATE_LAYOUT)},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.UpsertInterestRateParams{Currency: util.USD, EffectiveFrom: tomorrow, AnnualRate: "0.025"}
+				store.EXPECT().
+				UpsertInterestRate(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.InterestRate{Currency: util.USD, EffectiveFrom: tomorrow, AnnualRate: "0.025"}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, tomorrow.Format(statement.DATE_LAYOUT), got["effective_from"])
+			},
+		},
+		{
+			name: "Rate Out Of Range",
+			currency: util.USD,
+			body: gin.H{"annual_rate": "1.5", "effective_from": tomorrow.Format(statement.DATE_LAYOUT)},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertInterestRate(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Effective In The Past",
+			currency: util.USD,
+			body: gin.H{"annual_rate": "0.025", "effective_from": yesterday.Format(statement.DATE_LAYOUT)},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertInterestRate(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Invalid Date",
+			currency: util.USD,
+			body: gin.H{"annual_rate": "0.025", "effective_from": "01/02/2026"},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertInterestRate(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Not Admin",
+			currency: util.USD,
+			body: gin.H{"annual_rate": "0.025", "effective_from": tomorrow.Format(statement.DATE_LAYOUT)},
+			username: user.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertInterestRate(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/admin/interest_rates/"+tc.currency, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sssaang/simplebank/token"
+)
+
+// parseServiceCredentials reads a comma separated list of client_id:client_secret pairs
+func parseServiceCredentials(value string) (map[string]string, error) {
+	credentials := make(map[string]string)
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 || len(fields[0]) == 0 || len(fields[1]) == 0 {
+			return nil, fmt.Errorf("invalid service credentials %q: must be formatted as client_id:client_secret", entry)
+		}
+
+		credentials[fields[0]] = fields[1]
+	}
+
+	return credentials, nil
+}
+
+type introspectTokenRequest struct {
+	Token string `form:"token" binding:"required"`
+}
+
+// introspectToken tells internal services whether an access token is active, as described by RFC 7662.
+// Invalid, expired and revoked tokens are all reported as inactive without further detail.
+func (server *Server) introspectToken(ctx *gin.Context) {
+	var req introspectTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	inactive := token.IntrospectionResponse{Active: false}
+
+	payload, err := server.tokenManager.VerifyToken(req.Token)
+	if err != nil {
+		ctx.JSON(http.StatusOK, inactive)
+		return
+	}
+
+	user, err := server.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusOK, inactive)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	if isTokenRevoked(payload, user) {
+		ctx.JSON(http.StatusOK, inactive)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, token.IntrospectionResponse{
+		Active: true,
+		Payload: payload,
+	})
+}
@@ -0,0 +1,204 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIntrospectTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name string
+		createToken func(t *testing.T, tokenManager token.TokenManager) string
+		setupAuth func(request *http.Request)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Active Token",
+			createToken: func(t *testing.T, tokenManager token.TokenManager) string {
+				accessToken, err := tokenManager.CreateToken(user.Username, token.ALL_SCOPES, time.Minute)
+				require.NoError(t, err)
+				return accessToken
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("test_service", "test_service_secret")
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				res := requireIntrospectionResponse(t, recorder)
+				require.True(t, res.Active)
+				require.Equal(t, user.Username, res.Username)
+				require.NotZero(t, res.ID)
+			},
+		},
+		{
+			name: "Expired Token",
+			createToken: func(t *testing.T, tokenManager token.TokenManager) string {
+				accessToken, err := tokenManager.CreateToken(user.Username, token.ALL_SCOPES, -time.Minute)
+				require.NoError(t, err)
+				return accessToken
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("test_service", "test_service_secret")
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				res := requireIntrospectionResponse(t, recorder)
+				require.False(t, res.Active)
+				require.Nil(t, res.Payload)
+			},
+		},
+		{
+			name: "Revoked Token",
+			createToken: func(t *testing.T, tokenManager token.TokenManager) string {
+				accessToken, err := tokenManager.CreateToken(user.Username, token.ALL_SCOPES, time.Minute)
+				require.NoError(t, err)
+				return accessToken
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("test_service", "test_service_secret")
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				changedUser := user
+				changedUser.PasswordChangedAt = time.Now().Add(time.Minute)
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(changedUser, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.False(t, requireIntrospectionResponse(t, recorder).Active)
+			},
+		},
+		{
+			name: "Unknown User",
+			createToken: func(t *testing.T, tokenManager token.TokenManager) string {
+				accessToken, err := tokenManager.CreateToken(user.Username, token.ALL_SCOPES, time.Minute)
+				require.NoError(t, err)
+				return accessToken
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("test_service", "test_service_secret")
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.False(t, requireIntrospectionResponse(t, recorder).Active)
+			},
+		},
+		{
+			name: "Wrong Service Secret",
+			createToken: func(t *testing.T, tokenManager token.TokenManager) string {
+				accessToken, err := tokenManager.CreateToken(user.Username, token.ALL_SCOPES, time.Minute)
+				require.NoError(t, err)
+				return accessToken
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth("test_service", "wrong_secret")
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "No Service Credentials",
+			createToken: func(t *testing.T, tokenManager token.TokenManager) string {
+				accessToken, err := tokenManager.CreateToken(user.Username, token.ALL_SCOPES, time.Minute)
+				require.NoError(t, err)
+				return accessToken
+			},
+			setupAuth: func(request *http.Request) {
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			form := url.Values{"token": {tc.createToken(t, server.tokenManager)}}
+			request, err := http.NewRequest(http.MethodPost, "/introspect", strings.NewReader(form.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(request)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func requireIntrospectionResponse(t *testing.T, recorder *httptest.ResponseRecorder) token.IntrospectionResponse {
+	data, err := ioutil.ReadAll(recorder.Body)
+	require.NoError(t, err)
+
+	var res token.IntrospectionResponse
+	err = json.Unmarshal(data, &res)
+	require.NoError(t, err)
+	return res
+}
+
+func TestParseServiceCredentials(t *testing.T) {
+	credentials, err := parseServiceCredentials("service1:secret1, service2:secret:with:colons")
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{"service1": "secret1", "service2": "secret:with:colons"}, credentials)
+
+	credentials, err = parseServiceCredentials("")
+	require.NoError(t, err)
+	require.Empty(t, credentials)
+
+	_, err = parseServiceCredentials("service1")
+	require.Error(t, err)
+}
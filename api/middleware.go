@@ -1,51 +1,169 @@
 package api
 
 import (
-	"errors"
+	"crypto/subtle"
+	"database/sql"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
 	"github.com/sssaang/simplebank/token"
 )
 
 const (
 	AUTHORIZATION_HEADER = "authorization"
 	AUTHORIZATION_TYPE_BEARER = "bearer"
+	AUTHORIZATION_TYPE_API_KEY = "apikey"
 	AUTHORIZATION_PAYLOAD = "authorization_payload"
+	AUTHORIZATION_USER = "authorization_user"
+
+	REQUEST_ID_HEADER = "X-Request-ID"
+	REQUEST_ID = "request_id"
+	REQUEST_ID_MAX_LENGTH = 64
 )
 
-func authMiddleware(tokenManager token.TokenManager) gin.HandlerFunc {
+// requestIDMiddleware tags every request with an id, reusing the one set by a proxy,
+// so that an error reported by a client can be found in the logs
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		requestID := ctx.GetHeader(REQUEST_ID_HEADER)
+		if len(requestID) == 0 || len(requestID) > REQUEST_ID_MAX_LENGTH {
+			requestID = uuid.New().String()
+		}
+
+		ctx.Set(REQUEST_ID, requestID)
+		ctx.Header(REQUEST_ID_HEADER, requestID)
+		ctx.Next()
+	}
+}
+
+// authMiddleware verifies the bearer token or API key and loads the user it was issued for.
+// Tokens issued and API keys created before the last password change of the user are rejected,
+// so that recovering an account also locks out the keys an attacker may have created.
+func authMiddleware(tokenManager token.TokenManager, store db.Store) gin.HandlerFunc {
 	return func(ctx *gin.Context) {
 		authorizationHeader := ctx.GetHeader(AUTHORIZATION_HEADER)
 		if len(authorizationHeader) == 0 {
-			err := errors.New("authorization header is not provided")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "authorization header is not provided"))
 			return
 		}
 
 		fields := strings.Fields(authorizationHeader)
 		if len(fields) < 2 {
-			err := errors.New("invalid authorization header format")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "invalid authorization header format"))
 			return
 		}
 
+		var payload *token.Payload
+		var err error
+
 		authorizationType := strings.ToLower(fields[0])
-		if authorizationType != AUTHORIZATION_TYPE_BEARER {
-			err := errors.New("unsupported authorization type")
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+		switch authorizationType {
+		case AUTHORIZATION_TYPE_BEARER:
+			payload, err = tokenManager.VerifyToken(fields[1])
+			if err != nil {
+				abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, err.Error()))
+				return
+			}
+		case AUTHORIZATION_TYPE_API_KEY:
+			apiKey, err := store.GetApiKeyByHash(ctx, util.HashSecret(fields[1]))
+			if err != nil {
+				if err == sql.ErrNoRows {
+					abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "api key is invalid or revoked"))
+					return
+				}
+
+				abortWithError(ctx, err)
+				return
+			}
+			payload = newApiKeyPayload(apiKey)
+		default:
+			abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "unsupported authorization type"))
 			return
 		}
 
-		accessToken := fields[1]
-		payload, err := tokenManager.VerifyToken(accessToken)
+		user, err := store.GetUser(ctx, payload.Username)
 		if err != nil {
-			ctx.AbortWithStatusJSON(http.StatusUnauthorized, errorResponse(err))
+			if err == sql.ErrNoRows {
+				abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "the user of the token does not exist"))
+				return
+			}
+
+			abortWithError(ctx, err)
+			return
+		}
+
+		if isTokenRevoked(payload, user) {
+			abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "token or api key was issued before the last password change"))
 			return
 		}
 
 		ctx.Set(AUTHORIZATION_PAYLOAD, payload)
+		ctx.Set(AUTHORIZATION_USER, user)
+		ctx.Next()
+	}
+}
+
+// requireScope rejects requests whose token or API key was not granted the scope.
+// It must run after authMiddleware.
+func requireScope(scope string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+		if !authPayload.HasScope(scope) {
+			message := fmt.Sprintf("the %s scope is required", scope)
+			abortWithError(ctx, newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, message))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// requireRole rejects users who were not given the role.
+// It must run after authMiddleware.
+func requireRole(role string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		user := ctx.MustGet(AUTHORIZATION_USER).(db.User)
+		if user.Role != role {
+			message := fmt.Sprintf("the %s role is required", role)
+			abortWithError(ctx, newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, message))
+			return
+		}
+
+		ctx.Next()
+	}
+}
+
+// isTokenRevoked reports whether the user has changed their password since the token was issued or the API key created
+func isTokenRevoked(payload *token.Payload, user db.User) bool {
+	return payload.IssuedAt.Before(user.PasswordChangedAt)
+}
+
+// serviceAuthMiddleware authenticates internal services with HTTP basic auth against their configured credentials
+func serviceAuthMiddleware(credentials map[string]string) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		clientID, clientSecret, ok := ctx.Request.BasicAuth()
+		if !ok {
+			ctx.Header("WWW-Authenticate", `Basic realm="introspection"`)
+			abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "service credentials are not provided"))
+			return
+		}
+
+		expectedSecret, found := credentials[clientID]
+		if !found {
+			// compare anyway so that unknown clients take as long as known ones
+			expectedSecret = clientSecret + "!"
+		}
+
+		if subtle.ConstantTimeCompare([]byte(expectedSecret), []byte(clientSecret)) != 1 {
+			abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "invalid service credentials"))
+			return
+		}
+
 		ctx.Next()
 	}
-}
\ No newline at end of file
+}
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -8,6 +10,10 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
 	"github.com/sssaang/simplebank/token"
 	"github.com/stretchr/testify/require"
 )
@@ -20,7 +26,19 @@ func addAuthorization(
 	username string,
 	duration time.Duration,
 ) {
-	token, err := tokenManager.CreateToken(username, duration)
+	addScopedAuthorization(t, request, tokenManager, authorization_type, username, token.ALL_SCOPES, duration)
+}
+
+func addScopedAuthorization(
+	t *testing.T,
+	request *http.Request,
+	tokenManager token.TokenManager,
+	authorization_type string,
+	username string,
+	scopes []string,
+	duration time.Duration,
+) {
+	token, err := tokenManager.CreateToken(username, scopes, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -28,10 +46,24 @@ func addAuthorization(
 	request.Header.Set(AUTHORIZATION_HEADER, authorizationHeader)
 }
 
+const testApiKey = API_KEY_PREFIX + "0123456789abcdef"
+
+// stubAuthUsers lets authMiddleware load any user whose token is presented.
+// It should be called after the test case stubs so that their own GetUser expectations take precedence.
+func stubAuthUsers(store *testdb.MockStore) {
+	store.EXPECT().
+	GetUser(gomock.Any(), gomock.Any()).
+	AnyTimes().
+	DoAndReturn(func(ctx context.Context, username string) (db.User, error) {
+		return db.User{Username: username, IsEmailVerified: true}, nil
+	})
+}
+
 func TestAuthMiddleware(t *testing.T) {
 	testCases := []struct {
 		name string
 		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
 		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
 	}{	
 		{
@@ -39,6 +71,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
 				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "test_user", time.Minute)
 			},
+			buildStubs: stubAuthUsers,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
@@ -47,6 +80,7 @@ func TestAuthMiddleware(t *testing.T) {
 			name: "No Authorization Header",
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
 			},
+			buildStubs: stubAuthUsers,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -56,6 +90,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
 				addAuthorization(t, request, tokenManager, "", "test_user", time.Minute)
 			},
+			buildStubs: stubAuthUsers,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -65,6 +100,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
 				addAuthorization(t, request, tokenManager, "unsupported auth type", "test_user", time.Minute)
 			},
+			buildStubs: stubAuthUsers,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -74,6 +110,7 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
 				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "test_user", -time.Minute)
 			},
+			buildStubs: stubAuthUsers,
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -83,6 +120,146 @@ func TestAuthMiddleware(t *testing.T) {
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
 				addAuthorization(t, request, tokenManager, "", "user", time.Minute)
 			},
+			buildStubs: stubAuthUsers,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "User Not Found",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "test_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("test_user")).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "test_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("test_user")).
+				Times(1).
+				Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "Token Issued Before Password Change",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "test_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("test_user")).
+				Times(1).
+				Return(db.User{Username: "test_user", PasswordChangedAt: time.Now().Add(time.Minute)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Missing Scope",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				scopes := []string{token.SCOPE_TRANSFERS_WRITE}
+				addScopedAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "test_user", scopes, time.Minute)
+			},
+			buildStubs: stubAuthUsers,
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "API Key Success",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				request.Header.Set(AUTHORIZATION_HEADER, fmt.Sprintf("%s %s", AUTHORIZATION_TYPE_API_KEY, testApiKey))
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Eq(util.HashSecret(testApiKey))).
+				Times(1).
+				Return(db.ApiKey{
+					Username: "test_user",
+					Scopes: []string{token.SCOPE_ACCOUNTS_READ},
+					CreatedAt: time.Now().Add(-time.Hour),
+				}, nil)
+
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("test_user")).
+				Times(1).
+				Return(db.User{Username: "test_user", PasswordChangedAt: time.Now().Add(-2 * time.Hour)}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "API Key Created Before Password Change",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				request.Header.Set(AUTHORIZATION_HEADER, fmt.Sprintf("%s %s", AUTHORIZATION_TYPE_API_KEY, testApiKey))
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Eq(util.HashSecret(testApiKey))).
+				Times(1).
+				Return(db.ApiKey{
+					Username: "test_user",
+					Scopes: []string{token.SCOPE_ACCOUNTS_READ},
+					CreatedAt: time.Now().Add(-time.Hour),
+				}, nil)
+
+				// a password reset recovers the account from whoever created the key
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq("test_user")).
+				Times(1).
+				Return(db.User{Username: "test_user", PasswordChangedAt: time.Now()}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "API Key Missing Scope",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				request.Header.Set(AUTHORIZATION_HEADER, fmt.Sprintf("%s %s", AUTHORIZATION_TYPE_API_KEY, testApiKey))
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Eq(util.HashSecret(testApiKey))).
+				Times(1).
+				Return(db.ApiKey{Username: "test_user", Scopes: []string{token.SCOPE_TRANSFERS_READ}}, nil)
+				stubAuthUsers(store)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+		{
+			name: "Unknown Or Revoked API Key",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				request.Header.Set(AUTHORIZATION_HEADER, fmt.Sprintf("%s %s", AUTHORIZATION_TYPE_API_KEY, testApiKey))
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Eq(util.HashSecret(testApiKey))).
+				Times(1).
+				Return(db.ApiKey{}, sql.ErrNoRows)
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
 			},
@@ -91,11 +268,18 @@ func TestAuthMiddleware(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			server := NewTestServer(t, nil)
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
 
 			server.router.GET(
 				"/auth",
-				authMiddleware(server.tokenManager),
+				authMiddleware(server.tokenManager, server.store),
+				requireScope(token.SCOPE_ACCOUNTS_READ),
 				func(ctx *gin.Context) {
 					ctx.JSON(http.StatusOK, gin.H{})
 				},
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+// amounts are exchanged as decimal strings in the major units of the currency, "12.34",
+// while the store keeps integer minor units
+
+type accountResponse struct {
+	ID int64 `json:"id"`
+	Owner string `json:"owner"`
+	Balance util.Money `json:"balance"`
+	// AvailableBalance is the balance minus the active holds, only the responses which read the holds have it
+	AvailableBalance *util.Money `json:"available_balance,omitempty"`
+	Currency string `json:"currency"`
+	Type string `json:"type"`
+	WalletID int64 `json:"wallet_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		ID: account.ID,
+		Owner: account.Owner,
+		Balance: util.NewMoney(account.Balance, account.Currency),
+		Currency: account.Currency,
+		Type: account.Type,
+		WalletID: account.WalletID,
+		CreatedAt: account.CreatedAt,
+	}
+}
+
+func newAvailableAccountResponse(account db.Account, heldAmount int64) accountResponse {
+	res := newAccountResponse(account)
+	available := util.NewMoney(account.Balance-heldAmount, account.Currency)
+	res.AvailableBalance = &available
+	return res
+}
+
+func newAccountsResponse(accounts []db.Account) []accountResponse {
+	res := make([]accountResponse, len(accounts))
+	for i, account := range accounts {
+		res[i] = newAccountResponse(account)
+	}
+	return res
+}
+
+type entryResponse struct {
+	ID int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	Amount util.Money `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newEntryResponse(entry db.Entry, currency string) entryResponse {
+	return entryResponse{
+		ID: entry.ID,
+		AccountID: entry.AccountID,
+		Amount: util.NewMoney(entry.Amount, currency),
+		CreatedAt: entry.CreatedAt,
+	}
+}
+
+type transferResponse struct {
+	ID int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	// ToAccountID is omitted when the transfer was sent to a user, whose account the sender does not learn
+	ToAccountID int64 `json:"to_account_id,omitempty"`
+	Amount util.Money `json:"amount"`
+	// Fee is what the source account paid on top of the amount
+	Fee util.Money `json:"fee"`
+	Currency string `json:"currency"`
+	Description string `json:"description"`
+	Reference string `json:"reference"`
+	Metadata map[string]string `json:"metadata"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// newTransferResponse needs the currency of the accounts, transfers are always between accounts of the same currency
+func newTransferResponse(transfer db.Transfer, currency string) transferResponse {
+	return transferResponse{
+		ID: transfer.ID,
+		FromAccountID: transfer.FromAccountID,
+		ToAccountID: transfer.ToAccountID,
+		Amount: util.NewMoney(transfer.Amount, currency),
+		Fee: util.NewMoney(transfer.Fee, currency),
+		Currency: currency,
+		Description: transfer.Description,
+		Reference: transfer.Reference,
+		Metadata: unmarshalMetadata(transfer.Metadata),
+		CreatedAt: transfer.CreatedAt,
+	}
+}
+
+// marshalMetadata stores the metadata of a transfer request, an empty object when there is none
+func marshalMetadata(metadata map[string]string) json.RawMessage {
+	if metadata == nil {
+		metadata = map[string]string{}
+	}
+	// a map of strings always marshals
+	data, _ := json.Marshal(metadata)
+	return data
+}
+
+// unmarshalMetadata reads the metadata of a transfer, which the API only ever stores as an object of strings
+func unmarshalMetadata(data json.RawMessage) map[string]string {
+	metadata := map[string]string{}
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &metadata); err != nil {
+			return map[string]string{}
+		}
+	}
+	return metadata
+}
+
+type transferTxResponse struct {
+	Transfer transferResponse `json:"transfer"`
+	FromAccount accountResponse `json:"from_account"`
+	ToAccount accountResponse `json:"to_account"`
+	FromEntry entryResponse `json:"from_entry"`
+	ToEntry entryResponse `json:"to_entry"`
+	FeeEntry *entryResponse `json:"fee_entry,omitempty"`
+}
+
+func newTransferTxResponse(result db.TransferTxResult) transferTxResponse {
+	currency := result.FromAccount.Currency
+	res := transferTxResponse{
+		Transfer: newTransferResponse(result.Transfer, currency),
+		FromAccount: newAccountResponse(result.FromAccount),
+		ToAccount: newAccountResponse(result.ToAccount),
+		FromEntry: newEntryResponse(result.FromEntry, currency),
+		ToEntry: newEntryResponse(result.ToEntry, currency),
+	}
+	if result.FeeEntry != nil {
+		feeEntry := newEntryResponse(*result.FeeEntry, currency)
+		res.FeeEntry = &feeEntry
+	}
+	return res
+}
+
+// userTransferResponse is a transfer to a user as its sender sees it, nothing tells the account of the recipient
+type userTransferResponse struct {
+	Transfer transferResponse `json:"transfer"`
+	FromAccount accountResponse `json:"from_account"`
+	FromEntry entryResponse `json:"from_entry"`
+	FeeEntry *entryResponse `json:"fee_entry,omitempty"`
+}
+
+func newUserTransferResponse(result db.TransferTxResult) userTransferResponse {
+	res := newTransferTxResponse(result)
+	res.Transfer.ToAccountID = 0
+	return userTransferResponse{
+		Transfer: res.Transfer,
+		FromAccount: res.FromAccount,
+		FromEntry: res.FromEntry,
+		FeeEntry: res.FeeEntry,
+	}
+}
+
+// parseAmount reads the positive amount of a request in its currency,
+// the money validator has already checked the syntax but not the decimals of the currency
+func parseAmount(field string, value string, currency string) (util.Money, error) {
+	amount, err := util.ParseMoney(value, currency)
+	if err == nil && !amount.IsPositive() {
+		err = errors.New("must be positive")
+	}
+
+	if err != nil {
+		return amount, fieldError(field, "money", err.Error())
+	}
+
+	return amount, nil
+}
@@ -0,0 +1,435 @@
+package api
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+)
+
+const (
+	OAUTH_CLIENT_ID_BYTES = 16
+	OAUTH_CLIENT_SECRET_BYTES = 32
+	OAUTH_CODE_BYTES = 32
+	OAUTH_REFRESH_TOKEN_BYTES = 32
+
+	// error codes of RFC 6749 section 5.2
+	OAUTH_ERROR_INVALID_REQUEST = "invalid_request"
+	OAUTH_ERROR_INVALID_CLIENT = "invalid_client"
+	OAUTH_ERROR_INVALID_GRANT = "invalid_grant"
+	OAUTH_ERROR_UNSUPPORTED_GRANT_TYPE = "unsupported_grant_type"
+	OAUTH_ERROR_ACCESS_DENIED = "access_denied"
+	OAUTH_ERROR_SERVER_ERROR = "server_error"
+)
+
+var errInvalidOauthGrant = errors.New("the authorization grant is invalid, expired or revoked")
+
+// oauthError is the error body of the token endpoint, which RFC 6749 defines instead of problem details
+type oauthError struct {
+	Error string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+func oauthErrorResponse(code string, err error) oauthError {
+	return oauthError{Error: code, ErrorDescription: err.Error()}
+}
+
+// oauthServerError only logs the cause of internal errors, as abortWithError does
+func oauthServerError(ctx *gin.Context, err error) {
+	log.Printf("request %s failed: %v", ctx.GetString(REQUEST_ID), err)
+	ctx.JSON(http.StatusInternalServerError, oauthErrorResponse(OAUTH_ERROR_SERVER_ERROR, errInternal))
+}
+
+type createOauthClientRequest struct {
+	Name string `json:"name" binding:"required,max=64"`
+	RedirectURIs []string `json:"redirect_uris" binding:"required,min=1,dive,url"`
+	Scopes []string `json:"scopes" binding:"required,min=1,dive,oauth_scope"`
+}
+
+type oauthClientResponse struct {
+	ClientID string `json:"client_id"`
+	// ClientSecret is only returned on registration, the server keeps its hash
+	ClientSecret string `json:"client_secret,omitempty"`
+	Name string `json:"name"`
+	RedirectURIs []string `json:"redirect_uris"`
+	Scopes []string `json:"scopes"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// createOauthClient registers a third-party application owned by the user
+func (server *Server) createOauthClient(ctx *gin.Context) {
+	var req createOauthClientRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	clientID, err := util.RandomSecret(OAUTH_CLIENT_ID_BYTES)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	clientSecret, err := util.RandomSecret(OAUTH_CLIENT_SECRET_BYTES)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	arg := db.CreateOauthClientParams{
+		ClientID: clientID,
+		Owner: authPayload.Username,
+		Name: req.Name,
+		SecretHash: util.HashSecret(clientSecret),
+		RedirectUris: req.RedirectURIs,
+		Scopes: req.Scopes,
+	}
+
+	client, err := server.store.CreateOauthClient(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := oauthClientResponse{
+		ClientID: client.ClientID,
+		ClientSecret: clientSecret,
+		Name: client.Name,
+		RedirectURIs: client.RedirectUris,
+		Scopes: client.Scopes,
+		CreatedAt: client.CreatedAt,
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+type oauthAuthorizeRequest struct {
+	ResponseType string `form:"response_type" json:"response_type" binding:"required,eq=code"`
+	ClientID string `form:"client_id" json:"client_id" binding:"required"`
+	RedirectURI string `form:"redirect_uri" json:"redirect_uri" binding:"required"`
+	// Scope is space delimited, all the scopes of the client are requested when it is empty
+	Scope string `form:"scope" json:"scope"`
+	State string `form:"state" json:"state"`
+	// CodeChallenge is the unpadded base64url of a sha256 digest
+	CodeChallenge string `form:"code_challenge" json:"code_challenge" binding:"required,len=43"`
+	CodeChallengeMethod string `form:"code_challenge_method" json:"code_challenge_method" binding:"required,eq=S256"`
+}
+
+type oauthConsentResponse struct {
+	ClientID string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	RedirectURI string `json:"redirect_uri"`
+	Scopes []string `json:"scopes"`
+	State string `json:"state"`
+}
+
+// getOauthConsent validates an authorization request and describes what the user is asked to consent to
+func (server *Server) getOauthConsent(ctx *gin.Context) {
+	var req oauthAuthorizeRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	client, scopes, ok := server.checkOauthAuthorizeRequest(ctx, req)
+	if !ok {
+		return
+	}
+
+	res := oauthConsentResponse{
+		ClientID: client.ClientID,
+		ClientName: client.Name,
+		RedirectURI: req.RedirectURI,
+		Scopes: scopes,
+		State: req.State,
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type oauthAuthorizeConsentRequest struct {
+	oauthAuthorizeRequest
+	Approved *bool `json:"approved" binding:"required"`
+}
+
+type oauthAuthorizeResponse struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+// oauthAuthorize records the decision of the user and returns where the user agent must be redirected
+func (server *Server) oauthAuthorize(ctx *gin.Context) {
+	var req oauthAuthorizeConsentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	client, scopes, ok := server.checkOauthAuthorizeRequest(ctx, req.oauthAuthorizeRequest)
+	if !ok {
+		return
+	}
+
+	query := url.Values{}
+	if len(req.State) > 0 {
+		query.Set("state", req.State)
+	}
+
+	if !*req.Approved {
+		query.Set("error", OAUTH_ERROR_ACCESS_DENIED)
+		ctx.JSON(http.StatusOK, oauthAuthorizeResponse{RedirectTo: withQuery(req.RedirectURI, query)})
+		return
+	}
+
+	code, err := util.RandomSecret(OAUTH_CODE_BYTES)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	arg := db.CreateOauthAuthorizationCodeParams{
+		CodeHash: util.HashSecret(code),
+		ClientID: client.ClientID,
+		Username: authPayload.Username,
+		RedirectUri: req.RedirectURI,
+		Scopes: scopes,
+		CodeChallenge: req.CodeChallenge,
+		ExpiredAt: time.Now().Add(server.config.OauthCodeDuration),
+	}
+
+	_, err = server.store.CreateOauthAuthorizationCode(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	query.Set("code", code)
+	ctx.JSON(http.StatusOK, oauthAuthorizeResponse{RedirectTo: withQuery(req.RedirectURI, query)})
+}
+
+// checkOauthAuthorizeRequest loads the client and resolves the requested scopes,
+// it writes the error response and returns false when the request is invalid
+func (server *Server) checkOauthAuthorizeRequest(ctx *gin.Context, req oauthAuthorizeRequest) (db.OauthClient, []string, bool) {
+	client, err := server.store.GetOauthClient(ctx, req.ClientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, "unknown client"))
+			return client, nil, false
+		}
+
+		abortWithError(ctx, err)
+		return client, nil, false
+	}
+
+	// never redirect to an uri that was not registered, the code would leak
+	if !containsString(client.RedirectUris, req.RedirectURI) {
+		abortWithError(ctx, newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, "redirect_uri is not registered for the client"))
+		return client, nil, false
+	}
+
+	scopes := strings.Fields(req.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	for _, scope := range scopes {
+		if !containsString(client.Scopes, scope) {
+			message := fmt.Sprintf("the client cannot request the %s scope", scope)
+			abortWithError(ctx, newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, message))
+			return client, nil, false
+		}
+	}
+
+	return client, scopes, true
+}
+
+type oauthTokenRequest struct {
+	GrantType string `form:"grant_type" binding:"required"`
+	Code string `form:"code"`
+	RedirectURI string `form:"redirect_uri"`
+	CodeVerifier string `form:"code_verifier"`
+	RefreshToken string `form:"refresh_token"`
+	ClientID string `form:"client_id"`
+	ClientSecret string `form:"client_secret"`
+}
+
+type oauthTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType string `json:"token_type"`
+	ExpiresIn int64 `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+	Scope string `json:"scope"`
+}
+
+// oauthToken implements the authorization_code and refresh_token grants of the token endpoint
+func (server *Server) oauthToken(ctx *gin.Context) {
+	ctx.Header("Cache-Control", "no-store")
+
+	var req oauthTokenRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_ERROR_INVALID_REQUEST, err))
+		return
+	}
+
+	client, ok := server.authenticateOauthClient(ctx, req)
+	if !ok {
+		return
+	}
+
+	refreshToken, err := util.RandomSecret(OAUTH_REFRESH_TOKEN_BYTES)
+	if err != nil {
+		oauthServerError(ctx, err)
+		return
+	}
+
+	var grant db.OauthRefreshToken
+	switch req.GrantType {
+	case "authorization_code":
+		if len(req.Code) == 0 || len(req.RedirectURI) == 0 || len(req.CodeVerifier) < 43 || len(req.CodeVerifier) > 128 {
+			err := errors.New("code, redirect_uri and a code_verifier of 43 to 128 characters are required")
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_ERROR_INVALID_REQUEST, err))
+			return
+		}
+
+		grant, err = server.store.ExchangeOauthCodeTx(ctx, db.ExchangeOauthCodeTxParams{
+			CodeHash: util.HashSecret(req.Code),
+			ClientID: client.ClientID,
+			VerifyCode: func(code db.OauthAuthorizationCode) error {
+				if code.RedirectUri != req.RedirectURI {
+					return errInvalidOauthGrant
+				}
+
+				challenge := pkceChallenge(req.CodeVerifier)
+				if subtle.ConstantTimeCompare([]byte(challenge), []byte(code.CodeChallenge)) != 1 {
+					return errInvalidOauthGrant
+				}
+				return nil
+			},
+			RefreshTokenHash: util.HashSecret(refreshToken),
+			RefreshTokenExpiredAt: time.Now().Add(server.config.RefreshTokenDuration),
+		})
+	case "refresh_token":
+		if len(req.RefreshToken) == 0 {
+			err := errors.New("refresh_token is required")
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_ERROR_INVALID_REQUEST, err))
+			return
+		}
+
+		grant, err = server.store.RefreshOauthTokenTx(ctx, db.RefreshOauthTokenTxParams{
+			TokenHash: util.HashSecret(req.RefreshToken),
+			ClientID: client.ClientID,
+			NewTokenHash: util.HashSecret(refreshToken),
+			NewTokenExpiredAt: time.Now().Add(server.config.RefreshTokenDuration),
+		})
+	default:
+		err := fmt.Errorf("grant type %s is not supported", req.GrantType)
+		ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_ERROR_UNSUPPORTED_GRANT_TYPE, err))
+		return
+	}
+
+	if err != nil {
+		if err == sql.ErrNoRows || err == errInvalidOauthGrant {
+			ctx.JSON(http.StatusBadRequest, oauthErrorResponse(OAUTH_ERROR_INVALID_GRANT, errInvalidOauthGrant))
+			return
+		}
+
+		oauthServerError(ctx, err)
+		return
+	}
+
+	accessToken, err := server.tokenManager.CreateToken(
+		grant.Username,
+		grant.Scopes,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		oauthServerError(ctx, err)
+		return
+	}
+
+	res := oauthTokenResponse{
+		AccessToken: accessToken,
+		TokenType: "Bearer",
+		ExpiresIn: int64(server.config.AccessTokenDuration / time.Second),
+		RefreshToken: refreshToken,
+		Scope: strings.Join(grant.Scopes, " "),
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// authenticateOauthClient accepts the client credentials from HTTP basic auth or from the form,
+// it writes the error response and returns false when they are invalid
+func (server *Server) authenticateOauthClient(ctx *gin.Context, req oauthTokenRequest) (db.OauthClient, bool) {
+	clientID, clientSecret, ok := ctx.Request.BasicAuth()
+	if !ok {
+		clientID, clientSecret = req.ClientID, req.ClientSecret
+	}
+
+	invalidClient := func() {
+		err := errors.New("client authentication failed")
+		ctx.Header("WWW-Authenticate", `Basic realm="oauth"`)
+		ctx.JSON(http.StatusUnauthorized, oauthErrorResponse(OAUTH_ERROR_INVALID_CLIENT, err))
+	}
+
+	if len(clientID) == 0 || len(clientSecret) == 0 {
+		invalidClient()
+		return db.OauthClient{}, false
+	}
+
+	client, err := server.store.GetOauthClient(ctx, clientID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			invalidClient()
+			return client, false
+		}
+
+		oauthServerError(ctx, err)
+		return client, false
+	}
+
+	secretHash := util.HashSecret(clientSecret)
+	if subtle.ConstantTimeCompare([]byte(secretHash), []byte(client.SecretHash)) != 1 {
+		invalidClient()
+		return client, false
+	}
+
+	return client, true
+}
+
+// pkceChallenge derives the S256 code challenge of RFC 7636 from a code verifier
+func pkceChallenge(codeVerifier string) string {
+	digest := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+// withQuery adds the query parameters to an uri that may already have some
+func withQuery(uri string, query url.Values) string {
+	separator := "?"
+	if strings.Contains(uri, "?") {
+		separator = "&"
+	}
+	return uri + separator + query.Encode()
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,717 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+const testCodeVerifier = "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+
+func randomOauthClient(t *testing.T, owner string) (client db.OauthClient, secret string) {
+	secret, err := util.RandomSecret(OAUTH_CLIENT_SECRET_BYTES)
+	require.NoError(t, err)
+
+	client = db.OauthClient{
+		ClientID: util.RandomString(16),
+		Owner: owner,
+		Name: util.RandomOwner(),
+		SecretHash: util.HashSecret(secret),
+		RedirectUris: []string{"https://budget.example.com/callback"},
+		Scopes: []string{token.SCOPE_ACCOUNTS_READ, token.SCOPE_USER_READ},
+		CreatedAt: time.Now(),
+	}
+	return
+}
+
+func TestPkceChallenge(t *testing.T) {
+	// example of RFC 7636 appendix B
+	require.Equal(t, "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM", pkceChallenge(testCodeVerifier))
+}
+
+func TestCreateOauthClientAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{
+				"name": "budget app",
+				"redirect_uris": []string{"https://budget.example.com/callback"},
+				"scopes": []string{token.SCOPE_ACCOUNTS_READ},
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateOauthClient(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateOauthClientParams) (db.OauthClient, error) {
+					require.Equal(t, user.Username, arg.Owner)
+					require.Equal(t, "budget app", arg.Name)
+					require.NotEmpty(t, arg.ClientID)
+					require.NotEmpty(t, arg.SecretHash)
+					return db.OauthClient{
+						ClientID: arg.ClientID,
+						Owner: arg.Owner,
+						Name: arg.Name,
+						SecretHash: arg.SecretHash,
+						RedirectUris: arg.RedirectUris,
+						Scopes: arg.Scopes,
+					}, nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got oauthClientResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotEmpty(t, got.ClientID)
+				require.NotEmpty(t, got.ClientSecret)
+				require.Equal(t, []string{token.SCOPE_ACCOUNTS_READ}, got.Scopes)
+			},
+		},
+		{
+			name: "Write Scope",
+			body: gin.H{
+				"name": "budget app",
+				"redirect_uris": []string{"https://budget.example.com/callback"},
+				"scopes": []string{token.SCOPE_TRANSFERS_WRITE},
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateOauthClient(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Redirect URI",
+			body: gin.H{
+				"name": "budget app",
+				"redirect_uris": []string{"not an uri"},
+				"scopes": []string{token.SCOPE_ACCOUNTS_READ},
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateOauthClient(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/clients", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetOauthConsentAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOauthClient(t, "developer")
+
+	testCases := []struct {
+		name string
+		query url.Values
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			query: url.Values{
+				"response_type": {"code"},
+				"client_id": {client.ClientID},
+				"redirect_uri": {client.RedirectUris[0]},
+				"scope": {token.SCOPE_ACCOUNTS_READ},
+				"state": {"xyz"},
+				"code_challenge": {pkceChallenge(testCodeVerifier)},
+				"code_challenge_method": {"S256"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got oauthConsentResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, client.Name, got.ClientName)
+				require.Equal(t, []string{token.SCOPE_ACCOUNTS_READ}, got.Scopes)
+				require.Equal(t, "xyz", got.State)
+			},
+		},
+		{
+			name: "Unregistered Redirect URI",
+			query: url.Values{
+				"response_type": {"code"},
+				"client_id": {client.ClientID},
+				"redirect_uri": {"https://evil.example.com/callback"},
+				"code_challenge": {pkceChallenge(testCodeVerifier)},
+				"code_challenge_method": {"S256"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Scope Not Registered",
+			query: url.Values{
+				"response_type": {"code"},
+				"client_id": {client.ClientID},
+				"redirect_uri": {client.RedirectUris[0]},
+				"scope": {token.SCOPE_TRANSFERS_READ},
+				"code_challenge": {pkceChallenge(testCodeVerifier)},
+				"code_challenge_method": {"S256"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Plain PKCE Method",
+			query: url.Values{
+				"response_type": {"code"},
+				"client_id": {client.ClientID},
+				"redirect_uri": {client.RedirectUris[0]},
+				"code_challenge": {testCodeVerifier},
+				"code_challenge_method": {"plain"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Read Only Token Cannot Consent",
+			query: url.Values{
+				"response_type": {"code"},
+				"client_id": {client.ClientID},
+				"redirect_uri": {client.RedirectUris[0]},
+				"code_challenge": {pkceChallenge(testCodeVerifier)},
+				"code_challenge_method": {"S256"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addScopedAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusForbidden, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			store.EXPECT().
+			GetOauthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+			AnyTimes().
+			Return(client, nil)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/oauth/authorize?"+tc.query.Encode(), nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestOauthAuthorizeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, _ := randomOauthClient(t, "developer")
+
+	body := func(approved bool) gin.H {
+		return gin.H{
+			"response_type": "code",
+			"client_id": client.ClientID,
+			"redirect_uri": client.RedirectUris[0],
+			"state": "xyz",
+			"code_challenge": pkceChallenge(testCodeVerifier),
+			"code_challenge_method": "S256",
+			"approved": approved,
+		}
+	}
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Approved",
+			body: body(true),
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateOauthAuthorizationCode(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreateOauthAuthorizationCodeParams) (db.OauthAuthorizationCode, error) {
+					require.Equal(t, client.ClientID, arg.ClientID)
+					require.Equal(t, user.Username, arg.Username)
+					require.Equal(t, client.RedirectUris[0], arg.RedirectUri)
+					require.Equal(t, client.Scopes, arg.Scopes)
+					require.Equal(t, pkceChallenge(testCodeVerifier), arg.CodeChallenge)
+					return db.OauthAuthorizationCode{CodeHash: arg.CodeHash}, nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				redirect := requireOauthRedirect(t, recorder)
+				require.True(t, strings.HasPrefix(redirect.String(), client.RedirectUris[0]+"?"))
+				require.Equal(t, "xyz", redirect.Query().Get("state"))
+				require.NotEmpty(t, redirect.Query().Get("code"))
+			},
+		},
+		{
+			name: "Denied",
+			body: body(false),
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateOauthAuthorizationCode(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				redirect := requireOauthRedirect(t, recorder)
+				require.Equal(t, OAUTH_ERROR_ACCESS_DENIED, redirect.Query().Get("error"))
+				require.Empty(t, redirect.Query().Get("code"))
+			},
+		},
+		{
+			name: "Missing Decision",
+			body: gin.H{
+				"response_type": "code",
+				"client_id": client.ClientID,
+				"redirect_uri": client.RedirectUris[0],
+				"code_challenge": pkceChallenge(testCodeVerifier),
+				"code_challenge_method": "S256",
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CreateOauthAuthorizationCode(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+			GetOauthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+			AnyTimes().
+			Return(client, nil)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/authorize", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func requireOauthRedirect(t *testing.T, recorder *httptest.ResponseRecorder) *url.URL {
+	var res oauthAuthorizeResponse
+	err := json.Unmarshal(recorder.Body.Bytes(), &res)
+	require.NoError(t, err)
+
+	redirect, err := url.Parse(res.RedirectTo)
+	require.NoError(t, err)
+	return redirect
+}
+
+func TestOauthTokenAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	client, clientSecret := randomOauthClient(t, "developer")
+	code := "authorization-code"
+	refreshToken := "refresh-token"
+
+	// grant mimics the store, calling VerifyCode with the code it used
+	grant := func(store *testdb.MockStore, codeChallenge string) {
+		store.EXPECT().
+		ExchangeOauthCodeTx(gomock.Any(), gomock.Any()).
+		Times(1).
+		DoAndReturn(func(_ context.Context, arg db.ExchangeOauthCodeTxParams) (db.OauthRefreshToken, error) {
+			require.Equal(t, util.HashSecret(code), arg.CodeHash)
+			require.Equal(t, client.ClientID, arg.ClientID)
+
+			err := arg.VerifyCode(db.OauthAuthorizationCode{
+				ClientID: client.ClientID,
+				Username: user.Username,
+				RedirectUri: client.RedirectUris[0],
+				Scopes: []string{token.SCOPE_ACCOUNTS_READ},
+				CodeChallenge: codeChallenge,
+			})
+			if err != nil {
+				return db.OauthRefreshToken{}, err
+			}
+
+			return db.OauthRefreshToken{
+				TokenHash: arg.RefreshTokenHash,
+				ClientID: client.ClientID,
+				Username: user.Username,
+				Scopes: []string{token.SCOPE_ACCOUNTS_READ},
+			}, nil
+		})
+	}
+
+	testCases := []struct {
+		name string
+		form url.Values
+		setupAuth func(request *http.Request)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Authorization Code",
+			form: url.Values{
+				"grant_type": {"authorization_code"},
+				"code": {code},
+				"redirect_uri": {client.RedirectUris[0]},
+				"code_verifier": {testCodeVerifier},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, clientSecret)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				grant(store, pkceChallenge(testCodeVerifier))
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "no-store", recorder.Header().Get("Cache-Control"))
+
+				var got oauthTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, "Bearer", got.TokenType)
+				require.Equal(t, token.SCOPE_ACCOUNTS_READ, got.Scope)
+				require.NotEmpty(t, got.RefreshToken)
+
+				payload, err := server.tokenManager.VerifyToken(got.AccessToken)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, []string{token.SCOPE_ACCOUNTS_READ}, payload.Scopes)
+			},
+		},
+		{
+			name: "Client Secret In Form",
+			form: url.Values{
+				"grant_type": {"authorization_code"},
+				"code": {code},
+				"redirect_uri": {client.RedirectUris[0]},
+				"code_verifier": {testCodeVerifier},
+				"client_id": {client.ClientID},
+				"client_secret": {clientSecret},
+			},
+			setupAuth: func(request *http.Request) {
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				grant(store, pkceChallenge(testCodeVerifier))
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Wrong Code Verifier",
+			form: url.Values{
+				"grant_type": {"authorization_code"},
+				"code": {code},
+				"redirect_uri": {client.RedirectUris[0]},
+				"code_verifier": {testCodeVerifier},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, clientSecret)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				grant(store, pkceChallenge(util.RandomString(43)))
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), OAUTH_ERROR_INVALID_GRANT)
+			},
+		},
+		{
+			name: "Used Code",
+			form: url.Values{
+				"grant_type": {"authorization_code"},
+				"code": {code},
+				"redirect_uri": {client.RedirectUris[0]},
+				"code_verifier": {testCodeVerifier},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, clientSecret)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ExchangeOauthCodeTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.OauthRefreshToken{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), OAUTH_ERROR_INVALID_GRANT)
+			},
+		},
+		{
+			name: "Missing Code Verifier",
+			form: url.Values{
+				"grant_type": {"authorization_code"},
+				"code": {code},
+				"redirect_uri": {client.RedirectUris[0]},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, clientSecret)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ExchangeOauthCodeTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), OAUTH_ERROR_INVALID_REQUEST)
+			},
+		},
+		{
+			name: "Wrong Client Secret",
+			form: url.Values{
+				"grant_type": {"authorization_code"},
+				"code": {code},
+				"redirect_uri": {client.RedirectUris[0]},
+				"code_verifier": {testCodeVerifier},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, "wrong secret")
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ExchangeOauthCodeTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				require.Contains(t, recorder.Body.String(), OAUTH_ERROR_INVALID_CLIENT)
+			},
+		},
+		{
+			name: "Refresh Token",
+			form: url.Values{
+				"grant_type": {"refresh_token"},
+				"refresh_token": {refreshToken},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, clientSecret)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				RefreshOauthTokenTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.RefreshOauthTokenTxParams) (db.OauthRefreshToken, error) {
+					require.Equal(t, util.HashSecret(refreshToken), arg.TokenHash)
+					require.Equal(t, client.ClientID, arg.ClientID)
+					require.NotEqual(t, arg.TokenHash, arg.NewTokenHash)
+					return db.OauthRefreshToken{
+						TokenHash: arg.NewTokenHash,
+						ClientID: client.ClientID,
+						Username: user.Username,
+						Scopes: []string{token.SCOPE_ACCOUNTS_READ},
+					}, nil
+				})
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got oauthTokenResponse
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotEqual(t, refreshToken, got.RefreshToken)
+			},
+		},
+		{
+			name: "Revoked Refresh Token",
+			form: url.Values{
+				"grant_type": {"refresh_token"},
+				"refresh_token": {refreshToken},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, clientSecret)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				RefreshOauthTokenTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.OauthRefreshToken{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), OAUTH_ERROR_INVALID_GRANT)
+			},
+		},
+		{
+			name: "Unsupported Grant Type",
+			form: url.Values{
+				"grant_type": {"password"},
+			},
+			setupAuth: func(request *http.Request) {
+				request.SetBasicAuth(client.ClientID, clientSecret)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+			},
+			checkResponse: func(t *testing.T, server *Server, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				require.Contains(t, recorder.Body.String(), OAUTH_ERROR_UNSUPPORTED_GRANT_TYPE)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			store.EXPECT().
+			GetOauthClient(gomock.Any(), gomock.Eq(client.ClientID)).
+			AnyTimes().
+			Return(client, nil)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/oauth/token", strings.NewReader(tc.form.Encode()))
+			require.NoError(t, err)
+			request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			tc.setupAuth(request)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, server, recorder)
+		})
+	}
+}
+
+func TestOauthAccessTokenIsReadOnly(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+	Times(1).
+	Return(account, nil)
+	store.EXPECT().
+	GetHeldAmount(gomock.Any(), gomock.Eq(account.ID)).
+	Times(1).
+	Return(int64(0), nil)
+	store.EXPECT().
+	TransferTx(gomock.Any(), gomock.Any()).
+	Times(0)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+
+	recorder := httptest.NewRecorder()
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/account/%d", account.ID), nil)
+	require.NoError(t, err)
+	addScopedAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	data, err := json.Marshal(gin.H{
+		"from_account_id": account.ID,
+		"to_account_id": account.ID + 1,
+		"amount": 1,
+		"currency": account.Currency,
+	})
+	require.NoError(t, err)
+
+	recorder = httptest.NewRecorder()
+	request, err = http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+	require.NoError(t, err)
+	addScopedAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusForbidden, recorder.Code)
+}
@@ -0,0 +1,578 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/statement"
+	"github.com/sssaang/simplebank/token"
+)
+
+const (
+	SECURITY_NONE = ""
+	// SECURITY_USER accepts a bearer token or an API key, as authMiddleware does
+	SECURITY_USER = "user"
+	// SECURITY_CLIENT is HTTP basic auth of a service or an OAuth client
+	SECURITY_CLIENT = "client"
+)
+
+// apiOperation documents a route registered in NewServer.
+// The schemas are derived by reflection from the structs the handler binds and responds with,
+// so that the binding tags stay the single source of the validation rules.
+type apiOperation struct {
+	method string
+	path string
+	summary string
+	security string
+	scope string
+	role string
+	uri interface{}
+	query interface{}
+	form interface{}
+	body interface{}
+	status int
+	response interface{}
+	// documents are the media types of a response which is a file rather than JSON
+	documents []string
+	// errorResponse is the body of the error responses, ApiError problem details unless set
+	errorResponse interface{}
+}
+
+type messageResponse struct {
+	Message string `json:"message"`
+}
+
+var apiOperations = []apiOperation{
+	{method: http.MethodPost, path: "/user", summary: "Register a user and mail them an email verification link",
+		body: createUserRequest{}, status: http.StatusCreated, response: userResponse{}},
+	{method: http.MethodPost, path: "/login", summary: "Log in and receive an access token with every scope",
+		body: loginUserRequest{}, status: http.StatusOK, response: loginUserResponse{}},
+	{method: http.MethodPost, path: "/user/password/reset", summary: "Mail a password reset token if the email is registered",
+		body: requestPasswordResetRequest{}, status: http.StatusAccepted, response: messageResponse{}},
+	{method: http.MethodPost, path: "/user/password/reset/confirm", summary: "Set a new password with a password reset token",
+		body: resetPasswordRequest{}, status: http.StatusOK, response: userResponse{}},
+	{method: http.MethodGet, path: "/verify_email", summary: "Verify an email with the code of the verification link",
+		query: verifyEmailRequest{}, status: http.StatusOK, response: userResponse{}},
+	{method: http.MethodPost, path: "/introspect", summary: "Tell an internal service whether an access token is active (RFC 7662)",
+		security: SECURITY_CLIENT, form: introspectTokenRequest{}, status: http.StatusOK, response: token.IntrospectionResponse{}},
+	{method: http.MethodPost, path: "/oauth/token", summary: "Exchange an authorization code or a refresh token for an access token",
+		security: SECURITY_CLIENT, form: oauthTokenRequest{}, status: http.StatusOK, response: oauthTokenResponse{}, errorResponse: oauthError{}},
+	{method: http.MethodGet, path: "/openapi.json", summary: "This document",
+		status: http.StatusOK, response: map[string]interface{}{}},
+	{method: http.MethodGet, path: "/docs", summary: "Swagger UI for this document",
+		status: http.StatusOK},
+	{method: http.MethodGet, path: "/currencies", summary: "List the currencies accounts and transfers can use",
+		status: http.StatusOK, response: []currencyResponse{}},
+	{method: http.MethodGet, path: "/exchange_rates", summary: "List the exchange rates applied to the conversions within wallets",
+		status: http.StatusOK, response: []db.ExchangeRate{}},
+	{method: http.MethodGet, path: "/interest_rates", summary: "List the schedule of the annual interest rates savings accounts earn",
+		status: http.StatusOK, response: []interestRateResponse{}},
+	{method: http.MethodGet, path: "/fee_schedules", summary: "List the fees charged by type of transfer and currency on top of the amount",
+		status: http.StatusOK, response: []feeScheduleResponse{}},
+
+	{method: http.MethodGet, path: "/user/:username", summary: "Get the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_USER_READ, uri: getUserRequest{}, status: http.StatusOK, response: userResponse{}},
+	{method: http.MethodPatch, path: "/user/:username", summary: "Update the full name or the email of the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_USER_WRITE, uri: updateUserURI{}, body: updateUserRequest{}, status: http.StatusOK, response: userResponse{}},
+	{method: http.MethodGet, path: "/user/:username/export", summary: "Export every data held about the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_USER_READ, uri: userDataURI{}, status: http.StatusOK, response: userDataExportResponse{}},
+	{method: http.MethodPost, path: "/user/:username/erasure", summary: "Erase the personal data of the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_USER_WRITE, uri: userDataURI{}, body: eraseUserRequest{}, status: http.StatusOK, response: db.ErasureRequest{}},
+	{method: http.MethodPost, path: "/verify_email/resend", summary: "Mail a new verification link to the authenticated user, the links sent before stop working",
+		security: SECURITY_USER, scope: token.SCOPE_USER_WRITE, status: http.StatusAccepted, response: messageResponse{}},
+	{method: http.MethodPut, path: "/user/password", summary: "Change the password, which revokes the tokens issued before",
+		security: SECURITY_USER, scope: token.SCOPE_USER_WRITE, body: changePasswordRequest{}, status: http.StatusOK, response: userResponse{}},
+	{method: http.MethodPost, path: "/account", summary: "Open an account",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, body: createAccountRequest{}, status: http.StatusCreated, response: accountResponse{}},
+	{method: http.MethodGet, path: "/account/:id", summary: "Get an account of the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, uri: getAccountRequest{}, status: http.StatusOK, response: accountResponse{}},
+	{method: http.MethodGet, path: "/accounts", summary: "List the accounts of the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, query: listAccountsRequest{}, status: http.StatusOK, response: []accountResponse{}},
+	{method: http.MethodGet, path: "/accounts/:id/statements", summary: "Download the statement of an account for a period, closed calendar months are served from snapshots",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, uri: statementURI{}, query: statementRequest{}, status: http.StatusOK,
+		documents: []string{statementContentTypes[statement.FORMAT_CSV], statementContentTypes[statement.FORMAT_PDF]}},
+	{method: http.MethodGet, path: "/accounts/:id/transfers", summary: "Search the transfers of an account by text in their description or reference, by reference and by metadata[key]=value pairs, newest first, without the account of a user paid by username or email",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, uri: accountTransfersURI{}, query: listAccountTransfersRequest{}, status: http.StatusOK, response: []transferResponse{}},
+	{method: http.MethodGet, path: "/accounts/:id/holds", summary: "List the active holds of an account, the funds they reserve are not available to spend",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, uri: accountHoldsURI{}, status: http.StatusOK, response: []holdResponse{}},
+	{method: http.MethodGet, path: "/accounts/:id/members", summary: "List the members of an account with their roles, owners, spenders and viewers",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, uri: accountMembersURI{}, status: http.StatusOK, response: []accountMemberResponse{}},
+	{method: http.MethodPost, path: "/accounts/:id/members", summary: "Invite another user to share an account as an owner, a spender or a viewer, only owners invite and the user becomes a member once it accepts",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, uri: accountMembersURI{}, body: inviteAccountMemberRequest{}, status: http.StatusCreated, response: accountInviteResponse{}},
+	{method: http.MethodDelete, path: "/accounts/:id/members/:username", summary: "Remove a member from an account, owners remove anyone but the holder and members may leave",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, uri: accountMemberURI{}, status: http.StatusOK, response: accountMemberResponse{}},
+	{method: http.MethodGet, path: "/accounts/:id/invites", summary: "List the invites to an account its users have not answered yet, to its owners",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, uri: accountMembersURI{}, status: http.StatusOK, response: []accountInviteResponse{}},
+	{method: http.MethodDelete, path: "/accounts/:id/invites/:username", summary: "Withdraw an invite to an account before it is answered, only owners withdraw",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, uri: accountMemberURI{}, status: http.StatusOK, response: accountInviteResponse{}},
+	{method: http.MethodGet, path: "/account_invites", summary: "List the invites of the authenticated user to share accounts, newest first",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, status: http.StatusOK, response: []accountInviteResponse{}},
+	{method: http.MethodPost, path: "/account_invites/:account_id/accept", summary: "Accept an invite, the authenticated user becomes a member of the account in the role it was invited to",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, uri: accountInviteURI{}, status: http.StatusOK, response: accountMemberResponse{}},
+	{method: http.MethodPost, path: "/account_invites/:account_id/decline", summary: "Decline an invite to share an account",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, uri: accountInviteURI{}, status: http.StatusOK, response: accountInviteResponse{}},
+	{method: http.MethodPost, path: "/transfer", summary: "Transfer money between two accounts of the same currency, to an account, a payee or a user by username or email, transfers flagged by the fraud rules are held for review with 202",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, body: transferRequest{}, status: http.StatusOK, response: transferTxResponse{}},
+	{method: http.MethodPost, path: "/transfers/batch", summary: "Pay up to 500 accounts from one account, atomically or best effort with a result per transfer",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, body: batchTransferRequest{}, status: http.StatusOK, response: batchTransferResponse{}},
+	{method: http.MethodGet, path: "/limits", summary: "Get the transfer limits of the authenticated user with what remains of them today and this month",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, status: http.StatusOK, response: []limitResponse{}},
+	{method: http.MethodGet, path: "/accounts/:id/limits", summary: "Get the transfer limits of an account with what remains of them today and this month, they apply on top of the limits of the member transferring",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, uri: accountLimitsURI{}, status: http.StatusOK, response: accountLimitsResponse{}},
+	{method: http.MethodPut, path: "/accounts/:id/limits", summary: "Set the transfer limits of an account, a limit left out no longer applies, only owners set them",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, uri: accountLimitsURI{}, body: transferLimitRequest{}, status: http.StatusOK, response: transferLimitResponse{}},
+	{method: http.MethodDelete, path: "/accounts/:id/limits", summary: "Remove the transfer limits of an account, only owners remove them",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_WRITE, uri: accountLimitsURI{}, status: http.StatusOK, response: transferLimitResponse{}},
+	{method: http.MethodPost, path: "/payees", summary: "Save an account as a payee with a nickname, a first large transfer to an account waits until it has been a payee for the cooling-off period",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, body: createPayeeRequest{}, status: http.StatusCreated, response: payeeResponse{}},
+	{method: http.MethodGet, path: "/payees", summary: "List the payees of the authenticated user by nickname",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, status: http.StatusOK, response: []payeeResponse{}},
+	{method: http.MethodGet, path: "/payees/:id", summary: "Get a payee of the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, uri: payeeURI{}, status: http.StatusOK, response: payeeResponse{}},
+	{method: http.MethodPatch, path: "/payees/:id", summary: "Rename a payee, its account cannot change",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, uri: payeeURI{}, body: updatePayeeRequest{}, status: http.StatusOK, response: payeeResponse{}},
+	{method: http.MethodDelete, path: "/payees/:id", summary: "Delete a payee",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, uri: payeeURI{}, status: http.StatusOK, response: payeeResponse{}},
+	{method: http.MethodPost, path: "/payment_requests", summary: "Request a payment from another user, named by its username or its email, into an account",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, body: createPaymentRequestRequest{}, status: http.StatusCreated, response: paymentRequestResponse{}},
+	{method: http.MethodGet, path: "/payment_requests/incoming", summary: "List the pending payment requests the authenticated user can accept or decline",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, query: listPaymentRequestsRequest{}, status: http.StatusOK, response: []paymentRequestResponse{}},
+	{method: http.MethodGet, path: "/payment_requests/outgoing", summary: "List the payment requests the authenticated user sent, in any status",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, query: listPaymentRequestsRequest{}, status: http.StatusOK, response: []paymentRequestResponse{}},
+	{method: http.MethodGet, path: "/payment_requests/:id", summary: "Get a payment request the authenticated user sent or received",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, uri: paymentRequestURI{}, status: http.StatusOK, response: paymentRequestResponse{}},
+	{method: http.MethodGet, path: "/payment_requests/:id/events", summary: "List every status a payment request went through and who changed it",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_READ, uri: paymentRequestURI{}, status: http.StatusOK, response: []paymentRequestEventResponse{}},
+	{method: http.MethodPost, path: "/payment_requests/:id/accept", summary: "Pay a pending payment request from an account of the payer, a payment flagged by the fraud rules is held for review with 202 and accepts the request once approved",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, uri: paymentRequestURI{}, body: acceptPaymentRequestRequest{}, status: http.StatusOK, response: acceptPaymentRequestResponse{}},
+	{method: http.MethodPost, path: "/payment_requests/:id/decline", summary: "Decline a pending payment request, only its payer can",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, uri: paymentRequestURI{}, status: http.StatusOK, response: paymentRequestResponse{}},
+	{method: http.MethodPost, path: "/payment_requests/:id/cancel", summary: "Cancel a pending payment request, only its requester can",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, uri: paymentRequestURI{}, status: http.StatusOK, response: paymentRequestResponse{}},
+	{method: http.MethodGet, path: "/wallets/:id", summary: "Get a wallet of the authenticated user with its balance in every currency",
+		security: SECURITY_USER, scope: token.SCOPE_ACCOUNTS_READ, uri: walletURI{}, status: http.StatusOK, response: walletResponse{}},
+	{method: http.MethodPost, path: "/wallets/:id/conversions", summary: "Convert money between two currencies of a wallet at the current exchange rate",
+		security: SECURITY_USER, scope: token.SCOPE_TRANSFERS_WRITE, uri: walletURI{}, body: convertCurrencyRequest{}, status: http.StatusOK, response: convertCurrencyResponse{}},
+	{method: http.MethodPost, path: "/api_keys", summary: "Create an API key, the key is only returned once",
+		security: SECURITY_USER, scope: token.SCOPE_API_KEYS_WRITE, body: createApiKeyRequest{}, status: http.StatusCreated, response: createApiKeyResponse{}},
+	{method: http.MethodGet, path: "/api_keys", summary: "List the API keys of the authenticated user",
+		security: SECURITY_USER, scope: token.SCOPE_API_KEYS_READ, status: http.StatusOK, response: []apiKeyResponse{}},
+	{method: http.MethodDelete, path: "/api_keys/:id", summary: "Revoke an API key",
+		security: SECURITY_USER, scope: token.SCOPE_API_KEYS_WRITE, uri: revokeApiKeyRequest{}, status: http.StatusOK, response: apiKeyResponse{}},
+	{method: http.MethodPost, path: "/oauth/clients", summary: "Register a third-party OAuth client with read-only scopes",
+		security: SECURITY_USER, scope: token.SCOPE_USER_WRITE, body: createOauthClientRequest{}, status: http.StatusCreated, response: oauthClientResponse{}},
+	{method: http.MethodGet, path: "/oauth/authorize", summary: "Describe what an OAuth client asks the user to consent to",
+		security: SECURITY_USER, scope: token.SCOPE_USER_WRITE, query: oauthAuthorizeRequest{}, status: http.StatusOK, response: oauthConsentResponse{}},
+	{method: http.MethodPost, path: "/oauth/authorize", summary: "Approve or deny an OAuth client and get the redirect of the user agent",
+		security: SECURITY_USER, scope: token.SCOPE_USER_WRITE, body: oauthAuthorizeConsentRequest{}, status: http.StatusOK, response: oauthAuthorizeResponse{}},
+
+	{method: http.MethodGet, path: "/admin/currencies", summary: "List every currency, including the disabled ones",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, status: http.StatusOK, response: []adminCurrencyResponse{}},
+	{method: http.MethodPost, path: "/admin/currencies", summary: "Add a currency",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, body: createCurrencyRequest{}, status: http.StatusCreated, response: adminCurrencyResponse{}},
+	{method: http.MethodPatch, path: "/admin/currencies/:code", summary: "Enable or disable a currency",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: updateCurrencyURI{}, body: updateCurrencyRequest{}, status: http.StatusOK, response: adminCurrencyResponse{}},
+	{method: http.MethodPut, path: "/admin/exchange_rates/:from_currency/:to_currency", summary: "Set the exchange rate between two currencies",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: setExchangeRateURI{}, body: setExchangeRateRequest{}, status: http.StatusOK, response: db.ExchangeRate{}},
+	{method: http.MethodPut, path: "/admin/interest_rates/:currency", summary: "Schedule the annual interest rate of a currency from a day on",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: setInterestRateURI{}, body: setInterestRateRequest{}, status: http.StatusOK, response: interestRateResponse{}},
+	{method: http.MethodPut, path: "/admin/fee_schedules/:transfer_type/:currency", summary: "Set the fee tiers of a type of transfer in a currency",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: feeScheduleURI{}, body: feeScheduleRequest{}, status: http.StatusOK, response: feeScheduleResponse{}},
+	{method: http.MethodDelete, path: "/admin/fee_schedules/:transfer_type/:currency", summary: "Stop charging a fee for a type of transfer in a currency",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: feeScheduleURI{}, status: http.StatusOK, response: feeScheduleResponse{}},
+	{method: http.MethodGet, path: "/admin/fraud_rules", summary: "List the fraud rules transfers are screened against",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, status: http.StatusOK, response: []fraudRuleResponse{}},
+	{method: http.MethodPost, path: "/admin/fraud_rules", summary: "Add a fraud rule, it applies to the next transfer",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, body: fraudRuleRequest{}, status: http.StatusCreated, response: fraudRuleResponse{}},
+	{method: http.MethodPut, path: "/admin/fraud_rules/:id", summary: "Replace a fraud rule, including enabling or disabling it",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: fraudRuleURI{}, body: fraudRuleRequest{}, status: http.StatusOK, response: fraudRuleResponse{}},
+	{method: http.MethodGet, path: "/admin/held_transfers", summary: "List the transfers held by the fraud rules, oldest first",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, query: listHeldTransfersRequest{}, status: http.StatusOK, response: []adminHeldTransferResponse{}},
+	{method: http.MethodPost, path: "/admin/held_transfers/:id/approve", summary: "Approve a held transfer and make it",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: heldTransferURI{}, status: http.StatusOK, response: reviewHeldTransferResponse{}},
+	{method: http.MethodPost, path: "/admin/held_transfers/:id/reject", summary: "Reject a held transfer",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: heldTransferURI{}, status: http.StatusOK, response: reviewHeldTransferResponse{}},
+	{method: http.MethodGet, path: "/admin/transfer_limits", summary: "List the default transfer limits of every currency",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, status: http.StatusOK, response: []transferLimitResponse{}},
+	{method: http.MethodPut, path: "/admin/transfer_limits/:currency", summary: "Set the default transfer limits of a currency, a limit left out no longer applies",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: defaultTransferLimitURI{}, body: transferLimitRequest{}, status: http.StatusOK, response: transferLimitResponse{}},
+	{method: http.MethodGet, path: "/admin/users/:username/transfer_limits", summary: "List the transfer limits a user has instead of the defaults",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: userTransferLimitsURI{}, status: http.StatusOK, response: []transferLimitResponse{}},
+	{method: http.MethodPut, path: "/admin/users/:username/transfer_limits/:currency", summary: "Give a user its own transfer limits in a currency, replacing the defaults",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: userTransferLimitURI{}, body: transferLimitRequest{}, status: http.StatusOK, response: transferLimitResponse{}},
+	{method: http.MethodDelete, path: "/admin/users/:username/transfer_limits/:currency", summary: "Put a user back on the default transfer limits of a currency",
+		security: SECURITY_USER, scope: token.SCOPE_ADMIN, role: util.ROLE_ADMIN, uri: userTransferLimitURI{}, status: http.StatusOK, response: transferLimitResponse{}},
+}
+
+// openAPISpec is built once, the operations and the structs they refer to do not change at runtime
+var openAPISpec = newOpenAPISpec(apiOperations)
+
+func newOpenAPISpec(operations []apiOperation) gin.H {
+	generator := &schemaGenerator{schemas: gin.H{}}
+
+	paths := gin.H{}
+	for _, operation := range operations {
+		path := openAPIPath(operation.path)
+		item, ok := paths[path].(gin.H)
+		if !ok {
+			item = gin.H{}
+			paths[path] = item
+		}
+		item[strings.ToLower(operation.method)] = generator.operation(operation)
+	}
+
+	return gin.H{
+		"openapi": "3.0.3",
+		"info": gin.H{
+			"title": "Simple Bank API",
+			"version": "1.0.0",
+		},
+		"paths": paths,
+		"components": gin.H{
+			"schemas": generator.schemas,
+			"securitySchemes": gin.H{
+				"bearerAuth": gin.H{"type": "http", "scheme": "bearer"},
+				"apiKeyAuth": gin.H{
+					"type": "apiKey",
+					"in": "header",
+					"name": "Authorization",
+					"description": "ApiKey followed by a personal API key",
+				},
+				"basicAuth": gin.H{"type": "http", "scheme": "basic"},
+			},
+		},
+	}
+}
+
+// openAPIPath turns the gin parameters of a path into OpenAPI templates, /account/:id becomes /account/{id}
+func openAPIPath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if strings.HasPrefix(segment, ":") {
+			segments[i] = "{" + segment[1:] + "}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+type schemaGenerator struct {
+	schemas gin.H
+}
+
+func (generator *schemaGenerator) operation(operation apiOperation) gin.H {
+	errorContent := gin.H{PROBLEM_CONTENT_TYPE: gin.H{"schema": generator.schema(reflect.TypeOf(ApiError{}))}}
+	if operation.errorResponse != nil {
+		errorContent = gin.H{"application/json": gin.H{"schema": generator.schema(reflect.TypeOf(operation.errorResponse))}}
+	}
+
+	op := gin.H{
+		"summary": operation.summary,
+		"responses": gin.H{
+			"default": gin.H{"description": "error", "content": errorContent},
+		},
+	}
+
+	success := gin.H{"description": http.StatusText(operation.status)}
+	if operation.response != nil {
+		success["content"] = gin.H{"application/json": gin.H{"schema": generator.schema(reflect.TypeOf(operation.response))}}
+	}
+	if len(operation.documents) > 0 {
+		content := gin.H{}
+		for _, document := range operation.documents {
+			content[document] = gin.H{"schema": gin.H{"type": "string", "format": "binary"}}
+		}
+		success["content"] = content
+	}
+	op["responses"].(gin.H)[strconv.Itoa(operation.status)] = success
+
+	parameters := []gin.H{}
+	if operation.uri != nil {
+		parameters = append(parameters, generator.parameters(operation.uri, "uri", "path")...)
+	}
+	if operation.query != nil {
+		parameters = append(parameters, generator.parameters(operation.query, "form", "query")...)
+	}
+	if len(parameters) > 0 {
+		op["parameters"] = parameters
+	}
+
+	if operation.body != nil {
+		op["requestBody"] = gin.H{
+			"required": true,
+			"content": gin.H{"application/json": gin.H{"schema": generator.schema(reflect.TypeOf(operation.body))}},
+		}
+	}
+	if operation.form != nil {
+		op["requestBody"] = gin.H{
+			"required": true,
+			"content": gin.H{"application/x-www-form-urlencoded": gin.H{"schema": generator.inlineObject(reflect.TypeOf(operation.form), "form")}},
+		}
+	}
+
+	switch operation.security {
+	case SECURITY_USER:
+		op["security"] = []gin.H{{"bearerAuth": []string{}}, {"apiKeyAuth": []string{}}}
+		op["x-required-scope"] = operation.scope
+		if len(operation.role) > 0 {
+			op["x-required-role"] = operation.role
+		}
+	case SECURITY_CLIENT:
+		op["security"] = []gin.H{{"basicAuth": []string{}}}
+	}
+
+	return op
+}
+
+func (generator *schemaGenerator) parameters(value interface{}, tagKey string, in string) []gin.H {
+	var parameters []gin.H
+	for _, field := range fieldsOf(reflect.TypeOf(value), tagKey) {
+		parameters = append(parameters, gin.H{
+			"name": field.name,
+			"in": in,
+			"required": in == "path" || field.required,
+			"schema": generator.fieldSchema(field),
+		})
+	}
+	return parameters
+}
+
+// schema returns a reference to the named structs, which are collected in the components
+func (generator *schemaGenerator) schema(t reflect.Type) gin.H {
+	switch t {
+	case reflect.TypeOf(time.Time{}):
+		return gin.H{"type": "string", "format": "date-time"}
+	case reflect.TypeOf(uuid.UUID{}):
+		return gin.H{"type": "string", "format": "uuid"}
+	case reflect.TypeOf(util.Money{}):
+		return gin.H{"type": "string", "pattern": "^-?[0-9]+(\\.[0-9]+)?$", "example": "12.34"}
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		schema := generator.schema(t.Elem())
+		if _, isRef := schema["$ref"]; isRef {
+			return schema
+		}
+		schema["nullable"] = true
+		return schema
+	case reflect.String:
+		return gin.H{"type": "string"}
+	case reflect.Bool:
+		return gin.H{"type": "boolean"}
+	case reflect.Int32:
+		return gin.H{"type": "integer", "format": "int32"}
+	case reflect.Int, reflect.Int64:
+		return gin.H{"type": "integer", "format": "int64"}
+	case reflect.Float32, reflect.Float64:
+		return gin.H{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return gin.H{"type": "array", "items": generator.schema(t.Elem())}
+	case reflect.Map:
+		return gin.H{"type": "object", "additionalProperties": generator.schema(t.Elem())}
+	case reflect.Interface:
+		return gin.H{"type": "object"}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return generator.inlineObject(t, "json")
+		}
+
+		name := strings.ToUpper(t.Name()[:1]) + t.Name()[1:]
+		if _, found := generator.schemas[name]; !found {
+			// reserve the name first so that recursive types terminate
+			generator.schemas[name] = gin.H{}
+			generator.schemas[name] = generator.inlineObject(t, "json")
+		}
+		return gin.H{"$ref": "#/components/schemas/" + name}
+	}
+
+	return gin.H{}
+}
+
+func (generator *schemaGenerator) inlineObject(t reflect.Type, tagKey string) gin.H {
+	properties := gin.H{}
+	required := []string{}
+	for _, field := range fieldsOf(t, tagKey) {
+		properties[field.name] = generator.fieldSchema(field)
+		if field.required {
+			required = append(required, field.name)
+		}
+	}
+
+	schema := gin.H{"type": "object", "properties": properties}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// fieldSchema adds the constraints of the binding tag to the schema of the field type
+func (generator *schemaGenerator) fieldSchema(field structField) gin.H {
+	schema := generator.schema(field.typ)
+	if _, isRef := schema["$ref"]; isRef {
+		return schema
+	}
+
+	target := schema
+	inKeys := false
+	for _, rule := range strings.Split(field.binding, ",") {
+		name, param := rule, ""
+		if i := strings.Index(rule, "="); i >= 0 {
+			name, param = rule[:i], rule[i+1:]
+		}
+
+		// the rules on the keys of a map have no place in its schema
+		if name == "keys" || name == "endkeys" {
+			inKeys = name == "keys"
+			continue
+		}
+		if inKeys {
+			continue
+		}
+
+		switch name {
+		case "dive":
+			if items, ok := target["items"].(gin.H); ok {
+				target = items
+			} else if values, ok := target["additionalProperties"].(gin.H); ok {
+				target = values
+			}
+		case "min", "max", "len":
+			applyLimit(target, name, param)
+		case "eq":
+			target["enum"] = []string{param}
+		case "oneof":
+			target["enum"] = strings.Fields(param)
+		case "datetime":
+			if param == "2006-01-02" {
+				target["format"] = "date"
+			}
+		case "email":
+			target["format"] = "email"
+		case "url":
+			target["format"] = "uri"
+		case "money":
+			target["pattern"] = moneyPattern.String()
+			target["example"] = "12.34"
+		case "currency":
+			// the currencies are managed at runtime, GET /currencies lists them
+			target["pattern"] = "^[A-Z]{3}$"
+		case "scope":
+			target["enum"] = token.ALL_SCOPES
+		case "oauth_scope":
+			target["enum"] = token.READ_ONLY_SCOPES
+		}
+	}
+	return schema
+}
+
+func applyLimit(schema gin.H, rule string, param string) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+
+	var minKey, maxKey string
+	switch schema["type"] {
+	case "string":
+		minKey, maxKey = "minLength", "maxLength"
+	case "array":
+		minKey, maxKey = "minItems", "maxItems"
+	case "object":
+		minKey, maxKey = "minProperties", "maxProperties"
+	case "integer", "number":
+		minKey, maxKey = "minimum", "maximum"
+	default:
+		return
+	}
+
+	switch rule {
+	case "min":
+		schema[minKey] = n
+	case "max":
+		schema[maxKey] = n
+	case "len":
+		schema[minKey] = n
+		schema[maxKey] = n
+	}
+}
+
+type structField struct {
+	name string
+	typ reflect.Type
+	binding string
+	required bool
+}
+
+// fieldsOf lists the fields bound with the tag key, json, form or uri, flattening embedded structs as the binders do
+func fieldsOf(t reflect.Type, tagKey string) []structField {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get(tagKey)
+		if tag == "-" {
+			continue
+		}
+
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			fields = append(fields, fieldsOf(field.Type, tagKey)...)
+			continue
+		}
+
+		if field.PkgPath != "" {
+			continue
+		}
+
+		if name == "" {
+			// form and uri binding require the tag while encoding/json falls back to the field name
+			if tagKey != "json" {
+				continue
+			}
+			name = field.Name
+		}
+
+		binding := field.Tag.Get("binding")
+		fields = append(fields, structField{
+			name: name,
+			typ: field.Type,
+			binding: binding,
+			required: strings.HasPrefix(binding, "required"),
+		})
+	}
+	return fields
+}
+
+func (server *Server) getOpenAPISpec(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, openAPISpec)
+}
+
+const swaggerUIPage = `<!DOCTYPE html>
+<html lang="en">
+<head>
+  <meta charset="utf-8">
+  <title>Simple Bank API</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@3/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@3/swagger-ui-bundle.js"></script>
+  <script>
+    window.ui = SwaggerUIBundle({ url: "/openapi.json", dom_id: "#swagger-ui" });
+  </script>
+</body>
+</html>`
+
+func (server *Server) getSwaggerUI(ctx *gin.Context) {
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(swaggerUIPage))
+}
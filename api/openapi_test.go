@@ -0,0 +1,148 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOpenAPIMatchesRoutes fails when a route is registered without being documented, or the other way around
+func TestOpenAPIMatchesRoutes(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewTestServer(t, testdb.NewMockStore(ctrl))
+
+	registered := map[string]bool{}
+	for _, route := range server.router.Routes() {
+		registered[route.Method+" "+route.Path] = true
+	}
+
+	documented := map[string]bool{}
+	for _, operation := range apiOperations {
+		key := operation.method + " " + operation.path
+		require.False(t, documented[key], "%s is documented twice", key)
+		documented[key] = true
+	}
+
+	for key := range registered {
+		require.True(t, documented[key], "%s is registered in NewServer but missing from the OpenAPI specification", key)
+	}
+	for key := range documented {
+		require.True(t, registered[key], "%s is in the OpenAPI specification but not registered in NewServer", key)
+	}
+}
+
+func TestOpenAPIPathParameters(t *testing.T) {
+	for _, operation := range apiOperations {
+		var params []string
+		for _, segment := range strings.Split(operation.path, "/") {
+			if strings.HasPrefix(segment, ":") {
+				params = append(params, segment[1:])
+			}
+		}
+
+		var bound []string
+		if operation.uri != nil {
+			for _, field := range fieldsOf(reflect.TypeOf(operation.uri), "uri") {
+				bound = append(bound, field.name)
+			}
+		}
+		require.ElementsMatch(t, params, bound, "path parameters of %s %s", operation.method, operation.path)
+	}
+}
+
+func TestGetOpenAPISpecAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewTestServer(t, testdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/openapi.json", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var spec struct {
+		OpenAPI string `json:"openapi"`
+		Paths map[string]map[string]struct {
+			Parameters []struct {
+				Name string `json:"name"`
+				In string `json:"in"`
+			} `json:"parameters"`
+			RequestBody struct {
+				Content map[string]struct {
+					Schema map[string]interface{} `json:"schema"`
+				} `json:"content"`
+			} `json:"requestBody"`
+			Security []map[string][]string `json:"security"`
+			RequiredScope string `json:"x-required-scope"`
+		} `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]map[string]interface{} `json:"properties"`
+				Required []string `json:"required"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	err = json.Unmarshal(recorder.Body.Bytes(), &spec)
+	require.NoError(t, err)
+	require.Equal(t, "3.0.3", spec.OpenAPI)
+
+	getAccount, ok := spec.Paths["/account/{id}"]["get"]
+	require.True(t, ok)
+	require.Len(t, getAccount.Parameters, 1)
+	require.Equal(t, "id", getAccount.Parameters[0].Name)
+	require.Equal(t, "path", getAccount.Parameters[0].In)
+	require.Equal(t, "accounts:read", getAccount.RequiredScope)
+	require.NotEmpty(t, getAccount.Security)
+
+	transfer, ok := spec.Paths["/transfer"]["post"]
+	require.True(t, ok)
+	require.Equal(t, "#/components/schemas/TransferRequest", transfer.RequestBody.Content["application/json"].Schema["$ref"])
+
+	transferRequest := spec.Components.Schemas["TransferRequest"]
+	require.ElementsMatch(t, []string{"from_account_id", "amount", "currency"}, transferRequest.Required)
+	require.Equal(t, float64(1), transferRequest.Properties["payee_id"]["minimum"])
+	require.Equal(t, float64(1), transferRequest.Properties["from_account_id"]["minimum"])
+	require.Equal(t, "^[A-Z]{3}$", transferRequest.Properties["currency"]["pattern"])
+	require.Equal(t, float64(140), transferRequest.Properties["description"]["maxLength"])
+	require.Equal(t, float64(20), transferRequest.Properties["metadata"]["maxProperties"])
+	require.Equal(t, map[string]interface{}{"type": "string", "maxLength": float64(500)}, transferRequest.Properties["metadata"]["additionalProperties"])
+
+	createUser := spec.Components.Schemas["CreateUserRequest"]
+	require.Equal(t, "email", createUser.Properties["email"]["format"])
+	require.Equal(t, float64(8), createUser.Properties["password"]["minLength"])
+
+	_, ok = spec.Components.Schemas["AccountResponse"]
+	require.True(t, ok)
+
+	introspect, ok := spec.Paths["/introspect"]["post"]
+	require.True(t, ok)
+	require.Contains(t, introspect.RequestBody.Content, "application/x-www-form-urlencoded")
+}
+
+func TestGetSwaggerUIAPI(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	server := NewTestServer(t, testdb.NewMockStore(ctrl))
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/docs", nil)
+	require.NoError(t, err)
+
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Contains(t, recorder.Header().Get("Content-Type"), "text/html")
+	require.Contains(t, recorder.Body.String(), "/openapi.json")
+}
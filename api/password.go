@@ -0,0 +1,170 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+const PASSWORD_RESET_TOKEN_BYTES = 32
+
+var errIncorrectPassword = newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "the password is incorrect")
+
+type changePasswordRequest struct {
+	OldPassword string `json:"old_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+func (server *Server) changePassword(ctx *gin.Context) {
+	var req changePasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	user := ctx.MustGet(AUTHORIZATION_USER).(db.User)
+	err := util.CheckPassword(user.HashedPassword, req.OldPassword)
+	if err != nil {
+		abortWithError(ctx, errIncorrectPassword)
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	// a reset token sent before the change must not undo it, the tokens are invalidated first
+	err = server.store.InvalidatePasswordResets(ctx, user.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.UpdateUserPasswordParams{
+		Username: user.Username,
+		HashedPassword: hashedPassword,
+	}
+
+	user, err = server.store.UpdateUserPassword(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
+
+type requestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// requestPasswordReset mails a single-use reset token to the owner of the email.
+// It answers the same way and as fast whether the email is registered or not so that it cannot be used to probe for users,
+// the token is created and mailed after the answer.
+func (server *Server) requestPasswordReset(ctx *gin.Context) {
+	var req requestPasswordResetRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	res := gin.H{"message": "if the email is registered, a password reset token has been sent to it"}
+
+	user, err := server.store.GetUserByEmail(ctx, req.Email)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			ctx.JSON(http.StatusAccepted, res)
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	server.background.Add(1)
+	go func() {
+		defer server.background.Done()
+
+		// failing here would tell the caller that the email is registered, the errors are only logged
+		err := server.sendPasswordReset(context.Background(), user)
+		if err != nil {
+			log.Printf("cannot send password reset to user %s: %v", user.Username, err)
+		}
+	}()
+
+	ctx.JSON(http.StatusAccepted, res)
+}
+
+// sendPasswordReset stores a new reset token of the user and mails it
+func (server *Server) sendPasswordReset(ctx context.Context, user db.User) error {
+	resetToken, err := util.RandomSecret(PASSWORD_RESET_TOKEN_BYTES)
+	if err != nil {
+		return err
+	}
+
+	arg := db.CreatePasswordResetParams{
+		Username: user.Username,
+		TokenHash: util.HashSecret(resetToken),
+		ExpiredAt: time.Now().Add(server.config.PasswordResetTokenDuration),
+	}
+
+	_, err = server.store.CreatePasswordReset(ctx, arg)
+	if err != nil {
+		return err
+	}
+
+	content := fmt.Sprintf(
+		"Hello %s,\n\nuse the following token to reset your password: %s\nThe token expires in %s.",
+		user.FullName,
+		resetToken,
+		server.config.PasswordResetTokenDuration,
+	)
+
+	return server.mailer.SendEmail(user.Email, "Reset your password", content)
+}
+
+type resetPasswordRequest struct {
+	Token string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required,min=8"`
+}
+
+func (server *Server) resetPassword(ctx *gin.Context) {
+	var req resetPasswordRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	hashedPassword, err := util.HashPassword(req.NewPassword)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.ResetPasswordTxParams{
+		TokenHash: util.HashSecret(req.Token),
+		HashedPassword: hashedPassword,
+	}
+
+	user, err := server.store.ResetPasswordTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, newApiError(http.StatusUnauthorized, ERROR_CODE_UNAUTHENTICATED, "password reset token is invalid, already used or expired"))
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(user))
+}
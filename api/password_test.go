@@ -0,0 +1,503 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	mockmail "github.com/sssaang/simplebank/mail/mock"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+type eqPasswordParamsMatcher struct {
+	username string
+	tokenHash string
+	password string
+}
+
+// Matches both UpdateUserPasswordParams and ResetPasswordTxParams, whose hashed password is salted
+func (e eqPasswordParamsMatcher) Matches(x interface{}) bool {
+	var hashedPassword string
+	switch arg := x.(type) {
+	case db.UpdateUserPasswordParams:
+		if arg.Username != e.username {
+			return false
+		}
+		hashedPassword = arg.HashedPassword
+	case db.ResetPasswordTxParams:
+		if arg.TokenHash != e.tokenHash {
+			return false
+		}
+		hashedPassword = arg.HashedPassword
+	default:
+		return false
+	}
+
+	return util.CheckPassword(hashedPassword, e.password) == nil
+}
+
+func (e eqPasswordParamsMatcher) String() string {
+	return fmt.Sprintf("matches username %v, token hash %v and password %v", e.username, e.tokenHash, e.password)
+}
+
+func TestChangePasswordAPI(t *testing.T) {
+	user, password := randomUser(t)
+	newPassword := util.RandomString(10)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Change the password",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				store.EXPECT().
+				InvalidatePasswordResets(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(nil)
+
+				store.EXPECT().
+				UpdateUserPassword(gomock.Any(), eqPasswordParamsMatcher{username: user.Username, password: newPassword}).
+				Times(1).
+				Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "Wrong Old Password",
+			body: gin.H{
+				"old_password": util.RandomString(10),
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				store.EXPECT().
+				UpdateUserPassword(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Too Short New Password",
+			body: gin.H{
+				"old_password": password,
+				"new_password": "1234",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				store.EXPECT().
+				UpdateUserPassword(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				store.EXPECT().
+				InvalidatePasswordResets(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(nil)
+
+				store.EXPECT().
+				UpdateUserPassword(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "Invalidate Error",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				// the password is not changed while an older reset token could still undo it
+				store.EXPECT().
+				InvalidatePasswordResets(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(sql.ErrConnDone)
+
+				store.EXPECT().
+				UpdateUserPassword(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+		{
+			name: "No Authorization",
+			body: gin.H{
+				"old_password": password,
+				"new_password": newPassword,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpdateUserPassword(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/user/password", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestRequestPasswordResetAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore, mailer *mockmail.MockMailer)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Send a reset token",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+				Times(1).
+				Return(user, nil)
+
+				var tokenHash string
+				store.EXPECT().
+				CreatePasswordReset(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ interface{}, arg db.CreatePasswordResetParams) (db.PasswordReset, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.True(t, arg.ExpiredAt.After(time.Now()))
+					tokenHash = arg.TokenHash
+					return db.PasswordReset{Username: arg.Username, TokenHash: arg.TokenHash}, nil
+				})
+
+				mailer.EXPECT().
+				SendEmail(gomock.Eq(user.Email), gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(to string, subject string, content string) error {
+					// only the hash is stored, the mail must carry the raw token
+					require.NotContains(t, content, tokenHash)
+					return nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "Unknown Email",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+
+				store.EXPECT().
+				CreatePasswordReset(gomock.Any(), gomock.Any()).
+				Times(0)
+
+				mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Email",
+			body: gin.H{
+				"email": "invalidemail",
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Mailer Error",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+				Times(1).
+				Return(user, nil)
+
+				store.EXPECT().
+				CreatePasswordReset(gomock.Any(), gomock.Any()).
+				Times(1)
+
+				mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// the same answer as for an unknown email
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "Store Error",
+			body: gin.H{
+				"email": user.Email,
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(user.Email)).
+				Times(1).
+				Return(user, nil)
+
+				store.EXPECT().
+				CreatePasswordReset(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.PasswordReset{}, sql.ErrConnDone)
+
+				mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				// storing the token happens after the answer, it cannot tell the email is registered either
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
+
+			server := NewTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/password/reset", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+			// the token is mailed after the answer
+			server.background.Wait()
+		})
+	}
+}
+
+func TestResetPasswordAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	resetToken, err := util.RandomSecret(PASSWORD_RESET_TOKEN_BYTES)
+	require.NoError(t, err)
+	newPassword := util.RandomString(10)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Reset the password",
+			body: gin.H{
+				"token": resetToken,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				arg := eqPasswordParamsMatcher{tokenHash: util.HashSecret(resetToken), password: newPassword}
+				store.EXPECT().
+				ResetPasswordTx(gomock.Any(), arg).
+				Times(1).
+				Return(user, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "Invalid Or Used Token",
+			body: gin.H{
+				"token": resetToken,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ResetPasswordTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Too Short Password",
+			body: gin.H{
+				"token": resetToken,
+				"new_password": "1234",
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ResetPasswordTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			body: gin.H{
+				"token": resetToken,
+				"new_password": newPassword,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ResetPasswordTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/user/password/reset/confirm", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/token"
+)
+
+type payeeResponse struct {
+	ID int64 `json:"id"`
+	Nickname string `json:"nickname"`
+	AccountID int64 `json:"account_id"`
+	Currency string `json:"currency"`
+	// CoolingOffUntil is when large transfers to the payee are allowed, omitted once they are
+	CoolingOffUntil *time.Time `json:"cooling_off_until,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (server *Server) newPayeeResponse(payee db.Payee) payeeResponse {
+	res := payeeResponse{
+		ID: payee.ID,
+		Nickname: payee.Nickname,
+		AccountID: payee.AccountID,
+		Currency: payee.Currency,
+		CreatedAt: payee.CreatedAt,
+		UpdatedAt: payee.UpdatedAt,
+	}
+
+	if until := server.coolingOff.Until(payee); time.Now().Before(until) {
+		res.CoolingOffUntil = &until
+	}
+	return res
+}
+
+// checkCoolingOff aborts the request when the amount is too large for a recipient the user has not paid before,
+// whichever way the request names it
+func (server *Server) checkCoolingOff(ctx *gin.Context, toAccount db.Account, amount int64) bool {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	if err := server.coolingOff.Check(ctx, authPayload.Username, toAccount, amount); err != nil {
+		abortWithError(ctx, err)
+		return false
+	}
+	return true
+}
+
+type createPayeeRequest struct {
+	Nickname string `json:"nickname" binding:"required,max=64"`
+	AccountID int64 `json:"account_id" binding:"required,min=1"`
+	Currency string `json:"currency" binding:"required,currency"`
+}
+
+// createPayee adds an account to the payees of the user, the account must exist in the currency given
+func (server *Server) createPayee(ctx *gin.Context) {
+	var req createPayeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if !requireVerifiedEmail(ctx) {
+		return
+	}
+
+	account, isValid := server.validAccount(ctx, req.AccountID, req.Currency)
+	if !isValid {
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	payee, err := server.store.CreatePayee(ctx, db.CreatePayeeParams{
+		Owner: authPayload.Username,
+		Nickname: req.Nickname,
+		AccountID: account.ID,
+		Currency: account.Currency,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, server.newPayeeResponse(payee))
+}
+
+func (server *Server) listPayees(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	payees, err := server.store.ListPayees(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]payeeResponse, len(payees))
+	for i, payee := range payees {
+		res[i] = server.newPayeeResponse(payee)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type payeeURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// the payees of other users are not found rather than forbidden, their ids tell nothing
+func (server *Server) getPayee(ctx *gin.Context) {
+	var uri payeeURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	payee, err := server.store.GetPayee(ctx, db.GetPayeeParams{
+		ID: uri.ID,
+		Owner: authPayload.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newPayeeResponse(payee))
+}
+
+type updatePayeeRequest struct {
+	Nickname string `json:"nickname" binding:"required,max=64"`
+}
+
+// updatePayee renames a payee, its account cannot change
+func (server *Server) updatePayee(ctx *gin.Context) {
+	var uri payeeURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req updatePayeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	payee, err := server.store.UpdatePayee(ctx, db.UpdatePayeeParams{
+		ID: uri.ID,
+		Owner: authPayload.Username,
+		Nickname: req.Nickname,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newPayeeResponse(payee))
+}
+
+func (server *Server) deletePayee(ctx *gin.Context) {
+	var uri payeeURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	payee, err := server.store.DeletePayee(ctx, db.DeletePayeeParams{
+		ID: uri.ID,
+		Owner: authPayload.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, server.newPayeeResponse(payee))
+}
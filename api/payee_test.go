@@ -0,0 +1,468 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPayee(owner string, account db.Account) db.Payee {
+	return db.Payee{
+		ID: util.RandomInt(1, 1000),
+		Owner: owner,
+		Nickname: util.RandomOwner(),
+		AccountID: account.ID,
+		Currency: account.Currency,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func TestCreatePayeeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount("someone@example.com")
+	account.Currency = util.USD
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"nickname": "landlord", "account_id": account.ID, "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+
+				arg := db.CreatePayeeParams{
+					Owner: user.Username,
+					Nickname: "landlord",
+					AccountID: account.ID,
+					Currency: util.USD,
+				}
+				payee := randomPayee(user.Username, account)
+				payee.Nickname = arg.Nickname
+				store.EXPECT().
+				CreatePayee(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(payee, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, "landlord", got["nickname"])
+				require.Equal(t, float64(account.ID), got["account_id"])
+				require.Contains(t, got, "cooling_off_until")
+			},
+		},
+		{
+			name: "Currency Mismatch",
+			body: gin.H{"nickname": "landlord", "account_id": account.ID, "currency": util.EUR},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				CreatePayee(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_CURRENCY_MISMATCH)
+			},
+		},
+		{
+			name: "Account Not Found",
+			body: gin.H{"nickname": "landlord", "account_id": account.ID, "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+				CreatePayee(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+		{
+			name: "Nickname Taken",
+			body: gin.H{"nickname": "landlord", "account_id": account.ID, "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				CreatePayee(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Payee{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_ALREADY_EXISTS)
+			},
+		},
+		{
+			name: "Missing Nickname",
+			body: gin.H{"account_id": account.ID, "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payees", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestUpdatePayeeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	payee := randomPayee(user.Username, randomAccount("someone@example.com"))
+	payee.CreatedAt = time.Now().Add(-48 * time.Hour)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	arg := db.UpdatePayeeParams{ID: payee.ID, Owner: user.Username, Nickname: "plumber"}
+	updated := payee
+	updated.Nickname = arg.Nickname
+	store.EXPECT().
+	UpdatePayee(gomock.Any(), gomock.Eq(arg)).
+	Times(1).
+	Return(updated, nil)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"nickname": "plumber"})
+	require.NoError(t, err)
+
+	request, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("/payees/%d", payee.ID), bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, "plumber", got["nickname"])
+	// the cooling-off period is over
+	require.NotContains(t, got, "cooling_off_until")
+}
+
+func TestDeletePayeeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the payee of another user is not found
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	DeletePayee(gomock.Any(), gomock.Eq(db.DeletePayeeParams{ID: 42, Owner: user.Username})).
+	Times(1).
+	Return(db.Payee{}, sql.ErrNoRows)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodDelete, "/payees/42", nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+}
+
+func TestTransferToPayeeAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	fromAccount := randomAccount(user.Username)
+	fromAccount.Currency = util.USD
+	toAccount := randomAccount("someone@example.com")
+	toAccount.Currency = util.USD
+
+	newPayee := randomPayee(user.Username, toAccount)
+	oldPayee := randomPayee(user.Username, toAccount)
+	oldPayee.CreatedAt = time.Now().Add(-2 * time.Hour)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"from_account_id": fromAccount.ID, "payee_id": oldPayee.ID, "amount": "5000", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetPayee(gomock.Any(), gomock.Eq(db.GetPayeeParams{ID: oldPayee.ID, Owner: user.Username})).
+				Times(1).
+				Return(oldPayee, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Eq(db.HasTransferredToParams{Owner: user.Username, ToAccountID: toAccount.ID})).
+				Times(1).
+				Return(false, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Eq(db.GetPayeeByAccountParams{Owner: user.Username, AccountID: toAccount.ID})).
+				Times(1).
+				Return(oldPayee, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID: toAccount.ID,
+					Amount: 500000,
+					Metadata: json.RawMessage("{}"),
+					RequestedBy: user.Username,
+				}
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Cooling Off",
+			body: gin.H{"from_account_id": fromAccount.ID, "payee_id": newPayee.ID, "amount": "1000", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetPayee(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(newPayee, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(false, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(newPayee, nil)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_PAYEE_COOLING_OFF)
+			},
+		},
+		{
+			name: "Cooling Off By Account ID",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": toAccount.ID, "amount": "1000", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(false, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Payee{}, sql.ErrNoRows)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_PAYEE_COOLING_OFF)
+			},
+		},
+		{
+			name: "Paid Before By Account ID",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": toAccount.ID, "amount": "1000", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(true, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Cooling Off Small Amount",
+			body: gin.H{"from_account_id": fromAccount.ID, "payee_id": newPayee.ID, "amount": "999.99", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetPayee(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(newPayee, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Payee Not Found",
+			body: gin.H{"from_account_id": fromAccount.ID, "payee_id": 42, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetPayee(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.Payee{}, sql.ErrNoRows)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+		{
+			name: "Account And Payee",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_account_id": toAccount.ID, "payee_id": oldPayee.ID, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "No Recipient",
+			body: gin.H{"from_account_id": fromAccount.ID, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+			stubFraudRules(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
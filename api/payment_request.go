@@ -0,0 +1,411 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/sssaang/simplebank/token"
+)
+
+// DEFAULT_PAYMENT_REQUEST_DAYS is how long a payment request can be accepted when the requester does not say
+const DEFAULT_PAYMENT_REQUEST_DAYS = 7
+
+var errPaymentRequestToSelf = newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, "a user cannot request a payment from itself")
+
+type paymentRequestResponse struct {
+	ID int64 `json:"id"`
+	Requester string `json:"requester"`
+	Payer string `json:"payer"`
+	// AccountID is the account the payment goes to, only shown to the requester
+	AccountID int64 `json:"account_id,omitempty"`
+	Amount util.Money `json:"amount"`
+	Note string `json:"note"`
+	Status string `json:"status"`
+	TransferID int64 `json:"transfer_id,omitempty"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// newPaymentRequestResponse shows a payment request to one of its parties,
+// a pending request past its expiry is shown expired even before the job marks it
+func newPaymentRequestResponse(request db.PaymentRequest, username string) paymentRequestResponse {
+	res := paymentRequestResponse{
+		ID: request.ID,
+		Requester: request.Requester,
+		Payer: request.Payer,
+		Amount: util.NewMoney(request.Amount, request.Currency),
+		Note: request.Note,
+		Status: request.Status,
+		TransferID: request.TransferID.Int64,
+		ExpiresAt: request.ExpiresAt,
+		CreatedAt: request.CreatedAt,
+		UpdatedAt: request.UpdatedAt,
+	}
+
+	if username == request.Requester {
+		res.AccountID = request.AccountID
+	}
+	if request.Status == db.PAYMENT_REQUEST_PENDING && !request.IsPending() {
+		res.Status = db.PAYMENT_REQUEST_EXPIRED
+	}
+	return res
+}
+
+type paymentRequestEventResponse struct {
+	Status string `json:"status"`
+	// Actor is the user who changed the status, omitted when the request expired
+	Actor string `json:"actor,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// createPaymentRequestRequest asks another user, named by its username or its email, for a payment into an account
+type createPaymentRequestRequest struct {
+	PayerUsername string `json:"payer_username" binding:"omitempty,email"`
+	PayerEmail string `json:"payer_email" binding:"omitempty,email"`
+	AccountID int64 `json:"account_id" binding:"required,min=1"`
+	Amount string `json:"amount" binding:"required,money"`
+	Currency string `json:"currency" binding:"required,currency"`
+	Note string `json:"note" binding:"max=140"`
+	ExpiresInDays int `json:"expires_in_days" binding:"omitempty,min=1,max=30"`
+}
+
+func (server *Server) createPaymentRequest(ctx *gin.Context) {
+	var req createPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if (len(req.PayerUsername) > 0) == (len(req.PayerEmail) > 0) {
+		abortWithError(ctx, fieldError("payer_username", "required_without", "exactly one of payer_username and payer_email is required"))
+		return
+	}
+
+	if !requireVerifiedEmail(ctx) {
+		return
+	}
+
+	amount, err := parseAmount("amount", req.Amount, req.Currency)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	account, isValid := server.validAccount(ctx, req.AccountID, req.Currency)
+	if !isValid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountSpenders) {
+		return
+	}
+
+	payer, isValid := server.findUser(ctx, "payer", req.PayerUsername, req.PayerEmail)
+	if !isValid {
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	if payer.Username == authPayload.Username {
+		abortWithError(ctx, errPaymentRequestToSelf)
+		return
+	}
+
+	days := req.ExpiresInDays
+	if days == 0 {
+		days = DEFAULT_PAYMENT_REQUEST_DAYS
+	}
+
+	request, err := server.store.CreatePaymentRequestTx(ctx, db.CreatePaymentRequestParams{
+		Requester: authPayload.Username,
+		Payer: payer.Username,
+		AccountID: account.ID,
+		Amount: amount.Amount(),
+		Currency: account.Currency,
+		Note: req.Note,
+		ExpiresAt: time.Now().AddDate(0, 0, days),
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, newPaymentRequestResponse(request, authPayload.Username))
+}
+
+type listPaymentRequestsRequest struct {
+	PageID int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+}
+
+// listIncomingPaymentRequests is the inbox of the user, the requests it can still accept or decline
+func (server *Server) listIncomingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	requests, err := server.store.ListIncomingPaymentRequests(ctx, db.ListIncomingPaymentRequestsParams{
+		Payer: authPayload.Username,
+		Limit: req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaymentRequestResponses(requests, authPayload.Username))
+}
+
+// listOutgoingPaymentRequests shows the requests the user sent, in any status
+func (server *Server) listOutgoingPaymentRequests(ctx *gin.Context) {
+	var req listPaymentRequestsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	requests, err := server.store.ListOutgoingPaymentRequests(ctx, db.ListOutgoingPaymentRequestsParams{
+		Requester: authPayload.Username,
+		Limit: req.PageSize,
+		Offset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaymentRequestResponses(requests, authPayload.Username))
+}
+
+func newPaymentRequestResponses(requests []db.PaymentRequest, username string) []paymentRequestResponse {
+	res := make([]paymentRequestResponse, len(requests))
+	for i, request := range requests {
+		res[i] = newPaymentRequestResponse(request, username)
+	}
+	return res
+}
+
+type paymentRequestURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// partyPaymentRequest finds the payment request of the uri for one of its parties,
+// the requests of others are not found rather than forbidden, their ids tell nothing
+func (server *Server) partyPaymentRequest(ctx *gin.Context) (db.PaymentRequest, bool) {
+	var uri paymentRequestURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return db.PaymentRequest{}, false
+	}
+
+	request, err := server.store.GetPaymentRequest(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return db.PaymentRequest{}, false
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	if authPayload.Username != request.Requester && authPayload.Username != request.Payer {
+		abortWithError(ctx, errNotFound)
+		return db.PaymentRequest{}, false
+	}
+
+	return request, true
+}
+
+// requireParty aborts the request unless the user is the given party of the payment request
+func requireParty(ctx *gin.Context, party string, username string) bool {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	if authPayload.Username != username {
+		abortWithError(ctx, newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, fmt.Sprintf("only the %s of the payment request can do it", party)))
+		return false
+	}
+	return true
+}
+
+func (server *Server) getPaymentRequest(ctx *gin.Context) {
+	request, isValid := server.partyPaymentRequest(ctx)
+	if !isValid {
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	ctx.JSON(http.StatusOK, newPaymentRequestResponse(request, authPayload.Username))
+}
+
+// listPaymentRequestEvents shows every status the payment request went through, oldest first
+func (server *Server) listPaymentRequestEvents(ctx *gin.Context) {
+	request, isValid := server.partyPaymentRequest(ctx)
+	if !isValid {
+		return
+	}
+
+	events, err := server.store.ListPaymentRequestEvents(ctx, request.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]paymentRequestEventResponse, len(events))
+	for i, event := range events {
+		res[i] = paymentRequestEventResponse{
+			Status: event.Status,
+			Actor: event.Actor.String,
+			CreatedAt: event.CreatedAt,
+		}
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type acceptPaymentRequestRequest struct {
+	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
+}
+
+type acceptPaymentRequestResponse struct {
+	PaymentRequest paymentRequestResponse `json:"payment_request"`
+	Transfer *userTransferResponse `json:"transfer,omitempty"`
+	// HeldTransfer is set instead of the transfer when the fraud screening held it for review
+	HeldTransfer *heldTransferResponse `json:"held_transfer,omitempty"`
+}
+
+// acceptPaymentRequest pays the request from an account of the payer, screened as any other transfer.
+// A transfer the screening holds for review is answered with 202, the request stays pending until staff
+// approve the transfer, which accepts it.
+func (server *Server) acceptPaymentRequest(ctx *gin.Context) {
+	request, isValid := server.partyPaymentRequest(ctx)
+	if !isValid {
+		return
+	}
+
+	if !requireParty(ctx, "payer", request.Payer) {
+		return
+	}
+
+	var req acceptPaymentRequestRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if !requireVerifiedEmail(ctx) {
+		return
+	}
+
+	fromAccount, isValid := server.validAccount(ctx, req.FromAccountID, request.Currency)
+	if !isValid {
+		return
+	}
+
+	if !server.authorizeAccount(ctx, fromAccount, accountSpenders) {
+		return
+	}
+
+	toAccount, err := server.store.GetAccount(ctx, request.AccountID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.checkCoolingOff(ctx, toAccount, request.Amount) {
+		return
+	}
+
+	assessment, err := server.riskEngine.Screen(ctx, risk.Transfer{
+		FromAccount: fromAccount,
+		ToAccount: toAccount,
+		Amount: request.Amount,
+		RequestedBy: request.Payer,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+	switch assessment.Outcome {
+	case risk.OUTCOME_DENY:
+		abortWithError(ctx, errTransferDenied)
+		return
+	case risk.OUTCOME_REVIEW:
+		held, err := server.store.CreateHeldTransfer(ctx, db.CreateHeldTransferParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID: toAccount.ID,
+			Amount: request.Amount,
+			Currency: request.Currency,
+			RequestedBy: request.Payer,
+			Reasons: assessment.Reasons(),
+			Description: request.Note,
+			Metadata: marshalMetadata(nil),
+			RecipientHidden: true,
+			PaymentRequestID: sql.NullInt64{Int64: request.ID, Valid: true},
+		})
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		res := newHeldTransferResponse(held)
+		res.ToAccountID = 0
+		ctx.JSON(http.StatusAccepted, acceptPaymentRequestResponse{
+			PaymentRequest: newPaymentRequestResponse(request, request.Payer),
+			HeldTransfer: &res,
+		})
+		return
+	}
+
+	result, err := server.store.AcceptPaymentRequestTx(ctx, db.AcceptPaymentRequestTxParams{
+		ID: request.ID,
+		FromAccountID: fromAccount.ID,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	transfer := newUserTransferResponse(result.Transfer)
+	ctx.JSON(http.StatusOK, acceptPaymentRequestResponse{
+		PaymentRequest: newPaymentRequestResponse(result.PaymentRequest, request.Payer),
+		Transfer: &transfer,
+	})
+}
+
+func (server *Server) declinePaymentRequest(ctx *gin.Context) {
+	request, isValid := server.partyPaymentRequest(ctx)
+	if !isValid || !requireParty(ctx, "payer", request.Payer) {
+		return
+	}
+
+	request, err := server.store.DeclinePaymentRequestTx(ctx, request.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaymentRequestResponse(request, request.Payer))
+}
+
+func (server *Server) cancelPaymentRequest(ctx *gin.Context) {
+	request, isValid := server.partyPaymentRequest(ctx)
+	if !isValid || !requireParty(ctx, "requester", request.Requester) {
+		return
+	}
+
+	request, err := server.store.CancelPaymentRequestTx(ctx, request.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newPaymentRequestResponse(request, request.Requester))
+}
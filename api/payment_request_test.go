@@ -0,0 +1,711 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/stretchr/testify/require"
+)
+
+func randomPaymentRequest(requester string, payer string, account db.Account) db.PaymentRequest {
+	return db.PaymentRequest{
+		ID: util.RandomInt(1, 1000),
+		Requester: requester,
+		Payer: payer,
+		AccountID: account.ID,
+		Amount: 1000,
+		Currency: account.Currency,
+		Note: "dinner",
+		Status: db.PAYMENT_REQUEST_PENDING,
+		ExpiresAt: time.Now().Add(time.Hour),
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+}
+
+func TestCreatePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	payer.Email = util.RandomEmail()
+	account := randomAccount(requester.Username)
+	account.Currency = util.USD
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"payer_email": payer.Email, "account_id": account.ID, "amount": "10", "currency": util.USD, "note": "dinner"},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(payer.Email)).
+				Times(1).
+				Return(payer, nil)
+				store.EXPECT().
+				CreatePaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CreatePaymentRequestParams) (db.PaymentRequest, error) {
+					require.Equal(t, requester.Username, arg.Requester)
+					require.Equal(t, payer.Username, arg.Payer)
+					require.Equal(t, int64(1000), arg.Amount)
+					require.WithinDuration(t, time.Now().AddDate(0, 0, DEFAULT_PAYMENT_REQUEST_DAYS), arg.ExpiresAt, time.Minute)
+
+					request := randomPaymentRequest(arg.Requester, arg.Payer, account)
+					request.ExpiresAt = arg.ExpiresAt
+					return request, nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusCreated, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.PAYMENT_REQUEST_PENDING, got["status"])
+				require.Equal(t, float64(account.ID), got["account_id"])
+			},
+		},
+		{
+			name: "From Self",
+			body: gin.H{"payer_username": requester.Username, "account_id": account.ID, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				CreatePaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST)
+			},
+		},
+		{
+			name: "Unknown Payer",
+			body: gin.H{"payer_email": "nobody@example.com", "account_id": account.ID, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq("nobody@example.com")).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+				CreatePaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+		{
+			name: "Viewer Of Account",
+			body: gin.H{"payer_email": payer.Email, "account_id": account.ID, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				shared := account
+				shared.Owner = payer.Username
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(shared, nil)
+				stubAccountRole(store, shared, requester.Username, db.ACCOUNT_ROLE_VIEWER)
+				store.EXPECT().
+				CreatePaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Username And Email",
+			body: gin.H{"payer_username": payer.Username, "payer_email": payer.Email, "account_id": account.ID, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Expiry Too Long",
+			body: gin.H{"payer_email": payer.Email, "account_id": account.ID, "amount": "10", "currency": util.USD, "expires_in_days": 31},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/payment_requests", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, requester.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestGetPaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	stranger, _ := randomUser(t)
+	account := randomAccount(requester.Username)
+	paymentRequest := randomPaymentRequest(requester.Username, payer.Username, account)
+
+	testCases := []struct {
+		name string
+		username string
+		paymentRequest db.PaymentRequest
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Requester",
+			username: requester.Username,
+			paymentRequest: paymentRequest,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, float64(account.ID), got["account_id"])
+			},
+		},
+		{
+			name: "Payer",
+			username: payer.Username,
+			paymentRequest: paymentRequest,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// the payer never learns which account it pays into
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.NotContains(t, got, "account_id")
+				require.Equal(t, db.PAYMENT_REQUEST_PENDING, got["status"])
+			},
+		},
+		{
+			name: "Past Expiry",
+			username: payer.Username,
+			paymentRequest: func() db.PaymentRequest {
+				expired := paymentRequest
+				expired.ExpiresAt = time.Now().Add(-time.Minute)
+				return expired
+			}(),
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.PAYMENT_REQUEST_EXPIRED, got["status"])
+			},
+		},
+		{
+			name: "Stranger",
+			username: stranger.Username,
+			paymentRequest: paymentRequest,
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			store.EXPECT().
+			GetPaymentRequest(gomock.Any(), gomock.Eq(tc.paymentRequest.ID)).
+			Times(1).
+			Return(tc.paymentRequest, nil)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/payment_requests/%d", tc.paymentRequest.ID)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListPaymentRequestEventsAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	paymentRequest := randomPaymentRequest(requester.Username, payer.Username, randomAccount(requester.Username))
+	paymentRequest.Status = db.PAYMENT_REQUEST_EXPIRED
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+	Times(1).
+	Return(paymentRequest, nil)
+	store.EXPECT().
+	ListPaymentRequestEvents(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+	Times(1).
+	Return([]db.PaymentRequestEvent{
+		{PaymentRequestID: paymentRequest.ID, Status: db.PAYMENT_REQUEST_PENDING, Actor: sql.NullString{String: requester.Username, Valid: true}},
+		{PaymentRequestID: paymentRequest.ID, Status: db.PAYMENT_REQUEST_EXPIRED},
+	}, nil)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := fmt.Sprintf("/payment_requests/%d/events", paymentRequest.ID)
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, payer.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+	require.Equal(t, requester.Username, got[0]["actor"])
+	require.NotContains(t, got[1], "actor")
+}
+
+func TestAcceptPaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	stranger, _ := randomUser(t)
+	toAccount := randomAccount(requester.Username)
+	toAccount.Currency = util.USD
+	fromAccount := randomAccount(payer.Username)
+	fromAccount.Currency = util.USD
+	paymentRequest := randomPaymentRequest(requester.Username, payer.Username, toAccount)
+
+	testCases := []struct {
+		name string
+		username string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			username: payer.Username,
+			body: gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				stubFraudRules(store)
+
+				arg := db.AcceptPaymentRequestTxParams{ID: paymentRequest.ID, FromAccountID: fromAccount.ID}
+				accepted := paymentRequest
+				accepted.Status = db.PAYMENT_REQUEST_ACCEPTED
+				accepted.TransferID = sql.NullInt64{Int64: 1, Valid: true}
+				store.EXPECT().
+				AcceptPaymentRequestTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.AcceptPaymentRequestTxResult{
+					PaymentRequest: accepted,
+					Transfer: db.TransferTxResult{
+						Transfer: db.Transfer{ID: 1, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: paymentRequest.Amount},
+						FromAccount: fromAccount,
+						ToAccount: toAccount,
+						FromEntry: db.Entry{AccountID: fromAccount.ID, Amount: -paymentRequest.Amount},
+						ToEntry: db.Entry{AccountID: toAccount.ID, Amount: paymentRequest.Amount},
+					},
+				}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					PaymentRequest gin.H `json:"payment_request"`
+					Transfer gin.H `json:"transfer"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.PAYMENT_REQUEST_ACCEPTED, got.PaymentRequest["status"])
+				require.Equal(t, float64(1), got.PaymentRequest["transfer_id"])
+				require.NotContains(t, got.PaymentRequest, "account_id")
+				require.NotContains(t, got.Transfer, "to_account")
+				require.NotContains(t, got.Transfer["transfer"], "to_account_id")
+			},
+		},
+		{
+			name: "Not Pending",
+			username: payer.Username,
+			body: gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				stubFraudRules(store)
+				store.EXPECT().
+				AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AcceptPaymentRequestTxResult{}, db.ErrPaymentRequestNotPending)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_CONFLICT)
+			},
+		},
+		{
+			name: "Held For Review",
+			username: payer.Username,
+			body: gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				rule := amountRule("large transfer", risk.OUTCOME_REVIEW, util.USD)
+				store.EXPECT().
+				ListEnabledFraudRules(gomock.Any()).
+				Times(1).
+				Return([]db.FraudRule{rule}, nil)
+
+				// the transfer is held and tied to the request, which its approval accepts
+				arg := db.CreateHeldTransferParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID: toAccount.ID,
+					Amount: paymentRequest.Amount,
+					Currency: util.USD,
+					RequestedBy: payer.Username,
+					Reasons: []string{rule.Name},
+					Description: paymentRequest.Note,
+					Metadata: json.RawMessage("{}"),
+					RecipientHidden: true,
+					PaymentRequestID: sql.NullInt64{Int64: paymentRequest.ID, Valid: true},
+				}
+				store.EXPECT().
+				CreateHeldTransfer(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.HeldTransfer{
+					ID: 7,
+					FromAccountID: fromAccount.ID,
+					ToAccountID: toAccount.ID,
+					Amount: paymentRequest.Amount,
+					Currency: util.USD,
+					Status: db.HELD_TRANSFER_PENDING,
+					RecipientHidden: true,
+					PaymentRequestID: arg.PaymentRequestID,
+				}, nil)
+				store.EXPECT().
+				AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var got struct {
+					PaymentRequest gin.H `json:"payment_request"`
+					Transfer gin.H `json:"transfer"`
+					HeldTransfer gin.H `json:"held_transfer"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.PAYMENT_REQUEST_PENDING, got.PaymentRequest["status"])
+				require.Nil(t, got.Transfer)
+				require.Equal(t, float64(7), got.HeldTransfer["id"])
+				require.Equal(t, float64(paymentRequest.ID), got.HeldTransfer["payment_request_id"])
+				require.NotContains(t, got.HeldTransfer, "to_account_id")
+			},
+		},
+		{
+			name: "Denied",
+			username: payer.Username,
+			body: gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				store.EXPECT().
+				ListEnabledFraudRules(gomock.Any()).
+				Times(1).
+				Return([]db.FraudRule{amountRule("large transfer", risk.OUTCOME_DENY, util.USD)}, nil)
+				store.EXPECT().
+				CreateHeldTransfer(gomock.Any(), gomock.Any()).
+				Times(0)
+				store.EXPECT().
+				AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_TRANSFER_DENIED)
+			},
+		},
+		{
+			name: "Currency Mismatch",
+			username: payer.Username,
+			body: gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *testdb.MockStore) {
+				euroAccount := fromAccount
+				euroAccount.Currency = util.EUR
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(euroAccount, nil)
+				store.EXPECT().
+				AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_CURRENCY_MISMATCH)
+			},
+		},
+		{
+			name: "Requester",
+			username: requester.Username,
+			body: gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Stranger",
+			username: stranger.Username,
+			body: gin.H{"from_account_id": fromAccount.ID},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				AcceptPaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			store.EXPECT().
+			GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+			Times(1).
+			Return(paymentRequest, nil)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/payment_requests/%d/accept", paymentRequest.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestSettlePaymentRequestAPI(t *testing.T) {
+	requester, _ := randomUser(t)
+	payer, _ := randomUser(t)
+	paymentRequest := randomPaymentRequest(requester.Username, payer.Username, randomAccount(requester.Username))
+
+	testCases := []struct {
+		name string
+		action string
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Payer Declines",
+			action: "decline",
+			username: payer.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				declined := paymentRequest
+				declined.Status = db.PAYMENT_REQUEST_DECLINED
+				store.EXPECT().
+				DeclinePaymentRequestTx(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+				Times(1).
+				Return(declined, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.PAYMENT_REQUEST_DECLINED, got["status"])
+			},
+		},
+		{
+			name: "Requester Cannot Decline",
+			action: "decline",
+			username: requester.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				DeclinePaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Requester Cancels",
+			action: "cancel",
+			username: requester.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				cancelled := paymentRequest
+				cancelled.Status = db.PAYMENT_REQUEST_CANCELLED
+				store.EXPECT().
+				CancelPaymentRequestTx(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+				Times(1).
+				Return(cancelled, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, db.PAYMENT_REQUEST_CANCELLED, got["status"])
+			},
+		},
+		{
+			name: "Payer Cannot Cancel",
+			action: "cancel",
+			username: payer.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CancelPaymentRequestTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Already Settled",
+			action: "cancel",
+			username: requester.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				CancelPaymentRequestTx(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+				Times(1).
+				Return(db.PaymentRequest{}, db.ErrPaymentRequestNotPending)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_CONFLICT)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			store.EXPECT().
+			GetPaymentRequest(gomock.Any(), gomock.Eq(paymentRequest.ID)).
+			Times(1).
+			Return(paymentRequest, nil)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/payment_requests/%d/%s", paymentRequest.ID, tc.action)
+			request, err := http.NewRequest(http.MethodPost, url, nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package api
 
 import (
 	"fmt"
+	"io/ioutil"
+	"sync"
 	"testing"
 	"time"
 
@@ -10,6 +12,8 @@ import (
 	"github.com/go-playground/validator/v10"
 	db "github.com/sssaang/simplebank/db/sqlc"
 	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/mail"
+	"github.com/sssaang/simplebank/risk"
 	"github.com/sssaang/simplebank/token"
 	"github.com/stretchr/testify/require"
 )
@@ -18,47 +22,154 @@ type Server struct {
 	config util.Config
 	store  db.Store
 	tokenManager token.TokenManager
+	mailer mail.Mailer
+	riskEngine *risk.Engine
+	coolingOff *risk.CoolingOff
 	router *gin.Engine
+	// background tracks the work handlers leave running after they answered
+	background sync.WaitGroup
 }
 
 func NewTestServer(t *testing.T, store db.Store) *Server {
 	config := util.Config {
 		PasetoSymmetricKey: util.RandomString(32),
 		AccessTokenDuration: time.Minute,
+		PasswordResetTokenDuration: time.Minute,
+		VerifyEmailDuration: time.Minute,
+		OauthCodeDuration: time.Minute,
+		RefreshTokenDuration: time.Hour,
+		IntrospectionClients: "test_service:test_service_secret",
+		PayeeCoolingOffPeriod: time.Hour,
+		PayeeCoolingOffAmounts: "USD:1000",
 	}
 
-	server, err := NewServer(config, store)
+	server, err := NewServer(config, store, mail.NewLogMailer(ioutil.Discard))
 	require.NoError(t, err)
 	return server
 }
 
 // NewServer creates a new HTTP server, setup routing and return the server
-func NewServer(config util.Config, store db.Store) (*Server, error) {
-	tokenManager, err := token.NewPasetoManager(config.PasetoSymmetricKey)
+func NewServer(config util.Config, store db.Store, mailer mail.Mailer) (*Server, error) {
+	tokenManager, err := token.NewTokenManagerFromConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token manager %w", err)
 	}
 
+	introspectionClients, err := parseServiceCredentials(config.IntrospectionClients)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse introspection clients %w", err)
+	}
+
+	coolingOff, err := risk.NewCoolingOff(store, config.PayeeCoolingOffPeriod, config.PayeeCoolingOffAmounts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse payee cooling-off amounts %w", err)
+	}
+
 	server := &Server{
 		config: config,
 		store: store,
 		tokenManager: tokenManager,
+		mailer: mailer,
+		riskEngine: risk.NewEngine(store),
+		coolingOff: coolingOff,
 	}
 	router := gin.Default()
+	router.Use(requestIDMiddleware())
 
 	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(requestFieldName)
 		v.RegisterValidation("currency", validCurrency)
+		v.RegisterValidation("money", validMoney)
+		v.RegisterValidation("scope", validScope)
+		v.RegisterValidation("oauth_scope", validOauthScope)
 	}
 
 	router.POST("/user", server.createUser)
 	router.POST("/login", server.loginUser)
+	router.POST("/user/password/reset", server.requestPasswordReset)
+	router.POST("/user/password/reset/confirm", server.resetPassword)
+	router.GET("/verify_email", server.verifyEmail)
+	router.POST("/introspect", serviceAuthMiddleware(introspectionClients), server.introspectToken)
+	router.POST("/oauth/token", server.oauthToken)
+	router.GET("/openapi.json", server.getOpenAPISpec)
+	router.GET("/docs", server.getSwaggerUI)
+	router.GET("/currencies", server.listCurrencies)
+	router.GET("/exchange_rates", server.listExchangeRates)
+	router.GET("/interest_rates", server.listInterestRates)
+	router.GET("/fee_schedules", server.listFeeSchedules)
 
-	authRoutes := router.Group("/").Use(authMiddleware(server.tokenManager))
-	authRoutes.GET("/user/:username", server.getUser)
-	authRoutes.POST("/account", server.createAccount)
-	authRoutes.GET("/account/:id", server.getAccount)
-	authRoutes.GET("/accounts", server.listAccounts)
-	authRoutes.POST("/transfer", server.makeTransfer)
+	authRoutes := router.Group("/").Use(authMiddleware(server.tokenManager, server.store))
+	authRoutes.GET("/user/:username", requireScope(token.SCOPE_USER_READ), server.getUser)
+	authRoutes.PATCH("/user/:username", requireScope(token.SCOPE_USER_WRITE), server.updateUser)
+	authRoutes.GET("/user/:username/export", requireScope(token.SCOPE_USER_READ), server.exportUserData)
+	authRoutes.POST("/user/:username/erasure", requireScope(token.SCOPE_USER_WRITE), server.eraseUser)
+	authRoutes.POST("/verify_email/resend", requireScope(token.SCOPE_USER_WRITE), server.resendVerifyEmail)
+	authRoutes.PUT("/user/password", requireScope(token.SCOPE_USER_WRITE), server.changePassword)
+	authRoutes.POST("/account", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.createAccount)
+	authRoutes.GET("/account/:id", requireScope(token.SCOPE_ACCOUNTS_READ), server.getAccount)
+	authRoutes.GET("/accounts", requireScope(token.SCOPE_ACCOUNTS_READ), server.listAccounts)
+	authRoutes.GET("/accounts/:id/statements", requireScope(token.SCOPE_ACCOUNTS_READ), server.getStatement)
+	authRoutes.GET("/accounts/:id/transfers", requireScope(token.SCOPE_ACCOUNTS_READ), server.listAccountTransfers)
+	authRoutes.GET("/accounts/:id/holds", requireScope(token.SCOPE_ACCOUNTS_READ), server.listAccountHolds)
+	authRoutes.GET("/accounts/:id/members", requireScope(token.SCOPE_ACCOUNTS_READ), server.listAccountMembers)
+	authRoutes.POST("/accounts/:id/members", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.inviteAccountMember)
+	authRoutes.DELETE("/accounts/:id/members/:username", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.removeAccountMember)
+	authRoutes.GET("/accounts/:id/invites", requireScope(token.SCOPE_ACCOUNTS_READ), server.listAccountInvites)
+	authRoutes.DELETE("/accounts/:id/invites/:username", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.cancelAccountInvite)
+	authRoutes.GET("/account_invites", requireScope(token.SCOPE_ACCOUNTS_READ), server.listUserAccountInvites)
+	authRoutes.POST("/account_invites/:account_id/accept", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.acceptAccountInvite)
+	authRoutes.POST("/account_invites/:account_id/decline", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.declineAccountInvite)
+	authRoutes.POST("/transfer", requireScope(token.SCOPE_TRANSFERS_WRITE), server.makeTransfer)
+	authRoutes.POST("/transfers/batch", requireScope(token.SCOPE_TRANSFERS_WRITE), server.makeBatchTransfer)
+	authRoutes.GET("/limits", requireScope(token.SCOPE_TRANSFERS_READ), server.listLimits)
+	authRoutes.GET("/accounts/:id/limits", requireScope(token.SCOPE_TRANSFERS_READ), server.getAccountLimits)
+	authRoutes.PUT("/accounts/:id/limits", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.setAccountLimits)
+	authRoutes.DELETE("/accounts/:id/limits", requireScope(token.SCOPE_ACCOUNTS_WRITE), server.deleteAccountLimits)
+	authRoutes.POST("/payees", requireScope(token.SCOPE_TRANSFERS_WRITE), server.createPayee)
+	authRoutes.GET("/payees", requireScope(token.SCOPE_TRANSFERS_READ), server.listPayees)
+	authRoutes.GET("/payees/:id", requireScope(token.SCOPE_TRANSFERS_READ), server.getPayee)
+	authRoutes.PATCH("/payees/:id", requireScope(token.SCOPE_TRANSFERS_WRITE), server.updatePayee)
+	authRoutes.DELETE("/payees/:id", requireScope(token.SCOPE_TRANSFERS_WRITE), server.deletePayee)
+	authRoutes.POST("/payment_requests", requireScope(token.SCOPE_TRANSFERS_WRITE), server.createPaymentRequest)
+	authRoutes.GET("/payment_requests/incoming", requireScope(token.SCOPE_TRANSFERS_READ), server.listIncomingPaymentRequests)
+	authRoutes.GET("/payment_requests/outgoing", requireScope(token.SCOPE_TRANSFERS_READ), server.listOutgoingPaymentRequests)
+	authRoutes.GET("/payment_requests/:id", requireScope(token.SCOPE_TRANSFERS_READ), server.getPaymentRequest)
+	authRoutes.GET("/payment_requests/:id/events", requireScope(token.SCOPE_TRANSFERS_READ), server.listPaymentRequestEvents)
+	authRoutes.POST("/payment_requests/:id/accept", requireScope(token.SCOPE_TRANSFERS_WRITE), server.acceptPaymentRequest)
+	authRoutes.POST("/payment_requests/:id/decline", requireScope(token.SCOPE_TRANSFERS_WRITE), server.declinePaymentRequest)
+	authRoutes.POST("/payment_requests/:id/cancel", requireScope(token.SCOPE_TRANSFERS_WRITE), server.cancelPaymentRequest)
+	authRoutes.GET("/wallets/:id", requireScope(token.SCOPE_ACCOUNTS_READ), server.getWallet)
+	authRoutes.POST("/wallets/:id/conversions", requireScope(token.SCOPE_TRANSFERS_WRITE), server.convertCurrency)
+	authRoutes.POST("/api_keys", requireScope(token.SCOPE_API_KEYS_WRITE), server.createApiKey)
+	authRoutes.GET("/api_keys", requireScope(token.SCOPE_API_KEYS_READ), server.listApiKeys)
+	authRoutes.DELETE("/api_keys/:id", requireScope(token.SCOPE_API_KEYS_WRITE), server.revokeApiKey)
+	authRoutes.POST("/oauth/clients", requireScope(token.SCOPE_USER_WRITE), server.createOauthClient)
+	authRoutes.GET("/oauth/authorize", requireScope(token.SCOPE_USER_WRITE), server.getOauthConsent)
+	authRoutes.POST("/oauth/authorize", requireScope(token.SCOPE_USER_WRITE), server.oauthAuthorize)
+
+	adminRoutes := router.Group("/admin").Use(
+		authMiddleware(server.tokenManager, server.store),
+		requireScope(token.SCOPE_ADMIN),
+		requireRole(util.ROLE_ADMIN),
+	)
+	adminRoutes.GET("/currencies", server.adminListCurrencies)
+	adminRoutes.POST("/currencies", server.createCurrency)
+	adminRoutes.PATCH("/currencies/:code", server.updateCurrency)
+	adminRoutes.PUT("/exchange_rates/:from_currency/:to_currency", server.setExchangeRate)
+	adminRoutes.PUT("/interest_rates/:currency", server.setInterestRate)
+	adminRoutes.PUT("/fee_schedules/:transfer_type/:currency", server.setFeeSchedule)
+	adminRoutes.DELETE("/fee_schedules/:transfer_type/:currency", server.deleteFeeSchedule)
+	adminRoutes.GET("/fraud_rules", server.listFraudRules)
+	adminRoutes.POST("/fraud_rules", server.createFraudRule)
+	adminRoutes.PUT("/fraud_rules/:id", server.updateFraudRule)
+	adminRoutes.GET("/held_transfers", server.listHeldTransfers)
+	adminRoutes.POST("/held_transfers/:id/approve", server.approveHeldTransfer)
+	adminRoutes.POST("/held_transfers/:id/reject", server.rejectHeldTransfer)
+	adminRoutes.GET("/transfer_limits", server.listDefaultTransferLimits)
+	adminRoutes.PUT("/transfer_limits/:currency", server.setDefaultTransferLimit)
+	adminRoutes.GET("/users/:username/transfer_limits", server.listUserTransferLimits)
+	adminRoutes.PUT("/users/:username/transfer_limits/:currency", server.setUserTransferLimit)
+	adminRoutes.DELETE("/users/:username/transfer_limits/:currency", server.deleteUserTransferLimit)
 
 	server.router = router
 	return server, nil
@@ -68,7 +179,3 @@ func NewServer(config util.Config, store db.Store) (*Server, error) {
 func (server *Server) Start(address string) error {
 	return server.router.Run(address)
 }
-
-func errorResponse(err error) gin.H { // gin.H is a shortcut for map[string] interface
-	return gin.H {"error": err.Error()}
-}
\ No newline at end of file
@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/statement"
+)
+
+// STATEMENT_SETTLEMENT_DELAY is how long after its end a period is closed. Entries take the time their
+// transaction started, so a transaction still running at the end of the period can add entries to it.
+const STATEMENT_SETTLEMENT_DELAY = time.Hour
+
+// MAX_STATEMENT_DAYS bounds the size of a statement
+const MAX_STATEMENT_DAYS = 366
+
+var statementContentTypes = map[string]string{
+	statement.FORMAT_CSV: "text/csv; charset=utf-8",
+	statement.FORMAT_PDF: "application/pdf",
+}
+
+type statementURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// statementRequest takes the first and the last day of the period, in UTC
+type statementRequest struct {
+	From string `form:"from" binding:"required,datetime=2006-01-02"`
+	To string `form:"to" binding:"required,datetime=2006-01-02"`
+	Format string `form:"format" binding:"required,oneof=csv pdf"`
+}
+
+func (server *Server) getStatement(ctx *gin.Context) {
+	var uri statementURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req statementRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	// the binding has already checked the layout
+	from, _ := time.Parse(statement.DATE_LAYOUT, req.From)
+	to, _ := time.Parse(statement.DATE_LAYOUT, req.To)
+	periodEnd := to.AddDate(0, 0, 1)
+
+	if to.Before(from) {
+		abortWithError(ctx, fieldError("to", "gtefield", "must not be before from"))
+		return
+	}
+	if periodEnd.After(from.AddDate(0, 0, MAX_STATEMENT_DAYS)) {
+		abortWithError(ctx, fieldError("to", "max", fmt.Sprintf("the period must be at most %d days", MAX_STATEMENT_DAYS)))
+		return
+	}
+	if from.After(time.Now()) {
+		abortWithError(ctx, fieldError("from", "max", "must not be in the future"))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountReaders) {
+		return
+	}
+
+	// only whole calendar months are stored, any other period is computed on every request
+	// so that readers cannot fill the statements table by varying the dates
+	isMonth := from.Day() == 1 && periodEnd.Equal(from.AddDate(0, 1, 0))
+	arg := db.StatementTxParams{
+		AccountID: account.ID,
+		PeriodStart: from,
+		PeriodEnd: periodEnd,
+		Snapshot: isMonth && periodEnd.Add(STATEMENT_SETTLEMENT_DELAY).Before(time.Now()),
+	}
+
+	result, err := server.store.StatementTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	var document bytes.Buffer
+	if req.Format == statement.FORMAT_PDF {
+		err = statement.WritePDF(&document, result)
+	} else {
+		err = statement.WriteCSV(&document, result)
+	}
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", statement.Filename(result, req.Format)))
+	ctx.Data(http.StatusOK, statementContentTypes[req.Format], document.Bytes())
+}
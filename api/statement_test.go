@@ -0,0 +1,273 @@
+package api
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/statement"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetStatementAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+
+	closedStart := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	closed := db.AccountStatement{
+		Account: account,
+		PeriodStart: closedStart,
+		PeriodEnd: closedStart.AddDate(0, 1, 0),
+		OpeningBalance: 1000,
+		ClosingBalance: 1250,
+		Lines: []db.StatementLine{
+			{EntryID: 1, Amount: 250, Balance: 1250, TransferID: 1, CounterpartyAccountID: 34, CreatedAt: closedStart.Add(time.Hour)},
+		},
+		GeneratedAt: closedStart.AddDate(0, 1, 1),
+		IsSnapshot: true,
+	}
+
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	open := db.AccountStatement{
+		Account: account,
+		PeriodStart: today,
+		PeriodEnd: today.AddDate(0, 0, 1),
+		OpeningBalance: 1250,
+		ClosingBalance: 1250,
+		GeneratedAt: now,
+	}
+
+	testCases := []struct {
+		name string
+		query url.Values
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "CSV",
+			query: url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}, "format": {statement.FORMAT_CSV}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addScopedAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.StatementTxParams{
+					AccountID: account.ID,
+					PeriodStart: closed.PeriodStart,
+					PeriodEnd: closed.PeriodEnd,
+					Snapshot: true,
+				}
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				StatementTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(closed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "text/csv; charset=utf-8", recorder.Header().Get("Content-Type"))
+				require.Equal(t,
+					fmt.Sprintf(`attachment; filename="statement-%d-2026-09-01-2026-09-30.csv"`, account.ID),
+					recorder.Header().Get("Content-Disposition"),
+				)
+
+				rows, err := csv.NewReader(recorder.Body).ReadAll()
+				require.NoError(t, err)
+				require.Len(t, rows, 4)
+				require.Equal(t, "transfer from account 34", rows[2][2])
+			},
+		},
+		{
+			name: "PDF Of The Open Period",
+			query: url.Values{"from": {today.Format(statement.DATE_LAYOUT)}, "to": {today.Format(statement.DATE_LAYOUT)}, "format": {statement.FORMAT_PDF}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.StatementTxParams{
+					AccountID: account.ID,
+					PeriodStart: open.PeriodStart,
+					PeriodEnd: open.PeriodEnd,
+					Snapshot: false,
+				}
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				StatementTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(open, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				require.Equal(t, "application/pdf", recorder.Header().Get("Content-Type"))
+				require.Regexp(t, `^%PDF-1\.4\n`, recorder.Body.String())
+			},
+		},
+		{
+			name: "Closed Period Not A Month",
+			query: url.Values{"from": {"2026-09-02"}, "to": {"2026-09-30"}, "format": {statement.FORMAT_CSV}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				// the period is over but it is computed rather than stored
+				arg := db.StatementTxParams{
+					AccountID: account.ID,
+					PeriodStart: closedStart.AddDate(0, 0, 1),
+					PeriodEnd: closed.PeriodEnd,
+					Snapshot: false,
+				}
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				StatementTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(closed, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Unauthorized User",
+			query: url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}, "format": {statement.FORMAT_CSV}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().
+				StatementTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Invalid Format",
+			query: url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}, "format": {"xlsx"}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Invalid Date",
+			query: url.Values{"from": {"2026-09-31"}, "to": {"2026-10-30"}, "format": {statement.FORMAT_CSV}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "To Before From",
+			query: url.Values{"from": {"2026-09-30"}, "to": {"2026-09-01"}, "format": {statement.FORMAT_CSV}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Period Too Long",
+			query: url.Values{"from": {"2024-01-01"}, "to": {"2025-12-31"}, "format": {statement.FORMAT_CSV}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "No Authorization",
+			query: url.Values{"from": {"2026-09-01"}, "to": {"2026-09-30"}, "format": {statement.FORMAT_CSV}},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/accounts/%d/statements?%s", account.ID, tc.query.Encode())
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
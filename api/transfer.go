@@ -5,24 +5,51 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
 	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/risk"
 	"github.com/sssaang/simplebank/token"
 )
 
 
+// transferRequest pays one recipient: an account by its id, a payee of the user, or another user by its username
+// or email, whose account in the currency is never disclosed to the sender
 type transferRequest struct {
 	FromAccountID int64 `json:"from_account_id" binding:"required,min=1"`
-	ToAccountID int64 `json:"to_account_id" binding:"required,min=1"`
-	Amount int64 `json:"amount" binding:"required,min=0"`
+	ToAccountID int64 `json:"to_account_id" binding:"omitempty,min=1"`
+	PayeeID int64 `json:"payee_id" binding:"omitempty,min=1"`
+	ToUsername string `json:"to_username" binding:"omitempty,email"`
+	ToEmail string `json:"to_email" binding:"omitempty,email"`
+	Amount string `json:"amount" binding:"required,money"`
 	Currency string `json:"currency" binding:"required,currency"`
+	Description string `json:"description" binding:"max=140"`
+	// Reference identifies the transfer in the system of the sender, such as an invoice number
+	Reference string `json:"reference" binding:"max=64"`
+	Metadata map[string]string `json:"metadata" binding:"max=20,dive,keys,min=1,max=40,endkeys,max=500"`
 }
 
 func (server *Server) makeTransfer(ctx *gin.Context) {
 	var req transferRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if req.recipients() != 1 {
+		abortWithError(ctx, fieldError("to_account_id", "required_without", "exactly one of to_account_id, payee_id, to_username and to_email is required"))
+		return
+	}
+
+	if !requireVerifiedEmail(ctx) {
+		return
+	}
+
+	amount, err := parseAmount("amount", req.Amount, req.Currency)
+	if err != nil {
+		abortWithError(ctx, err)
 		return
 	}
 
@@ -33,50 +60,270 @@ func (server *Server) makeTransfer(ctx *gin.Context) {
 		return
 	}
 
+	if !server.authorizeAccount(ctx, fromAccount, accountSpenders) {
+		return
+	}
+
 	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
-	if fromAccount.Owner != authPayload.Username {
-		err := errors.New("the user has no access to the from account")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+
+	var toAccount db.Account
+	switch {
+	case req.toUser():
+		toAccount, isValid = server.recipientAccount(ctx, req)
+		if !isValid {
+			return
+		}
+	case req.PayeeID != 0:
+		payee, err := server.store.GetPayee(ctx, db.GetPayeeParams{
+			ID: req.PayeeID,
+			Owner: authPayload.Username,
+		})
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		toAccount, isValid = server.validAccount(ctx, payee.AccountID, req.Currency)
+		if !isValid {
+			return
+		}
+	default:
+		toAccount, isValid = server.validAccount(ctx, req.ToAccountID, req.Currency)
+		if !isValid {
+			return
+		}
+	}
+
+	if !server.checkCoolingOff(ctx, toAccount, amount.Amount()) {
 		return
 	}
 
-	_, isValid = server.validAccount(ctx, req.ToAccountID, req.Currency) 
-	if !isValid {
+	assessment, err := server.riskEngine.Screen(ctx, risk.Transfer{
+		FromAccount: fromAccount,
+		ToAccount: toAccount,
+		Amount: amount.Amount(),
+		RequestedBy: authPayload.Username,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
 		return
 	}
 
 	arg := db.TransferTxParams{
-		FromAccountID: req.FromAccountID,
-		ToAccountID: req.ToAccountID,
-		Amount: req.Amount,
+		FromAccountID: fromAccount.ID,
+		ToAccountID: toAccount.ID,
+		Amount: amount.Amount(),
+		Description: req.Description,
+		Reference: req.Reference,
+		Metadata: marshalMetadata(req.Metadata),
+		RequestedBy: authPayload.Username,
+		RecipientHidden: req.toUser(),
+	}
+
+	switch assessment.Outcome {
+	case risk.OUTCOME_DENY:
+		abortWithError(ctx, errTransferDenied)
+		return
+	case risk.OUTCOME_REVIEW:
+		held, err := server.holdTransfer(ctx, fromAccount.Currency, arg, assessment)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		res := newHeldTransferResponse(held)
+		if req.toUser() {
+			res.ToAccountID = 0
+		}
+		ctx.JSON(http.StatusAccepted, res)
+		return
 	}
 
 	result, err := server.store.TransferTx(ctx, arg)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return 
 	}
 
-	ctx.JSON(http.StatusOK, result)
+	if req.toUser() {
+		ctx.JSON(http.StatusOK, newUserTransferResponse(result))
+		return
+	}
+	ctx.JSON(http.StatusOK, newTransferTxResponse(result))
+}
+
+// recipients counts the recipients the request names, a transfer has exactly one
+func (req transferRequest) recipients() int {
+	count := 0
+	for _, given := range []bool{req.ToAccountID != 0, req.PayeeID != 0, len(req.ToUsername) > 0, len(req.ToEmail) > 0} {
+		if given {
+			count++
+		}
+	}
+	return count
+}
+
+// toUser tells whether the recipient is a user, whose account is resolved by the server and kept from the sender
+func (req transferRequest) toUser() bool {
+	return len(req.ToUsername) > 0 || len(req.ToEmail) > 0
+}
+
+// recipientAccount finds the checking account of the recipient user in the currency of the transfer,
+// a user has at most one per currency and its savings accounts are not paid into
+func (server *Server) recipientAccount(ctx *gin.Context, req transferRequest) (db.Account, bool) {
+	user, isValid := server.findUser(ctx, "recipient", req.ToUsername, req.ToEmail)
+	if !isValid {
+		return db.Account{}, false
+	}
+
+	account, err := server.store.GetOwnerAccount(ctx, db.GetOwnerAccountParams{
+		Owner: user.Username,
+		Currency: req.Currency,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		message := fmt.Sprintf("the recipient has no %s checking account", req.Currency)
+		abortWithError(ctx, newApiError(http.StatusUnprocessableEntity, ERROR_CODE_NO_RECIPIENT_ACCOUNT, message))
+		return db.Account{}, false
+	}
+	if err != nil {
+		abortWithError(ctx, err)
+		return db.Account{}, false
+	}
+
+	return account, true
+}
+
+// findUser finds the other party of a request by its username, or by its email when no username is given
+func (server *Server) findUser(ctx *gin.Context, party string, username string, email string) (db.User, bool) {
+	var user db.User
+	var err error
+	if len(username) > 0 {
+		user, err = server.store.GetUser(ctx, username)
+	} else {
+		user, err = server.store.GetUserByEmail(ctx, email)
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		abortWithError(ctx, newApiError(http.StatusNotFound, ERROR_CODE_NOT_FOUND, fmt.Sprintf("the %s does not exist", party)))
+		return db.User{}, false
+	}
+	if err != nil {
+		abortWithError(ctx, err)
+		return db.User{}, false
+	}
+
+	return user, true
 }
 
 func (server *Server) validAccount(ctx *gin.Context, accountID int64, currency string) (db.Account, bool) {
 	account, err := server.store.GetAccount(ctx, accountID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return db.Account{}, false
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return db.Account{}, false
 	}
 
 	if account.Currency != currency {
-		err := fmt.Errorf("account [%d] currency mismatch: the currency of the account is %s while the currency of the transfer is %s", accountID, account.Currency, currency)
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		message := fmt.Sprintf("account [%d] currency mismatch: the currency of the account is %s while the currency of the transfer is %s", accountID, account.Currency, currency)
+		abortWithError(ctx, newApiError(http.StatusBadRequest, ERROR_CODE_CURRENCY_MISMATCH, message))
 		return db.Account{}, false
 	}
 
 	return account, true
-}
\ No newline at end of file
+}
+
+type accountTransfersURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// listAccountTransfersRequest filters the transfers of an account, every filter left out matches all of them
+type listAccountTransfersRequest struct {
+	PageID int32 `form:"page_id" binding:"required,min=1"`
+	PageSize int32 `form:"page_size" binding:"required,min=5,max=10"`
+	// Query is searched for in the description and the reference, ignoring case
+	Query string `form:"q" binding:"max=140"`
+	Reference string `form:"reference" binding:"max=64"`
+	// Metadata is read from the metadata[key]=value parameters, a transfer must have every pair
+	Metadata map[string]string `form:"-" binding:"max=20,dive,keys,min=1,max=40,endkeys,max=500"`
+}
+
+// likeEscaper keeps the wildcards of a search query literal in a LIKE pattern
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// searchPattern matches the query anywhere in a column, an empty pattern disables the search
+func searchPattern(query string) string {
+	if query == "" {
+		return ""
+	}
+	return "%" + likeEscaper.Replace(query) + "%"
+}
+
+// listAccountTransfers is the transfer history of an account, searchable by its description, reference and metadata
+func (server *Server) listAccountTransfers(ctx *gin.Context) {
+	var uri accountTransfersURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req listAccountTransfersRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	// gin does not bind maps from a query string, the metadata filters are validated once read
+	req.Metadata = ctx.QueryMap("metadata")
+	if err := binding.Validator.ValidateStruct(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	if !server.authorizeAccount(ctx, account, accountReaders) {
+		return
+	}
+
+	transfers, err := server.store.SearchTransfers(ctx, db.SearchTransfersParams{
+		AccountID: account.ID,
+		Pattern: searchPattern(req.Query),
+		Reference: req.Reference,
+		Metadata: marshalMetadata(req.Metadata),
+		RowLimit: req.PageSize,
+		RowOffset: (req.PageID - 1) * req.PageSize,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	// the account of a recipient the server resolved is only shown to the members of that account
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	visible := map[int64]bool{account.ID: true}
+
+	res := make([]transferResponse, len(transfers))
+	for i, transfer := range transfers {
+		res[i] = newTransferResponse(transfer, account.Currency)
+		if !transfer.RecipientHidden {
+			continue
+		}
+
+		shown, ok := visible[transfer.ToAccountID]
+		if !ok {
+			shown, err = server.isAccountMember(ctx, transfer.ToAccountID, authPayload.Username)
+			if err != nil {
+				abortWithError(ctx, err)
+				return
+			}
+			visible[transfer.ToAccountID] = shown
+		}
+		if !shown {
+			res[i].ToAccountID = 0
+		}
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
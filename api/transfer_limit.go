@@ -0,0 +1,437 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+)
+
+// periodUsageResponse is the state of a daily or monthly limit
+type periodUsageResponse struct {
+	Limit util.Money `json:"limit"`
+	Used util.Money `json:"used"`
+	Remaining util.Money `json:"remaining"`
+	ResetsAt time.Time `json:"resets_at"`
+}
+
+func newPeriodUsageResponse(limit int64, used int64, currency string, resetsAt time.Time) *periodUsageResponse {
+	return &periodUsageResponse{
+		Limit: util.NewMoney(limit, currency),
+		Used: util.NewMoney(used, currency),
+		Remaining: util.NewMoney(db.RemainingLimit(limit, used), currency),
+		ResetsAt: resetsAt,
+	}
+}
+
+// limitResponse leaves out the limits which do not apply
+type limitResponse struct {
+	Currency string `json:"currency"`
+	PerTransaction *util.Money `json:"per_transaction,omitempty"`
+	Daily *periodUsageResponse `json:"daily,omitempty"`
+	Monthly *periodUsageResponse `json:"monthly,omitempty"`
+}
+
+func newLimitResponse(currency string, perTransaction, daily, monthly sql.NullInt64, dailyUsed, monthlyUsed int64) limitResponse {
+	day, month := db.TransferPeriods(time.Now())
+
+	res := limitResponse{Currency: currency}
+	res.PerTransaction = optionalMoney(perTransaction, currency)
+	if daily.Valid {
+		res.Daily = newPeriodUsageResponse(daily.Int64, dailyUsed, currency, day.AddDate(0, 0, 1))
+	}
+	if monthly.Valid {
+		res.Monthly = newPeriodUsageResponse(monthly.Int64, monthlyUsed, currency, month.AddDate(0, 1, 0))
+	}
+	return res
+}
+
+// listLimits tells the user what it can still transfer today and this month in every currency with limits
+func (server *Server) listLimits(ctx *gin.Context) {
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+
+	limits, err := server.store.ListTransferLimits(ctx, authPayload.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	day, month := db.TransferPeriods(time.Now())
+	usage, err := server.store.ListTransferUsage(ctx, db.ListTransferUsageParams{
+		Owner: authPayload.Username,
+		Day: day,
+		Month: month,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	used := make(map[string]int64, len(usage))
+	for _, u := range usage {
+		used[u.Currency+"/"+u.Period] = u.Amount
+	}
+
+	res := make([]limitResponse, len(limits))
+	for i, limit := range limits {
+		res[i] = newLimitResponse(limit.Currency, limit.PerTransaction, limit.Daily, limit.Monthly,
+			used[limit.Currency+"/"+db.TRANSFER_PERIOD_DAY], used[limit.Currency+"/"+db.TRANSFER_PERIOD_MONTH])
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func optionalMoney(amount sql.NullInt64, currency string) *util.Money {
+	if !amount.Valid {
+		return nil
+	}
+	money := util.NewMoney(amount.Int64, currency)
+	return &money
+}
+
+type transferLimitResponse struct {
+	Username string `json:"username,omitempty"`
+	AccountID int64 `json:"account_id,omitempty"`
+	Currency string `json:"currency"`
+	PerTransaction *util.Money `json:"per_transaction,omitempty"`
+	Daily *util.Money `json:"daily,omitempty"`
+	Monthly *util.Money `json:"monthly,omitempty"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func newTransferLimitResponse(limit db.TransferLimit) transferLimitResponse {
+	return transferLimitResponse{
+		Currency: limit.Currency,
+		PerTransaction: optionalMoney(limit.PerTransaction, limit.Currency),
+		Daily: optionalMoney(limit.Daily, limit.Currency),
+		Monthly: optionalMoney(limit.Monthly, limit.Currency),
+		UpdatedAt: limit.UpdatedAt,
+	}
+}
+
+func newUserTransferLimitResponse(limit db.UserTransferLimit) transferLimitResponse {
+	return transferLimitResponse{
+		Username: limit.Username,
+		Currency: limit.Currency,
+		PerTransaction: optionalMoney(limit.PerTransaction, limit.Currency),
+		Daily: optionalMoney(limit.Daily, limit.Currency),
+		Monthly: optionalMoney(limit.Monthly, limit.Currency),
+		UpdatedAt: limit.UpdatedAt,
+	}
+}
+
+func newAccountTransferLimitResponse(limit db.AccountTransferLimit, currency string) transferLimitResponse {
+	return transferLimitResponse{
+		AccountID: limit.AccountID,
+		Currency: currency,
+		PerTransaction: optionalMoney(limit.PerTransaction, currency),
+		Daily: optionalMoney(limit.Daily, currency),
+		Monthly: optionalMoney(limit.Monthly, currency),
+		UpdatedAt: limit.UpdatedAt,
+	}
+}
+
+// transferLimitRequest sets every limit of a currency at once, a limit left out no longer applies
+type transferLimitRequest struct {
+	PerTransaction string `json:"per_transaction" binding:"omitempty,money"`
+	Daily string `json:"daily" binding:"omitempty,money"`
+	Monthly string `json:"monthly" binding:"omitempty,money"`
+}
+
+type transferLimitAmounts struct {
+	PerTransaction sql.NullInt64
+	Daily sql.NullInt64
+	Monthly sql.NullInt64
+}
+
+// parseTransferLimits reads the limits in the currency, a limit cannot be lower than the limit of a shorter period
+func parseTransferLimits(req transferLimitRequest, currency string) (transferLimitAmounts, error) {
+	var amounts transferLimitAmounts
+
+	fields := []struct {
+		name string
+		value string
+		amount *sql.NullInt64
+	}{
+		{"per_transaction", req.PerTransaction, &amounts.PerTransaction},
+		{"daily", req.Daily, &amounts.Daily},
+		{"monthly", req.Monthly, &amounts.Monthly},
+	}
+
+	var shorter *sql.NullInt64
+	var shorterName string
+	for _, field := range fields {
+		if len(field.value) == 0 {
+			continue
+		}
+
+		amount, err := parseAmount(field.name, field.value, currency)
+		if err != nil {
+			return amounts, err
+		}
+		*field.amount = sql.NullInt64{Int64: amount.Amount(), Valid: true}
+
+		if shorter != nil && field.amount.Int64 < shorter.Int64 {
+			return amounts, fieldError(field.name, "gtefield", "cannot be lower than "+shorterName)
+		}
+		shorter, shorterName = field.amount, field.name
+	}
+
+	return amounts, nil
+}
+
+func (server *Server) listDefaultTransferLimits(ctx *gin.Context) {
+	limits, err := server.store.ListDefaultTransferLimits(ctx)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]transferLimitResponse, len(limits))
+	for i, limit := range limits {
+		res[i] = newTransferLimitResponse(limit)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type defaultTransferLimitURI struct {
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+// setDefaultTransferLimit replaces the limits of the users of a currency who have none of their own
+func (server *Server) setDefaultTransferLimit(ctx *gin.Context) {
+	var uri defaultTransferLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req transferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	amounts, err := parseTransferLimits(req, uri.Currency)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	limit, err := server.store.UpsertDefaultTransferLimit(ctx, db.UpsertDefaultTransferLimitParams{
+		Currency: uri.Currency,
+		PerTransaction: amounts.PerTransaction,
+		Daily: amounts.Daily,
+		Monthly: amounts.Monthly,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newTransferLimitResponse(limit))
+}
+
+type userTransferLimitsURI struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+func (server *Server) listUserTransferLimits(ctx *gin.Context) {
+	var uri userTransferLimitsURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	limits, err := server.store.ListUserTransferLimits(ctx, uri.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := make([]transferLimitResponse, len(limits))
+	for i, limit := range limits {
+		res[i] = newUserTransferLimitResponse(limit)
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type userTransferLimitURI struct {
+	Username string `uri:"username" binding:"required"`
+	Currency string `uri:"currency" binding:"required,currency"`
+}
+
+// setUserTransferLimit gives a user its own limits in a currency, they replace the defaults as a whole
+func (server *Server) setUserTransferLimit(ctx *gin.Context) {
+	var uri userTransferLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req transferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	amounts, err := parseTransferLimits(req, uri.Currency)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	limit, err := server.store.UpsertUserTransferLimit(ctx, db.UpsertUserTransferLimitParams{
+		Username: uri.Username,
+		Currency: uri.Currency,
+		PerTransaction: amounts.PerTransaction,
+		Daily: amounts.Daily,
+		Monthly: amounts.Monthly,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserTransferLimitResponse(limit))
+}
+
+// deleteUserTransferLimit puts the user back on the defaults of the currency
+func (server *Server) deleteUserTransferLimit(ctx *gin.Context) {
+	var uri userTransferLimitURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	limit, err := server.store.DeleteUserTransferLimit(ctx, db.DeleteUserTransferLimitParams{
+		Username: uri.Username,
+		Currency: uri.Currency,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserTransferLimitResponse(limit))
+}
+
+type accountLimitsURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// accountLimitsResponse leaves out the limits the account does not have
+type accountLimitsResponse struct {
+	AccountID int64 `json:"account_id"`
+	limitResponse
+}
+
+// getAccountLimits tells the members what can still be transferred from the account today and this month,
+// on top of the limits of each of them
+func (server *Server) getAccountLimits(ctx *gin.Context) {
+	account, isValid := server.limitedAccount(ctx, accountReaders)
+	if !isValid {
+		return
+	}
+
+	limit, err := server.store.GetAccountTransferLimit(ctx, account.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		abortWithError(ctx, err)
+		return
+	}
+
+	day, month := db.TransferPeriods(time.Now())
+	usage, err := server.store.ListAccountTransferUsage(ctx, db.ListAccountTransferUsageParams{
+		AccountID: account.ID,
+		Day: day,
+		Month: month,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	used := make(map[string]int64, len(usage))
+	for _, u := range usage {
+		used[u.Period] = u.Amount
+	}
+
+	ctx.JSON(http.StatusOK, accountLimitsResponse{
+		AccountID: account.ID,
+		limitResponse: newLimitResponse(account.Currency, limit.PerTransaction, limit.Daily, limit.Monthly,
+			used[db.TRANSFER_PERIOD_DAY], used[db.TRANSFER_PERIOD_MONTH]),
+	})
+}
+
+// setAccountLimits caps the transfers from an account whoever makes them, only its owners set the limits
+func (server *Server) setAccountLimits(ctx *gin.Context) {
+	account, isValid := server.limitedAccount(ctx, accountOwners)
+	if !isValid {
+		return
+	}
+
+	var req transferLimitRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	amounts, err := parseTransferLimits(req, account.Currency)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	limit, err := server.store.UpsertAccountTransferLimit(ctx, db.UpsertAccountTransferLimitParams{
+		AccountID: account.ID,
+		PerTransaction: amounts.PerTransaction,
+		Daily: amounts.Daily,
+		Monthly: amounts.Monthly,
+	})
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountTransferLimitResponse(limit, account.Currency))
+}
+
+// deleteAccountLimits leaves the transfers from the account to the limits of the members alone
+func (server *Server) deleteAccountLimits(ctx *gin.Context) {
+	account, isValid := server.limitedAccount(ctx, accountOwners)
+	if !isValid {
+		return
+	}
+
+	limit, err := server.store.DeleteAccountTransferLimit(ctx, account.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newAccountTransferLimitResponse(limit, account.Currency))
+}
+
+// limitedAccount finds the account of the uri and checks that the user has one of the roles on it
+func (server *Server) limitedAccount(ctx *gin.Context, roles []string) (db.Account, bool) {
+	var uri accountLimitsURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return db.Account{}, false
+	}
+
+	account, err := server.store.GetAccount(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return db.Account{}, false
+	}
+
+	return account, server.authorizeAccount(ctx, account, roles)
+}
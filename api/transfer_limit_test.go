@@ -0,0 +1,449 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+func limitAmount(amount int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: amount, Valid: true}
+}
+
+func TestListLimitsAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	day, month := db.TransferPeriods(time.Now())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	ListTransferLimits(gomock.Any(), gomock.Eq(user.Username)).
+	Times(1).
+	Return([]db.ListTransferLimitsRow{
+		{Currency: util.EUR, Daily: limitAmount(50000)},
+		{Currency: util.USD, PerTransaction: limitAmount(10000), Daily: limitAmount(50000), Monthly: limitAmount(100000)},
+	}, nil)
+	store.EXPECT().
+	ListTransferUsage(gomock.Any(), gomock.Eq(db.ListTransferUsageParams{Owner: user.Username, Day: day, Month: month})).
+	Times(1).
+	Return([]db.TransferUsage{
+		{Owner: user.Username, Currency: util.USD, Period: db.TRANSFER_PERIOD_DAY, PeriodStart: day, Amount: 60000},
+		{Owner: user.Username, Currency: util.USD, Period: db.TRANSFER_PERIOD_MONTH, PeriodStart: month, Amount: 70000},
+	}, nil)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, "/limits", nil)
+	require.NoError(t, err)
+
+	addScopedAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got []gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Len(t, got, 2)
+
+	// limits which do not apply are left out, usage without a row is zero
+	require.NotContains(t, got[0], "per_transaction")
+	require.NotContains(t, got[0], "monthly")
+	require.Equal(t, "0.00", got[0]["daily"].(map[string]interface{})["used"])
+
+	require.Equal(t, "100.00", got[1]["per_transaction"])
+	daily := got[1]["daily"].(map[string]interface{})
+	require.Equal(t, "600.00", daily["used"])
+	// a limit lowered below the usage leaves nothing
+	require.Equal(t, "0.00", daily["remaining"])
+	require.Equal(t, day.AddDate(0, 0, 1).Format(time.RFC3339), daily["resets_at"])
+	monthly := got[1]["monthly"].(map[string]interface{})
+	require.Equal(t, "300.00", monthly["remaining"])
+	require.Equal(t, month.AddDate(0, 1, 0).Format(time.RFC3339), monthly["resets_at"])
+}
+
+func TestSetDefaultTransferLimitAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+
+	testCases := []struct {
+		name string
+		currency string
+		body gin.H
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			currency: util.USD,
+			body: gin.H{"per_transaction": "1000.00", "monthly": "20000.00"},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.UpsertDefaultTransferLimitParams{
+					Currency: util.USD,
+					PerTransaction: limitAmount(100000),
+					Monthly: limitAmount(2000000),
+				}
+				store.EXPECT().
+				UpsertDefaultTransferLimit(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.TransferLimit{Currency: arg.Currency, PerTransaction: arg.PerTransaction, Monthly: arg.Monthly}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, "1000.00", got["per_transaction"])
+				require.NotContains(t, got, "daily")
+				require.NotContains(t, got, "username")
+			},
+		},
+		{
+			name: "Daily Below Per Transaction",
+			currency: util.USD,
+			body: gin.H{"per_transaction": "1000.00", "daily": "500.00"},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertDefaultTransferLimit(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "daily", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Too Many Decimals",
+			currency: util.KRW,
+			body: gin.H{"daily": "1000.50"},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertDefaultTransferLimit(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Unsupported Currency",
+			currency: "XYZ",
+			body: gin.H{"daily": "1000.00"},
+			username: admin.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertDefaultTransferLimit(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Not Admin",
+			currency: util.USD,
+			body: gin.H{"daily": "1000.00"},
+			username: user.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertDefaultTransferLimit(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPut, "/admin/transfer_limits/"+tc.currency, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestSetUserTransferLimitAPI(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	arg := db.UpsertUserTransferLimitParams{
+		Username: user.Username,
+		Currency: util.EUR,
+		Daily: limitAmount(1000000),
+	}
+	store.EXPECT().
+	UpsertUserTransferLimit(gomock.Any(), gomock.Eq(arg)).
+	Times(1).
+	Return(db.UserTransferLimit{Username: arg.Username, Currency: arg.Currency, Daily: arg.Daily}, nil)
+	stubAdminUser(store, admin)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	data, err := json.Marshal(gin.H{"daily": "10000"})
+	require.NoError(t, err)
+
+	url := "/admin/users/" + user.Username + "/transfer_limits/" + util.EUR
+	request, err := http.NewRequest(http.MethodPut, url, bytes.NewReader(data))
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, got["username"])
+	require.Equal(t, "10000.00", got["daily"])
+}
+
+func TestDeleteUserTransferLimitAPINotFound(t *testing.T) {
+	admin := randomAdmin(t)
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	DeleteUserTransferLimit(gomock.Any(), gomock.Eq(db.DeleteUserTransferLimitParams{Username: user.Username, Currency: util.USD})).
+	Times(1).
+	Return(db.UserTransferLimit{}, sql.ErrNoRows)
+	stubAdminUser(store, admin)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	url := "/admin/users/" + user.Username + "/transfer_limits/" + util.USD
+	request, err := http.NewRequest(http.MethodDelete, url, nil)
+	require.NoError(t, err)
+
+	addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+}
+
+func TestGetAccountLimitsAPI(t *testing.T) {
+	holder, _ := randomUser(t)
+	viewer, _ := randomUser(t)
+	account := randomAccount(holder.Username)
+	account.Currency = util.USD
+	day, month := db.TransferPeriods(time.Now())
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+	Times(1).
+	Return(account, nil)
+	stubAccountRole(store, account, viewer.Username, db.ACCOUNT_ROLE_VIEWER)
+	store.EXPECT().
+	GetAccountTransferLimit(gomock.Any(), gomock.Eq(account.ID)).
+	Times(1).
+	Return(db.AccountTransferLimit{AccountID: account.ID, Daily: limitAmount(50000)}, nil)
+	store.EXPECT().
+	ListAccountTransferUsage(gomock.Any(), gomock.Eq(db.ListAccountTransferUsageParams{AccountID: account.ID, Day: day, Month: month})).
+	Times(1).
+	Return([]db.AccountTransferUsage{
+		{AccountID: account.ID, Period: db.TRANSFER_PERIOD_DAY, PeriodStart: day, Amount: 20000},
+	}, nil)
+	stubAuthUsers(store)
+
+	server := NewTestServer(t, store)
+	recorder := httptest.NewRecorder()
+
+	request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/limits", account.ID), nil)
+	require.NoError(t, err)
+
+	addScopedAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, viewer.Username, token.READ_ONLY_SCOPES, time.Minute)
+	server.router.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code)
+
+	var got gin.H
+	err = json.Unmarshal(recorder.Body.Bytes(), &got)
+	require.NoError(t, err)
+	require.Equal(t, float64(account.ID), got["account_id"])
+	require.Equal(t, util.USD, got["currency"])
+	require.NotContains(t, got, "per_transaction")
+	require.NotContains(t, got, "monthly")
+	require.Equal(t, "300.00", got["daily"].(map[string]interface{})["remaining"])
+}
+
+func TestSetAccountLimitsAPI(t *testing.T) {
+	holder, _ := randomUser(t)
+	spender, _ := randomUser(t)
+	account := randomAccount(holder.Username)
+	account.Currency = util.USD
+
+	testCases := []struct {
+		name string
+		method string
+		body gin.H
+		username string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			method: http.MethodPut,
+			body: gin.H{"per_transaction": "100.00", "daily": "500.00"},
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.UpsertAccountTransferLimitParams{
+					AccountID: account.ID,
+					PerTransaction: limitAmount(10000),
+					Daily: limitAmount(50000),
+				}
+				store.EXPECT().
+				UpsertAccountTransferLimit(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.AccountTransferLimit{AccountID: account.ID, PerTransaction: arg.PerTransaction, Daily: arg.Daily}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, float64(account.ID), got["account_id"])
+				require.Equal(t, "100.00", got["per_transaction"])
+				require.NotContains(t, got, "monthly")
+			},
+		},
+		{
+			name: "Daily Below Per Transaction",
+			method: http.MethodPut,
+			body: gin.H{"per_transaction": "100.00", "daily": "50.00"},
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "daily", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Spender",
+			method: http.MethodPut,
+			body: gin.H{"daily": "1000.00"},
+			username: spender.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				stubAccountRole(store, account, spender.Username, db.ACCOUNT_ROLE_SPENDER)
+				store.EXPECT().
+				UpsertAccountTransferLimit(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Delete",
+			method: http.MethodDelete,
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				DeleteAccountTransferLimit(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(db.AccountTransferLimit{AccountID: account.ID, Monthly: limitAmount(100000)}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Delete Not Found",
+			method: http.MethodDelete,
+			username: holder.Username,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				DeleteAccountTransferLimit(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(db.AccountTransferLimit{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			store.EXPECT().
+			GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+			Times(1).
+			Return(account, nil)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(tc.method, fmt.Sprintf("/accounts/%d/limits", account.ID), bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
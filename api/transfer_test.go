@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"database/sql"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"testing"
 	"time"
 
@@ -14,14 +16,38 @@ import (
 	db "github.com/sssaang/simplebank/db/sqlc"
 	testdb "github.com/sssaang/simplebank/db/test"
 	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/risk"
 	"github.com/sssaang/simplebank/token"
 	"github.com/stretchr/testify/require"
 )
 
+// stubFraudRules screens every transfer without rules, after the expectations of the test case
+func stubFraudRules(store *testdb.MockStore) {
+	store.EXPECT().
+	ListEnabledFraudRules(gomock.Any()).
+	AnyTimes().
+	Return([]db.FraudRule{}, nil)
+}
+
+// amountRule matches every transfer of the currency
+func amountRule(name string, action string, currency string) db.FraudRule {
+	return db.FraudRule{
+		ID: util.RandomInt(1, 1000),
+		Name: name,
+		Kind: risk.RULE_AMOUNT,
+		Action: action,
+		Currency: sql.NullString{String: currency, Valid: true},
+		MinAmount: sql.NullInt64{Int64: 1, Valid: true},
+		IsEnabled: true,
+	}
+}
+
 func TestMakeTransfer(t *testing.T){
-	amount := int64(10)
+	amount := "10"
 	user1, _ := randomUser(t)
 	account1 := randomAccount(user1.Username)
+	minorAmount, err := util.ParseMoney(amount, account1.Currency)
+	require.NoError(t, err)
 	user2, _ := randomUser(t)
 	account2 := randomAccount(user2.Username)
 	account2.Currency = account1.Currency
@@ -64,7 +90,47 @@ func TestMakeTransfer(t *testing.T){
 				arg := db.TransferTxParams{
 					FromAccountID: account1.ID,
 					ToAccountID: account2.ID,
-					Amount: 10,
+					Amount: minorAmount.Amount(),
+					Metadata: json.RawMessage("{}"),
+					RequestedBy: user1.Username,
+				}
+
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Spender Of A Shared Account",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user3.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+				stubAccountRole(store, account1, user3.Username, db.ACCOUNT_ROLE_SPENDER)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				// the transfer counts against the limits of the spender, not those of the holder
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID: account2.ID,
+					Amount: minorAmount.Amount(),
+					Metadata: json.RawMessage("{}"),
+					RequestedBy: user3.Username,
 				}
 
 				store.EXPECT().
@@ -75,6 +141,156 @@ func TestMakeTransfer(t *testing.T){
 				require.Equal(t, http.StatusOK, recorder.Code)
 			},
 		},
+		{
+			name: "Transfer With Fee",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				result := db.TransferTxResult{
+					Transfer: db.Transfer{FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: minorAmount.Amount(), Fee: 25},
+					FromAccount: account1,
+					ToAccount: account2,
+					FeeEntry: &db.Entry{AccountID: account1.ID, Amount: -25},
+				}
+
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(result, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					Transfer gin.H `json:"transfer"`
+					FeeEntry gin.H `json:"fee_entry"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, util.NewMoney(25, account1.Currency).String(), got.Transfer["fee"])
+				require.Equal(t, util.NewMoney(-25, account1.Currency).String(), got.FeeEntry["amount"])
+			},
+		},
+		{
+			name: "With Details",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+				"description": "March rent",
+				"reference": "INV-2042",
+				"metadata": gin.H{"order": "42"},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID: account2.ID,
+					Amount: minorAmount.Amount(),
+					Description: "March rent",
+					Reference: "INV-2042",
+					Metadata: json.RawMessage(`{"order":"42"}`),
+					RequestedBy: user1.Username,
+				}
+				result := db.TransferTxResult{
+					Transfer: db.Transfer{
+						FromAccountID: account1.ID,
+						ToAccountID: account2.ID,
+						Amount: minorAmount.Amount(),
+						Description: arg.Description,
+						Reference: arg.Reference,
+						Metadata: arg.Metadata,
+					},
+					FromAccount: account1,
+					ToAccount: account2,
+				}
+
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(result, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					Transfer gin.H `json:"transfer"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, "March rent", got.Transfer["description"])
+				require.Equal(t, "INV-2042", got.Transfer["reference"])
+				require.Equal(t, map[string]interface{}{"order": "42"}, got.Transfer["metadata"])
+			},
+		},
+		{
+			name: "Description Too Long",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+				"description": util.RandomString(141),
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "description", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Metadata Value Too Long",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+				"metadata": gin.H{"note": util.RandomString(501)},
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "metadata[note]", apiErr.Details[0].Field)
+			},
+		},
 		{
 			name: "Currency Mismatch",
 			body: gin.H {
@@ -98,7 +314,7 @@ func TestMakeTransfer(t *testing.T){
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_CURRENCY_MISMATCH)
 			},
 		},
 		{
@@ -124,7 +340,7 @@ func TestMakeTransfer(t *testing.T){
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
 			},
 		},
 		{
@@ -132,7 +348,26 @@ func TestMakeTransfer(t *testing.T){
 			body: gin.H{
 				"from_account_id": account1.ID,
 				"to_account_id":   account2.ID,
-				"amount":          -amount,
+				"amount":          "-" + amount,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Too Many Decimals",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          "0.001",
 				"currency":        account1.Currency,
 			},
 			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
@@ -143,7 +378,8 @@ func TestMakeTransfer(t *testing.T){
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusBadRequest, recorder.Code)
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "amount", apiErr.Details[0].Field)
 			},
 		},
 		{
@@ -167,7 +403,7 @@ func TestMakeTransfer(t *testing.T){
 				Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+				requireApiError(t, recorder, http.StatusInternalServerError, ERROR_CODE_INTERNAL)
 			},
 		},
 		{
@@ -198,6 +434,92 @@ func TestMakeTransfer(t *testing.T){
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
 			},
 		},
+		{
+			name: "Insufficient Funds",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id":   account2.ID,
+				"amount":          amount,
+				"currency":        account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).Return(db.TransferTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_INSUFFICIENT_FUNDS)
+			},
+		},
+		{
+			name: "Account Closed",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).Return(db.TransferTxResult{}, db.ErrAccountClosed)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_CONFLICT)
+			},
+		},
+		{
+			name: "Limit Exceeded",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				limitErr := &db.TransferLimitError{Limit: db.LIMIT_DAILY, Currency: account1.Currency, Remaining: 0}
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).Return(db.TransferTxResult{}, limitErr)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_LIMIT_EXCEEDED)
+				require.Contains(t, apiErr.Message, "daily limit")
+			},
+		},
 		{
 			name: "Unauthorized user transfer",
 			body: gin.H {
@@ -213,6 +535,10 @@ func TestMakeTransfer(t *testing.T){
 				store.EXPECT().
 				GetAccount(gomock.Any(), gomock.Any()).
 				Times(1).Return(account1, nil)
+				store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{}, sql.ErrNoRows)
 				
 				store.EXPECT().
 				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
@@ -221,7 +547,118 @@ func TestMakeTransfer(t *testing.T){
 				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Unverified Email",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user1.Username)).
+				Times(1).
+				Return(user1, nil)
+
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_EMAIL_NOT_VERIFIED)
+			},
+		},
+		{
+			name: "Held For Review",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				store.EXPECT().
+				ListEnabledFraudRules(gomock.Any()).
+				Times(1).
+				Return([]db.FraudRule{amountRule("large transfer", risk.OUTCOME_REVIEW, account1.Currency)}, nil)
+
+				arg := db.CreateHeldTransferParams{
+					FromAccountID: account1.ID,
+					ToAccountID: account2.ID,
+					Amount: minorAmount.Amount(),
+					Currency: account1.Currency,
+					RequestedBy: user1.Username,
+					Reasons: []string{"large transfer"},
+					Metadata: json.RawMessage("{}"),
+				}
+
+				store.EXPECT().
+				CreateHeldTransfer(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.HeldTransfer{ID: 1, Amount: arg.Amount, Currency: arg.Currency, Status: db.HELD_TRANSFER_PENDING}, nil)
+
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.Equal(t, db.HELD_TRANSFER_PENDING, res["status"])
+				require.NotContains(t, res, "reasons")
+			},
+		},
+		{
+			name: "Denied",
+			body: gin.H{
+				"from_account_id": account1.ID,
+				"to_account_id": account2.ID,
+				"amount": amount,
+				"currency": account1.Currency,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager){
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user1.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account1.ID)).
+				Times(1).Return(account1, nil)
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account2.ID)).
+				Times(1).Return(account2, nil)
+
+				store.EXPECT().
+				ListEnabledFraudRules(gomock.Any()).
+				Times(1).
+				Return([]db.FraudRule{
+					amountRule("large transfer", risk.OUTCOME_REVIEW, account1.Currency),
+					amountRule("very large transfer", risk.OUTCOME_DENY, account1.Currency),
+				}, nil)
+
+				store.EXPECT().CreateHeldTransfer(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_TRANSFER_DENIED)
 			},
 		},
 		{
@@ -258,6 +695,8 @@ func TestMakeTransfer(t *testing.T){
 
 			store := testdb.NewMockStore(ctrl)
 			tc.buildStubs(store)
+			stubAuthUsers(store)
+			stubFraudRules(store)
 
 			server := NewTestServer(t, store)
 			recorder := httptest.NewRecorder()
@@ -274,4 +713,388 @@ func TestMakeTransfer(t *testing.T){
 		})
 	}
 
-}
\ No newline at end of file
+}
+
+func TestTransferToUserAPI(t *testing.T) {
+	sender, _ := randomUser(t)
+	recipient, _ := randomUser(t)
+	recipient.Email = util.RandomEmail()
+	fromAccount := randomAccount(sender.Username)
+	fromAccount.Currency = util.USD
+	toAccount := randomAccount(recipient.Username)
+	toAccount.Currency = util.USD
+
+	ownerAccount := db.GetOwnerAccountParams{Owner: recipient.Username, Currency: util.USD}
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "By Username",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_username": recipient.Username, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(recipient.Username)).
+				Times(1).
+				Return(recipient, nil)
+				store.EXPECT().
+				GetOwnerAccount(gomock.Any(), gomock.Eq(ownerAccount)).
+				Times(1).
+				Return(toAccount, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: fromAccount.ID,
+					ToAccountID: toAccount.ID,
+					Amount: 1000,
+					Metadata: json.RawMessage("{}"),
+					RequestedBy: sender.Username,
+					RecipientHidden: true,
+				}
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.TransferTxResult{
+					Transfer: db.Transfer{ID: 1, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: arg.Amount},
+					FromAccount: fromAccount,
+					ToAccount: toAccount,
+					FromEntry: db.Entry{AccountID: fromAccount.ID, Amount: -arg.Amount},
+					ToEntry: db.Entry{AccountID: toAccount.ID, Amount: arg.Amount},
+				}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				// the id of the account of the recipient is nowhere in the response
+				var res gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotContains(t, res, "to_account")
+				require.NotContains(t, res, "to_entry")
+				require.NotContains(t, res["transfer"], "to_account_id")
+			},
+		},
+		{
+			name: "By Email Held For Review",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_email": recipient.Email, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq(recipient.Email)).
+				Times(1).
+				Return(recipient, nil)
+				store.EXPECT().
+				GetOwnerAccount(gomock.Any(), gomock.Eq(ownerAccount)).
+				Times(1).
+				Return(toAccount, nil)
+				store.EXPECT().
+				ListEnabledFraudRules(gomock.Any()).
+				Times(1).
+				Return([]db.FraudRule{amountRule("large transfer", risk.OUTCOME_REVIEW, util.USD)}, nil)
+				store.EXPECT().
+				CreateHeldTransfer(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.HeldTransfer{ID: 1, FromAccountID: fromAccount.ID, ToAccountID: toAccount.ID, Amount: 1000, Currency: util.USD, Status: db.HELD_TRANSFER_PENDING}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+
+				var res gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &res)
+				require.NoError(t, err)
+				require.NotContains(t, res, "to_account_id")
+			},
+		},
+		{
+			name: "No Account In Currency",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_username": recipient.Username, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(recipient.Username)).
+				Times(1).
+				Return(recipient, nil)
+				store.EXPECT().
+				GetOwnerAccount(gomock.Any(), gomock.Eq(ownerAccount)).
+				Times(1).
+				Return(db.Account{}, sql.ErrNoRows)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_NO_RECIPIENT_ACCOUNT)
+			},
+		},
+		{
+			name: "Unknown Recipient",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_email": "nobody@example.com", "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(fromAccount.ID)).
+				Times(1).
+				Return(fromAccount, nil)
+				store.EXPECT().
+				GetUserByEmail(gomock.Any(), gomock.Eq("nobody@example.com")).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+				store.EXPECT().
+				GetOwnerAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+		{
+			name: "Username And Email",
+			body: gin.H{"from_account_id": fromAccount.ID, "to_username": recipient.Username, "to_email": recipient.Email, "amount": "10", "currency": util.USD},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+			stubFraudRules(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			request, err := http.NewRequest(http.MethodPost, "/transfer", bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, sender.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestListAccountTransfersAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Currency = util.USD
+	transfer := db.Transfer{
+		ID: util.RandomInt(1, 1000),
+		FromAccountID: account.ID,
+		ToAccountID: util.RandomInt(1, 1000),
+		Amount: 1250,
+		Description: "50% of the rent",
+		Reference: "INV_7",
+		Metadata: json.RawMessage(`{"order":"42"}`),
+		CreatedAt: time.Now(),
+	}
+
+	testCases := []struct {
+		name string
+		username string
+		query url.Values
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			username: user.Username,
+			query: url.Values{"page_id": {"2"}, "page_size": {"5"}},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					AccountID: account.ID,
+					Metadata: json.RawMessage("{}"),
+					RowLimit: 5,
+					RowOffset: 5,
+				})).
+				Times(1).
+				Return([]db.Transfer{transfer}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 1)
+				require.Equal(t, float64(transfer.ID), got[0]["id"])
+				require.Equal(t, "12.50", got[0]["amount"])
+				require.Equal(t, transfer.Description, got[0]["description"])
+				require.Equal(t, transfer.Reference, got[0]["reference"])
+				require.Equal(t, map[string]interface{}{"order": "42"}, got[0]["metadata"])
+			},
+		},
+		{
+			name: "Filters",
+			username: user.Username,
+			query: url.Values{
+				"page_id": {"1"},
+				"page_size": {"5"},
+				"q": {"50%"},
+				"reference": {"INV_7"},
+				"metadata[order]": {"42"},
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				// the wildcards of the query are matched literally
+				store.EXPECT().
+				SearchTransfers(gomock.Any(), gomock.Eq(db.SearchTransfersParams{
+					AccountID: account.ID,
+					Pattern: `%50\%%`,
+					Reference: "INV_7",
+					Metadata: json.RawMessage(`{"order":"42"}`),
+					RowLimit: 5,
+				})).
+				Times(1).
+				Return([]db.Transfer{transfer}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Hidden Recipient",
+			username: user.Username,
+			query: url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			buildStubs: func(store *testdb.MockStore) {
+				// the user sent by email twice to the same account of another user, and once to an account of its own
+				recipient, _ := randomUser(t)
+				toAccount := randomAccount(recipient.Username)
+				ownAccount := randomAccount(user.Username)
+				toAccount.ID, ownAccount.ID = account.ID+1, account.ID+2
+				sent := db.Transfer{ID: 1, FromAccountID: account.ID, ToAccountID: toAccount.ID, Amount: 100, RecipientHidden: true}
+				sentAgain := db.Transfer{ID: 2, FromAccountID: account.ID, ToAccountID: toAccount.ID, Amount: 200, RecipientHidden: true}
+				sentToSelf := db.Transfer{ID: 3, FromAccountID: account.ID, ToAccountID: ownAccount.ID, Amount: 300, RecipientHidden: true}
+				received := db.Transfer{ID: 4, FromAccountID: toAccount.ID, ToAccountID: account.ID, Amount: 400, RecipientHidden: true}
+
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				SearchTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return([]db.Transfer{sent, sentAgain, sentToSelf, received}, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(toAccount.ID)).
+				Times(1).
+				Return(toAccount, nil)
+				stubAccountRole(store, toAccount, user.Username, "")
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(ownAccount.ID)).
+				Times(1).
+				Return(ownAccount, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got []gin.H
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got, 4)
+				require.NotContains(t, got[0], "to_account_id")
+				require.NotContains(t, got[1], "to_account_id")
+				require.Contains(t, got[2], "to_account_id")
+				require.Equal(t, float64(account.ID), got[3]["to_account_id"])
+			},
+		},
+		{
+			name: "Metadata Key Too Long",
+			username: user.Username,
+			query: url.Values{
+				"page_id": {"1"},
+				"page_size": {"5"},
+				"metadata[" + util.RandomString(41) + "]": {"42"},
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Invalid Page Size",
+			username: user.Username,
+			query: url.Values{"page_id": {"1"}, "page_size": {"100"}},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "page_size", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Not A Member",
+			username: "other_user",
+			query: url.Values{"page_id": {"1"}, "page_size": {"5"}},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAccountRole(store, account, "other_user", "")
+				store.EXPECT().SearchTransfers(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/accounts/%d/transfers?%s", account.ID, tc.query.Encode()), nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,13 +1,10 @@
 package api
 
 import (
-	"database/sql"
-	"errors"
 	"net/http"
 	"time"
 
 	"github.com/gin-gonic/gin"
-	"github.com/lib/pq"
 	db "github.com/sssaang/simplebank/db/sqlc"
 	"github.com/sssaang/simplebank/db/util"
 	"github.com/sssaang/simplebank/token"
@@ -27,51 +24,64 @@ type userResponse struct {
 	Email             string    `json:"email"`
 	CreatedAt         time.Time `json:"created_at"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
+	IsEmailVerified bool `json:"is_email_verified"`
+}
+
+func newUserResponse(user db.User) userResponse {
+	return userResponse{
+		Username: user.Username,
+		FullName: user.FullName,
+		Email: user.Email,
+		CreatedAt: user.CreatedAt,
+		PasswordChangedAt: user.PasswordChangedAt,
+		IsEmailVerified: user.IsEmailVerified,
+	}
 }
 
 func (server *Server) createUser(ctx *gin.Context) {
 	var req createUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	hashedPassword, hashErr := util.HashPassword(req.Password)
 	if hashErr != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(hashErr))
+		abortWithError(ctx, hashErr)
+		return
 	}
 
-	arg := db.CreateUserParams{
-		Username: req.Username,
-		HashedPassword: hashedPassword,
-		FullName: req.FullName,
-		Email: req.Email,
+	secretCode, err := util.RandomSecret(VERIFY_EMAIL_SECRET_CODE_BYTES)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
 	}
 
-	user, err := server.store.CreateUser(ctx, arg)
-	if err != nil {
-		if pqErr, ok := err.(*pq.Error); ok {
-			switch pqErr.Code.Name() {
-			case "unique_violation":
-				ctx.JSON(http.StatusForbidden, errorResponse(err))
-				return 
-			}
-		}
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return 
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username: req.Username,
+			HashedPassword: hashedPassword,
+			FullName: req.FullName,
+			Email: req.Email,
+		},
+		SecretCodeHash: util.HashSecret(secretCode),
+		VerifyEmailExpiredAt: time.Now().Add(server.config.VerifyEmailDuration),
+		AfterCreate: func(user db.User, verifyEmail db.VerifyEmail) error {
+			return server.sendVerifyEmail(user, verifyEmail, secretCode)
+		},
 	}
 
-	res := userResponse{
-		Username: user.Username,
-		FullName: user.FullName,
-		Email: user.Email,
-		CreatedAt: user.CreatedAt,
-		PasswordChangedAt: user.PasswordChangedAt,
+	result, err := server.store.CreateUserTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return 
 	}
 
-	ctx.JSON(http.StatusCreated, res)
+	ctx.JSON(http.StatusCreated, newUserResponse(result.User))
 }
 
+var errNoAccessToUser = newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, "the user has no access to the information of the requested user")
+
 type getUserRequest struct {
 	Username string `uri:"username" binding:"required"`
 }
@@ -79,36 +89,19 @@ type getUserRequest struct {
 func (server *Server) getUser(ctx *gin.Context) {
 	var req getUserRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
 	if req.Username != authPayload.Username {
-		err := errors.New("the user has no access to the information of the requested user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-	}
-
-	user, err := server.store.GetUser(ctx, req.Username)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return 
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return 
-	}
-
-	res := userResponse{
-		Username: user.Username,
-		FullName: user.FullName,
-		Email: user.Email,
-		CreatedAt: user.CreatedAt,
-		PasswordChangedAt: user.PasswordChangedAt,
+		abortWithError(ctx, errNoAccessToUser)
+		return
 	}
 
-	ctx.JSON(http.StatusOK, res)
+	// the user has already been loaded by authMiddleware
+	user := ctx.MustGet(AUTHORIZATION_USER).(db.User)
+	ctx.JSON(http.StatusOK, newUserResponse(user))
 }
 
 type loginUserRequest struct {
@@ -124,47 +117,106 @@ type loginUserResponse struct {
 func (server *Server) loginUser(ctx *gin.Context) {
 	var req loginUserRequest
 	if err := ctx.ShouldBindJSON(&req); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
+		abortWithError(ctx, bindingError(err))
 		return
 	}
 
 	user, err := server.store.GetUser(ctx, req.Username)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			ctx.JSON(http.StatusNotFound, errorResponse(err))
-			return
-		}
-
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
 	err = util.CheckPassword(user.HashedPassword, req.Password)
 	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
+		abortWithError(ctx, errIncorrectPassword)
 		return
 	}
 
 	accessToken, err := server.tokenManager.CreateToken(
 		user.Username,
+		token.ALL_SCOPES,
 		server.config.AccessTokenDuration,
 	)
 
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
+		abortWithError(ctx, err)
 		return
 	}
 
 	res := loginUserResponse{
 		AccessToken: accessToken,
-		User: userResponse{
+		User: newUserResponse(user),
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+type updateUserURI struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+type updateUserRequest struct {
+	FullName *string `json:"full_name" binding:"omitempty,min=1"`
+	Email *string `json:"email" binding:"omitempty,email"`
+}
+
+// updateUser changes the full name and the email of a user.
+// A new email has to be verified again, until then the user is treated as unverified.
+func (server *Server) updateUser(ctx *gin.Context) {
+	var uri updateUserURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	var req updateUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	if uri.Username != authPayload.Username {
+		abortWithError(ctx, errNoAccessToUser)
+		return
+	}
+
+	user := ctx.MustGet(AUTHORIZATION_USER).(db.User)
+	arg := db.UpdateUserTxParams{
+		UpdateUserParams: db.UpdateUserParams{
 			Username: user.Username,
 			FullName: user.FullName,
 			Email: user.Email,
-			CreatedAt: user.CreatedAt,
-			PasswordChangedAt: user.PasswordChangedAt,
+			IsEmailVerified: user.IsEmailVerified,
 		},
 	}
 
-	ctx.JSON(http.StatusOK, res)
-}
\ No newline at end of file
+	if req.FullName != nil {
+		arg.FullName = *req.FullName
+	}
+
+	if req.Email != nil && *req.Email != user.Email {
+		secretCode, err := util.RandomSecret(VERIFY_EMAIL_SECRET_CODE_BYTES)
+		if err != nil {
+			abortWithError(ctx, err)
+			return
+		}
+
+		arg.Email = *req.Email
+		arg.IsEmailVerified = false
+		arg.SecretCodeHash = util.HashSecret(secretCode)
+		arg.VerifyEmailExpiredAt = time.Now().Add(server.config.VerifyEmailDuration)
+		arg.AfterUpdate = func(user db.User, verifyEmail db.VerifyEmail) error {
+			return server.sendVerifyEmail(user, verifyEmail, secretCode)
+		}
+	}
+
+	result, err := server.store.UpdateUserTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(result.User))
+}
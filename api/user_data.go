@@ -0,0 +1,139 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+)
+
+type userDataURI struct {
+	Username string `uri:"username" binding:"required"`
+}
+
+type userDataExportResponse struct {
+	User userResponse `json:"user"`
+	Accounts []accountResponse `json:"accounts"`
+	Entries []entryResponse `json:"entries"`
+	Transfers []transferResponse `json:"transfers"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
+// bindUserDataURI binds the username of the path and makes sure it is the authenticated user
+func bindUserDataURI(ctx *gin.Context) (userDataURI, bool) {
+	var uri userDataURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return uri, false
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	if uri.Username != authPayload.Username {
+		abortWithError(ctx, newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, "the user has no access to the data of the requested user"))
+		return uri, false
+	}
+
+	return uri, true
+}
+
+func newUserDataExportResponse(export db.UserDataExport) userDataExportResponse {
+	// every entry and transfer involves at least one account of the user, which tells its currency
+	currencies := make(map[int64]string, len(export.Accounts))
+	for _, account := range export.Accounts {
+		currencies[account.ID] = account.Currency
+	}
+
+	res := userDataExportResponse{
+		User: newUserResponse(export.User),
+		Accounts: newAccountsResponse(export.Accounts),
+		Entries: make([]entryResponse, len(export.Entries)),
+		Transfers: make([]transferResponse, len(export.Transfers)),
+		ExportedAt: time.Now(),
+	}
+
+	for i, entry := range export.Entries {
+		res.Entries[i] = newEntryResponse(entry, currencies[entry.AccountID])
+	}
+
+	for i, transfer := range export.Transfers {
+		currency, ok := currencies[transfer.FromAccountID]
+		if !ok {
+			currency = currencies[transfer.ToAccountID]
+		}
+		res.Transfers[i] = newTransferResponse(transfer, currency)
+		// a recipient the server resolved stays hidden from the sender, in the export as everywhere else
+		if _, own := currencies[transfer.ToAccountID]; transfer.RecipientHidden && !own {
+			res.Transfers[i].ToAccountID = 0
+		}
+	}
+
+	return res
+}
+
+// exportUserData returns everything stored about the user, as required for data portability requests
+func (server *Server) exportUserData(ctx *gin.Context) {
+	uri, ok := bindUserDataURI(ctx)
+	if !ok {
+		return
+	}
+
+	export, err := server.store.ExportUserDataTx(ctx, uri.Username)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	res := newUserDataExportResponse(export)
+
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", "user_data.json"))
+	ctx.JSON(http.StatusOK, res)
+}
+
+type eraseUserRequest struct {
+	Password string `json:"password" binding:"required,min=8"`
+}
+
+// eraseUser pseudonymizes the personal data of the user. The ledger rows of the user are kept.
+func (server *Server) eraseUser(ctx *gin.Context) {
+	uri, ok := bindUserDataURI(ctx)
+	if !ok {
+		return
+	}
+
+	var req eraseUserRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	user := ctx.MustGet(AUTHORIZATION_USER).(db.User)
+	err := util.CheckPassword(user.HashedPassword, req.Password)
+	if err != nil {
+		abortWithError(ctx, errIncorrectPassword)
+		return
+	}
+
+	pseudonym, err := uuid.NewRandom()
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.EraseUserTxParams{
+		Username: uri.Username,
+		Pseudonym: fmt.Sprintf("erased-%s", pseudonym),
+	}
+
+	erasure, err := server.store.EraseUserTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, erasure)
+}
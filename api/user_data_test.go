@@ -0,0 +1,259 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportUserDataAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	export := db.UserDataExport{
+		User: user,
+		Accounts: []db.Account{account},
+		Entries: []db.Entry{{ID: 1, AccountID: account.ID, Amount: account.Balance}},
+		Transfers: []db.Transfer{},
+	}
+
+	testCases := []struct {
+		name string
+		username string
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Export the data",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ExportUserDataTx(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(export, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				data, err := ioutil.ReadAll(recorder.Body)
+				require.NoError(t, err)
+
+				var got struct {
+					User userResponse `json:"user"`
+					Accounts json.RawMessage `json:"accounts"`
+					Entries json.RawMessage `json:"entries"`
+					Transfers []interface{} `json:"transfers"`
+				}
+				err = json.Unmarshal(data, &got)
+				require.NoError(t, err)
+				require.Equal(t, user.Username, got.User.Username)
+				require.Empty(t, got.Transfers)
+
+				expected := newUserDataExportResponse(export)
+				expectedAccounts, err := json.Marshal(expected.Accounts)
+				require.NoError(t, err)
+				require.JSONEq(t, string(expectedAccounts), string(got.Accounts))
+
+				expectedEntries, err := json.Marshal(expected.Entries)
+				require.NoError(t, err)
+				require.JSONEq(t, string(expectedEntries), string(got.Entries))
+				require.NotContains(t, string(data), user.HashedPassword)
+			},
+		},
+		{
+			name: "Hidden Recipient",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				recipient, _ := randomUser(t)
+				toAccount := randomAccount(recipient.Username)
+				sent := db.UserDataExport{
+					User: user,
+					Accounts: []db.Account{account},
+					Entries: []db.Entry{},
+					Transfers: []db.Transfer{
+						{ID: 1, FromAccountID: account.ID, ToAccountID: toAccount.ID, Amount: 10, RecipientHidden: true},
+						{ID: 2, FromAccountID: toAccount.ID, ToAccountID: account.ID, Amount: 10, RecipientHidden: true},
+					},
+				}
+				store.EXPECT().
+				ExportUserDataTx(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(sent, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					Transfers []gin.H `json:"transfers"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Transfers, 2)
+
+				// the sender does not learn the account of the user it paid, the recipient keeps its own
+				require.NotContains(t, got.Transfers[0], "to_account_id")
+				require.Equal(t, float64(account.ID), got.Transfers[1]["to_account_id"])
+			},
+		},
+		{
+			name: "Unauthorized User",
+			username: "other_user",
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ExportUserDataTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Internal Error",
+			username: user.Username,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ExportUserDataTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.UserDataExport{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			url := fmt.Sprintf("/user/%s/export", tc.username)
+			request, err := http.NewRequest(http.MethodGet, url, nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestEraseUserAPI(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name string
+		body gin.H
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Erase the user",
+			body: gin.H{
+				"password": password,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				EraseUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ interface{}, arg db.EraseUserTxParams) (db.ErasureRequest, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.NotContains(t, arg.Pseudonym, user.Username)
+					return db.ErasureRequest{ID: 1, Pseudonym: arg.Pseudonym}, nil
+				})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Wrong Password",
+			body: gin.H{
+				"password": "wrong password",
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				EraseUserTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+		{
+			name: "Accounts Not Empty",
+			body: gin.H{
+				"password": password,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				EraseUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ErasureRequest{}, db.ErrAccountsNotEmpty)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusConflict, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(user.Username)).
+			AnyTimes().
+			Return(user, nil)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/user/%s/erasure", user.Username)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -18,17 +18,18 @@ import (
 	db "github.com/sssaang/simplebank/db/sqlc"
 	testdb "github.com/sssaang/simplebank/db/test"
 	"github.com/sssaang/simplebank/db/util"
+	mockmail "github.com/sssaang/simplebank/mail/mock"
 	"github.com/sssaang/simplebank/token"
 	"github.com/stretchr/testify/require"
 )
 
-type eqCreateUserParamsMatcher struct {
-	arg db.CreateUserParams
+type eqCreateUserTxParamsMatcher struct {
+	arg db.CreateUserTxParams
 	password string
 }
 
-func (e eqCreateUserParamsMatcher) Matches(x interface{}) bool {
-	arg, ok := x.(db.CreateUserParams)
+func (e eqCreateUserTxParamsMatcher) Matches(x interface{}) bool {
+	arg, ok := x.(db.CreateUserTxParams)
 	if !ok {
 		return false
 	}
@@ -39,15 +40,19 @@ func (e eqCreateUserParamsMatcher) Matches(x interface{}) bool {
 	}
 
 	e.arg.HashedPassword = arg.HashedPassword
-	return reflect.DeepEqual(e.arg, arg)
+	if !reflect.DeepEqual(e.arg.CreateUserParams, arg.CreateUserParams) {
+		return false
+	}
+
+	return len(arg.SecretCodeHash) > 0 && arg.VerifyEmailExpiredAt.After(time.Now())
 }
 
-func (e eqCreateUserParamsMatcher) String() string {
+func (e eqCreateUserTxParamsMatcher) String() string {
 	return fmt.Sprintf("matches arg %v and password %v", e.arg, e.password)
 }
 
-func EqCreateUserParams(arg db.CreateUserParams, password string) gomock.Matcher {
-	return eqCreateUserParamsMatcher{arg, password}
+func EqCreateUserTxParams(arg db.CreateUserTxParams, password string) gomock.Matcher {
+	return eqCreateUserTxParamsMatcher{arg, password}
 }
 
 
@@ -57,7 +62,7 @@ func TestCreateUserAPI(t *testing.T) {
 	testCases := []struct {
 		name string
 		body gin.H
-		buildStubs func(store *testdb.MockStore)
+		buildStubs func(store *testdb.MockStore, mailer *mockmail.MockMailer)
 		checkResponse func(recorder *httptest.ResponseRecorder)
 	}{
 		{
@@ -68,17 +73,29 @@ func TestCreateUserAPI(t *testing.T) {
 				"full_name": user.FullName,
 				"email": user.Email,
 			},
-			buildStubs: func(store *testdb.MockStore) {
-				arg := db.CreateUserParams{
-					Username: user.Username,
-					FullName: user.FullName,
-					Email:    user.Email,
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				arg := db.CreateUserTxParams{
+					CreateUserParams: db.CreateUserParams{
+						Username: user.Username,
+						FullName: user.FullName,
+						Email:    user.Email,
+					},
 				}
 
 				store.EXPECT().
-				CreateUser(gomock.Any(), EqCreateUserParams(arg, password)).
+				CreateUserTx(gomock.Any(), EqCreateUserTxParams(arg, password)).
 				Times(1).
-				Return(user, nil)
+				DoAndReturn(func(_ interface{}, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+					// run the callback as the store would before committing
+					verifyEmail := db.VerifyEmail{ID: 1, Username: user.Username, Email: user.Email}
+					err := arg.AfterCreate(user, verifyEmail)
+					return db.CreateUserTxResult{User: user, VerifyEmail: verifyEmail}, err
+				})
+
+				mailer.EXPECT().
+				SendEmail(gomock.Eq(user.Email), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusCreated, recorder.Code)
@@ -93,9 +110,9 @@ func TestCreateUserAPI(t *testing.T) {
 				"full_name": user.FullName,
 				"email": user.Email,
 			},
-			buildStubs: func(store *testdb.MockStore) {
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
 				store.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any()).
+				CreateUserTx(gomock.Any(), gomock.Any()).
 				Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -110,9 +127,9 @@ func TestCreateUserAPI(t *testing.T) {
 				"full_name": user.FullName,
 				"email": "invalidemail",
 			},
-			buildStubs: func(store *testdb.MockStore) {
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
 				store.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any()).
+				CreateUserTx(gomock.Any(), gomock.Any()).
 				Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -127,9 +144,9 @@ func TestCreateUserAPI(t *testing.T) {
 				"full_name": user.FullName,
 				"email": user.Email,
 			},
-			buildStubs: func(store *testdb.MockStore) {
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
 				store.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any()).
+				CreateUserTx(gomock.Any(), gomock.Any()).
 				Times(0)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
@@ -144,14 +161,14 @@ func TestCreateUserAPI(t *testing.T) {
 				"full_name": user.FullName,
 				"email": user.Email,
 			},
-			buildStubs: func(store *testdb.MockStore) {
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
 				store.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any()).
+				CreateUserTx(gomock.Any(), gomock.Any()).
 				Times(1).
-				Return(db.User{}, &pq.Error{Code: "23505"})
+				Return(db.CreateUserTxResult{}, &pq.Error{Code: "23505"})
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusForbidden, recorder.Code)
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_ALREADY_EXISTS)
 			},
 		},
 		{
@@ -162,11 +179,11 @@ func TestCreateUserAPI(t *testing.T) {
 				"full_name": user.FullName,
 				"email": user.Email,
 			},
-			buildStubs: func(store *testdb.MockStore) {
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
 				store.EXPECT().
-				CreateUser(gomock.Any(), gomock.Any()).
+				CreateUserTx(gomock.Any(), gomock.Any()).
 				Times(1).
-				Return(db.User{}, sql.ErrConnDone)
+				Return(db.CreateUserTxResult{}, sql.ErrConnDone)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusInternalServerError, recorder.Code)
@@ -180,9 +197,11 @@ func TestCreateUserAPI(t *testing.T) {
 			defer ctrl.Finish()
 
 			store := testdb.NewMockStore(ctrl)
-			tc.buildStubs(store)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
 
 			server := NewTestServer(t, store)
+			server.mailer = mailer
 			recorder := httptest.NewRecorder()
 
 			data, err := json.Marshal(tc.body)
@@ -252,7 +271,7 @@ func TestGetUserAPI(t *testing.T) {
 				Times(1)
 			},
 			checkResponse: func(recorder *httptest.ResponseRecorder) {
-				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
 			},
 		},
 		{
@@ -321,4 +340,182 @@ func requireBodyMatchUser(t *testing.T, body *bytes.Buffer, user db.User) {
 	require.Equal(t, user.FullName, gotUser.FullName)
 	require.Equal(t, user.Email, gotUser.Email)
 	require.Empty(t, gotUser.HashedPassword)
-}
\ No newline at end of file
+}
+
+func TestUpdateUserAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.IsEmailVerified = true
+	newFullName := util.RandomOwner()
+	newEmail := util.RandomEmail()
+
+	testCases := []struct {
+		name string
+		username string
+		body gin.H
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore, mailer *mockmail.MockMailer)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Update the full name",
+			username: user.Username,
+			body: gin.H{
+				"full_name": newFullName,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				arg := db.UpdateUserTxParams{
+					UpdateUserParams: db.UpdateUserParams{
+						Username: user.Username,
+						FullName: newFullName,
+						Email: user.Email,
+						IsEmailVerified: true,
+					},
+				}
+
+				updatedUser := user
+				updatedUser.FullName = newFullName
+				store.EXPECT().
+				UpdateUserTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.UpdateUserTxResult{User: updatedUser}, nil)
+
+				mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Update the email",
+			username: user.Username,
+			body: gin.H{
+				"email": newEmail,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+
+				updatedUser := user
+				updatedUser.Email = newEmail
+				updatedUser.IsEmailVerified = false
+				store.EXPECT().
+				UpdateUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ interface{}, arg db.UpdateUserTxParams) (db.UpdateUserTxResult, error) {
+					require.Equal(t, newEmail, arg.Email)
+					require.False(t, arg.IsEmailVerified)
+					require.NotEmpty(t, arg.SecretCodeHash)
+
+					verifyEmail := db.VerifyEmail{ID: 1, Username: user.Username, Email: newEmail}
+					err := arg.AfterUpdate(updatedUser, verifyEmail)
+					return db.UpdateUserTxResult{User: updatedUser, VerifyEmail: verifyEmail}, err
+				})
+
+				mailer.EXPECT().
+				SendEmail(gomock.Eq(newEmail), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Email",
+			username: user.Username,
+			body: gin.H{
+				"email": "invalidemail",
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				UpdateUserTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Duplicate Email",
+			username: user.Username,
+			body: gin.H{
+				"email": newEmail,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				UpdateUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.UpdateUserTxResult{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_ALREADY_EXISTS)
+			},
+		},
+		{
+			name: "Unauthorized User",
+			username: user.Username,
+			body: gin.H{
+				"full_name": newFullName,
+			},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "other_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				UpdateUserTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/user/%s", tc.username)
+			request, err := http.NewRequest(http.MethodPatch, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
@@ -1,8 +1,13 @@
 package api
 
 import (
+	"reflect"
+	"regexp"
+	"strings"
+
 	"github.com/go-playground/validator/v10"
 	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
 )
 
 var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
@@ -10,4 +15,39 @@ var validCurrency validator.Func = func(fieldLevel validator.FieldLevel) bool {
 		return util.IsSupportedCurrency(currency)
 	}
 	return false
-}
\ No newline at end of file
+}
+
+var validScope validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if scope, ok := fieldLevel.Field().Interface().(string); ok {
+		return token.IsSupportedScope(scope)
+	}
+	return false
+}
+
+var validOauthScope validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if scope, ok := fieldLevel.Field().Interface().(string); ok {
+		return token.IsReadOnlyScope(scope)
+	}
+	return false
+}
+
+// moneyPattern is the syntax of the amounts of requests, the decimals allowed depend on the currency
+var moneyPattern = regexp.MustCompile(`^[0-9]+(\.[0-9]+)?$`)
+
+var validMoney validator.Func = func(fieldLevel validator.FieldLevel) bool {
+	if amount, ok := fieldLevel.Field().Interface().(string); ok {
+		return moneyPattern.MatchString(amount)
+	}
+	return false
+}
+
+// requestFieldName names the fields of validation errors as the client sent them, rather than as the Go fields
+func requestFieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "form", "uri"} {
+		name := strings.Split(field.Tag.Get(key), ",")[0]
+		if len(name) > 0 && name != "-" {
+			return name
+		}
+	}
+	return field.Name
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+const VERIFY_EMAIL_SECRET_CODE_BYTES = 16
+
+var errEmailNotVerified = newApiError(http.StatusForbidden, ERROR_CODE_EMAIL_NOT_VERIFIED, "the email of the user has not been verified yet")
+
+func (server *Server) sendVerifyEmail(user db.User, verifyEmail db.VerifyEmail, secretCode string) error {
+	verifyURL := fmt.Sprintf(
+		"http://%s/verify_email?email_id=%d&secret_code=%s",
+		server.config.ApiAddress,
+		verifyEmail.ID,
+		secretCode,
+	)
+	content := fmt.Sprintf(
+		"Hello %s,\n\nplease confirm your email address by visiting %s\nThe link expires in %s.",
+		user.FullName,
+		verifyURL,
+		server.config.VerifyEmailDuration,
+	)
+
+	return server.mailer.SendEmail(verifyEmail.Email, "Verify your email address", content)
+}
+
+type verifyEmailRequest struct {
+	EmailID int64 `form:"email_id" binding:"required,min=1"`
+	SecretCode string `form:"secret_code" binding:"required"`
+}
+
+func (server *Server) verifyEmail(ctx *gin.Context) {
+	var req verifyEmailRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	arg := db.VerifyEmailTxParams{
+		EmailID: req.EmailID,
+		SecretCodeHash: util.HashSecret(req.SecretCode),
+	}
+
+	result, err := server.store.VerifyEmailTx(ctx, arg)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			abortWithError(ctx, newApiError(http.StatusBadRequest, ERROR_CODE_INVALID_REQUEST, "verification code is invalid, already used or expired"))
+			return
+		}
+
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newUserResponse(result.User))
+}
+
+var errEmailAlreadyVerified = newApiError(http.StatusConflict, ERROR_CODE_CONFLICT, "the email of the user is already verified")
+
+// resendVerifyEmail mails a new verification link to the authenticated user, the links sent before stop working
+func (server *Server) resendVerifyEmail(ctx *gin.Context) {
+	user := ctx.MustGet(AUTHORIZATION_USER).(db.User)
+	if user.IsEmailVerified {
+		abortWithError(ctx, errEmailAlreadyVerified)
+		return
+	}
+
+	secretCode, err := util.RandomSecret(VERIFY_EMAIL_SECRET_CODE_BYTES)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	arg := db.ResendVerifyEmailTxParams{
+		User: user,
+		SecretCodeHash: util.HashSecret(secretCode),
+		ExpiredAt: time.Now().Add(server.config.VerifyEmailDuration),
+		AfterCreate: func(verifyEmail db.VerifyEmail) error {
+			return server.sendVerifyEmail(user, verifyEmail, secretCode)
+		},
+	}
+
+	_, err = server.store.ResendVerifyEmailTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "a new verification link has been sent to the email"})
+}
+
+// requireVerifiedEmail aborts the request unless the authenticated user has verified their email
+func requireVerifiedEmail(ctx *gin.Context) bool {
+	user := ctx.MustGet(AUTHORIZATION_USER).(db.User)
+	if !user.IsEmailVerified {
+		abortWithError(ctx, errEmailNotVerified)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,207 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	mockmail "github.com/sssaang/simplebank/mail/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyEmailAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.IsEmailVerified = true
+	emailID := util.RandomInt(1, 1000)
+	secretCode, err := util.RandomSecret(VERIFY_EMAIL_SECRET_CODE_BYTES)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		query string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Verify the email",
+			query: fmt.Sprintf("email_id=%d&secret_code=%s", emailID, secretCode),
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.VerifyEmailTxParams{
+					EmailID: emailID,
+					SecretCodeHash: util.HashSecret(secretCode),
+				}
+
+				store.EXPECT().
+				VerifyEmailTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.VerifyEmailTxResult{User: user}, nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+				requireBodyMatchUser(t, recorder.Body, user)
+			},
+		},
+		{
+			name: "Invalid Secret Code",
+			query: fmt.Sprintf("email_id=%d&secret_code=%s", emailID, "invalid"),
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				VerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.VerifyEmailTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Missing Email ID",
+			query: fmt.Sprintf("secret_code=%s", secretCode),
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				VerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusBadRequest, recorder.Code)
+			},
+		},
+		{
+			name: "Internal Error",
+			query: fmt.Sprintf("email_id=%d&secret_code=%s", emailID, secretCode),
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				VerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.VerifyEmailTxResult{}, sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, "/verify_email?"+tc.query, nil)
+			require.NoError(t, err)
+
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
+
+func TestResendVerifyEmailAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	user.IsEmailVerified = false
+	verifiedUser, _ := randomUser(t)
+	verifiedUser.IsEmailVerified = true
+
+	testCases := []struct {
+		name string
+		user db.User
+		buildStubs func(store *testdb.MockStore, mailer *mockmail.MockMailer)
+		checkResponse func(recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "Resend",
+			user: user,
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				verifyEmail := db.VerifyEmail{ID: util.RandomInt(1, 1000), Username: user.Username, Email: user.Email}
+
+				store.EXPECT().
+				ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.ResendVerifyEmailTxParams) (db.VerifyEmail, error) {
+					require.Equal(t, user.Username, arg.User.Username)
+					require.True(t, arg.ExpiredAt.After(time.Now()))
+					return verifyEmail, arg.AfterCreate(verifyEmail)
+				})
+
+				mailer.EXPECT().
+				SendEmail(gomock.Eq(user.Email), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusAccepted, recorder.Code)
+			},
+		},
+		{
+			name: "Already Verified",
+			user: verifiedUser,
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusConflict, ERROR_CODE_CONFLICT)
+			},
+		},
+		{
+			name: "Mailer Error",
+			user: user,
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				ResendVerifyEmailTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.ResendVerifyEmailTxParams) (db.VerifyEmail, error) {
+					return db.VerifyEmail{}, arg.AfterCreate(db.VerifyEmail{Email: user.Email})
+				})
+
+				mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(sql.ErrConnDone)
+			},
+			checkResponse: func(recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusInternalServerError, recorder.Code)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
+			store.EXPECT().
+			GetUser(gomock.Any(), gomock.Eq(tc.user.Username)).
+			AnyTimes().
+			Return(tc.user, nil)
+
+			server := NewTestServer(t, store)
+			server.mailer = mailer
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPost, "/verify_email/resend", nil)
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, tc.user.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(recorder)
+		})
+	}
+}
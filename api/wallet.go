@@ -0,0 +1,177 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+)
+
+// a wallet groups the checking accounts of a user, one per currency, under a single id
+
+type walletBalanceResponse struct {
+	AccountID int64 `json:"account_id"`
+	Currency string `json:"currency"`
+	Balance util.Money `json:"balance"`
+}
+
+type walletResponse struct {
+	ID int64 `json:"id"`
+	Owner string `json:"owner"`
+	Balances []walletBalanceResponse `json:"balances"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func newWalletResponse(wallet db.Wallet, accounts []db.Account) walletResponse {
+	balances := make([]walletBalanceResponse, len(accounts))
+	for i, account := range accounts {
+		balances[i] = walletBalanceResponse{
+			AccountID: account.ID,
+			Currency: account.Currency,
+			Balance: util.NewMoney(account.Balance, account.Currency),
+		}
+	}
+
+	return walletResponse{
+		ID: wallet.ID,
+		Owner: wallet.Owner,
+		Balances: balances,
+		CreatedAt: wallet.CreatedAt,
+	}
+}
+
+type walletURI struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// authorizedWallet gets the wallet of the uri, which must belong to the authenticated user
+func (server *Server) authorizedWallet(ctx *gin.Context) (db.Wallet, bool) {
+	var uri walletURI
+	if err := ctx.ShouldBindUri(&uri); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return db.Wallet{}, false
+	}
+
+	wallet, err := server.store.GetWallet(ctx, uri.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return db.Wallet{}, false
+	}
+
+	authPayload := ctx.MustGet(AUTHORIZATION_PAYLOAD).(*token.Payload)
+	if wallet.Owner != authPayload.Username {
+		abortWithError(ctx, newApiError(http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED, "the user has no access to the wallet"))
+		return db.Wallet{}, false
+	}
+
+	return wallet, true
+}
+
+func (server *Server) getWallet(ctx *gin.Context) {
+	wallet, ok := server.authorizedWallet(ctx)
+	if !ok {
+		return
+	}
+
+	accounts, err := server.store.ListWalletAccounts(ctx, wallet.ID)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newWalletResponse(wallet, accounts))
+}
+
+type convertCurrencyRequest struct {
+	FromCurrency string `json:"from_currency" binding:"required,currency"`
+	ToCurrency string `json:"to_currency" binding:"required,currency"`
+	Amount string `json:"amount" binding:"required,money"`
+}
+
+type conversionResponse struct {
+	ID int64 `json:"id"`
+	WalletID int64 `json:"wallet_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	FromAmount util.Money `json:"from_amount"`
+	ToAmount util.Money `json:"to_amount"`
+	Rate string `json:"rate"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type convertCurrencyResponse struct {
+	Conversion conversionResponse `json:"conversion"`
+	FromAccount accountResponse `json:"from_account"`
+	ToAccount accountResponse `json:"to_account"`
+	FromEntry entryResponse `json:"from_entry"`
+	ToEntry entryResponse `json:"to_entry"`
+}
+
+func newConvertCurrencyResponse(result db.ConvertCurrencyTxResult) convertCurrencyResponse {
+	fromCurrency := result.FromAccount.Currency
+	toCurrency := result.ToAccount.Currency
+
+	return convertCurrencyResponse{
+		Conversion: conversionResponse{
+			ID: result.Conversion.ID,
+			WalletID: result.Conversion.WalletID,
+			FromAccountID: result.Conversion.FromAccountID,
+			ToAccountID: result.Conversion.ToAccountID,
+			FromAmount: util.NewMoney(result.Conversion.FromAmount, fromCurrency),
+			ToAmount: util.NewMoney(result.Conversion.ToAmount, toCurrency),
+			Rate: result.Conversion.Rate,
+			CreatedAt: result.Conversion.CreatedAt,
+		},
+		FromAccount: newAccountResponse(result.FromAccount),
+		ToAccount: newAccountResponse(result.ToAccount),
+		FromEntry: newEntryResponse(result.FromEntry, fromCurrency),
+		ToEntry: newEntryResponse(result.ToEntry, toCurrency),
+	}
+}
+
+// convertCurrency moves money between two balances of a wallet at the current exchange rate
+func (server *Server) convertCurrency(ctx *gin.Context) {
+	var req convertCurrencyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		abortWithError(ctx, bindingError(err))
+		return
+	}
+
+	if req.FromCurrency == req.ToCurrency {
+		abortWithError(ctx, fieldError("to_currency", "nefield", "must differ from from_currency"))
+		return
+	}
+
+	if !requireVerifiedEmail(ctx) {
+		return
+	}
+
+	amount, err := parseAmount("amount", req.Amount, req.FromCurrency)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	wallet, ok := server.authorizedWallet(ctx)
+	if !ok {
+		return
+	}
+
+	arg := db.ConvertCurrencyTxParams{
+		WalletID: wallet.ID,
+		FromCurrency: req.FromCurrency,
+		ToCurrency: req.ToCurrency,
+		Amount: amount.Amount(),
+	}
+
+	result, err := server.store.ConvertCurrencyTx(ctx, arg)
+	if err != nil {
+		abortWithError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, newConvertCurrencyResponse(result))
+}
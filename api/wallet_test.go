@@ -0,0 +1,495 @@
+package api
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+)
+
+func randomWallet(username string) db.Wallet {
+	return db.Wallet{
+		ID: util.RandomInt(1, 10000),
+		Owner: username,
+		CreatedAt: time.Now(),
+	}
+}
+
+func randomWalletAccount(wallet db.Wallet, currency string) db.Account {
+	account := randomAccount(wallet.Owner)
+	account.Currency = currency
+	account.WalletID = wallet.ID
+	return account
+}
+
+func TestGetWalletAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	wallet := randomWallet(user.Username)
+	accounts := []db.Account{
+		randomWalletAccount(wallet, util.EUR),
+		randomWalletAccount(wallet, util.USD),
+	}
+
+	testCases := []struct {
+		name string
+		walletID int64
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			walletID: wallet.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(wallet, nil)
+				store.EXPECT().
+				ListWalletAccounts(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(accounts, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				expected, err := json.Marshal(newWalletResponse(wallet, accounts))
+				require.NoError(t, err)
+				require.JSONEq(t, string(expected), recorder.Body.String())
+
+				var got struct {
+					Balances []struct {
+						Currency string `json:"currency"`
+						Balance string `json:"balance"`
+					} `json:"balances"`
+				}
+				err = json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Len(t, got.Balances, 2)
+				require.Equal(t, util.NewMoney(accounts[1].Balance, util.USD).String(), got.Balances[1].Balance)
+			},
+		},
+		{
+			name: "Not Found",
+			walletID: wallet.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(db.Wallet{}, sql.ErrNoRows)
+				store.EXPECT().
+				ListWalletAccounts(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusNotFound, ERROR_CODE_NOT_FOUND)
+			},
+		},
+		{
+			name: "Unauthorized User",
+			walletID: wallet.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(wallet, nil)
+				store.EXPECT().
+				ListWalletAccounts(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Invalid ID",
+			walletID: 0,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "No Authorization",
+			walletID: wallet.ID,
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodGet, fmt.Sprintf("/wallets/%d", tc.walletID), nil)
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestConvertCurrencyAPI(t *testing.T) {
+	user, _ := randomUser(t)
+	wallet := randomWallet(user.Username)
+	usd := randomWalletAccount(wallet, util.USD)
+	eur := randomWalletAccount(wallet, util.EUR)
+
+	result := db.ConvertCurrencyTxResult{
+		Conversion: db.Conversion{
+			ID: util.RandomInt(1, 1000),
+			WalletID: wallet.ID,
+			FromAccountID: usd.ID,
+			ToAccountID: eur.ID,
+			FromAmount: 1000,
+			ToAmount: 921,
+			Rate: "0.9215",
+			CreatedAt: time.Now(),
+		},
+		FromAccount: usd,
+		ToAccount: eur,
+		FromEntry: db.Entry{AccountID: usd.ID, Amount: -1000},
+		ToEntry: db.Entry{AccountID: eur.ID, Amount: 921},
+	}
+
+	testCases := []struct {
+		name string
+		body gin.H
+		setupAuth func(t *testing.T, request *http.Request, tokenManager token.TokenManager)
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			body: gin.H{"from_currency": util.USD, "to_currency": util.EUR, "amount": "10"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(wallet, nil)
+
+				arg := db.ConvertCurrencyTxParams{
+					WalletID: wallet.ID,
+					FromCurrency: util.USD,
+					ToCurrency: util.EUR,
+					Amount: 1000,
+				}
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(result, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+
+				var got struct {
+					Conversion struct {
+						FromAmount string `json:"from_amount"`
+						ToAmount string `json:"to_amount"`
+						Rate string `json:"rate"`
+					} `json:"conversion"`
+				}
+				err := json.Unmarshal(recorder.Body.Bytes(), &got)
+				require.NoError(t, err)
+				require.Equal(t, "10.00", got.Conversion.FromAmount)
+				require.Equal(t, "9.21", got.Conversion.ToAmount)
+				require.Equal(t, "0.9215", got.Conversion.Rate)
+			},
+		},
+		{
+			name: "Same Currency",
+			body: gin.H{"from_currency": util.USD, "to_currency": util.USD, "amount": "10"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "to_currency", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Too Many Decimals",
+			body: gin.H{"from_currency": util.KRW, "to_currency": util.USD, "amount": "10.5"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "amount", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Unauthorized User",
+			body: gin.H{"from_currency": util.USD, "to_currency": util.EUR, "amount": "10"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, "unauthorized_user", time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(wallet, nil)
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Read Only Scope",
+			body: gin.H{"from_currency": util.USD, "to_currency": util.EUR, "amount": "10"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addScopedAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusForbidden, ERROR_CODE_PERMISSION_DENIED)
+			},
+		},
+		{
+			name: "Insufficient Funds",
+			body: gin.H{"from_currency": util.USD, "to_currency": util.EUR, "amount": "10"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(wallet, nil)
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ConvertCurrencyTxResult{}, db.ErrInsufficientFunds)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_INSUFFICIENT_FUNDS)
+			},
+		},
+		{
+			name: "No Exchange Rate",
+			body: gin.H{"from_currency": util.USD, "to_currency": util.EUR, "amount": "10"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(wallet, nil)
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ConvertCurrencyTxResult{}, db.ErrNoExchangeRate)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_NO_EXCHANGE_RATE)
+			},
+		},
+		{
+			name: "Amount Too Small",
+			body: gin.H{"from_currency": util.USD, "to_currency": util.EUR, "amount": "0.01"},
+			setupAuth: func(t *testing.T, request *http.Request, tokenManager token.TokenManager) {
+				addAuthorization(t, request, tokenManager, AUTHORIZATION_TYPE_BEARER, user.Username, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetWallet(gomock.Any(), gomock.Eq(wallet.ID)).
+				Times(1).
+				Return(wallet, nil)
+				store.EXPECT().
+				ConvertCurrencyTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ConvertCurrencyTxResult{}, db.ErrConversionTooSmall)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusUnprocessableEntity, ERROR_CODE_AMOUNT_TOO_SMALL)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			data, err := json.Marshal(tc.body)
+			require.NoError(t, err)
+
+			url := fmt.Sprintf("/wallets/%d/conversions", wallet.ID)
+			request, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(data))
+			require.NoError(t, err)
+
+			tc.setupAuth(t, request, server.tokenManager)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
+
+func TestSetExchangeRateAPI(t *testing.T) {
+	admin := randomAdmin(t)
+
+	testCases := []struct {
+		name string
+		path string
+		body string
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, recorder *httptest.ResponseRecorder)
+	}{
+		{
+			name: "OK",
+			path: "/admin/exchange_rates/USD/EUR",
+			body: `{"rate": "0.9215"}`,
+			buildStubs: func(store *testdb.MockStore) {
+				arg := db.UpsertExchangeRateParams{FromCurrency: util.USD, ToCurrency: util.EUR, Rate: "0.9215"}
+				store.EXPECT().
+				UpsertExchangeRate(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.ExchangeRate{FromCurrency: util.USD, ToCurrency: util.EUR, Rate: "0.9215", UpdatedAt: time.Now()}, nil)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				require.Equal(t, http.StatusOK, recorder.Code)
+			},
+		},
+		{
+			name: "Invalid Rate",
+			path: "/admin/exchange_rates/USD/EUR",
+			body: `{"rate": "-0.5"}`,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertExchangeRate(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				apiErr := requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+				require.Equal(t, "rate", apiErr.Details[0].Field)
+			},
+		},
+		{
+			name: "Same Currency",
+			path: "/admin/exchange_rates/USD/USD",
+			body: `{"rate": "1"}`,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertExchangeRate(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+		{
+			name: "Unsupported Currency",
+			path: "/admin/exchange_rates/USD/XYZ",
+			body: `{"rate": "1"}`,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				UpsertExchangeRate(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
+				requireApiError(t, recorder, http.StatusBadRequest, ERROR_CODE_VALIDATION_FAILED)
+			},
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAdminUser(store, admin)
+
+			server := NewTestServer(t, store)
+			recorder := httptest.NewRecorder()
+
+			request, err := http.NewRequest(http.MethodPut, tc.path, bytes.NewBufferString(tc.body))
+			require.NoError(t, err)
+
+			addAuthorization(t, request, server.tokenManager, AUTHORIZATION_TYPE_BEARER, admin.Username, time.Minute)
+			server.router.ServeHTTP(recorder, request)
+			tc.checkResponse(t, recorder)
+		})
+	}
+}
@@ -0,0 +1,10 @@
+DROP TABLE IF EXISTS "erasure_requests";
+
+ALTER TABLE IF EXISTS "verify_emails" DROP CONSTRAINT IF EXISTS "verify_emails_username_fkey";
+ALTER TABLE IF EXISTS "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE IF EXISTS "password_resets" DROP CONSTRAINT IF EXISTS "password_resets_username_fkey";
+ALTER TABLE IF EXISTS "password_resets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_owner_fkey";
+ALTER TABLE IF EXISTS "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
@@ -0,0 +1,17 @@
+-- usernames are emails, erasing a user renames it to a pseudonym so that ledger rows keep their owner
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "accounts_owner_fkey";
+ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "password_resets" DROP CONSTRAINT IF EXISTS "password_resets_username_fkey";
+ALTER TABLE "password_resets" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "verify_emails" DROP CONSTRAINT IF EXISTS "verify_emails_username_fkey";
+ALTER TABLE "verify_emails" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+CREATE TABLE "erasure_requests" (
+  "id" bigserial PRIMARY KEY,
+  "pseudonym" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+COMMENT ON COLUMN "erasure_requests"."pseudonym" IS 'username given to the erased user, the original username is not kept';
@@ -0,0 +1,53 @@
+CREATE TABLE "oauth_clients" (
+  "client_id" varchar PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "name" varchar NOT NULL,
+  "secret_hash" varchar NOT NULL,
+  "redirect_uris" varchar[] NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "oauth_authorization_codes" (
+  "id" bigserial PRIMARY KEY,
+  "code_hash" varchar UNIQUE NOT NULL,
+  "client_id" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "redirect_uri" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "code_challenge" varchar NOT NULL,
+  "is_used" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL
+);
+
+CREATE TABLE "oauth_refresh_tokens" (
+  "id" bigserial PRIMARY KEY,
+  "token_hash" varchar UNIQUE NOT NULL,
+  "client_id" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "scopes" varchar[] NOT NULL,
+  "is_revoked" boolean NOT NULL DEFAULT false,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "expired_at" timestamptz NOT NULL
+);
+
+ALTER TABLE "oauth_clients" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("client_id");
+
+ALTER TABLE "oauth_authorization_codes" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "oauth_refresh_tokens" ADD FOREIGN KEY ("client_id") REFERENCES "oauth_clients" ("client_id");
+
+ALTER TABLE "oauth_refresh_tokens" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+CREATE INDEX ON "oauth_clients" ("owner");
+
+CREATE INDEX ON "oauth_authorization_codes" ("username");
+
+CREATE INDEX ON "oauth_refresh_tokens" ("username");
+
+COMMENT ON COLUMN "oauth_clients"."secret_hash" IS 'sha256 of the client secret shown once to the owner';
+
+COMMENT ON COLUMN "oauth_authorization_codes"."code_challenge" IS 'PKCE S256 challenge, base64url of the sha256 of the code verifier';
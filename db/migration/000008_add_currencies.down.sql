@@ -0,0 +1,5 @@
+ALTER TABLE IF EXISTS "users" DROP COLUMN IF EXISTS "role";
+
+ALTER TABLE IF EXISTS "accounts" DROP CONSTRAINT IF EXISTS "accounts_currency_fkey";
+
+DROP TABLE IF EXISTS "currencies";
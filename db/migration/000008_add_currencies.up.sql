@@ -0,0 +1,23 @@
+CREATE TABLE "currencies" (
+  "code" varchar(3) PRIMARY KEY,
+  "minor_units" int NOT NULL,
+  "is_enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("code" ~ '^[A-Z]{3}$'),
+  CHECK ("minor_units" BETWEEN 0 AND 4)
+);
+
+COMMENT ON COLUMN "currencies"."code" IS 'ISO 4217 alphabetic code';
+
+COMMENT ON COLUMN "currencies"."minor_units" IS 'number of decimals, amounts are stored in minor units so it never changes';
+
+INSERT INTO "currencies" ("code", "minor_units") VALUES
+  ('USD', 2),
+  ('EUR', 2),
+  ('KRW', 0);
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+ALTER TABLE "users" ADD COLUMN "role" varchar NOT NULL DEFAULT 'depositor';
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or admin, admins are promoted directly in the database';
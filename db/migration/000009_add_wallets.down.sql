@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "conversions";
+
+DROP TABLE IF EXISTS "exchange_rates";
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "wallet_id";
+
+DROP TABLE IF EXISTS "wallets";
@@ -0,0 +1,61 @@
+CREATE TABLE "wallets" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar UNIQUE NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "wallets" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+-- the accounts of a user are the per-currency balances of its wallet, owner_currency_key keeps one per currency
+INSERT INTO "wallets" ("owner") SELECT DISTINCT "owner" FROM "accounts";
+
+ALTER TABLE "accounts" ADD COLUMN "wallet_id" bigint;
+
+UPDATE "accounts" SET "wallet_id" = "wallets"."id" FROM "wallets" WHERE "wallets"."owner" = "accounts"."owner";
+
+ALTER TABLE "accounts" ALTER COLUMN "wallet_id" SET NOT NULL;
+
+ALTER TABLE "accounts" ADD FOREIGN KEY ("wallet_id") REFERENCES "wallets" ("id");
+
+CREATE INDEX ON "accounts" ("wallet_id");
+
+CREATE TABLE "exchange_rates" (
+  "from_currency" varchar(3) NOT NULL,
+  "to_currency" varchar(3) NOT NULL,
+  "rate" numeric NOT NULL,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("from_currency", "to_currency"),
+  CHECK ("from_currency" <> "to_currency"),
+  CHECK ("rate" > 0)
+);
+
+ALTER TABLE "exchange_rates" ADD FOREIGN KEY ("from_currency") REFERENCES "currencies" ("code");
+
+ALTER TABLE "exchange_rates" ADD FOREIGN KEY ("to_currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS 'price of one major unit of from_currency in major units of to_currency';
+
+CREATE TABLE "conversions" (
+  "id" bigserial PRIMARY KEY,
+  "wallet_id" bigint NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "from_amount" bigint NOT NULL,
+  "to_amount" bigint NOT NULL,
+  "rate" numeric NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "conversions" ADD FOREIGN KEY ("wallet_id") REFERENCES "wallets" ("id");
+
+ALTER TABLE "conversions" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "conversions" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+CREATE INDEX ON "conversions" ("wallet_id");
+
+COMMENT ON COLUMN "conversions"."from_amount" IS 'must be positive, in minor units of the from account';
+
+COMMENT ON COLUMN "conversions"."to_amount" IS 'must be positive, in minor units of the to account';
+
+COMMENT ON COLUMN "conversions"."rate" IS 'exchange rate applied, kept since exchange_rates is updated in place';
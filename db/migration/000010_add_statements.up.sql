@@ -0,0 +1,47 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD COLUMN "conversion_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("conversion_id") REFERENCES "conversions" ("id");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer the entry was posted for, which tells the counterparty';
+
+COMMENT ON COLUMN "entries"."conversion_id" IS 'conversion within a wallet the entry was posted for';
+
+-- entries and the transfer or conversion they were posted for were created in the same transaction,
+-- so they share the now() of its start
+UPDATE "entries" SET "transfer_id" = "transfers"."id"
+FROM "transfers"
+WHERE "entries"."created_at" = "transfers"."created_at"
+  AND (("entries"."account_id" = "transfers"."from_account_id" AND "entries"."amount" = -"transfers"."amount")
+    OR ("entries"."account_id" = "transfers"."to_account_id" AND "entries"."amount" = "transfers"."amount"));
+
+UPDATE "entries" SET "conversion_id" = "conversions"."id"
+FROM "conversions"
+WHERE "entries"."created_at" = "conversions"."created_at"
+  AND (("entries"."account_id" = "conversions"."from_account_id" AND "entries"."amount" = -"conversions"."from_amount")
+    OR ("entries"."account_id" = "conversions"."to_account_id" AND "entries"."amount" = "conversions"."to_amount"));
+
+CREATE INDEX ON "entries" ("account_id", "created_at");
+
+CREATE TABLE "statements" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period_start" timestamptz NOT NULL,
+  "period_end" timestamptz NOT NULL,
+  "opening_balance" bigint NOT NULL,
+  "closing_balance" bigint NOT NULL,
+  "lines" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("period_start" < "period_end")
+);
+
+ALTER TABLE "statements" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "statements" ADD CONSTRAINT "account_period_key" UNIQUE ("account_id", "period_start", "period_end");
+
+COMMENT ON COLUMN "statements"."period_end" IS 'exclusive';
+
+COMMENT ON COLUMN "statements"."lines" IS 'entries of the period with their counterparty and the running balance';
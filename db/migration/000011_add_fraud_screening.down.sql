@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "held_transfers";
+
+DROP INDEX IF EXISTS "transfers_from_account_id_created_at_idx";
+
+DROP TABLE IF EXISTS "fraud_rules";
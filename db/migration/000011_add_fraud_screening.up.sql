@@ -0,0 +1,81 @@
+CREATE TABLE "fraud_rules" (
+  "id" bigserial PRIMARY KEY,
+  "name" varchar UNIQUE NOT NULL,
+  "kind" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "currency" varchar(3),
+  "min_amount" bigint,
+  "max_count" int,
+  "window_seconds" int,
+  "is_enabled" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("kind" IN ('amount', 'account_velocity', 'user_velocity', 'new_recipient', 'repeated_transfer')),
+  CHECK ("action" IN ('review', 'deny')),
+  CHECK (("currency" IS NULL) = ("min_amount" IS NULL)),
+  CHECK ("min_amount" > 0),
+  CHECK (("max_count" IS NULL) = ("window_seconds" IS NULL)),
+  CHECK ("max_count" > 0 AND "window_seconds" > 0),
+  CHECK ("kind" <> 'amount' OR "min_amount" IS NOT NULL),
+  CHECK ("kind" NOT IN ('account_velocity', 'user_velocity', 'repeated_transfer') OR "max_count" IS NOT NULL)
+);
+
+ALTER TABLE "fraud_rules" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "fraud_rules"."kind" IS 'amount, account_velocity, user_velocity, new_recipient or repeated_transfer';
+
+COMMENT ON COLUMN "fraud_rules"."action" IS 'review holds the matching transfers for staff, deny rejects them';
+
+COMMENT ON COLUMN "fraud_rules"."min_amount" IS 'in minor units of currency, the rule only applies to transfers of that currency from this amount';
+
+COMMENT ON COLUMN "fraud_rules"."max_count" IS 'number of transfers allowed within window_seconds, the transfer after them matches';
+
+-- conservative defaults, staff tune them through the admin API
+INSERT INTO "fraud_rules" ("name", "kind", "action", "currency", "min_amount", "max_count", "window_seconds") VALUES
+  ('account burst', 'account_velocity', 'review', NULL, NULL, 10, 600),
+  ('user daily volume', 'user_velocity', 'review', NULL, NULL, 50, 86400),
+  ('repeated transfer', 'repeated_transfer', 'review', NULL, NULL, 3, 3600),
+  ('large new recipient USD', 'new_recipient', 'review', 'USD', 100000, NULL, NULL),
+  ('large transfer USD', 'amount', 'review', 'USD', 1000000, NULL, NULL),
+  ('very large transfer USD', 'amount', 'deny', 'USD', 10000000, NULL, NULL);
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+CREATE TABLE "held_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar(3) NOT NULL,
+  "requested_by" varchar NOT NULL,
+  "reasons" varchar[] NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reviewed_by" varchar,
+  "reviewed_at" timestamptz,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("amount" > 0),
+  CHECK ("status" IN ('pending', 'approved', 'rejected')),
+  CHECK (("status" = 'pending') = ("reviewed_at" IS NULL)),
+  CHECK (("status" = 'approved') = ("transfer_id" IS NOT NULL))
+);
+
+ALTER TABLE "held_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "held_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "held_transfers" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+ALTER TABLE "held_transfers" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "held_transfers" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "held_transfers" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "held_transfers" ("status", "created_at");
+
+COMMENT ON COLUMN "held_transfers"."currency" IS 'currency of both accounts when the transfer was held';
+
+COMMENT ON COLUMN "held_transfers"."reasons" IS 'names of the fraud rules which held the transfer, at the time it was held';
+
+COMMENT ON COLUMN "held_transfers"."transfer_id" IS 'the transfer made when staff approved it';
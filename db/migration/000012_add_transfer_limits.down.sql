@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "transfer_usage";
+
+DROP TABLE IF EXISTS "user_transfer_limits";
+
+DROP TABLE IF EXISTS "transfer_limits";
@@ -0,0 +1,60 @@
+-- default limits of the users in a currency, a null limit does not apply
+CREATE TABLE "transfer_limits" (
+  "currency" varchar(3) PRIMARY KEY,
+  "per_transaction" bigint,
+  "daily" bigint,
+  "monthly" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("per_transaction" > 0),
+  CHECK ("daily" > 0),
+  CHECK ("monthly" > 0)
+);
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "transfer_limits"."daily" IS 'in minor units of currency, total of the transfers of a user within a UTC day';
+
+COMMENT ON COLUMN "transfer_limits"."monthly" IS 'in minor units of currency, total of the transfers of a user within a UTC month';
+
+-- defaults of the seeded currencies, staff tune them through the admin API
+INSERT INTO "transfer_limits" ("currency", "per_transaction", "daily", "monthly") VALUES
+  ('USD', 2500000, 5000000, 20000000),
+  ('EUR', 2500000, 5000000, 20000000),
+  ('KRW', 30000000, 50000000, 200000000);
+
+-- the limits of a user replace the defaults of the currency as a whole
+CREATE TABLE "user_transfer_limits" (
+  "username" varchar NOT NULL,
+  "currency" varchar(3) NOT NULL,
+  "per_transaction" bigint,
+  "daily" bigint,
+  "monthly" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("username", "currency"),
+  CHECK ("per_transaction" > 0),
+  CHECK ("daily" > 0),
+  CHECK ("monthly" > 0)
+);
+
+ALTER TABLE "user_transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "user_transfer_limits" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+CREATE TABLE "transfer_usage" (
+  "owner" varchar NOT NULL,
+  "currency" varchar(3) NOT NULL,
+  "period" varchar NOT NULL,
+  "period_start" date NOT NULL,
+  "amount" bigint NOT NULL,
+  PRIMARY KEY ("owner", "currency", "period", "period_start"),
+  CHECK ("period" IN ('day', 'month')),
+  CHECK ("amount" >= 0)
+);
+
+ALTER TABLE "transfer_usage" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "transfer_usage" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "transfer_usage"."period_start" IS 'first day of the UTC day or month';
+
+COMMENT ON COLUMN "transfer_usage"."amount" IS 'in minor units of currency, total of the transfers of the owner within the period';
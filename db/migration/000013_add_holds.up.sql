@@ -0,0 +1,38 @@
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "captured_amount" bigint,
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "settled_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("amount" > 0),
+  CHECK ("account_id" <> "to_account_id"),
+  CHECK ("status" IN ('active', 'captured', 'voided', 'expired')),
+  CHECK (("status" = 'active') = ("settled_at" IS NULL)),
+  CHECK (("status" = 'captured') = ("captured_amount" IS NOT NULL)),
+  CHECK ("captured_amount" > 0 AND "captured_amount" <= "amount"),
+  CHECK ("status" = 'captured' OR "transfer_id" IS NULL)
+);
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+-- the active holds of an account are summed by every spend check
+CREATE INDEX ON "holds" ("account_id") WHERE "status" = 'active';
+
+CREATE INDEX ON "holds" ("expires_at") WHERE "status" = 'active';
+
+COMMENT ON COLUMN "holds"."to_account_id" IS 'account the captured amount is transferred to';
+
+COMMENT ON COLUMN "holds"."amount" IS 'must be positive, reserved on account_id until the hold is settled or expires';
+
+COMMENT ON COLUMN "holds"."status" IS 'active, captured, voided or expired, an active hold past expires_at no longer reserves funds';
+
+COMMENT ON COLUMN "holds"."captured_amount" IS 'at most amount, the rest of the hold is released by the capture';
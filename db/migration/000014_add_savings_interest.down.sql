@@ -0,0 +1,26 @@
+DROP TABLE IF EXISTS "interest_postings";
+
+DROP TABLE IF EXISTS "interest_accruals";
+
+DROP TABLE IF EXISTS "interest_accrual_runs";
+
+DROP TABLE IF EXISTS "interest_rates";
+
+-- the interest paid is taken off the ledger along with the accounts of the bank
+DELETE FROM "entries" WHERE "transfer_id" IN (
+  SELECT "transfers"."id" FROM "transfers"
+  JOIN "accounts" ON "accounts"."id" = "transfers"."from_account_id"
+  WHERE "accounts"."owner" = 'bank:interest_expense'
+);
+
+DELETE FROM "transfers" WHERE "from_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'bank:interest_expense');
+
+DELETE FROM "accounts" WHERE "owner" = 'bank:interest_expense';
+
+DELETE FROM "wallets" WHERE "owner" = 'bank:interest_expense';
+
+DELETE FROM "users" WHERE "username" = 'bank:interest_expense';
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or admin, admins are promoted directly in the database';
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "type";
@@ -0,0 +1,67 @@
+ALTER TABLE "accounts" ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+ALTER TABLE "accounts" ADD CHECK ("type" IN ('checking', 'savings'));
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings, only savings accounts earn interest';
+
+-- the accounts the bank books its own side of the ledger on belong to system users, who cannot sign in
+COMMENT ON COLUMN "users"."role" IS 'depositor, admin or system, admins are promoted directly in the database';
+
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified", "role") VALUES
+  ('bank:interest_expense', '', 'Interest expense', 'interest_expense@bank.invalid', true, 'system');
+
+-- the schedule of the annual rates of a currency, a rate applies from its day until the next one
+CREATE TABLE "interest_rates" (
+  "currency" varchar(3) NOT NULL,
+  "effective_from" date NOT NULL,
+  "annual_rate" numeric NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("currency", "effective_from"),
+  CHECK ("annual_rate" >= 0 AND "annual_rate" < 1)
+);
+
+ALTER TABLE "interest_rates" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "interest_rates"."annual_rate" IS 'fraction of the balance paid over a year of 365 days, 0.025 is 2.5%';
+
+-- a row per UTC day the job accrued, so that a rerun of a day pays nothing
+CREATE TABLE "interest_accrual_runs" (
+  "accrual_date" date PRIMARY KEY,
+  "accounts" int NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "annual_rate" numeric NOT NULL,
+  "amount" numeric NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "accrual_date")
+);
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'in minor units, balance of the account at the end of the UTC day';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'in minor units, not rounded, balance * annual_rate / 365';
+
+CREATE TABLE "interest_postings" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "period_start" date NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("account_id", "period_start"),
+  CHECK ("amount" > 0)
+);
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_postings" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+COMMENT ON COLUMN "interest_postings"."period_start" IS 'first day of the UTC month the interest was accrued in';
+
+COMMENT ON COLUMN "interest_postings"."amount" IS 'in minor units, the whole minor units accrued so far minus the earlier postings, the fraction is carried';
@@ -0,0 +1,14 @@
+-- the fees are taken off the ledger along with the accounts of the bank
+DELETE FROM "entries" WHERE "fee_transfer_id" IS NOT NULL;
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "fee_transfer_id";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+DROP TABLE IF EXISTS "fee_schedules";
+
+DELETE FROM "accounts" WHERE "owner" = 'bank:fee_revenue';
+
+DELETE FROM "wallets" WHERE "owner" = 'bank:fee_revenue';
+
+DELETE FROM "users" WHERE "username" = 'bank:fee_revenue';
@@ -0,0 +1,41 @@
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email", "is_email_verified", "role") VALUES
+  ('bank:fee_revenue', '', 'Fee revenue', 'fee_revenue@bank.invalid', true, 'system');
+
+-- the fee of a type of transfer in a currency, a flat fee, a percentage or both, by tier of the amount
+CREATE TABLE "fee_schedules" (
+  "id" bigserial PRIMARY KEY,
+  "transfer_type" varchar NOT NULL,
+  "currency" varchar(3) NOT NULL,
+  "tiers" jsonb NOT NULL,
+  "min_fee" bigint,
+  "max_fee" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("transfer_type", "currency"),
+  CHECK ("transfer_type" IN ('transfer', 'batch', 'capture')),
+  CHECK ("min_fee" >= 0),
+  CHECK ("max_fee" >= 0),
+  CHECK ("min_fee" <= "max_fee")
+);
+
+ALTER TABLE "fee_schedules" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "fee_schedules"."transfer_type" IS 'transfer, batch or capture, the operation the transfer was made by';
+
+COMMENT ON COLUMN "fee_schedules"."tiers" IS 'from the lowest min_amount, the tier of the highest min_amount up to the amount applies its flat fee plus rate times the amount';
+
+COMMENT ON COLUMN "fee_schedules"."min_fee" IS 'in minor units of currency, the lowest fee charged whatever the tier';
+
+COMMENT ON COLUMN "fee_schedules"."max_fee" IS 'in minor units of currency, the cap of the fee whatever the tier';
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+ALTER TABLE "transfers" ADD CHECK ("fee" >= 0);
+
+COMMENT ON COLUMN "transfers"."fee" IS 'in minor units, charged to from_account_id on top of amount';
+
+ALTER TABLE "entries" ADD COLUMN "fee_transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("fee_transfer_id") REFERENCES "transfers" ("id");
+
+COMMENT ON COLUMN "entries"."fee_transfer_id" IS 'transfer the entry charged the fee of, on the paying account and on the revenue account of the bank';
@@ -0,0 +1,26 @@
+-- the users who share an account, the holder in accounts.owner is always one of its owners
+CREATE TABLE "account_members" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "invited_by" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username"),
+  CHECK ("role" IN ('owner', 'spender', 'viewer'))
+);
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "account_members" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+-- the accounts of a user are listed through its memberships
+CREATE INDEX ON "account_members" ("username");
+
+COMMENT ON COLUMN "account_members"."role" IS 'owner, spender or viewer, owners manage the members, spenders transfer and viewers only read';
+
+COMMENT ON COLUMN "account_members"."invited_by" IS 'the owner who added the member, null for the holder';
+
+INSERT INTO "account_members" ("account_id", "username", "role")
+SELECT "id", "owner", 'owner' FROM "accounts";
@@ -0,0 +1,23 @@
+-- the address book of a user, the accounts it pays by nickname
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "nickname" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "currency" varchar(3) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  UNIQUE ("owner", "nickname"),
+  UNIQUE ("owner", "account_id"),
+  CHECK (length("nickname") > 0)
+);
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+COMMENT ON COLUMN "payees"."currency" IS 'currency of the account, checked when the payee is added';
+
+COMMENT ON COLUMN "payees"."created_at" IS 'start of the cooling-off period, large transfers to the payee are refused until it ends';
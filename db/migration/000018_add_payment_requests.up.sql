@@ -0,0 +1,58 @@
+-- a request of a user to be paid by another user, settled by the payer accepting or declining it
+CREATE TABLE "payment_requests" (
+  "id" bigserial PRIMARY KEY,
+  "requester" varchar NOT NULL,
+  "payer" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "currency" varchar(3) NOT NULL,
+  "note" varchar NOT NULL DEFAULT '',
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("amount" > 0),
+  CHECK ("requester" <> "payer"),
+  CHECK ("status" IN ('pending', 'accepted', 'declined', 'cancelled', 'expired')),
+  CHECK (("status" = 'accepted') = ("transfer_id" IS NOT NULL))
+);
+
+-- every status a payment request went through, the first one is pending
+CREATE TABLE "payment_request_events" (
+  "id" bigserial PRIMARY KEY,
+  "payment_request_id" bigint NOT NULL,
+  "status" varchar NOT NULL,
+  "actor" varchar,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("requester") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("payer") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("currency") REFERENCES "currencies" ("code");
+
+ALTER TABLE "payment_requests" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payment_request_events" ADD FOREIGN KEY ("payment_request_id") REFERENCES "payment_requests" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "payment_request_events" ADD FOREIGN KEY ("actor") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+CREATE INDEX ON "payment_requests" ("payer") WHERE "status" = 'pending';
+
+CREATE INDEX ON "payment_requests" ("requester");
+
+CREATE INDEX ON "payment_requests" ("expires_at") WHERE "status" = 'pending';
+
+CREATE INDEX ON "payment_request_events" ("payment_request_id");
+
+COMMENT ON COLUMN "payment_requests"."account_id" IS 'account of the requester the payment is transferred to';
+
+COMMENT ON COLUMN "payment_requests"."status" IS 'pending, accepted, declined, cancelled or expired, a pending request past expires_at can no longer be accepted';
+
+COMMENT ON COLUMN "payment_requests"."transfer_id" IS 'the transfer which paid the request, set once it is accepted';
+
+COMMENT ON COLUMN "payment_request_events"."actor" IS 'the user who changed the status, null when the request expired';
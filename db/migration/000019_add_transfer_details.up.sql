@@ -0,0 +1,28 @@
+ALTER TABLE "transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+ALTER TABLE "transfers" ADD CHECK (length("description") <= 140);
+
+ALTER TABLE "transfers" ADD CHECK (length("reference") <= 64);
+
+ALTER TABLE "transfers" ADD CHECK (jsonb_typeof("metadata") = 'object');
+
+-- a held transfer keeps the details of the transfer it makes once approved
+ALTER TABLE "held_transfers" ADD COLUMN "description" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "held_transfers" ADD COLUMN "reference" varchar NOT NULL DEFAULT '';
+
+ALTER TABLE "held_transfers" ADD COLUMN "metadata" jsonb NOT NULL DEFAULT '{}';
+
+CREATE INDEX ON "transfers" ("reference") WHERE "reference" <> '';
+
+CREATE INDEX ON "transfers" USING GIN ("metadata" jsonb_path_ops);
+
+COMMENT ON COLUMN "transfers"."description" IS 'free text of the sender, shown on the statements of both accounts';
+
+COMMENT ON COLUMN "transfers"."reference" IS 'identifier of the transfer in the system of the sender, such as an invoice number';
+
+COMMENT ON COLUMN "transfers"."metadata" IS 'object of string values set by the sender, searched by containment';
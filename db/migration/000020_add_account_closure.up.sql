@@ -0,0 +1,3 @@
+ALTER TABLE "accounts" ADD COLUMN "closed_at" timestamptz;
+
+COMMENT ON COLUMN "accounts"."closed_at" IS 'set when the owner is erased, a closed account takes no more transfers';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "account_transfer_usage";
+
+DROP TABLE IF EXISTS "account_transfer_limits";
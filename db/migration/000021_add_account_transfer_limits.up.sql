@@ -0,0 +1,30 @@
+-- the limits of an account apply on top of the limits of the user making the transfer,
+-- so the owners of a joint account can cap what is spent from it
+CREATE TABLE "account_transfer_limits" (
+  "account_id" bigint PRIMARY KEY,
+  "per_transaction" bigint,
+  "daily" bigint,
+  "monthly" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  CHECK ("per_transaction" > 0),
+  CHECK ("daily" > 0),
+  CHECK ("monthly" > 0)
+);
+
+ALTER TABLE "account_transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+CREATE TABLE "account_transfer_usage" (
+  "account_id" bigint NOT NULL,
+  "period" varchar NOT NULL,
+  "period_start" date NOT NULL,
+  "amount" bigint NOT NULL,
+  PRIMARY KEY ("account_id", "period", "period_start"),
+  CHECK ("period" IN ('day', 'month')),
+  CHECK ("amount" >= 0)
+);
+
+ALTER TABLE "account_transfer_usage" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+COMMENT ON COLUMN "account_transfer_usage"."period_start" IS 'first day of the UTC day or month';
+
+COMMENT ON COLUMN "account_transfer_usage"."amount" IS 'in minor units of the currency of the account, total of the transfers from it within the period';
@@ -0,0 +1,4 @@
+-- an owner has one account of each type per currency, so a savings account can be opened next to the checking one
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+
+ALTER TABLE "accounts" ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "type");
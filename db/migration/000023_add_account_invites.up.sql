@@ -0,0 +1,23 @@
+-- an owner invites a user to share an account, the user only becomes a member once it accepts
+CREATE TABLE "account_invites" (
+  "account_id" bigint NOT NULL,
+  "username" varchar NOT NULL,
+  "role" varchar NOT NULL,
+  "invited_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  PRIMARY KEY ("account_id", "username"),
+  CHECK ("role" IN ('owner', 'spender', 'viewer'))
+);
+
+ALTER TABLE "account_invites" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id") ON DELETE CASCADE;
+
+ALTER TABLE "account_invites" ADD FOREIGN KEY ("username") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+ALTER TABLE "account_invites" ADD FOREIGN KEY ("invited_by") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+-- the pending invites of a user are listed to it
+CREATE INDEX ON "account_invites" ("username");
+
+COMMENT ON COLUMN "account_invites"."role" IS 'the role the user is given on the account once it accepts';
+
+COMMENT ON COLUMN "account_invites"."invited_by" IS 'the owner who invited the user';
@@ -0,0 +1,14 @@
+-- the members of a shared account transfer from it, the velocity rules and the limits of a user count the transfers
+-- it made rather than those from the accounts it holds
+ALTER TABLE "transfers" ADD COLUMN "requested_by" varchar;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("requested_by") REFERENCES "users" ("username") ON UPDATE CASCADE;
+
+-- before the accounts were shared the holder made every transfer from them
+UPDATE "transfers" SET "requested_by" = "accounts"."owner"
+FROM "accounts"
+WHERE "accounts"."id" = "transfers"."from_account_id";
+
+CREATE INDEX ON "transfers" ("requested_by", "created_at");
+
+COMMENT ON COLUMN "transfers"."requested_by" IS 'the user who made the transfer, null for the interest the bank posts';
@@ -0,0 +1,3 @@
+ALTER TABLE IF EXISTS "held_transfers" DROP COLUMN IF EXISTS "recipient_hidden";
+
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "recipient_hidden";
@@ -0,0 +1,13 @@
+-- the server resolves the account of a recipient named by username or email, and the account of the requester of a
+-- payment request, the sender of such a transfer never learns the account it paid
+ALTER TABLE "transfers" ADD COLUMN "recipient_hidden" boolean NOT NULL DEFAULT false;
+
+ALTER TABLE "held_transfers" ADD COLUMN "recipient_hidden" boolean NOT NULL DEFAULT false;
+
+UPDATE "transfers" SET "recipient_hidden" = true
+FROM "payment_requests"
+WHERE "payment_requests"."transfer_id" = "transfers"."id";
+
+COMMENT ON COLUMN "transfers"."recipient_hidden" IS 'the server resolved to_account_id, it is kept from the sender';
+
+COMMENT ON COLUMN "held_transfers"."recipient_hidden" IS 'the server resolved to_account_id, it is kept from the sender';
@@ -0,0 +1,2 @@
+-- the names removed from the snapshots are not restored, they are still looked up when a snapshot is served
+COMMENT ON COLUMN "statements"."lines" IS 'entries of the period with their counterparty and the running balance';
@@ -0,0 +1,8 @@
+-- the names of the counterparties are looked up whenever a snapshot is served, so that erasing a user also removes
+-- its name from the statements of the others
+UPDATE "statements" SET "lines" = (
+  SELECT COALESCE(jsonb_agg("line" - 'counterparty_name' ORDER BY "position"), '[]'::jsonb)
+  FROM jsonb_array_elements("statements"."lines") WITH ORDINALITY AS "lines" ("line", "position")
+);
+
+COMMENT ON COLUMN "statements"."lines" IS 'entries of the period with their counterparty account and the running balance, the names of the counterparties are not stored';
@@ -0,0 +1 @@
+ALTER TABLE IF EXISTS "held_transfers" DROP COLUMN IF EXISTS "payment_request_id";
@@ -0,0 +1,8 @@
+-- a payment request accepted with a transfer the fraud rules held stays pending until staff review the transfer
+ALTER TABLE "held_transfers" ADD COLUMN "payment_request_id" bigint;
+
+ALTER TABLE "held_transfers" ADD FOREIGN KEY ("payment_request_id") REFERENCES "payment_requests" ("id");
+
+CREATE UNIQUE INDEX ON "held_transfers" ("payment_request_id") WHERE "status" = 'pending';
+
+COMMENT ON COLUMN "held_transfers"."payment_request_id" IS 'the payment request the transfer pays, accepted once staff approve the transfer';
@@ -1,12 +1,27 @@
 -- name: CreateAccount :one
-INSERT INTO accounts (
-  owner,
-  balance,
-  currency
-) VALUES (
-  $1, $2, $3
+-- The wallet of the owner is created along with its first account, and the owner is made the first owner member
+WITH wallet AS (
+  INSERT INTO wallets (owner) VALUES ($1)
+  ON CONFLICT (owner) DO UPDATE SET owner = EXCLUDED.owner
+  RETURNING id
+),
+account AS (
+  INSERT INTO accounts (
+    owner,
+    balance,
+    currency,
+    type,
+    wallet_id
+  ) SELECT
+    $1, $2, $3, $4, wallet.id
+  FROM wallet
+  RETURNING *
+),
+member AS (
+  INSERT INTO account_members (account_id, username, role)
+  SELECT id, owner, 'owner' FROM account
 )
-RETURNING *;
+SELECT * FROM account;
 
 -- name: GetAccount :one
 SELECT * FROM accounts
@@ -17,10 +32,24 @@ SELECT * FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE;
 
--- name: ListAccounts :many
+-- name: GetOwnerAccount :one
+-- The checking account of the owner in the currency, the one transfers to the owner are paid into
 SELECT * FROM accounts
-WHERE owner = $1
+WHERE owner = $1 AND currency = $2 AND type = 'checking';
+
+-- name: LockAccounts :many
+-- The rows are locked in the order of their ids, as every transaction writing several accounts must do
+SELECT * FROM accounts
+WHERE id = ANY(sqlc.arg(ids)::bigint[])
 ORDER BY id
+FOR NO KEY UPDATE;
+
+-- name: ListAccounts :many
+-- The accounts the user is a member of, in any role
+SELECT accounts.* FROM accounts
+JOIN account_members ON account_members.account_id = accounts.id
+WHERE account_members.username = $1
+ORDER BY accounts.id
 LIMIT $2
 OFFSET $3;
 
@@ -38,4 +67,25 @@ RETURNING *;
 
 -- name: DeleteAccount :exec
 DELETE FROM accounts
-WHERE id = $1;
\ No newline at end of file
+WHERE id = $1;
+
+-- name: ListAccountsByOwner :many
+SELECT * FROM accounts
+WHERE owner = $1
+ORDER BY id;
+
+-- name: CloseAccountsByOwner :exec
+UPDATE accounts
+SET closed_at = now()
+WHERE owner = $1
+  AND closed_at IS NULL;
+
+-- name: GetWalletAccount :one
+-- The balances of a wallet are the checking accounts of its owner
+SELECT * FROM accounts
+WHERE wallet_id = $1 AND currency = $2 AND type = 'checking';
+
+-- name: ListWalletAccounts :many
+SELECT * FROM accounts
+WHERE wallet_id = $1 AND type = 'checking'
+ORDER BY currency;
@@ -0,0 +1,32 @@
+-- name: CreateAccountInvite :one
+INSERT INTO account_invites (
+  account_id,
+  username,
+  role,
+  invited_by
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListAccountInvites :many
+-- The invites to the account its users have not answered yet
+SELECT * FROM account_invites
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: ListUserAccountInvites :many
+-- The invites the user has not answered yet, newest first
+SELECT * FROM account_invites
+WHERE username = $1
+ORDER BY created_at DESC, account_id;
+
+-- name: DeleteAccountInvite :one
+DELETE FROM account_invites
+WHERE account_id = $1 AND username = $2
+RETURNING *;
+
+-- name: DeleteAccountInvitesByUser :exec
+-- The invites to the user and the invites it sent are dropped with it
+DELETE FROM account_invites
+WHERE username = sqlc.arg(username) OR invited_by = sqlc.arg(username);
@@ -0,0 +1,32 @@
+-- name: CreateAccountMember :one
+INSERT INTO account_members (
+  account_id,
+  username,
+  role,
+  invited_by
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetAccountMember :one
+SELECT * FROM account_members
+WHERE account_id = $1 AND username = $2 LIMIT 1;
+
+-- name: ListAccountMembers :many
+SELECT * FROM account_members
+WHERE account_id = $1
+ORDER BY created_at, username;
+
+-- name: DeleteAccountMember :one
+DELETE FROM account_members
+WHERE account_id = $1 AND username = $2
+RETURNING *;
+
+-- name: DeleteAccountMembershipsByUser :exec
+-- The user leaves the accounts held by others, the memberships of the accounts it holds are kept
+DELETE FROM account_members
+USING accounts
+WHERE accounts.id = account_members.account_id
+  AND account_members.username = sqlc.arg(username)
+  AND accounts.owner <> sqlc.arg(username);
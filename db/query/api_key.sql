@@ -0,0 +1,32 @@
+-- name: CreateApiKey :one
+INSERT INTO api_keys (
+  username,
+  name,
+  key_hash,
+  scopes
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetApiKeyByHash :one
+SELECT * FROM api_keys
+WHERE key_hash = $1
+  AND is_revoked = FALSE
+LIMIT 1;
+
+-- name: ListApiKeysByUser :many
+SELECT * FROM api_keys
+WHERE username = $1
+ORDER BY id;
+
+-- name: RevokeApiKey :one
+UPDATE api_keys
+SET is_revoked = TRUE
+WHERE id = $1
+  AND username = $2
+RETURNING *;
+
+-- name: DeleteApiKeysByUser :exec
+DELETE FROM api_keys
+WHERE username = $1;
@@ -0,0 +1,16 @@
+-- name: CreateConversion :one
+INSERT INTO conversions (
+  wallet_id,
+  from_account_id,
+  to_account_id,
+  from_amount,
+  to_amount,
+  rate
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetConversion :one
+SELECT * FROM conversions
+WHERE id = $1 LIMIT 1;
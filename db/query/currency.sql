@@ -0,0 +1,23 @@
+-- name: CreateCurrency :one
+INSERT INTO currencies (
+  code,
+  minor_units,
+  is_enabled
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: GetCurrency :one
+SELECT * FROM currencies
+WHERE code = $1 LIMIT 1;
+
+-- name: ListCurrencies :many
+SELECT * FROM currencies
+ORDER BY code;
+
+-- name: UpdateCurrency :one
+UPDATE currencies
+SET is_enabled = $2
+WHERE code = $1
+RETURNING *;
@@ -0,0 +1,7 @@
+-- name: CreateErasureRequest :one
+INSERT INTO erasure_requests (
+  pseudonym
+) VALUES (
+  $1
+)
+RETURNING *;
@@ -0,0 +1,29 @@
+-- name: ListFeeSchedules :many
+SELECT * FROM fee_schedules
+ORDER BY transfer_type, currency;
+
+-- name: GetFeeSchedule :one
+SELECT * FROM fee_schedules
+WHERE transfer_type = $1 AND currency = $2 LIMIT 1;
+
+-- name: UpsertFeeSchedule :one
+INSERT INTO fee_schedules (
+  transfer_type,
+  currency,
+  tiers,
+  min_fee,
+  max_fee
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (transfer_type, currency) DO UPDATE
+SET tiers = EXCLUDED.tiers,
+  min_fee = EXCLUDED.min_fee,
+  max_fee = EXCLUDED.max_fee,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteFeeSchedule :one
+DELETE FROM fee_schedules
+WHERE transfer_type = $1 AND currency = $2
+RETURNING *;
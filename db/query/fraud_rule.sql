@@ -0,0 +1,41 @@
+-- name: CreateFraudRule :one
+INSERT INTO fraud_rules (
+  name,
+  kind,
+  action,
+  currency,
+  min_amount,
+  max_count,
+  window_seconds,
+  is_enabled
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+)
+RETURNING *;
+
+-- name: GetFraudRule :one
+SELECT * FROM fraud_rules
+WHERE id = $1 LIMIT 1;
+
+-- name: ListFraudRules :many
+SELECT * FROM fraud_rules
+ORDER BY id;
+
+-- name: ListEnabledFraudRules :many
+SELECT * FROM fraud_rules
+WHERE is_enabled = TRUE
+ORDER BY id;
+
+-- name: UpdateFraudRule :one
+UPDATE fraud_rules
+SET name = $2,
+  kind = $3,
+  action = $4,
+  currency = $5,
+  min_amount = $6,
+  max_count = $7,
+  window_seconds = $8,
+  is_enabled = $9,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,42 @@
+-- name: CreateHeldTransfer :one
+INSERT INTO held_transfers (
+  from_account_id,
+  to_account_id,
+  amount,
+  currency,
+  requested_by,
+  reasons,
+  description,
+  reference,
+  metadata,
+  recipient_hidden,
+  payment_request_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+)
+RETURNING *;
+
+-- name: GetHeldTransfer :one
+SELECT * FROM held_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHeldTransferForUpdate :one
+SELECT * FROM held_transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListHeldTransfers :many
+SELECT * FROM held_transfers
+WHERE status = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ReviewHeldTransfer :one
+UPDATE held_transfers
+SET status = $2,
+  reviewed_by = $3,
+  transfer_id = $4,
+  reviewed_at = now()
+WHERE id = $1
+RETURNING *;
@@ -0,0 +1,54 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+  account_id,
+  to_account_id,
+  amount,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListActiveHolds :many
+SELECT * FROM holds
+WHERE account_id = $1 AND status = 'active' AND expires_at > now()
+ORDER BY id;
+
+-- name: GetHeldAmount :one
+-- The funds the active holds reserve on the account, an expired hold reserves nothing even before it is marked
+SELECT COALESCE(SUM(amount), 0)::bigint AS held_amount FROM holds
+WHERE account_id = $1 AND status = 'active' AND expires_at > now();
+
+-- name: ListHeldAmounts :many
+SELECT account_id, SUM(amount)::bigint AS held_amount FROM holds
+WHERE account_id = ANY(sqlc.arg(account_ids)::bigint[]) AND status = 'active' AND expires_at > now()
+GROUP BY account_id;
+
+-- name: SettleHold :one
+UPDATE holds
+SET status = $2,
+  captured_amount = $3,
+  settled_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: SetHoldTransfer :one
+UPDATE holds
+SET transfer_id = $2
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpireHolds :execrows
+UPDATE holds
+SET status = 'expired',
+  settled_at = now()
+WHERE status = 'active' AND expires_at <= now();
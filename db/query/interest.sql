@@ -0,0 +1,114 @@
+-- name: ListInterestRates :many
+SELECT * FROM interest_rates
+ORDER BY currency, effective_from;
+
+-- name: UpsertInterestRate :one
+INSERT INTO interest_rates (
+  currency,
+  effective_from,
+  annual_rate
+) VALUES (
+  $1, $2, $3
+)
+ON CONFLICT (currency, effective_from) DO UPDATE
+SET annual_rate = EXCLUDED.annual_rate
+RETURNING *;
+
+-- name: GetLastInterestAccrualRun :one
+SELECT * FROM interest_accrual_runs
+ORDER BY accrual_date DESC
+LIMIT 1;
+
+-- name: CreateInterestAccrualRun :one
+-- A day already run is left as is and no row is returned
+INSERT INTO interest_accrual_runs (
+  accrual_date,
+  accounts
+) VALUES (
+  $1, $2
+)
+ON CONFLICT (accrual_date) DO NOTHING
+RETURNING *;
+
+-- name: AccrueInterest :execrows
+-- Accrues the UTC day on every savings account with a positive balance at its end, at the rate of the currency on
+-- that day. The balance at the end of the day is the balance less the entries posted since, so a late run accrues
+-- on the same balance. An account accrues a day at most once.
+INSERT INTO interest_accruals (
+  account_id,
+  accrual_date,
+  balance,
+  annual_rate,
+  amount
+) SELECT
+  accounts.id,
+  sqlc.arg(accrual_date)::date,
+  closing.balance,
+  rate.annual_rate,
+  closing.balance * rate.annual_rate / 365
+FROM accounts
+CROSS JOIN LATERAL (
+  SELECT (accounts.balance - COALESCE(SUM(entries.amount), 0))::bigint AS balance FROM entries
+  WHERE entries.account_id = accounts.id
+    AND entries.created_at >= (sqlc.arg(accrual_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+) AS closing
+JOIN LATERAL (
+  SELECT interest_rates.annual_rate FROM interest_rates
+  WHERE interest_rates.currency = accounts.currency AND interest_rates.effective_from <= sqlc.arg(accrual_date)::date
+  ORDER BY interest_rates.effective_from DESC
+  LIMIT 1
+) AS rate ON true
+WHERE accounts.type = 'savings'
+  AND accounts.created_at < (sqlc.arg(accrual_date)::date + 1)::timestamp AT TIME ZONE 'UTC'
+  AND closing.balance > 0
+  AND rate.annual_rate > 0
+ON CONFLICT (account_id, accrual_date) DO NOTHING;
+
+-- name: ListInterestDue :many
+-- The accounts with whole minor units accrued before period_end and not posted yet, but for those already posted
+-- for the period
+SELECT accrued.account_id, (floor(accrued.amount) - COALESCE(posted.amount, 0))::bigint AS amount
+FROM (
+  SELECT interest_accruals.account_id, SUM(interest_accruals.amount) AS amount FROM interest_accruals
+  WHERE interest_accruals.accrual_date < sqlc.arg(period_end)::date
+  GROUP BY interest_accruals.account_id
+) AS accrued
+LEFT JOIN (
+  SELECT interest_postings.account_id, SUM(interest_postings.amount) AS amount FROM interest_postings
+  GROUP BY interest_postings.account_id
+) AS posted ON posted.account_id = accrued.account_id
+WHERE floor(accrued.amount) > COALESCE(posted.amount, 0)
+  AND NOT EXISTS (
+    SELECT 1 FROM interest_postings
+    WHERE interest_postings.account_id = accrued.account_id AND interest_postings.period_start = sqlc.arg(period_start)::date
+  )
+ORDER BY accrued.account_id;
+
+-- name: GetInterestDue :one
+-- The whole minor units the account accrued before period_end less what was posted to it, the fraction is carried
+-- to the next posting so that rounding never pays more or less than accrued over time
+SELECT (
+  floor(COALESCE((
+    SELECT SUM(interest_accruals.amount) FROM interest_accruals
+    WHERE interest_accruals.account_id = sqlc.arg(account_id) AND interest_accruals.accrual_date < sqlc.arg(period_end)::date
+  ), 0)) - COALESCE((
+    SELECT SUM(interest_postings.amount) FROM interest_postings
+    WHERE interest_postings.account_id = sqlc.arg(account_id)
+  ), 0)
+)::bigint AS amount;
+
+-- name: CreateInterestPosting :one
+INSERT INTO interest_postings (
+  account_id,
+  period_start,
+  amount,
+  transfer_id
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: ListInterestPostings :many
+SELECT * FROM interest_postings
+WHERE account_id = $1
+ORDER BY period_start;
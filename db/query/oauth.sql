@@ -0,0 +1,74 @@
+-- name: CreateOauthClient :one
+INSERT INTO oauth_clients (
+  client_id,
+  owner,
+  name,
+  secret_hash,
+  redirect_uris,
+  scopes
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+RETURNING *;
+
+-- name: GetOauthClient :one
+SELECT * FROM oauth_clients
+WHERE client_id = $1
+LIMIT 1;
+
+-- name: CreateOauthAuthorizationCode :one
+INSERT INTO oauth_authorization_codes (
+  code_hash,
+  client_id,
+  username,
+  redirect_uri,
+  scopes,
+  code_challenge,
+  expired_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: UseOauthAuthorizationCode :one
+UPDATE oauth_authorization_codes
+SET is_used = TRUE
+WHERE code_hash = $1
+  AND client_id = $2
+  AND is_used = FALSE
+  AND expired_at > now()
+RETURNING *;
+
+-- name: DeleteOauthAuthorizationCodesByUser :exec
+DELETE FROM oauth_authorization_codes
+WHERE username = $1;
+
+-- name: CreateOauthRefreshToken :one
+INSERT INTO oauth_refresh_tokens (
+  token_hash,
+  client_id,
+  username,
+  scopes,
+  expired_at
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+RETURNING *;
+
+-- name: UseOauthRefreshToken :one
+-- Refresh tokens issued before the last password change of their user can no longer be used
+UPDATE oauth_refresh_tokens
+SET is_revoked = TRUE
+WHERE token_hash = $1
+  AND client_id = $2
+  AND is_revoked = FALSE
+  AND expired_at > now()
+  AND created_at >= (
+    SELECT password_changed_at FROM users
+    WHERE users.username = oauth_refresh_tokens.username
+  )
+RETURNING *;
+
+-- name: DeleteOauthRefreshTokensByUser :exec
+DELETE FROM oauth_refresh_tokens
+WHERE username = $1;
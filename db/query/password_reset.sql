@@ -0,0 +1,28 @@
+-- name: CreatePasswordReset :one
+INSERT INTO password_resets (
+  username,
+  token_hash,
+  expired_at
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: UsePasswordReset :one
+UPDATE password_resets
+SET is_used = TRUE
+WHERE token_hash = $1
+  AND is_used = FALSE
+  AND expired_at > now()
+RETURNING *;
+
+-- name: InvalidatePasswordResets :exec
+-- Marks the outstanding reset tokens of the user as used, once the password is reset or changed
+UPDATE password_resets
+SET is_used = TRUE
+WHERE username = $1
+  AND is_used = FALSE;
+
+-- name: DeletePasswordResetsByUser :exec
+DELETE FROM password_resets
+WHERE username = $1;
@@ -0,0 +1,45 @@
+-- name: CreatePayee :one
+INSERT INTO payees (
+  owner,
+  nickname,
+  account_id,
+  currency
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: GetPayee :one
+SELECT * FROM payees
+WHERE id = $1
+  AND owner = $2
+LIMIT 1;
+
+-- name: GetPayeeByAccount :one
+SELECT * FROM payees
+WHERE owner = $1
+  AND account_id = $2;
+
+-- name: ListPayees :many
+SELECT * FROM payees
+WHERE owner = $1
+ORDER BY nickname;
+
+-- name: UpdatePayee :one
+-- Only the nickname changes, a payee paying another account is a new payee with its own cooling-off period
+UPDATE payees
+SET nickname = $3,
+  updated_at = now()
+WHERE id = $1
+  AND owner = $2
+RETURNING *;
+
+-- name: DeletePayee :one
+DELETE FROM payees
+WHERE id = $1
+  AND owner = $2
+RETURNING *;
+
+-- name: DeletePayeesByOwner :exec
+DELETE FROM payees
+WHERE owner = $1;
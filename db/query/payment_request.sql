@@ -0,0 +1,78 @@
+-- name: CreatePaymentRequest :one
+INSERT INTO payment_requests (
+  requester,
+  payer,
+  account_id,
+  amount,
+  currency,
+  note,
+  expires_at
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7
+)
+RETURNING *;
+
+-- name: GetPaymentRequest :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentRequestForUpdate :one
+SELECT * FROM payment_requests
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: ListIncomingPaymentRequests :many
+-- The inbox of the payer, the requests it can still accept
+SELECT * FROM payment_requests
+WHERE payer = $1 AND status = 'pending' AND expires_at > now()
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListOutgoingPaymentRequests :many
+-- The requests the requester sent, in any status
+SELECT * FROM payment_requests
+WHERE requester = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: SettlePaymentRequest :one
+UPDATE payment_requests
+SET status = $2,
+  transfer_id = $3,
+  updated_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: ExpirePaymentRequests :execrows
+-- Every expired request gets its expired event, which is what the rows counted are.
+-- A request accepted with a transfer held for review waits for the review instead.
+WITH expired AS (
+  UPDATE payment_requests
+  SET status = 'expired',
+    updated_at = now()
+  WHERE status = 'pending' AND expires_at <= now()
+    AND NOT EXISTS (
+      SELECT 1 FROM held_transfers
+      WHERE held_transfers.payment_request_id = payment_requests.id AND held_transfers.status = 'pending'
+    )
+  RETURNING id
+)
+INSERT INTO payment_request_events (payment_request_id, status)
+SELECT id, 'expired' FROM expired;
+
+-- name: CreatePaymentRequestEvent :one
+INSERT INTO payment_request_events (
+  payment_request_id,
+  status,
+  actor
+) VALUES (
+  $1, $2, $3
+)
+RETURNING *;
+
+-- name: ListPaymentRequestEvents :many
+SELECT * FROM payment_request_events
+WHERE payment_request_id = $1
+ORDER BY id;
@@ -0,0 +1,73 @@
+-- name: GetAccountBalanceBefore :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS balance FROM entries
+WHERE account_id = $1 AND created_at < $2;
+
+-- name: ListStatementEntries :many
+-- The entries of the period with their counterparty, the account of a recipient the server resolved is kept from its sender
+SELECT
+  entries.id,
+  entries.amount,
+  entries.created_at,
+  entries.transfer_id,
+  entries.conversion_id,
+  entries.fee_transfer_id,
+  CASE
+    WHEN transfers.recipient_hidden AND transfers.from_account_id = entries.account_id THEN NULL
+    ELSE counterparty.id
+  END AS counterparty_account_id,
+  counterparty.currency AS counterparty_currency,
+  users.full_name AS counterparty_name,
+  transfers.description AS transfer_description,
+  transfers.reference AS transfer_reference
+FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+LEFT JOIN conversions ON conversions.id = entries.conversion_id
+LEFT JOIN accounts AS counterparty ON counterparty.id = CASE
+    WHEN transfers.from_account_id = entries.account_id THEN transfers.to_account_id
+    WHEN transfers.to_account_id = entries.account_id THEN transfers.from_account_id
+    WHEN conversions.from_account_id = entries.account_id THEN conversions.to_account_id
+    WHEN conversions.to_account_id = entries.account_id THEN conversions.from_account_id
+  END
+LEFT JOIN users ON users.username = counterparty.owner
+WHERE entries.account_id = sqlc.arg(account_id)
+  AND entries.created_at >= sqlc.arg(period_start)
+  AND entries.created_at < sqlc.arg(period_end)
+ORDER BY entries.created_at, entries.id;
+
+-- name: ListStatementCounterpartyNames :many
+-- The current names of the holders of the counterparty accounts of the entries, the snapshots do not store them
+-- so that an erased user is not named in the statements of others
+SELECT
+  entries.id,
+  users.full_name AS counterparty_name
+FROM entries
+LEFT JOIN transfers ON transfers.id = entries.transfer_id
+LEFT JOIN conversions ON conversions.id = entries.conversion_id
+JOIN accounts AS counterparty ON counterparty.id = CASE
+    WHEN transfers.from_account_id = entries.account_id THEN transfers.to_account_id
+    WHEN transfers.to_account_id = entries.account_id THEN transfers.from_account_id
+    WHEN conversions.from_account_id = entries.account_id THEN conversions.to_account_id
+    WHEN conversions.to_account_id = entries.account_id THEN conversions.from_account_id
+  END
+JOIN users ON users.username = counterparty.owner
+WHERE entries.id = ANY(sqlc.arg(entry_ids)::bigint[]);
+
+-- name: CreateStatement :one
+-- Concurrent requests for the same closed period compute the same lines, the first one is kept
+-- and sql.ErrNoRows is returned to the others
+INSERT INTO statements (
+  account_id,
+  period_start,
+  period_end,
+  opening_balance,
+  closing_balance,
+  lines
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+)
+ON CONFLICT (account_id, period_start, period_end) DO NOTHING
+RETURNING *;
+
+-- name: GetStatement :one
+SELECT * FROM statements
+WHERE account_id = $1 AND period_start = $2 AND period_end = $3 LIMIT 1;
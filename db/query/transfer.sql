@@ -2,9 +2,15 @@
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  fee,
+  description,
+  reference,
+  metadata,
+  requested_by,
+  recipient_hidden
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
 RETURNING *;
 
@@ -19,6 +25,50 @@ ORDER BY id
 LIMIT $3
 OFFSET $4;
 
+-- name: SearchTransfers :many
+-- The transfers from and to the account which match every filter, newest first. The pattern is matched
+-- case-insensitively against the description and the reference, an empty pattern, reference or metadata object
+-- matches every transfer.
+SELECT * FROM transfers
+WHERE (from_account_id = sqlc.arg(account_id) OR to_account_id = sqlc.arg(account_id))
+  AND (sqlc.arg(pattern)::text = '' OR description ILIKE sqlc.arg(pattern) OR reference ILIKE sqlc.arg(pattern))
+  AND (sqlc.arg(reference)::text = '' OR reference = sqlc.arg(reference))
+  AND metadata @> sqlc.arg(metadata)::jsonb
+ORDER BY id DESC
+LIMIT sqlc.arg(row_limit)::int
+OFFSET sqlc.arg(row_offset)::int;
+
 -- name: DeleteTransfer :exec
 DELETE FROM transfers
 WHERE id = $1;
+
+-- name: ListTransfersByOwner :many
+SELECT * FROM transfers
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+  OR to_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id;
+
+-- name: CountTransfersFromAccountSince :one
+SELECT count(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2;
+
+-- name: CountTransfersRequestedBySince :one
+-- Counts the transfers the user made, from its own accounts and from the accounts shared with it
+SELECT count(*) FROM transfers
+WHERE requested_by = $1 AND created_at >= $2;
+
+-- name: CountRepeatedTransfers :one
+-- Counts the transfers identical to a new one, same accounts and same amount
+SELECT count(*) FROM transfers
+WHERE from_account_id = $1
+  AND to_account_id = $2
+  AND amount = $3
+  AND created_at >= $4;
+
+-- name: HasTransferredTo :one
+-- Tells whether any account of the owner has ever paid the account
+SELECT EXISTS (
+  SELECT 1 FROM transfers
+  WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    AND to_account_id = $2
+);
@@ -0,0 +1,131 @@
+-- name: ListDefaultTransferLimits :many
+SELECT * FROM transfer_limits
+ORDER BY currency;
+
+-- name: UpsertDefaultTransferLimit :one
+INSERT INTO transfer_limits (
+  currency,
+  per_transaction,
+  daily,
+  monthly
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (currency) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+  daily = EXCLUDED.daily,
+  monthly = EXCLUDED.monthly,
+  updated_at = now()
+RETURNING *;
+
+-- name: ListUserTransferLimits :many
+SELECT * FROM user_transfer_limits
+WHERE username = $1
+ORDER BY currency;
+
+-- name: UpsertUserTransferLimit :one
+INSERT INTO user_transfer_limits (
+  username,
+  currency,
+  per_transaction,
+  daily,
+  monthly
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (username, currency) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+  daily = EXCLUDED.daily,
+  monthly = EXCLUDED.monthly,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteUserTransferLimit :one
+DELETE FROM user_transfer_limits
+WHERE username = $1 AND currency = $2
+RETURNING *;
+
+-- name: GetTransferLimit :one
+-- The limits which apply to the user in the currency, its own ones or else the defaults
+SELECT DISTINCT ON (currency) currency, per_transaction, daily, monthly FROM (
+  SELECT currency, per_transaction, daily, monthly, 0 AS priority FROM user_transfer_limits
+  WHERE username = $1 AND currency = $2
+  UNION ALL
+  SELECT currency, per_transaction, daily, monthly, 1 AS priority FROM transfer_limits
+  WHERE currency = $2
+) AS limits
+ORDER BY currency, priority;
+
+-- name: ListTransferLimits :many
+-- The limits which apply to the user in every currency with limits
+SELECT DISTINCT ON (currency) currency, per_transaction, daily, monthly FROM (
+  SELECT currency, per_transaction, daily, monthly, 0 AS priority FROM user_transfer_limits
+  WHERE username = $1
+  UNION ALL
+  SELECT currency, per_transaction, daily, monthly, 1 AS priority FROM transfer_limits
+) AS limits
+ORDER BY currency, priority;
+
+-- name: AddTransferUsage :one
+-- Adding to the usage locks its row until the transaction ends, adding 0 reads the usage and locks it
+INSERT INTO transfer_usage (
+  owner,
+  currency,
+  period,
+  period_start,
+  amount
+) VALUES (
+  $1, $2, $3, $4, $5
+)
+ON CONFLICT (owner, currency, period, period_start) DO UPDATE
+SET amount = transfer_usage.amount + EXCLUDED.amount
+RETURNING *;
+
+-- name: ListTransferUsage :many
+SELECT * FROM transfer_usage
+WHERE owner = $1
+  AND ((period = 'day' AND period_start = sqlc.arg(day)) OR (period = 'month' AND period_start = sqlc.arg(month)));
+
+-- name: GetAccountTransferLimit :one
+SELECT * FROM account_transfer_limits
+WHERE account_id = $1;
+
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO account_transfer_limits (
+  account_id,
+  per_transaction,
+  daily,
+  monthly
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id) DO UPDATE
+SET per_transaction = EXCLUDED.per_transaction,
+  daily = EXCLUDED.daily,
+  monthly = EXCLUDED.monthly,
+  updated_at = now()
+RETURNING *;
+
+-- name: DeleteAccountTransferLimit :one
+DELETE FROM account_transfer_limits
+WHERE account_id = $1
+RETURNING *;
+
+-- name: AddAccountTransferUsage :one
+-- Adding to the usage locks its row until the transaction ends, adding 0 reads the usage and locks it
+INSERT INTO account_transfer_usage (
+  account_id,
+  period,
+  period_start,
+  amount
+) VALUES (
+  $1, $2, $3, $4
+)
+ON CONFLICT (account_id, period, period_start) DO UPDATE
+SET amount = account_transfer_usage.amount + EXCLUDED.amount
+RETURNING *;
+
+-- name: ListAccountTransferUsage :many
+SELECT * FROM account_transfer_usage
+WHERE account_id = $1
+  AND ((period = 'day' AND period_start = sqlc.arg(day)) OR (period = 'month' AND period_start = sqlc.arg(month)));
@@ -11,4 +11,42 @@ RETURNING *;
 
 -- name: GetUser :one
 SELECT * FROM users
-WHERE username = $1 LIMIT 1;
\ No newline at end of file
+WHERE username = $1 LIMIT 1;
+
+-- name: GetUserByEmail :one
+SELECT * FROM users
+WHERE email = $1 LIMIT 1;
+
+-- name: UpdateUserPassword :one
+UPDATE users
+SET hashed_password = $2,
+  password_changed_at = now()
+WHERE username = $1
+RETURNING *;
+
+
+-- name: VerifyUserEmail :one
+UPDATE users
+SET is_email_verified = TRUE
+WHERE username = $1
+  AND email = $2
+RETURNING *;
+
+-- name: UpdateUser :one
+UPDATE users
+SET full_name = $2,
+  email = $3,
+  is_email_verified = $4
+WHERE username = $1
+RETURNING *;
+
+-- name: PseudonymizeUser :one
+UPDATE users
+SET username = sqlc.arg(pseudonym),
+  hashed_password = sqlc.arg(hashed_password),
+  full_name = sqlc.arg(full_name),
+  email = sqlc.arg(email),
+  is_email_verified = FALSE,
+  password_changed_at = now()
+WHERE username = sqlc.arg(username)
+RETURNING *;
@@ -0,0 +1,30 @@
+-- name: CreateVerifyEmail :one
+INSERT INTO verify_emails (
+  username,
+  email,
+  secret_code_hash,
+  expired_at
+) VALUES (
+  $1, $2, $3, $4
+)
+RETURNING *;
+
+-- name: UseVerifyEmail :one
+UPDATE verify_emails
+SET is_used = TRUE
+WHERE id = $1
+  AND secret_code_hash = $2
+  AND is_used = FALSE
+  AND expired_at > now()
+RETURNING *;
+
+-- name: InvalidateVerifyEmails :exec
+-- Marks the outstanding verification codes of the user as used, once a new one replaces them
+UPDATE verify_emails
+SET is_used = TRUE
+WHERE username = $1
+  AND is_used = FALSE;
+
+-- name: DeleteVerifyEmailsByUser :exec
+DELETE FROM verify_emails
+WHERE username = $1;
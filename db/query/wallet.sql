@@ -0,0 +1,3 @@
+-- name: GetWallet :one
+SELECT * FROM wallets
+WHERE id = $1 LIMIT 1;
@@ -5,13 +5,15 @@ package db
 
 import (
 	"context"
+
+	"github.com/lib/pq"
 )
 
 const addAccountBalance = `-- name: AddAccountBalance :one
 UPDATE accounts
 SET balance = balance + $1
 WHERE id = $2
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, wallet_id, type, closed_at
 `
 
 type AddAccountBalanceParams struct {
@@ -28,29 +30,65 @@ func (q *Queries) AddAccountBalance(ctx context.Context, arg AddAccountBalancePa
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WalletID,
+		&i.Type,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
+const closeAccountsByOwner = `-- name: CloseAccountsByOwner :exec
+UPDATE accounts
+SET closed_at = now()
+WHERE owner = $1
+  AND closed_at IS NULL
+`
+
+func (q *Queries) CloseAccountsByOwner(ctx context.Context, owner string) error {
+	_, err := q.db.ExecContext(ctx, closeAccountsByOwner, owner)
+	return err
+}
+
 const createAccount = `-- name: CreateAccount :one
-INSERT INTO accounts (
-  owner,
-  balance,
-  currency
-) VALUES (
-  $1, $2, $3
+WITH wallet AS (
+  INSERT INTO wallets (owner) VALUES ($1)
+  ON CONFLICT (owner) DO UPDATE SET owner = EXCLUDED.owner
+  RETURNING id
+),
+account AS (
+  INSERT INTO accounts (
+    owner,
+    balance,
+    currency,
+    type,
+    wallet_id
+  ) SELECT
+    $1, $2, $3, $4, wallet.id
+  FROM wallet
+  RETURNING id, owner, balance, currency, created_at, wallet_id, type, closed_at
+),
+member AS (
+  INSERT INTO account_members (account_id, username, role)
+  SELECT id, owner, 'owner' FROM account
 )
-RETURNING id, owner, balance, currency, created_at
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM account
 `
 
 type CreateAccountParams struct {
 	Owner    string `json:"owner"`
 	Balance  int64  `json:"balance"`
 	Currency string `json:"currency"`
+	Type     string `json:"type"`
 }
 
+// The wallet of the owner is created along with its first account, and the owner is made the first owner member
 func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error) {
-	row := q.db.QueryRowContext(ctx, createAccount, arg.Owner, arg.Balance, arg.Currency)
+	row := q.db.QueryRowContext(ctx, createAccount,
+		arg.Owner,
+		arg.Balance,
+		arg.Currency,
+		arg.Type,
+	)
 	var i Account
 	err := row.Scan(
 		&i.ID,
@@ -58,6 +96,9 @@ func (q *Queries) CreateAccount(ctx context.Context, arg CreateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WalletID,
+		&i.Type,
+		&i.ClosedAt,
 	)
 	return i, err
 }
@@ -73,7 +114,7 @@ func (q *Queries) DeleteAccount(ctx context.Context, id int64) error {
 }
 
 const getAccount = `-- name: GetAccount :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM accounts
 WHERE id = $1 LIMIT 1
 `
 
@@ -86,12 +127,15 @@ func (q *Queries) GetAccount(ctx context.Context, id int64) (Account, error) {
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WalletID,
+		&i.Type,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
 const getAccountForUpdate = `-- name: GetAccountForUpdate :one
-SELECT id, owner, balance, currency, created_at FROM accounts
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM accounts
 WHERE id = $1 LIMIT 1
 FOR NO KEY UPDATE
 `
@@ -105,26 +149,201 @@ func (q *Queries) GetAccountForUpdate(ctx context.Context, id int64) (Account, e
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WalletID,
+		&i.Type,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getOwnerAccount = `-- name: GetOwnerAccount :one
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM accounts
+WHERE owner = $1 AND currency = $2 AND type = 'checking'
+`
+
+type GetOwnerAccountParams struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+}
+
+// The checking account of the owner in the currency, the one transfers to the owner are paid into
+func (q *Queries) GetOwnerAccount(ctx context.Context, arg GetOwnerAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getOwnerAccount, arg.Owner, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WalletID,
+		&i.Type,
+		&i.ClosedAt,
+	)
+	return i, err
+}
+
+const getWalletAccount = `-- name: GetWalletAccount :one
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM accounts
+WHERE wallet_id = $1 AND currency = $2 AND type = 'checking'
+`
+
+type GetWalletAccountParams struct {
+	WalletID int64  `json:"wallet_id"`
+	Currency string `json:"currency"`
+}
+
+// The balances of a wallet are the checking accounts of its owner
+func (q *Queries) GetWalletAccount(ctx context.Context, arg GetWalletAccountParams) (Account, error) {
+	row := q.db.QueryRowContext(ctx, getWalletAccount, arg.WalletID, arg.Currency)
+	var i Account
+	err := row.Scan(
+		&i.ID,
+		&i.Owner,
+		&i.Balance,
+		&i.Currency,
+		&i.CreatedAt,
+		&i.WalletID,
+		&i.Type,
+		&i.ClosedAt,
 	)
 	return i, err
 }
 
 const listAccounts = `-- name: ListAccounts :many
-SELECT id, owner, balance, currency, created_at FROM accounts
-WHERE owner = $1
-ORDER BY id
+SELECT accounts.id, accounts.owner, accounts.balance, accounts.currency, accounts.created_at, accounts.wallet_id, accounts.type FROM accounts
+JOIN account_members ON account_members.account_id = accounts.id
+WHERE account_members.username = $1
+ORDER BY accounts.id
 LIMIT $2
 OFFSET $3
 `
 
 type ListAccountsParams struct {
-	Owner  string `json:"owner"`
-	Limit  int32  `json:"limit"`
-	Offset int32  `json:"offset"`
+	Username string `json:"username"`
+	Limit    int32  `json:"limit"`
+	Offset   int32  `json:"offset"`
 }
 
+// The accounts the user is a member of, in any role
 func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error) {
-	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Owner, arg.Limit, arg.Offset)
+	rows, err := q.db.QueryContext(ctx, listAccounts, arg.Username, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.WalletID,
+			&i.Type,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listAccountsByOwner = `-- name: ListAccountsByOwner :many
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM accounts
+WHERE owner = $1
+ORDER BY id
+`
+
+func (q *Queries) ListAccountsByOwner(ctx context.Context, owner string) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listAccountsByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.WalletID,
+			&i.Type,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWalletAccounts = `-- name: ListWalletAccounts :many
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM accounts
+WHERE wallet_id = $1 AND type = 'checking'
+ORDER BY currency
+`
+
+func (q *Queries) ListWalletAccounts(ctx context.Context, walletID int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, listWalletAccounts, walletID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Account{}
+	for rows.Next() {
+		var i Account
+		if err := rows.Scan(
+			&i.ID,
+			&i.Owner,
+			&i.Balance,
+			&i.Currency,
+			&i.CreatedAt,
+			&i.WalletID,
+			&i.Type,
+			&i.ClosedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAccounts = `-- name: LockAccounts :many
+SELECT id, owner, balance, currency, created_at, wallet_id, type, closed_at FROM accounts
+WHERE id = ANY($1::bigint[])
+ORDER BY id
+FOR NO KEY UPDATE
+`
+
+// The rows are locked in the order of their ids, as every transaction writing several accounts must do
+func (q *Queries) LockAccounts(ctx context.Context, ids []int64) ([]Account, error) {
+	rows, err := q.db.QueryContext(ctx, lockAccounts, pq.Array(ids))
 	if err != nil {
 		return nil, err
 	}
@@ -138,6 +357,9 @@ func (q *Queries) ListAccounts(ctx context.Context, arg ListAccountsParams) ([]A
 			&i.Balance,
 			&i.Currency,
 			&i.CreatedAt,
+			&i.WalletID,
+			&i.Type,
+			&i.ClosedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -156,7 +378,7 @@ const updateAccount = `-- name: UpdateAccount :one
 UPDATE accounts
 SET balance = $2
 WHERE id = $1
-RETURNING id, owner, balance, currency, created_at
+RETURNING id, owner, balance, currency, created_at, wallet_id, type, closed_at
 `
 
 type UpdateAccountParams struct {
@@ -173,6 +395,9 @@ func (q *Queries) UpdateAccount(ctx context.Context, arg UpdateAccountParams) (A
 		&i.Balance,
 		&i.Currency,
 		&i.CreatedAt,
+		&i.WalletID,
+		&i.Type,
+		&i.ClosedAt,
 	)
 	return i, err
 }
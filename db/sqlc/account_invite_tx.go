@@ -0,0 +1,38 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+type AcceptAccountInviteTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Username is the invited user, who accepts
+	Username string `json:"username"`
+}
+
+// AcceptAccountInviteTx makes the invited user a member of the account in the role it was invited to.
+// The invite is deleted first, so it cannot be accepted twice, and sql.ErrNoRows is returned when there is none.
+func (store *SQLStore) AcceptAccountInviteTx(ctx context.Context, arg AcceptAccountInviteTxParams) (AccountMember, error) {
+	var member AccountMember
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		invite, err := q.DeleteAccountInvite(ctx, DeleteAccountInviteParams{
+			AccountID: arg.AccountID,
+			Username: arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		member, err = q.CreateAccountMember(ctx, CreateAccountMemberParams{
+			AccountID: invite.AccountID,
+			Username: invite.Username,
+			Role: invite.Role,
+			InvitedBy: sql.NullString{String: invite.InvitedBy, Valid: true},
+		})
+		return err
+	})
+
+	return member, err
+}
@@ -0,0 +1,77 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAcceptAccountInviteTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := CreateRandomAccount(t)
+	defer testQueries.DeleteAccount(context.Background(), account.ID)
+	user := createRandomUser(t)
+
+	invite, err := testQueries.CreateAccountInvite(context.Background(), CreateAccountInviteParams{
+		AccountID: account.ID,
+		Username: user.Username,
+		Role: ACCOUNT_ROLE_SPENDER,
+		InvitedBy: account.Owner,
+	})
+	require.NoError(t, err)
+	require.NotZero(t, invite.CreatedAt)
+
+	// the invite gives no access until it is accepted
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username: user.Username,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	invites, err := testQueries.ListUserAccountInvites(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, invites, 1)
+	require.Equal(t, account.ID, invites[0].AccountID)
+
+	member, err := store.AcceptAccountInviteTx(context.Background(), AcceptAccountInviteTxParams{
+		AccountID: account.ID,
+		Username: user.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ACCOUNT_ROLE_SPENDER, member.Role)
+	require.Equal(t, sql.NullString{String: account.Owner, Valid: true}, member.InvitedBy)
+
+	invites, err = testQueries.ListAccountInvites(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Empty(t, invites)
+
+	// an invite is accepted once
+	_, err = store.AcceptAccountInviteTx(context.Background(), AcceptAccountInviteTxParams{
+		AccountID: account.ID,
+		Username: user.Username,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestDeleteAccountInvitesByUser(t *testing.T) {
+	account := CreateRandomAccount(t)
+	defer testQueries.DeleteAccount(context.Background(), account.ID)
+	user := createRandomUser(t)
+
+	_, err := testQueries.CreateAccountInvite(context.Background(), CreateAccountInviteParams{
+		AccountID: account.ID,
+		Username: user.Username,
+		Role: ACCOUNT_ROLE_VIEWER,
+		InvitedBy: account.Owner,
+	})
+	require.NoError(t, err)
+
+	err = testQueries.DeleteAccountInvitesByUser(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	invites, err := testQueries.ListUserAccountInvites(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, invites)
+}
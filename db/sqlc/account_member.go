@@ -0,0 +1,11 @@
+package db
+
+// the roles of the members of an account
+const (
+	// ACCOUNT_ROLE_OWNER manages the members and spends, the holder of the account is always one
+	ACCOUNT_ROLE_OWNER = "owner"
+	// ACCOUNT_ROLE_SPENDER transfers from the account
+	ACCOUNT_ROLE_SPENDER = "spender"
+	// ACCOUNT_ROLE_VIEWER only reads the account
+	ACCOUNT_ROLE_VIEWER = "viewer"
+)
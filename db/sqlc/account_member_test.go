@@ -0,0 +1,115 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomAccountMember(t *testing.T, account Account, role string) AccountMember {
+	user := createRandomUser(t)
+
+	arg := CreateAccountMemberParams{
+		AccountID: account.ID,
+		Username: user.Username,
+		Role: role,
+		InvitedBy: sql.NullString{String: account.Owner, Valid: true},
+	}
+
+	member, err := testQueries.CreateAccountMember(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.AccountID, member.AccountID)
+	require.Equal(t, arg.Username, member.Username)
+	require.Equal(t, arg.Role, member.Role)
+	require.Equal(t, arg.InvitedBy, member.InvitedBy)
+	require.NotZero(t, member.CreatedAt)
+
+	return member
+}
+
+func TestCreateAccountAddsOwner(t *testing.T) {
+	account := CreateRandomAccount(t)
+	defer testQueries.DeleteAccount(context.Background(), account.ID)
+
+	member, err := testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username: account.Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ACCOUNT_ROLE_OWNER, member.Role)
+	require.False(t, member.InvitedBy.Valid)
+}
+
+func TestAccountMembers(t *testing.T) {
+	account := CreateRandomAccount(t)
+	defer testQueries.DeleteAccount(context.Background(), account.ID)
+
+	spender := createRandomAccountMember(t, account, ACCOUNT_ROLE_SPENDER)
+	viewer := createRandomAccountMember(t, account, ACCOUNT_ROLE_VIEWER)
+
+	_, err := testQueries.CreateAccountMember(context.Background(), CreateAccountMemberParams{
+		AccountID: account.ID,
+		Username: viewer.Username,
+		Role: ACCOUNT_ROLE_OWNER,
+	})
+	require.Error(t, err)
+
+	members, err := testQueries.ListAccountMembers(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Len(t, members, 3)
+
+	// the shared account is listed to its members
+	accounts, err := testQueries.ListAccounts(context.Background(), ListAccountsParams{
+		Username: spender.Username,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, accounts, 1)
+	require.Equal(t, account.ID, accounts[0].ID)
+	require.Equal(t, account.Owner, accounts[0].Owner)
+
+	deleted, err := testQueries.DeleteAccountMember(context.Background(), DeleteAccountMemberParams{
+		AccountID: account.ID,
+		Username: spender.Username,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ACCOUNT_ROLE_SPENDER, deleted.Role)
+
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: account.ID,
+		Username: spender.Username,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestDeleteAccountMembershipsByUser(t *testing.T) {
+	shared := CreateRandomAccount(t)
+	defer testQueries.DeleteAccount(context.Background(), shared.ID)
+	member := createRandomAccountMember(t, shared, ACCOUNT_ROLE_SPENDER)
+
+	own, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: member.Username,
+		Currency: shared.Currency,
+		Type: ACCOUNT_CHECKING,
+	})
+	require.NoError(t, err)
+	defer testQueries.DeleteAccount(context.Background(), own.ID)
+
+	err = testQueries.DeleteAccountMembershipsByUser(context.Background(), member.Username)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: shared.ID,
+		Username: member.Username,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// the user stays the owner of the account it holds
+	_, err = testQueries.GetAccountMember(context.Background(), GetAccountMemberParams{
+		AccountID: own.ID,
+		Username: member.Username,
+	})
+	require.NoError(t, err)
+}
@@ -16,6 +16,7 @@ func CreateRandomAccount(t *testing.T) Account {
 		Owner: user.Username,
 		Balance: util.RandomMoney(),
 		Currency: util.RandomCurrency(),
+		Type: ACCOUNT_CHECKING,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -26,8 +27,10 @@ func CreateRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Type, account.Type)
 
 	require.NotZero(t, account.ID)
+	require.NotZero(t, account.WalletID)
 	require.NotZero(t, account.CreatedAt)
 
 	return account
@@ -74,7 +77,7 @@ func TestListAccounts(t *testing.T) {
 	}
 
 	arg := ListAccountsParams{
-		Owner: testAccount.Owner,
+		Username: testAccount.Owner,
 		Limit: 5,
 		Offset: 0,
 	}
@@ -109,3 +112,42 @@ func TestUpdateAccount(t *testing.T) {
 	require.Equal(t, accountCreated.Currency, accountUpdated.Currency)
 
 }
+
+func TestGetOwnerAccountChecking(t *testing.T) {
+	// the savings account comes first, the lookup must not depend on the order of the rows
+	savings, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: createRandomUser(t).Username,
+		Currency: util.USD,
+		Type: ACCOUNT_SAVINGS,
+	})
+	require.NoError(t, err)
+
+	checking, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: savings.Owner,
+		Currency: util.USD,
+		Type: ACCOUNT_CHECKING,
+	})
+	require.NoError(t, err)
+
+	// one account of each type per currency
+	_, err = testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: savings.Owner,
+		Currency: util.USD,
+		Type: ACCOUNT_SAVINGS,
+	})
+	require.Error(t, err)
+
+	account, err := testQueries.GetOwnerAccount(context.Background(), GetOwnerAccountParams{
+		Owner: savings.Owner,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, checking.ID, account.ID)
+
+	account, err = testQueries.GetWalletAccount(context.Background(), GetWalletAccountParams{
+		WalletID: savings.WalletID,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, checking.ID, account.ID)
+}
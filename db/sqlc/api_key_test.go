@@ -0,0 +1,84 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomApiKey(t *testing.T, user User) ApiKey {
+	secret, err := util.RandomSecret(32)
+	require.NoError(t, err)
+
+	arg := CreateApiKeyParams{
+		Username: user.Username,
+		Name: util.RandomString(8),
+		KeyHash: util.HashSecret(secret),
+		Scopes: []string{"accounts:read", "transfers:write"},
+	}
+
+	apiKey, err := testQueries.CreateApiKey(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, apiKey)
+
+	require.Equal(t, arg.Username, apiKey.Username)
+	require.Equal(t, arg.Name, apiKey.Name)
+	require.Equal(t, arg.KeyHash, apiKey.KeyHash)
+	require.Equal(t, arg.Scopes, apiKey.Scopes)
+	require.False(t, apiKey.IsRevoked)
+	require.NotZero(t, apiKey.ID)
+	require.NotZero(t, apiKey.CreatedAt)
+
+	return apiKey
+}
+
+func TestCreateApiKey(t *testing.T) {
+	createRandomApiKey(t, createRandomUser(t))
+}
+
+func TestGetApiKeyByHash(t *testing.T) {
+	apiKey1 := createRandomApiKey(t, createRandomUser(t))
+
+	apiKey2, err := testQueries.GetApiKeyByHash(context.Background(), apiKey1.KeyHash)
+	require.NoError(t, err)
+	require.Equal(t, apiKey1, apiKey2)
+}
+
+func TestListApiKeysByUser(t *testing.T) {
+	user := createRandomUser(t)
+	for i := 0; i < 3; i++ {
+		createRandomApiKey(t, user)
+	}
+
+	apiKeys, err := testQueries.ListApiKeysByUser(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, apiKeys, 3)
+
+	for _, apiKey := range apiKeys {
+		require.Equal(t, user.Username, apiKey.Username)
+	}
+}
+
+func TestRevokeApiKey(t *testing.T) {
+	apiKey := createRandomApiKey(t, createRandomUser(t))
+
+	// only the owner can revoke a key
+	_, err := testQueries.RevokeApiKey(context.Background(), RevokeApiKeyParams{
+		ID: apiKey.ID,
+		Username: createRandomUser(t).Username,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	revokedKey, err := testQueries.RevokeApiKey(context.Background(), RevokeApiKeyParams{
+		ID: apiKey.ID,
+		Username: apiKey.Username,
+	})
+	require.NoError(t, err)
+	require.True(t, revokedKey.IsRevoked)
+
+	_, err = testQueries.GetApiKeyByHash(context.Background(), apiKey.KeyHash)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
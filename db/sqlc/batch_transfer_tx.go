@@ -0,0 +1,276 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrCurrencyMismatch is returned when an account of a transfer is not in the currency of the transfer
+	ErrCurrencyMismatch = errors.New("the currency of the account differs from the currency of the transfer")
+	// ErrSameAccount is returned when an account would transfer to itself
+	ErrSameAccount = errors.New("an account cannot transfer to itself")
+)
+
+// BatchItemError tells which transfer made an atomic batch fail
+type BatchItemError struct {
+	Index int
+	Err error
+}
+
+func (e *BatchItemError) Error() string {
+	return fmt.Sprintf("transfer %d of the batch: %v", e.Index, e.Err)
+}
+
+func (e *BatchItemError) Unwrap() error {
+	return e.Err
+}
+
+type BatchTransferItem struct {
+	ToAccountID int64 `json:"to_account_id"`
+	Amount int64 `json:"amount"`
+	// HoldReasons are the fraud rules which flagged the item, a flagged item is held for review instead of made
+	HoldReasons []string `json:"hold_reasons"`
+}
+
+type BatchTransferTxParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	Currency string `json:"currency"`
+	Items []BatchTransferItem `json:"items"`
+	// BestEffort makes the transfers which can be made and reports why the others failed,
+	// otherwise the first failure rolls the whole batch back
+	BestEffort bool `json:"best_effort"`
+	// RequestedBy is the user making the batch, whose limits it counts against and whom the staff reviewing
+	// the held items see as the one who asked for them
+	RequestedBy string `json:"requested_by"`
+}
+
+// BatchTransferItemResult has either the transfer, the held transfer or the reason it was not made
+type BatchTransferItemResult struct {
+	Transfer Transfer `json:"transfer"`
+	HeldTransfer HeldTransfer `json:"held_transfer"`
+	Held bool `json:"held"`
+	Err error `json:"-"`
+}
+
+type BatchTransferTxResult struct {
+	FromAccount Account `json:"from_account"`
+	// Items are in the order of the request
+	Items []BatchTransferItemResult `json:"items"`
+	Completed int `json:"completed"`
+	Held int `json:"held"`
+}
+
+// BatchTransferTx pays several accounts from one account in a single transaction.
+// Every account of the batch is locked up front in the order of the ids, the order TransferMoney writes accounts in,
+// so a batch cannot deadlock with transfers between the same accounts. The funds are then allocated to the items
+// in the order of the request and every balance is written once, the source account with the total of the batch.
+// Every item counts against the transfer limits of the user making the batch and those of the source account, as a separate transfer would,
+// and is charged the fee of a batch item on top of its amount. The revenue account is credited the fees last.
+// The items with hold reasons are only checked against their accounts and held for review, they move no money.
+func (store *SQLStore) BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error) {
+	var result BatchTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		ids := []int64{arg.FromAccountID}
+		for _, item := range arg.Items {
+			ids = append(ids, item.ToAccountID)
+		}
+
+		accounts, err := q.LockAccounts(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		lockedAccounts := make(map[int64]Account, len(accounts))
+		for _, account := range accounts {
+			lockedAccounts[account.ID] = account
+		}
+
+		fromAccount, found := lockedAccounts[arg.FromAccountID]
+		if !found {
+			return sql.ErrNoRows
+		}
+		if fromAccount.Currency != arg.Currency {
+			return ErrCurrencyMismatch
+		}
+		if fromAccount.ClosedAt.Valid {
+			return ErrAccountClosed
+		}
+
+		allowance, err := lockTransferAllowance(ctx, q, arg.RequestedBy, fromAccount)
+		if err != nil {
+			return err
+		}
+
+		balance, err := availableBalance(ctx, q, fromAccount)
+		if err != nil {
+			return err
+		}
+
+		schedule, charged, err := findFeeSchedule(ctx, q, TRANSFER_TYPE_BATCH, arg.Currency)
+		if err != nil {
+			return err
+		}
+
+		var revenue Account
+		if charged {
+			revenue, err = bankAccount(ctx, q, BANK_FEE_REVENUE, arg.Currency)
+			if err != nil {
+				return err
+			}
+		}
+
+		var debit, fees int64
+		credits := make(map[int64]int64)
+		result.Items = make([]BatchTransferItemResult, len(arg.Items))
+
+		for i, item := range arg.Items {
+			err := checkBatchItem(lockedAccounts, arg, item)
+			if err == nil && len(item.HoldReasons) > 0 {
+				result.Items[i].HeldTransfer, err = q.CreateHeldTransfer(ctx, CreateHeldTransferParams{
+					FromAccountID: arg.FromAccountID,
+					ToAccountID: item.ToAccountID,
+					Amount: item.Amount,
+					Currency: arg.Currency,
+					RequestedBy: arg.RequestedBy,
+					Reasons: item.HoldReasons,
+					Metadata: transferMetadata(nil),
+				})
+				if err != nil {
+					return err
+				}
+
+				result.Items[i].Held = true
+				result.Held++
+				continue
+			}
+
+			var fee int64
+			if err == nil && charged {
+				fee, err = schedule.Fee(item.Amount)
+			}
+			if err == nil && item.Amount+fee > balance {
+				err = ErrInsufficientFunds
+			}
+			if err == nil {
+				err = allowance.check(item.Amount)
+			}
+			if err != nil {
+				if !arg.BestEffort {
+					return &BatchItemError{Index: i, Err: err}
+				}
+				result.Items[i].Err = err
+				continue
+			}
+
+			result.Items[i].Transfer, err = createTransferEntries(ctx, q, CreateTransferParams{
+				FromAccountID: arg.FromAccountID,
+				ToAccountID: item.ToAccountID,
+				Amount: item.Amount,
+				Fee: fee,
+				RequestedBy: sql.NullString{String: arg.RequestedBy, Valid: true},
+			}, revenue.ID)
+			if err != nil {
+				return err
+			}
+
+			balance -= item.Amount + fee
+			debit += item.Amount + fee
+			fees += fee
+			credits[item.ToAccountID] += item.Amount
+			allowance.use(item.Amount)
+			result.Completed++
+		}
+
+		if err := allowance.save(ctx, q); err != nil {
+			return err
+		}
+
+		result.FromAccount = fromAccount
+		credits[arg.FromAccountID] = -debit
+
+		for _, account := range accounts {
+			amount := credits[account.ID]
+			if amount == 0 {
+				continue
+			}
+
+			account, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+				ID: account.ID,
+				Amount: amount,
+			})
+			if err != nil {
+				return err
+			}
+
+			if account.ID == arg.FromAccountID {
+				result.FromAccount = account
+			}
+		}
+
+		if fees == 0 {
+			return nil
+		}
+
+		_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID: revenue.ID,
+			Amount: fees,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// checkBatchItem tells why the recipient of an item cannot be paid, the funds are checked by the caller
+// against what is left of the available balance once the item is to be made
+func checkBatchItem(lockedAccounts map[int64]Account, arg BatchTransferTxParams, item BatchTransferItem) error {
+	toAccount, found := lockedAccounts[item.ToAccountID]
+	switch {
+	case !found:
+		return sql.ErrNoRows
+	case toAccount.ID == arg.FromAccountID:
+		return ErrSameAccount
+	case toAccount.Currency != arg.Currency:
+		return ErrCurrencyMismatch
+	case toAccount.ClosedAt.Valid:
+		return ErrAccountClosed
+	}
+	return nil
+}
+
+// createTransferEntries records a transfer and its entries, along with the entries of its fee paid to
+// revenueAccountID, the balances are left to the caller
+func createTransferEntries(ctx context.Context, q *Queries, arg CreateTransferParams, revenueAccountID int64) (Transfer, error) {
+	arg.Metadata = transferMetadata(arg.Metadata)
+	transfer, err := q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return transfer, err
+	}
+
+	transferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+
+	_, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.FromAccountID,
+		Amount: -arg.Amount,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return transfer, err
+	}
+
+	_, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		TransferID: transferID,
+	})
+	if err != nil || arg.Fee == 0 {
+		return transfer, err
+	}
+
+	_, err = createFeeEntries(ctx, q, transfer, revenueAccountID)
+	return transfer, err
+}
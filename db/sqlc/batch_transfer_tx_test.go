@@ -0,0 +1,217 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createBatchAccounts funds accounts in one currency, the first one pays the others
+func createBatchAccounts(t *testing.T, n int) []Account {
+	accounts := make([]Account, n)
+	for i := range accounts {
+		user := createRandomUser(t)
+		accounts[i] = createWalletAccount(t, user.Username, util.USD, 1000)
+	}
+	return accounts
+}
+
+func TestBatchTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 3)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{
+			{ToAccountID: accounts[2].ID, Amount: 300},
+			{ToAccountID: accounts[1].ID, Amount: 200},
+			{ToAccountID: accounts[2].ID, Amount: 100},
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, 3, result.Completed)
+	require.Equal(t, int64(400), result.FromAccount.Balance)
+
+	for _, item := range result.Items {
+		require.NoError(t, item.Err)
+		require.NotZero(t, item.Transfer.ID)
+		require.Equal(t, accounts[0].ID, item.Transfer.FromAccountID)
+	}
+
+	// the source account has an entry per transfer even though its balance is written once
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{
+		AccountID: accounts[0].ID,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	for i, entry := range entries {
+		require.Equal(t, -result.Items[i].Transfer.Amount, entry.Amount)
+		require.Equal(t, result.Items[i].Transfer.ID, entry.TransferID.Int64)
+	}
+
+	account1, err := testQueries.GetAccount(context.Background(), accounts[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1200), account1.Balance)
+
+	account2, err := testQueries.GetAccount(context.Background(), accounts[2].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1400), account2.Balance)
+}
+
+func TestBatchTransferTxAtomic(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 3)
+
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{
+			{ToAccountID: accounts[1].ID, Amount: 600},
+			{ToAccountID: accounts[2].ID, Amount: 600},
+		},
+	})
+
+	var itemErr *BatchItemError
+	require.ErrorAs(t, err, &itemErr)
+	require.Equal(t, 1, itemErr.Index)
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the first transfer is rolled back along with the second
+	account0, err := testQueries.GetAccount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account0.Balance)
+
+	account1, err := testQueries.GetAccount(context.Background(), accounts[1].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account1.Balance)
+}
+
+func TestBatchTransferTxBestEffort(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 3)
+	user := createRandomUser(t)
+	eur := createWalletAccount(t, user.Username, util.EUR, 0)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{
+			{ToAccountID: accounts[1].ID, Amount: 600},
+			{ToAccountID: accounts[2].ID, Amount: 600},
+			{ToAccountID: eur.ID, Amount: 100},
+			{ToAccountID: accounts[0].ID, Amount: 100},
+			{ToAccountID: accounts[2].ID, Amount: 400},
+		},
+		BestEffort: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Completed)
+	require.Equal(t, int64(0), result.FromAccount.Balance)
+
+	require.NoError(t, result.Items[0].Err)
+	require.ErrorIs(t, result.Items[1].Err, ErrInsufficientFunds)
+	require.ErrorIs(t, result.Items[2].Err, ErrCurrencyMismatch)
+	require.ErrorIs(t, result.Items[3].Err, ErrSameAccount)
+	require.NoError(t, result.Items[4].Err)
+	require.Zero(t, result.Items[1].Transfer.ID)
+}
+
+func TestBatchTransferTxHeldItem(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 3)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{
+			{ToAccountID: accounts[1].ID, Amount: 300},
+			{ToAccountID: accounts[2].ID, Amount: 5000, HoldReasons: []string{"large transfer"}},
+		},
+		RequestedBy: accounts[0].Owner,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, result.Completed)
+	require.Equal(t, 1, result.Held)
+	require.Equal(t, int64(700), result.FromAccount.Balance)
+
+	// the held item moves no money, so it is not checked against the balance
+	held := result.Items[1]
+	require.NoError(t, held.Err)
+	require.True(t, held.Held)
+	require.Zero(t, held.Transfer.ID)
+	require.Equal(t, HELD_TRANSFER_PENDING, held.HeldTransfer.Status)
+	require.Equal(t, accounts[0].Owner, held.HeldTransfer.RequestedBy)
+	require.Equal(t, []string{"large transfer"}, held.HeldTransfer.Reasons)
+
+	account2, err := testQueries.GetAccount(context.Background(), accounts[2].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account2.Balance)
+}
+
+func TestBatchTransferTxUnknownAccount(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 1)
+
+	_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{{ToAccountID: accounts[0].ID + 1000000, Amount: 1}},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+
+	_, err = store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.EUR,
+		Items: []BatchTransferItem{{ToAccountID: accounts[0].ID, Amount: 1}},
+	})
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+}
+
+// TestBatchTransferTxDeadlock runs batches against transfers in the opposite direction, which lock the same accounts
+func TestBatchTransferTxDeadlock(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 4)
+
+	n := 10
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+				FromAccountID: accounts[1].ID,
+				Currency: util.USD,
+				Items: []BatchTransferItem{
+					{ToAccountID: accounts[3].ID, Amount: 10},
+					{ToAccountID: accounts[0].ID, Amount: 10},
+					{ToAccountID: accounts[2].ID, Amount: 10},
+				},
+			})
+			errs <- err
+		}()
+
+		go func(i int) {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: accounts[i%3+1].ID,
+				ToAccountID: accounts[0].ID,
+				Amount: 10,
+			})
+			errs <- err
+		}(i)
+	}
+
+	for i := 0; i < 2*n; i++ {
+		require.NoError(t, <-errs)
+	}
+
+	account1, err := testQueries.GetAccount(context.Background(), accounts[1].ID)
+	require.NoError(t, err)
+
+	// account 1 pays 30 per batch and 10 in every third transfer
+	transfersFrom1 := int64((n + 2) / 3)
+	require.Equal(t, 1000-int64(n)*30-transfersFrom1*10, account1.Balance)
+}
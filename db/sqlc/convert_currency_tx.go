@@ -0,0 +1,175 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/sssaang/simplebank/db/util"
+)
+
+var (
+	// ErrNoExchangeRate is returned when no rate is set between the currencies of a conversion
+	ErrNoExchangeRate = errors.New("no exchange rate is set between the currencies")
+	// ErrConversionTooSmall is returned when the converted amount rounds down to zero
+	ErrConversionTooSmall = errors.New("the amount is too small to be converted")
+)
+
+type ConvertCurrencyTxParams struct {
+	WalletID int64 `json:"wallet_id"`
+	FromCurrency string `json:"from_currency"`
+	ToCurrency string `json:"to_currency"`
+	Amount int64 `json:"amount"`
+}
+
+type ConvertCurrencyTxResult struct {
+	Conversion Conversion `json:"conversion"`
+	FromAccount Account `json:"from_account"`
+	ToAccount Account `json:"to_account"`
+	FromEntry Entry `json:"from_entry"`
+	ToEntry Entry `json:"to_entry"`
+}
+
+// ConvertCurrencyTx moves an amount between two balances of a wallet at the current exchange rate.
+// The balance in the target currency is opened when the wallet has none yet.
+// A wallet without a balance in the source currency has insufficient funds.
+func (store *SQLStore) ConvertCurrencyTx(ctx context.Context, arg ConvertCurrencyTxParams) (ConvertCurrencyTxResult, error) {
+	var result ConvertCurrencyTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		fromAccount, err := q.GetWalletAccount(ctx, GetWalletAccountParams{
+			WalletID: arg.WalletID,
+			Currency: arg.FromCurrency,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInsufficientFunds
+		}
+		if err != nil {
+			return err
+		}
+
+		rate, err := q.GetExchangeRate(ctx, GetExchangeRateParams{
+			FromCurrency: arg.FromCurrency,
+			ToCurrency: arg.ToCurrency,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrNoExchangeRate
+		}
+		if err != nil {
+			return err
+		}
+
+		converted, err := util.NewMoney(arg.Amount, arg.FromCurrency).Convert(arg.ToCurrency, rate.Rate)
+		if err != nil {
+			return err
+		}
+		if !converted.IsPositive() {
+			return ErrConversionTooSmall
+		}
+
+		toAccount, err := q.GetWalletAccount(ctx, GetWalletAccountParams{
+			WalletID: arg.WalletID,
+			Currency: arg.ToCurrency,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			toAccount, err = q.CreateAccount(ctx, CreateAccountParams{
+				Owner: fromAccount.Owner,
+				Balance: 0,
+				Currency: arg.ToCurrency,
+				Type: ACCOUNT_CHECKING,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		result.Conversion, err = q.CreateConversion(ctx, CreateConversionParams{
+			WalletID: arg.WalletID,
+			FromAccountID: fromAccount.ID,
+			ToAccountID: toAccount.ID,
+			FromAmount: arg.Amount,
+			ToAmount: converted.Amount(),
+			Rate: rate.Rate,
+		})
+		if err != nil {
+			return err
+		}
+
+		conversionID := sql.NullInt64{Int64: result.Conversion.ID, Valid: true}
+
+		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: fromAccount.ID,
+			Amount: -arg.Amount,
+			ConversionID: conversionID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: toAccount.ID,
+			Amount: converted.Amount(),
+			ConversionID: conversionID,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, result.ToAccount, err = exchangeMoney(ctx, q, fromAccount.ID, arg.Amount, toAccount.ID, converted.Amount())
+		if err != nil {
+			return err
+		}
+
+		// as in TransferTx, the balance is checked once the row lock is held
+		available, err := availableBalance(ctx, q, result.FromAccount)
+		if err != nil {
+			return err
+		}
+		if available < 0 {
+			return ErrInsufficientFunds
+		}
+
+		return nil
+	})
+
+	return result, err
+}
+
+// exchangeMoney debits and credits amounts which differ since they are in different currencies,
+// the accounts are updated in the order of their ids like in TransferMoney to avoid deadlocks
+func exchangeMoney(
+	ctx context.Context,
+	q *Queries,
+	fromAccountID int64,
+	fromAmount int64,
+	toAccountID int64,
+	toAmount int64,
+) (fromAccount Account, toAccount Account, err error) {
+	debit := func() error {
+		fromAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID: fromAccountID,
+			Amount: -fromAmount,
+		})
+		return err
+	}
+
+	credit := func() error {
+		toAccount, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID: toAccountID,
+			Amount: toAmount,
+		})
+		return err
+	}
+
+	if fromAccountID < toAccountID {
+		if err = debit(); err == nil {
+			err = credit()
+		}
+	} else {
+		if err = credit(); err == nil {
+			err = debit()
+		}
+	}
+
+	return
+}
@@ -0,0 +1,51 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type CreateUserTxParams struct {
+	CreateUserParams
+	SecretCodeHash string `json:"secret_code_hash"`
+	VerifyEmailExpiredAt time.Time `json:"verify_email_expired_at"`
+	// AfterCreate is called before the transaction commits, an error rolls back the new user
+	AfterCreate func(user User, verifyEmail VerifyEmail) error `json:"-"`
+}
+
+type CreateUserTxResult struct {
+	User User `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// CreateUserTx creates a user together with the verification of their email.
+// The verification code is delivered by AfterCreate so that a user is never left without a way to verify.
+func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error) {
+	var result CreateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.CreateUser(ctx, arg.CreateUserParams)
+		if err != nil {
+			return err
+		}
+
+		result.VerifyEmail, err = q.CreateVerifyEmail(ctx, CreateVerifyEmailParams{
+			Username: result.User.Username,
+			Email: result.User.Email,
+			SecretCodeHash: arg.SecretCodeHash,
+			ExpiredAt: arg.VerifyEmailExpiredAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterCreate == nil {
+			return nil
+		}
+		return arg.AfterCreate(result.User, result.VerifyEmail)
+	})
+
+	return result, err
+}
@@ -0,0 +1,31 @@
+package db
+
+import (
+	"context"
+
+	"github.com/sssaang/simplebank/db/util"
+)
+
+func NewRegistryCurrency(currency Currency) util.Currency {
+	return util.Currency{
+		Code: currency.Code,
+		MinorUnits: int(currency.MinorUnits),
+		IsEnabled: currency.IsEnabled,
+	}
+}
+
+// LoadCurrencies replaces the cached currencies of the registry with the currencies table
+func LoadCurrencies(ctx context.Context, q Querier, registry *util.CurrencyRegistry) error {
+	currencies, err := q.ListCurrencies(ctx)
+	if err != nil {
+		return err
+	}
+
+	cached := make([]util.Currency, len(currencies))
+	for i, currency := range currencies {
+		cached[i] = NewRegistryCurrency(currency)
+	}
+
+	registry.Load(cached)
+	return nil
+}
@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+// currencyTestQueries runs the test in a transaction that is rolled back,
+// so that the random currencies never become available to the application
+func currencyTestQueries(t *testing.T) *Queries {
+	tx, err := testDB.BeginTx(context.Background(), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		tx.Rollback()
+	})
+
+	return New(tx)
+}
+
+func createRandomCurrency(t *testing.T, q *Queries) Currency {
+	arg := CreateCurrencyParams{
+		Code: strings.ToUpper(util.RandomString(3)),
+		MinorUnits: int32(util.RandomInt(0, 4)),
+		IsEnabled: true,
+	}
+
+	// a random code may already be seeded, USD for instance
+	if _, err := q.GetCurrency(context.Background(), arg.Code); err == nil {
+		return createRandomCurrency(t, q)
+	}
+
+	currency, err := q.CreateCurrency(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.Code, currency.Code)
+	require.Equal(t, arg.MinorUnits, currency.MinorUnits)
+	require.True(t, currency.IsEnabled)
+	require.NotZero(t, currency.CreatedAt)
+
+	return currency
+}
+
+func TestCreateCurrency(t *testing.T) {
+	createRandomCurrency(t, currencyTestQueries(t))
+}
+
+func TestCreateCurrencyInvalidCode(t *testing.T) {
+	q := currencyTestQueries(t)
+
+	_, err := q.CreateCurrency(context.Background(), CreateCurrencyParams{Code: "usd", MinorUnits: 2})
+	require.Error(t, err)
+}
+
+func TestUpdateCurrency(t *testing.T) {
+	q := currencyTestQueries(t)
+	currency1 := createRandomCurrency(t, q)
+
+	currency2, err := q.UpdateCurrency(context.Background(), UpdateCurrencyParams{Code: currency1.Code, IsEnabled: false})
+	require.NoError(t, err)
+	require.Equal(t, currency1.Code, currency2.Code)
+	require.Equal(t, currency1.MinorUnits, currency2.MinorUnits)
+	require.False(t, currency2.IsEnabled)
+}
+
+func TestLoadCurrencies(t *testing.T) {
+	q := currencyTestQueries(t)
+	currency := createRandomCurrency(t, q)
+
+	registry := util.NewCurrencyRegistry(nil)
+	err := LoadCurrencies(context.Background(), q, registry)
+	require.NoError(t, err)
+
+	cached, ok := registry.Lookup(currency.Code)
+	require.True(t, ok)
+	require.Equal(t, NewRegistryCurrency(currency), cached)
+
+	for _, code := range []string{util.USD, util.EUR, util.KRW} {
+		_, ok := registry.Lookup(code)
+		require.True(t, ok, code)
+	}
+}
@@ -0,0 +1,111 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const ERASED_USER_FULL_NAME = "erased user"
+
+var ErrAccountsNotEmpty = errors.New("all accounts of the user must be emptied before erasure")
+
+type EraseUserTxParams struct {
+	Username string `json:"username"`
+	Pseudonym string `json:"pseudonym"`
+}
+
+// EraseUserTx pseudonymizes the personal data of a user.
+// Accounts, entries and transfers are kept for the ledger and follow the user to its pseudonym, the accounts are closed
+// so that nothing can be credited to them anymore, while pending password resets and email verifications, which hold the email, and the credentials of the user are deleted.
+// The user leaves the joint accounts held by others, its pending invites are dropped and its payees, named by the user, are deleted.
+// The statement snapshots of other accounts do not store its name, they show the pseudonymized one from now on.
+func (store *SQLStore) EraseUserTx(ctx context.Context, arg EraseUserTxParams) (ErasureRequest, error) {
+	var erasure ErasureRequest
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accounts, err := q.ListAccountsByOwner(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		ids := make([]int64, len(accounts))
+		for i, account := range accounts {
+			ids[i] = account.ID
+		}
+
+		// the balances are read under the row locks, a transfer cannot credit an account between the check and the closing
+		accounts, err = q.LockAccounts(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		for _, account := range accounts {
+			if account.Balance != 0 {
+				return ErrAccountsNotEmpty
+			}
+		}
+
+		err = q.CloseAccountsByOwner(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeletePasswordResetsByUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteVerifyEmailsByUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteApiKeysByUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteOauthAuthorizationCodesByUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteOauthRefreshTokensByUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccountMembershipsByUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeleteAccountInvitesByUser(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		err = q.DeletePayeesByOwner(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.PseudonymizeUser(ctx, PseudonymizeUserParams{
+			Pseudonym: arg.Pseudonym,
+			// no bcrypt hash matches an empty string, the user can never log in again
+			HashedPassword: "",
+			FullName: ERASED_USER_FULL_NAME,
+			Email: fmt.Sprintf("%s@erased.invalid", arg.Pseudonym),
+			Username: arg.Username,
+		})
+		if err != nil {
+			return err
+		}
+
+		erasure, err = q.CreateErasureRequest(ctx, arg.Pseudonym)
+		return err
+	})
+
+	return erasure, err
+}
@@ -0,0 +1,48 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+// UserDataExport holds everything the bank stores about a user
+type UserDataExport struct {
+	User User `json:"user"`
+	Accounts []Account `json:"accounts"`
+	Entries []Entry `json:"entries"`
+	Transfers []Transfer `json:"transfers"`
+}
+
+// ExportUserDataTx reads the data of a user from a single snapshot so that balances, entries and transfers agree
+func (store *SQLStore) ExportUserDataTx(ctx context.Context, username string) (UserDataExport, error) {
+	var export UserDataExport
+
+	opts := &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly: true,
+	}
+
+	err := store.execTxWithOptions(ctx, opts, func(q *Queries) error {
+		var err error
+
+		export.User, err = q.GetUser(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		export.Accounts, err = q.ListAccountsByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		export.Entries, err = q.ListEntriesByOwner(ctx, username)
+		if err != nil {
+			return err
+		}
+
+		export.Transfers, err = q.ListTransfersByOwner(ctx, username)
+		return err
+	})
+
+	return export, err
+}
@@ -0,0 +1,113 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	"github.com/sssaang/simplebank/db/util"
+)
+
+// the operations a transfer is made by, each has its own fee schedules
+const (
+	TRANSFER_TYPE_TRANSFER = "transfer"
+	TRANSFER_TYPE_BATCH = "batch"
+	TRANSFER_TYPE_CAPTURE = "capture"
+)
+
+// BANK_FEE_REVENUE is the system user whose accounts collect the fees, one per currency
+const BANK_FEE_REVENUE = "bank:fee_revenue"
+
+// Fee is the fee of a transfer of amount under the schedule, the tier fee raised to the min fee and capped at the max fee
+func (schedule FeeSchedule) Fee(amount int64) (int64, error) {
+	var tiers []util.FeeTier
+	if err := json.Unmarshal(schedule.Tiers, &tiers); err != nil {
+		return 0, err
+	}
+
+	fee, err := util.Fee(tiers, amount)
+	if err != nil {
+		return 0, err
+	}
+
+	if schedule.MinFee.Valid && fee < schedule.MinFee.Int64 {
+		fee = schedule.MinFee.Int64
+	}
+	if schedule.MaxFee.Valid && fee > schedule.MaxFee.Int64 {
+		fee = schedule.MaxFee.Int64
+	}
+	return fee, nil
+}
+
+// findFeeSchedule tells whether the transfers of the type in the currency are charged a fee, and by which schedule
+func findFeeSchedule(ctx context.Context, q *Queries, transferType string, currency string) (FeeSchedule, bool, error) {
+	schedule, err := q.GetFeeSchedule(ctx, GetFeeScheduleParams{
+		TransferType: transferType,
+		Currency: currency,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return schedule, false, nil
+	}
+	return schedule, err == nil, err
+}
+
+// transferFee is the fee of a transfer of the type, 0 when no schedule applies to it
+func transferFee(ctx context.Context, q *Queries, transferType string, currency string, amount int64) (int64, error) {
+	schedule, found, err := findFeeSchedule(ctx, q, transferType, currency)
+	if !found {
+		return 0, err
+	}
+	return schedule.Fee(amount)
+}
+
+// createFeeEntries records the fee of a transfer on the paying account and on the revenue account,
+// the balances are left to the caller. The entry of the paying account is returned.
+func createFeeEntries(ctx context.Context, q *Queries, transfer Transfer, revenueAccountID int64) (Entry, error) {
+	feeTransferID := sql.NullInt64{Int64: transfer.ID, Valid: true}
+
+	entry, err := q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: transfer.FromAccountID,
+		Amount: -transfer.Fee,
+		FeeTransferID: feeTransferID,
+	})
+	if err != nil {
+		return entry, err
+	}
+
+	_, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: revenueAccountID,
+		Amount: transfer.Fee,
+		FeeTransferID: feeTransferID,
+	})
+	return entry, err
+}
+
+// chargeFee moves the fee of a transfer from its source account to the revenue account of the currency.
+// It is called last, once the accounts and the transfer usage are locked, so that the revenue account is the last
+// row every transaction locks and transfers cannot deadlock on it.
+func chargeFee(ctx context.Context, q *Queries, transfer Transfer, currency string) (Account, Entry, error) {
+	revenue, err := bankAccount(ctx, q, BANK_FEE_REVENUE, currency)
+	if err != nil {
+		return Account{}, Entry{}, err
+	}
+
+	entry, err := createFeeEntries(ctx, q, transfer, revenue.ID)
+	if err != nil {
+		return Account{}, entry, err
+	}
+
+	fromAccount, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID: transfer.FromAccountID,
+		Amount: -transfer.Fee,
+	})
+	if err != nil {
+		return fromAccount, entry, err
+	}
+
+	_, err = q.AddAccountBalance(ctx, AddAccountBalanceParams{
+		ID: revenue.ID,
+		Amount: transfer.Fee,
+	})
+	return fromAccount, entry, err
+}
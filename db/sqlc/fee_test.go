@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+// setFeeSchedule charges the fees for the rest of the test, the schedule applies to every USD transfer of its type
+func setFeeSchedule(t *testing.T, transferType string, tiers []util.FeeTier, minFee sql.NullInt64, maxFee sql.NullInt64) FeeSchedule {
+	data, err := json.Marshal(tiers)
+	require.NoError(t, err)
+
+	schedule, err := testQueries.UpsertFeeSchedule(context.Background(), UpsertFeeScheduleParams{
+		TransferType: transferType,
+		Currency: util.USD,
+		Tiers: data,
+		MinFee: minFee,
+		MaxFee: maxFee,
+	})
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		testQueries.DeleteFeeSchedule(context.Background(), DeleteFeeScheduleParams{
+			TransferType: transferType,
+			Currency: util.USD,
+		})
+	})
+	return schedule
+}
+
+// revenueBalance is the balance of the USD fee revenue account, 0 until the first fee opens it
+func revenueBalance(t *testing.T) int64 {
+	account, err := testQueries.GetOwnerAccount(context.Background(), GetOwnerAccountParams{
+		Owner: BANK_FEE_REVENUE,
+		Currency: util.USD,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0
+	}
+	require.NoError(t, err)
+	return account.Balance
+}
+
+func TestFeeScheduleFee(t *testing.T) {
+	schedule := setFeeSchedule(t, TRANSFER_TYPE_TRANSFER, []util.FeeTier{
+		{MinAmount: 0, Rate: "0.01"},
+		{MinAmount: 10000, Flat: 50, Rate: "0.02"},
+	}, sql.NullInt64{Int64: 10, Valid: true}, sql.NullInt64{Int64: 1000, Valid: true})
+
+	testCases := []struct {
+		amount int64
+		fee int64
+	}{
+		// 1% of 500 is raised to the min fee
+		{amount: 500, fee: 10},
+		{amount: 5000, fee: 50},
+		{amount: 10000, fee: 250},
+		// 50 + 2% of 100000 is capped at the max fee
+		{amount: 100000, fee: 1000},
+	}
+
+	for _, tc := range testCases {
+		fee, err := schedule.Fee(tc.amount)
+		require.NoError(t, err)
+		require.Equal(t, tc.fee, fee, tc.amount)
+	}
+}
+
+func TestTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+	setFeeSchedule(t, TRANSFER_TYPE_TRANSFER, []util.FeeTier{{MinAmount: 0, Flat: 25}}, sql.NullInt64{}, sql.NullInt64{})
+	revenue := revenueBalance(t)
+
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID: accounts[1].ID,
+		Amount: 100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(25), result.Transfer.Fee)
+	require.Equal(t, int64(875), result.FromAccount.Balance)
+	require.Equal(t, int64(1100), result.ToAccount.Balance)
+
+	require.NotNil(t, result.FeeEntry)
+	require.Equal(t, accounts[0].ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-25), result.FeeEntry.Amount)
+	require.Equal(t, result.Transfer.ID, result.FeeEntry.FeeTransferID.Int64)
+	require.False(t, result.FeeEntry.TransferID.Valid)
+
+	require.Equal(t, revenue+25, revenueBalance(t))
+}
+
+func TestTransferTxFeeInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+	setFeeSchedule(t, TRANSFER_TYPE_TRANSFER, []util.FeeTier{{MinAmount: 0, Flat: 25}}, sql.NullInt64{}, sql.NullInt64{})
+
+	// the amount alone is available, not with the fee on top
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID: accounts[1].ID,
+		Amount: 990,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	account, err := testQueries.GetAccount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), account.Balance)
+}
+
+func TestTransferTxFeeByType(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+	setFeeSchedule(t, TRANSFER_TYPE_CAPTURE, []util.FeeTier{{MinAmount: 0, Flat: 25}}, sql.NullInt64{}, sql.NullInt64{})
+
+	// only captures are charged
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID: accounts[1].ID,
+		Amount: 100,
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.Fee)
+	require.Nil(t, result.FeeEntry)
+	require.Equal(t, int64(900), result.FromAccount.Balance)
+}
+
+func TestBatchTransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 3)
+	setFeeSchedule(t, TRANSFER_TYPE_BATCH, []util.FeeTier{{MinAmount: 0, Rate: "0.01"}}, sql.NullInt64{Int64: 5, Valid: true}, sql.NullInt64{})
+	revenue := revenueBalance(t)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{
+			{ToAccountID: accounts[1].ID, Amount: 600},
+			{ToAccountID: accounts[2].ID, Amount: 200},
+			// 189 is left once the first two are paid with their fees, enough for 185 but not for its fee
+			{ToAccountID: accounts[2].ID, Amount: 185},
+		},
+		BestEffort: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Completed)
+	require.Equal(t, int64(6), result.Items[0].Transfer.Fee)
+	require.Equal(t, int64(5), result.Items[1].Transfer.Fee)
+	require.ErrorIs(t, result.Items[2].Err, ErrInsufficientFunds)
+	require.Equal(t, int64(1000-800-11), result.FromAccount.Balance)
+
+	entries, err := testQueries.ListEntries(context.Background(), ListEntriesParams{
+		AccountID: accounts[0].ID,
+		Limit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, entries, 4)
+
+	var fees int64
+	for _, entry := range entries {
+		if entry.FeeTransferID.Valid {
+			fees -= entry.Amount
+		}
+	}
+	require.Equal(t, int64(11), fees)
+	require.Equal(t, revenue+11, revenueBalance(t))
+}
@@ -0,0 +1,100 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+)
+
+const (
+	HELD_TRANSFER_PENDING = "pending"
+	HELD_TRANSFER_APPROVED = "approved"
+	HELD_TRANSFER_REJECTED = "rejected"
+)
+
+// ErrHeldTransferReviewed is returned when a held transfer is reviewed a second time
+var ErrHeldTransferReviewed = errors.New("the held transfer has already been reviewed")
+
+type ReviewHeldTransferTxParams struct {
+	ID int64 `json:"id"`
+	Reviewer string `json:"reviewer"`
+	Approve bool `json:"approve"`
+}
+
+type ReviewHeldTransferTxResult struct {
+	HeldTransfer HeldTransfer `json:"held_transfer"`
+	// Transfer is only set when the held transfer is approved
+	Transfer *TransferTxResult `json:"transfer"`
+	// PaymentRequest is only set when an approved transfer pays a payment request
+	PaymentRequest *PaymentRequest `json:"payment_request"`
+}
+
+// ReviewHeldTransferTx approves or rejects a transfer held by the fraud rules.
+// An approved transfer is made in the same transaction, so a transfer which fails, for lack of funds, stays pending.
+// An approved transfer paying a payment request accepts it, which fails once the request was cancelled or declined
+// meanwhile, the transfer can then only be rejected. A rejected transfer leaves the request pending.
+func (store *SQLStore) ReviewHeldTransferTx(ctx context.Context, arg ReviewHeldTransferTxParams) (ReviewHeldTransferTxResult, error) {
+	var result ReviewHeldTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		held, err := q.GetHeldTransferForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if held.Status != HELD_TRANSFER_PENDING {
+			return ErrHeldTransferReviewed
+		}
+
+		review := ReviewHeldTransferParams{
+			ID: held.ID,
+			Status: HELD_TRANSFER_REJECTED,
+			ReviewedBy: sql.NullString{String: arg.Reviewer, Valid: true},
+		}
+
+		if arg.Approve {
+			var request PaymentRequest
+			if held.PaymentRequestID.Valid {
+				// the request does not expire while its transfer is held
+				request, err = q.GetPaymentRequestForUpdate(ctx, held.PaymentRequestID.Int64)
+				if err != nil {
+					return err
+				}
+				if request.Status != PAYMENT_REQUEST_PENDING {
+					return ErrPaymentRequestNotPending
+				}
+			}
+
+			transferResult, err := transfer(ctx, q, TransferTxParams{
+				FromAccountID: held.FromAccountID,
+				ToAccountID: held.ToAccountID,
+				Amount: held.Amount,
+				Description: held.Description,
+				Reference: held.Reference,
+				Metadata: held.Metadata,
+				RequestedBy: held.RequestedBy,
+				RecipientHidden: held.RecipientHidden,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.Transfer = &transferResult
+			review.Status = HELD_TRANSFER_APPROVED
+			review.TransferID = sql.NullInt64{Int64: transferResult.Transfer.ID, Valid: true}
+
+			if held.PaymentRequestID.Valid {
+				request, err = finishPaymentRequest(ctx, q, request, PAYMENT_REQUEST_ACCEPTED, request.Payer, review.TransferID)
+				if err != nil {
+					return err
+				}
+				result.PaymentRequest = &request
+			}
+		}
+
+		result.HeldTransfer, err = q.ReviewHeldTransfer(ctx, review)
+		return err
+	})
+
+	return result, err
+}
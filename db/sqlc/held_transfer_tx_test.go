@@ -0,0 +1,181 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomHeldTransfer(t *testing.T, amount int64) (HeldTransfer, []Account) {
+	accounts := createBatchAccounts(t, 2)
+
+	held, err := testQueries.CreateHeldTransfer(context.Background(), CreateHeldTransferParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID: accounts[1].ID,
+		Amount: amount,
+		Currency: util.USD,
+		RequestedBy: accounts[0].Owner,
+		Reasons: []string{"new recipient"},
+		Description: util.RandomString(20),
+		Reference: util.RandomString(8),
+		Metadata: json.RawMessage(`{"order":"42"}`),
+	})
+	require.NoError(t, err)
+	require.Equal(t, HELD_TRANSFER_PENDING, held.Status)
+	require.Equal(t, []string{"new recipient"}, held.Reasons)
+	return held, accounts
+}
+
+func TestReviewHeldTransferTxApprove(t *testing.T) {
+	store := NewStore(testDB)
+	held, accounts := createRandomHeldTransfer(t, 300)
+	reviewer := createRandomUser(t)
+
+	result, err := store.ReviewHeldTransferTx(context.Background(), ReviewHeldTransferTxParams{
+		ID: held.ID,
+		Reviewer: reviewer.Username,
+		Approve: true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer)
+	require.Equal(t, HELD_TRANSFER_APPROVED, result.HeldTransfer.Status)
+	require.Equal(t, reviewer.Username, result.HeldTransfer.ReviewedBy.String)
+	require.True(t, result.HeldTransfer.ReviewedAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.HeldTransfer.TransferID.Int64)
+	require.Equal(t, int64(700), result.Transfer.FromAccount.Balance)
+	require.Equal(t, accounts[1].Balance+300, result.Transfer.ToAccount.Balance)
+	require.Equal(t, held.Description, result.Transfer.Transfer.Description)
+	require.Equal(t, held.Reference, result.Transfer.Transfer.Reference)
+	require.JSONEq(t, string(held.Metadata), string(result.Transfer.Transfer.Metadata))
+
+	// a held transfer is made at most once
+	_, err = store.ReviewHeldTransferTx(context.Background(), ReviewHeldTransferTxParams{
+		ID: held.ID,
+		Reviewer: reviewer.Username,
+		Approve: true,
+	})
+	require.ErrorIs(t, err, ErrHeldTransferReviewed)
+}
+
+func TestReviewHeldTransferTxReject(t *testing.T) {
+	store := NewStore(testDB)
+	held, accounts := createRandomHeldTransfer(t, 300)
+	reviewer := createRandomUser(t)
+
+	result, err := store.ReviewHeldTransferTx(context.Background(), ReviewHeldTransferTxParams{
+		ID: held.ID,
+		Reviewer: reviewer.Username,
+	})
+	require.NoError(t, err)
+	require.Nil(t, result.Transfer)
+	require.Equal(t, HELD_TRANSFER_REJECTED, result.HeldTransfer.Status)
+	require.False(t, result.HeldTransfer.TransferID.Valid)
+
+	account, err := testQueries.GetAccount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, accounts[0].Balance, account.Balance)
+}
+
+func TestReviewHeldTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	held, _ := createRandomHeldTransfer(t, 5000)
+	reviewer := createRandomUser(t)
+
+	_, err := store.ReviewHeldTransferTx(context.Background(), ReviewHeldTransferTxParams{
+		ID: held.ID,
+		Reviewer: reviewer.Username,
+		Approve: true,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the review is rolled back so the transfer can be approved once the account is funded
+	held, err = testQueries.GetHeldTransfer(context.Background(), held.ID)
+	require.NoError(t, err)
+	require.Equal(t, HELD_TRANSFER_PENDING, held.Status)
+}
+
+// holdPaymentRequest holds the transfer paying a request, as its payer accepting it would
+func holdPaymentRequest(t *testing.T, request PaymentRequest, fromAccount Account) (HeldTransfer, error) {
+	return testQueries.CreateHeldTransfer(context.Background(), CreateHeldTransferParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID: request.AccountID,
+		Amount: request.Amount,
+		Currency: request.Currency,
+		RequestedBy: request.Payer,
+		Reasons: []string{"large transfer"},
+		Description: request.Note,
+		Metadata: json.RawMessage("{}"),
+		RecipientHidden: true,
+		PaymentRequestID: sql.NullInt64{Int64: request.ID, Valid: true},
+	})
+}
+
+func TestReviewHeldTransferTxPaymentRequest(t *testing.T) {
+	store := NewStore(testDB)
+	// the request expires while its transfer waits for the review
+	request, accounts := createRandomPaymentRequest(t, 300, time.Now().Add(-time.Second))
+	reviewer := createRandomUser(t)
+
+	held, err := holdPaymentRequest(t, request, accounts[1])
+	require.NoError(t, err)
+	require.Equal(t, request.ID, held.PaymentRequestID.Int64)
+
+	// a request is held at most once at a time
+	_, err = holdPaymentRequest(t, request, accounts[1])
+	require.Error(t, err)
+
+	_, err = testQueries.ExpirePaymentRequests(context.Background())
+	require.NoError(t, err)
+	request, err = testQueries.GetPaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_PENDING, request.Status)
+
+	result, err := store.ReviewHeldTransferTx(context.Background(), ReviewHeldTransferTxParams{
+		ID: held.ID,
+		Reviewer: reviewer.Username,
+		Approve: true,
+	})
+	require.NoError(t, err)
+	require.NotNil(t, result.Transfer)
+	require.True(t, result.Transfer.Transfer.RecipientHidden)
+	require.NotNil(t, result.PaymentRequest)
+	require.Equal(t, PAYMENT_REQUEST_ACCEPTED, result.PaymentRequest.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, result.PaymentRequest.TransferID.Int64)
+
+	events := requirePaymentRequestEvents(t, request, PAYMENT_REQUEST_PENDING, PAYMENT_REQUEST_ACCEPTED)
+	require.Equal(t, sql.NullString{String: request.Payer, Valid: true}, events[1].Actor)
+}
+
+func TestReviewHeldTransferTxCancelledPaymentRequest(t *testing.T) {
+	store := NewStore(testDB)
+	request, accounts := createRandomPaymentRequest(t, 300, time.Now().Add(time.Hour))
+	reviewer := createRandomUser(t)
+
+	held, err := holdPaymentRequest(t, request, accounts[1])
+	require.NoError(t, err)
+
+	_, err = store.CancelPaymentRequestTx(context.Background(), request.ID)
+	require.NoError(t, err)
+
+	// the transfer cannot pay a request the requester withdrew, it can only be rejected
+	_, err = store.ReviewHeldTransferTx(context.Background(), ReviewHeldTransferTxParams{
+		ID: held.ID,
+		Reviewer: reviewer.Username,
+		Approve: true,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	result, err := store.ReviewHeldTransferTx(context.Background(), ReviewHeldTransferTxParams{
+		ID: held.ID,
+		Reviewer: reviewer.Username,
+		Approve: false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HELD_TRANSFER_REJECTED, result.HeldTransfer.Status)
+	require.Nil(t, result.PaymentRequest)
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	HOLD_ACTIVE = "active"
+	HOLD_CAPTURED = "captured"
+	HOLD_VOIDED = "voided"
+	HOLD_EXPIRED = "expired"
+)
+
+var (
+	// ErrHoldNotActive is returned when a hold is captured or voided once it was settled or expired
+	ErrHoldNotActive = errors.New("the hold is no longer active")
+	// ErrCaptureExceedsHold is returned when a capture is larger than the funds the hold reserved
+	ErrCaptureExceedsHold = errors.New("the captured amount exceeds the hold")
+)
+
+// IsActive tells whether the hold still reserves funds, a hold past its expiry does even before it is marked expired
+func (hold Hold) IsActive() bool {
+	return hold.Status == HOLD_ACTIVE && time.Now().Before(hold.ExpiresAt)
+}
+
+// availableBalance is the balance minus the funds the active holds reserve, every spend check compares against it.
+// The account must be locked, so that no hold is placed on it meanwhile.
+func availableBalance(ctx context.Context, q *Queries, account Account) (int64, error) {
+	held, err := q.GetHeldAmount(ctx, account.ID)
+	return account.Balance - held, err
+}
+
+type PlaceHoldTxParams struct {
+	AccountID int64 `json:"account_id"`
+	ToAccountID int64 `json:"to_account_id"`
+	Amount int64 `json:"amount"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// PlaceHoldTx reserves funds of an account for a later transfer to another account of the same currency.
+// The account is locked before its holds are summed, as in every spend check, so concurrent holds and transfers
+// cannot overdraw it together.
+func (store *SQLStore) PlaceHoldTx(ctx context.Context, arg PlaceHoldTxParams) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+		if err != nil {
+			return err
+		}
+		if toAccount.ID == account.ID {
+			return ErrSameAccount
+		}
+		if toAccount.Currency != account.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		available, err := availableBalance(ctx, q, account)
+		if err != nil {
+			return err
+		}
+		if arg.Amount > available {
+			return ErrInsufficientFunds
+		}
+
+		hold, err = q.CreateHold(ctx, CreateHoldParams{
+			AccountID: arg.AccountID,
+			ToAccountID: arg.ToAccountID,
+			Amount: arg.Amount,
+			ExpiresAt: arg.ExpiresAt,
+		})
+		return err
+	})
+
+	return hold, err
+}
+
+type CaptureHoldTxParams struct {
+	ID int64 `json:"id"`
+	// Amount is at most the amount of the hold, 0 captures all of it
+	Amount int64 `json:"amount"`
+}
+
+type CaptureHoldTxResult struct {
+	Hold Hold `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureHoldTx settles a hold with a transfer of the captured amount, a partial capture releases the rest of the hold.
+// The fee of the capture is not reserved by the hold, it must be available on top of the captured amount.
+func (store *SQLStore) CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error) {
+	var result CaptureHoldTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if !hold.IsActive() {
+			return ErrHoldNotActive
+		}
+
+		amount := arg.Amount
+		if amount == 0 {
+			amount = hold.Amount
+		}
+		if amount > hold.Amount {
+			return ErrCaptureExceedsHold
+		}
+
+		// the hold is settled first, so the funds it reserved are available to its own transfer
+		_, err = q.SettleHold(ctx, SettleHoldParams{
+			ID: hold.ID,
+			Status: HOLD_CAPTURED,
+			CapturedAmount: sql.NullInt64{Int64: amount, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: hold.AccountID,
+			ToAccountID: hold.ToAccountID,
+			Amount: amount,
+			Type: TRANSFER_TYPE_CAPTURE,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.SetHoldTransfer(ctx, SetHoldTransferParams{
+			ID: hold.ID,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		return err
+	})
+
+	return result, err
+}
+
+// VoidHoldTx releases the funds of a hold without transferring any of them
+func (store *SQLStore) VoidHoldTx(ctx context.Context, id int64) (Hold, error) {
+	var hold Hold
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		hold, err = q.GetHoldForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !hold.IsActive() {
+			return ErrHoldNotActive
+		}
+
+		hold, err = q.SettleHold(ctx, SettleHoldParams{
+			ID: hold.ID,
+			Status: HOLD_VOIDED,
+		})
+		return err
+	})
+
+	return hold, err
+}
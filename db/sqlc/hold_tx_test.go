@@ -0,0 +1,144 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomHold(t *testing.T, amount int64, expiresAt time.Time) (Hold, []Account) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+
+	hold, err := store.PlaceHoldTx(context.Background(), PlaceHoldTxParams{
+		AccountID: accounts[0].ID,
+		ToAccountID: accounts[1].ID,
+		Amount: amount,
+		ExpiresAt: expiresAt,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HOLD_ACTIVE, hold.Status)
+	require.Equal(t, amount, hold.Amount)
+	require.False(t, hold.SettledAt.Valid)
+	return hold, accounts
+}
+
+func TestPlaceHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	hold, accounts := createRandomHold(t, 600, time.Now().Add(time.Hour))
+
+	held, err := testQueries.GetHeldAmount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(600), held)
+
+	// the ledger balance is untouched, the held funds are no longer available
+	account, err := testQueries.GetAccount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, accounts[0].Balance, account.Balance)
+
+	_, err = store.PlaceHoldTx(context.Background(), PlaceHoldTxParams{
+		AccountID: accounts[0].ID,
+		ToAccountID: accounts[1].ID,
+		Amount: 500,
+		ExpiresAt: time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID: accounts[1].ID,
+		Amount: 500,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	holds, err := testQueries.ListActiveHolds(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Len(t, holds, 1)
+	require.Equal(t, hold.ID, holds[0].ID)
+}
+
+func TestCaptureHoldTxPartial(t *testing.T) {
+	store := NewStore(testDB)
+	hold, accounts := createRandomHold(t, 600, time.Now().Add(time.Hour))
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: hold.ID, Amount: 400})
+	require.NoError(t, err)
+	require.Equal(t, HOLD_CAPTURED, result.Hold.Status)
+	require.Equal(t, int64(400), result.Hold.CapturedAmount.Int64)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Hold.TransferID.Int64)
+	require.True(t, result.Hold.SettledAt.Valid)
+	require.Equal(t, int64(600), result.Transfer.FromAccount.Balance)
+	require.Equal(t, accounts[1].Balance+400, result.Transfer.ToAccount.Balance)
+
+	// the rest of the hold is released
+	held, err := testQueries.GetHeldAmount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestCaptureHoldTxFull(t *testing.T) {
+	store := NewStore(testDB)
+	hold, _ := createRandomHold(t, 1000, time.Now().Add(time.Hour))
+
+	result, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: hold.ID})
+	require.NoError(t, err)
+	require.Equal(t, int64(1000), result.Hold.CapturedAmount.Int64)
+	require.Zero(t, result.Transfer.FromAccount.Balance)
+}
+
+func TestCaptureHoldTxExceedsHold(t *testing.T) {
+	store := NewStore(testDB)
+	hold, _ := createRandomHold(t, 300, time.Now().Add(time.Hour))
+
+	_, err := store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: hold.ID, Amount: 301})
+	require.ErrorIs(t, err, ErrCaptureExceedsHold)
+
+	hold, err = testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HOLD_ACTIVE, hold.Status)
+}
+
+func TestVoidHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+	hold, accounts := createRandomHold(t, 600, time.Now().Add(time.Hour))
+
+	voided, err := store.VoidHoldTx(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HOLD_VOIDED, voided.Status)
+	require.False(t, voided.CapturedAmount.Valid)
+	require.True(t, voided.SettledAt.Valid)
+
+	held, err := testQueries.GetHeldAmount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.VoidHoldTx(context.Background(), hold.ID)
+	require.ErrorIs(t, err, ErrHoldNotActive)
+}
+
+func TestExpireHolds(t *testing.T) {
+	store := NewStore(testDB)
+	hold, accounts := createRandomHold(t, 600, time.Now().Add(-time.Second))
+
+	// a hold past its expiry reserves nothing and cannot be captured, even before it is marked expired
+	held, err := testQueries.GetHeldAmount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Zero(t, held)
+
+	_, err = store.CaptureHoldTx(context.Background(), CaptureHoldTxParams{ID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotActive)
+
+	n, err := testQueries.ExpireHolds(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	hold, err = testQueries.GetHold(context.Background(), hold.ID)
+	require.NoError(t, err)
+	require.Equal(t, HOLD_EXPIRED, hold.Status)
+	require.True(t, hold.SettledAt.Valid)
+}
@@ -0,0 +1,192 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+// the types of accounts, only savings accounts earn interest
+const (
+	ACCOUNT_CHECKING = "checking"
+	ACCOUNT_SAVINGS = "savings"
+)
+
+// BANK_INTEREST_EXPENSE is the system user whose accounts pay the interest, one per currency
+const BANK_INTEREST_EXPENSE = "bank:interest_expense"
+
+var (
+	// ErrInterestAccrued is returned when a day is accrued again
+	ErrInterestAccrued = errors.New("the interest of the day is already accrued")
+	// ErrNoInterestDue is returned when an account has no whole minor unit of interest left to post
+	ErrNoInterestDue = errors.New("no interest is due")
+)
+
+// AccrueInterestTx accrues the interest of a UTC day on the savings accounts. The run of the day is recorded along
+// with its accruals, so a rerun of the day returns ErrInterestAccrued and accrues nothing.
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, accrualDate time.Time) (InterestAccrualRun, error) {
+	var run InterestAccrualRun
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accounts, err := q.AccrueInterest(ctx, accrualDate)
+		if err != nil {
+			return err
+		}
+
+		run, err = q.CreateInterestAccrualRun(ctx, CreateInterestAccrualRunParams{
+			AccrualDate: accrualDate,
+			Accounts: int32(accounts),
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInterestAccrued
+		}
+		return err
+	})
+
+	return run, err
+}
+
+type PostInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// PeriodStart is the first day of the UTC month the interest is posted for
+	PeriodStart time.Time `json:"period_start"`
+}
+
+// PostInterestTx pays an account the interest it accrued up to the end of the month, from the interest expense
+// account of the bank in its currency. The interest is a transfer from the bank, it is not screened, limited or held.
+func (store *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (InterestPosting, error) {
+	var posting InterestPosting
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		bank, err := bankAccount(ctx, q, BANK_INTEREST_EXPENSE, account.Currency)
+		if err != nil {
+			return err
+		}
+
+		// the due amount is read once both accounts are locked, so that concurrent postings pay it once
+		_, err = q.LockAccounts(ctx, []int64{bank.ID, account.ID})
+		if err != nil {
+			return err
+		}
+
+		amount, err := q.GetInterestDue(ctx, GetInterestDueParams{
+			AccountID: account.ID,
+			PeriodEnd: arg.PeriodStart.AddDate(0, 1, 0),
+		})
+		if err != nil {
+			return err
+		}
+		if amount <= 0 {
+			return ErrNoInterestDue
+		}
+
+		result, err := postTransfer(ctx, q, TransferTxParams{
+			FromAccountID: bank.ID,
+			ToAccountID: account.ID,
+			Amount: amount,
+		}, 0)
+		if err != nil {
+			return err
+		}
+
+		posting, err = q.CreateInterestPosting(ctx, CreateInterestPostingParams{
+			AccountID: account.ID,
+			PeriodStart: arg.PeriodStart,
+			Amount: amount,
+			TransferID: result.Transfer.ID,
+		})
+		return err
+	})
+
+	return posting, err
+}
+
+// bankAccount is the checking account of a system user in the currency, it is opened on first use for the currencies
+// added after the user was created
+func bankAccount(ctx context.Context, q *Queries, owner string, currency string) (Account, error) {
+	account, err := q.GetOwnerAccount(ctx, GetOwnerAccountParams{
+		Owner: owner,
+		Currency: currency,
+	})
+	if !errors.Is(err, sql.ErrNoRows) {
+		return account, err
+	}
+
+	return q.CreateAccount(ctx, CreateAccountParams{
+		Owner: owner,
+		Currency: currency,
+		Type: ACCOUNT_CHECKING,
+	})
+}
+
+type PayInterestResult struct {
+	// Days is the number of days accrued
+	Days int `json:"days"`
+	// Postings is the number of accounts paid the interest of the last month
+	Postings int `json:"postings"`
+}
+
+// PayInterest accrues every UTC day which ended since the last accrued day, then posts the interest of the last
+// ended month to the accounts which were not paid it yet. Every step is idempotent, so the job can run as often as
+// wanted, on any number of instances, and a failed run is caught up by the next one.
+func PayInterest(ctx context.Context, store Store, now time.Time) (PayInterestResult, error) {
+	var result PayInterestResult
+
+	now = now.UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	// the first run only accrues yesterday, the days before the job ran earn nothing
+	day := today.AddDate(0, 0, -1)
+	last, err := store.GetLastInterestAccrualRun(ctx)
+	switch {
+	case err == nil:
+		day = last.AccrualDate.AddDate(0, 0, 1)
+	case !errors.Is(err, sql.ErrNoRows):
+		return result, err
+	}
+
+	for ; day.Before(today); day = day.AddDate(0, 0, 1) {
+		_, err := store.AccrueInterestTx(ctx, day)
+		if errors.Is(err, ErrInterestAccrued) {
+			continue
+		}
+		if err != nil {
+			return result, err
+		}
+		result.Days++
+	}
+
+	periodStart := month.AddDate(0, -1, 0)
+	due, err := store.ListInterestDue(ctx, ListInterestDueParams{
+		PeriodEnd: month,
+		PeriodStart: periodStart,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// an account which fails is retried by the next run, it does not hold back the others
+	var postErr error
+	for _, d := range due {
+		_, err := store.PostInterestTx(ctx, PostInterestTxParams{
+			AccountID: d.AccountID,
+			PeriodStart: periodStart,
+		})
+		switch {
+		case err == nil:
+			result.Postings++
+		case errors.Is(err, ErrNoInterestDue):
+		case postErr == nil:
+			postErr = err
+		}
+	}
+
+	return result, postErr
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+// every test accrues at 3.65% a year, which is 0.1 minor unit a day on a balance of 1000
+const testAnnualRate = "0.0365"
+
+func createSavingsAccount(t *testing.T, balance int64) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: user.Username,
+		Balance: balance,
+		Currency: util.USD,
+		Type: ACCOUNT_SAVINGS,
+	})
+	require.NoError(t, err)
+	require.Equal(t, ACCOUNT_SAVINGS, account.Type)
+
+	_, err = testQueries.UpsertInterestRate(context.Background(), UpsertInterestRateParams{
+		Currency: util.USD,
+		EffectiveFrom: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC),
+		AnnualRate: testAnnualRate,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+// randomInterestMonth is a month far enough in the future that no other test accrued it
+func randomInterestMonth() time.Time {
+	return time.Date(int(util.RandomInt(2100, 2900)), time.Month(util.RandomInt(1, 11)), 1, 0, 0, 0, 0, time.UTC)
+}
+
+func accrueDays(t *testing.T, from time.Time, days int) {
+	for day := from; day.Before(from.AddDate(0, 0, days)); day = day.AddDate(0, 0, 1) {
+		_, err := testQueries.AccrueInterest(context.Background(), day)
+		require.NoError(t, err)
+	}
+}
+
+func TestAccrueInterest(t *testing.T) {
+	savings := createSavingsAccount(t, 1000)
+	checking := createWalletAccount(t, createRandomUser(t).Username, util.USD, 1000)
+	month := randomInterestMonth()
+
+	accrueDays(t, month, 3)
+	// a day accrues once however often it is run
+	accrueDays(t, month, 3)
+
+	due, err := testQueries.GetInterestDue(context.Background(), GetInterestDueParams{
+		AccountID: savings.ID,
+		PeriodEnd: month.AddDate(0, 1, 0),
+	})
+	require.NoError(t, err)
+	// 0.3 minor units are accrued, nothing whole is due yet
+	require.Zero(t, due)
+
+	due, err = testQueries.GetInterestDue(context.Background(), GetInterestDueParams{
+		AccountID: checking.ID,
+		PeriodEnd: month.AddDate(0, 1, 0),
+	})
+	require.NoError(t, err)
+	require.Zero(t, due)
+}
+
+func TestPostInterestTxCarriesFraction(t *testing.T) {
+	store := NewStore(testDB)
+	savings := createSavingsAccount(t, 1000)
+	month := randomInterestMonth()
+	nextMonth := month.AddDate(0, 1, 0)
+
+	// 1.5 minor units pay 1, the half is carried to the next month
+	accrueDays(t, month, 15)
+
+	posting, err := store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: savings.ID, PeriodStart: month})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), posting.Amount)
+	require.Equal(t, month, posting.PeriodStart.UTC())
+
+	transfer, err := testQueries.GetTransfer(context.Background(), posting.TransferID)
+	require.NoError(t, err)
+	require.Equal(t, savings.ID, transfer.ToAccountID)
+
+	bank, err := testQueries.GetAccount(context.Background(), transfer.FromAccountID)
+	require.NoError(t, err)
+	require.Equal(t, BANK_INTEREST_EXPENSE, bank.Owner)
+
+	// a rerun pays nothing
+	_, err = store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: savings.ID, PeriodStart: month})
+	require.ErrorIs(t, err, ErrNoInterestDue)
+
+	// 1.5 more minor units and the carried half pay 2
+	accrueDays(t, nextMonth, 15)
+
+	posting, err = store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: savings.ID, PeriodStart: nextMonth})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), posting.Amount)
+
+	account, err := testQueries.GetAccount(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(1003), account.Balance)
+
+	postings, err := testQueries.ListInterestPostings(context.Background(), savings.ID)
+	require.NoError(t, err)
+	require.Len(t, postings, 2)
+}
+
+func TestListInterestDue(t *testing.T) {
+	store := NewStore(testDB)
+	savings := createSavingsAccount(t, 1000)
+	month := randomInterestMonth()
+
+	accrueDays(t, month, 20)
+
+	arg := ListInterestDueParams{PeriodEnd: month.AddDate(0, 1, 0), PeriodStart: month}
+	due, err := testQueries.ListInterestDue(context.Background(), arg)
+	require.NoError(t, err)
+	requireInterestDue(t, due, savings.ID, 2)
+
+	_, err = store.PostInterestTx(context.Background(), PostInterestTxParams{AccountID: savings.ID, PeriodStart: month})
+	require.NoError(t, err)
+
+	due, err = testQueries.ListInterestDue(context.Background(), arg)
+	require.NoError(t, err)
+	requireInterestDue(t, due, savings.ID, 0)
+}
+
+func requireInterestDue(t *testing.T, due []ListInterestDueRow, accountID int64, amount int64) {
+	for _, d := range due {
+		if d.AccountID == accountID {
+			require.Equal(t, amount, d.Amount)
+			return
+		}
+	}
+	require.Zero(t, amount)
+}
+
+func TestAccrueInterestTxOncePerDay(t *testing.T) {
+	store := NewStore(testDB)
+	// a day long past, so that the run does not move the day the job resumes from
+	day := time.Date(1900, 1, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(util.RandomInt(0, 30000)))
+
+	run, err := store.AccrueInterestTx(context.Background(), day)
+	require.NoError(t, err)
+	require.Equal(t, day, run.AccrualDate.UTC())
+	require.Zero(t, run.Accounts)
+
+	_, err = store.AccrueInterestTx(context.Background(), day)
+	require.ErrorIs(t, err, ErrInterestAccrued)
+}
@@ -3,6 +3,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 )
 
@@ -12,6 +14,82 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	WalletID  int64     `json:"wallet_id"`
+	// checking or savings, only savings accounts earn interest
+	Type string `json:"type"`
+	// set when the owner is erased, a closed account takes no more transfers
+	ClosedAt sql.NullTime `json:"closed_at"`
+}
+
+type AccountInvite struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	// the role the user is given on the account once it accepts
+	Role string `json:"role"`
+	// the owner who invited the user
+	InvitedBy string    `json:"invited_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AccountMember struct {
+	AccountID int64  `json:"account_id"`
+	Username  string `json:"username"`
+	// owner, spender or viewer, owners manage the members, spenders transfer and viewers only read
+	Role string `json:"role"`
+	// the owner who added the member, null for the holder
+	InvitedBy sql.NullString `json:"invited_by"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type AccountTransferLimit struct {
+	AccountID      int64         `json:"account_id"`
+	PerTransaction sql.NullInt64 `json:"per_transaction"`
+	Daily          sql.NullInt64 `json:"daily"`
+	Monthly        sql.NullInt64 `json:"monthly"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type AccountTransferUsage struct {
+	AccountID int64  `json:"account_id"`
+	Period    string `json:"period"`
+	// first day of the UTC day or month
+	PeriodStart time.Time `json:"period_start"`
+	// in minor units of the currency of the account, total of the transfers from it within the period
+	Amount int64 `json:"amount"`
+}
+
+type ApiKey struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Name     string `json:"name"`
+	// sha256 of the key shown once to the user, the raw key is never stored
+	KeyHash   string    `json:"key_hash"`
+	Scopes    []string  `json:"scopes"`
+	IsRevoked bool      `json:"is_revoked"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Conversion struct {
+	ID            int64 `json:"id"`
+	WalletID      int64 `json:"wallet_id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive, in minor units of the from account
+	FromAmount int64 `json:"from_amount"`
+	// must be positive, in minor units of the to account
+	ToAmount int64 `json:"to_amount"`
+	// exchange rate applied, kept since exchange_rates is updated in place
+	Rate      string    `json:"rate"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Currency struct {
+	// ISO 4217 alphabetic code
+	Code string `json:"code"`
+	// number of decimals, amounts are stored in minor units so it never changes
+	MinorUnits int32     `json:"minor_units"`
+	IsEnabled  bool      `json:"is_enabled"`
+	CreatedAt  time.Time `json:"created_at"`
 }
 
 type Entry struct {
@@ -20,6 +98,236 @@ type Entry struct {
 	// can be either negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer the entry was posted for, which tells the counterparty
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// conversion within a wallet the entry was posted for
+	ConversionID sql.NullInt64 `json:"conversion_id"`
+	// transfer the entry charged the fee of, on the paying account and on the revenue account of the bank
+	FeeTransferID sql.NullInt64 `json:"fee_transfer_id"`
+}
+
+type ErasureRequest struct {
+	ID int64 `json:"id"`
+	// username given to the erased user, the original username is not kept
+	Pseudonym string    `json:"pseudonym"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ExchangeRate struct {
+	FromCurrency string `json:"from_currency"`
+	ToCurrency   string `json:"to_currency"`
+	// price of one major unit of from_currency in major units of to_currency
+	Rate      string    `json:"rate"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type FeeSchedule struct {
+	ID int64 `json:"id"`
+	// transfer, batch or capture, the operation the transfer was made by
+	TransferType string `json:"transfer_type"`
+	Currency     string `json:"currency"`
+	// from the lowest min_amount, the tier of the highest min_amount up to the amount applies its flat fee plus rate times the amount
+	Tiers json.RawMessage `json:"tiers"`
+	// in minor units of currency, the lowest fee charged whatever the tier
+	MinFee sql.NullInt64 `json:"min_fee"`
+	// in minor units of currency, the cap of the fee whatever the tier
+	MaxFee    sql.NullInt64 `json:"max_fee"`
+	CreatedAt time.Time     `json:"created_at"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type FraudRule struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// amount, account_velocity, user_velocity, new_recipient or repeated_transfer
+	Kind string `json:"kind"`
+	// review holds the matching transfers for staff, deny rejects them
+	Action   string         `json:"action"`
+	Currency sql.NullString `json:"currency"`
+	// in minor units of currency, the rule only applies to transfers of that currency from this amount
+	MinAmount sql.NullInt64 `json:"min_amount"`
+	// number of transfers allowed within window_seconds, the transfer after them matches
+	MaxCount      sql.NullInt32 `json:"max_count"`
+	WindowSeconds sql.NullInt32 `json:"window_seconds"`
+	IsEnabled     bool          `json:"is_enabled"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+type HeldTransfer struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	Amount        int64 `json:"amount"`
+	// currency of both accounts when the transfer was held
+	Currency    string `json:"currency"`
+	RequestedBy string `json:"requested_by"`
+	// names of the fraud rules which held the transfer, at the time it was held
+	Reasons    []string       `json:"reasons"`
+	Status     string         `json:"status"`
+	ReviewedBy sql.NullString `json:"reviewed_by"`
+	ReviewedAt sql.NullTime   `json:"reviewed_at"`
+	// the transfer made when staff approved it
+	TransferID  sql.NullInt64   `json:"transfer_id"`
+	CreatedAt   time.Time       `json:"created_at"`
+	Description string          `json:"description"`
+	Reference   string          `json:"reference"`
+	Metadata    json.RawMessage `json:"metadata"`
+	// the server resolved to_account_id, it is kept from the sender
+	RecipientHidden bool `json:"recipient_hidden"`
+	// the payment request the transfer pays, accepted once staff approve the transfer
+	PaymentRequestID sql.NullInt64 `json:"payment_request_id"`
+}
+
+type Hold struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// account the captured amount is transferred to
+	ToAccountID int64 `json:"to_account_id"`
+	// must be positive, reserved on account_id until the hold is settled or expires
+	Amount int64 `json:"amount"`
+	// active, captured, voided or expired, an active hold past expires_at no longer reserves funds
+	Status string `json:"status"`
+	// at most amount, the rest of the hold is released by the capture
+	CapturedAmount sql.NullInt64 `json:"captured_amount"`
+	TransferID     sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt      time.Time     `json:"expires_at"`
+	SettledAt      sql.NullTime  `json:"settled_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+type InterestAccrual struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// in minor units, balance of the account at the end of the UTC day
+	Balance    int64  `json:"balance"`
+	AnnualRate string `json:"annual_rate"`
+	// in minor units, not rounded, balance * annual_rate / 365
+	Amount    string    `json:"amount"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type InterestAccrualRun struct {
+	AccrualDate time.Time `json:"accrual_date"`
+	Accounts    int32     `json:"accounts"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type InterestPosting struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// first day of the UTC month the interest was accrued in
+	PeriodStart time.Time `json:"period_start"`
+	// in minor units, the whole minor units accrued so far minus the earlier postings, the fraction is carried
+	Amount     int64     `json:"amount"`
+	TransferID int64     `json:"transfer_id"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type InterestRate struct {
+	Currency      string    `json:"currency"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	// fraction of the balance paid over a year of 365 days, 0.025 is 2.5%
+	AnnualRate string    `json:"annual_rate"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+type OauthAuthorizationCode struct {
+	ID          int64    `json:"id"`
+	CodeHash    string   `json:"code_hash"`
+	ClientID    string   `json:"client_id"`
+	Username    string   `json:"username"`
+	RedirectUri string   `json:"redirect_uri"`
+	Scopes      []string `json:"scopes"`
+	// PKCE S256 challenge, base64url of the sha256 of the code verifier
+	CodeChallenge string    `json:"code_challenge"`
+	IsUsed        bool      `json:"is_used"`
+	CreatedAt     time.Time `json:"created_at"`
+	ExpiredAt     time.Time `json:"expired_at"`
+}
+
+type OauthClient struct {
+	ClientID string `json:"client_id"`
+	Owner    string `json:"owner"`
+	Name     string `json:"name"`
+	// sha256 of the client secret shown once to the owner
+	SecretHash   string    `json:"secret_hash"`
+	RedirectUris []string  `json:"redirect_uris"`
+	Scopes       []string  `json:"scopes"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type OauthRefreshToken struct {
+	ID        int64     `json:"id"`
+	TokenHash string    `json:"token_hash"`
+	ClientID  string    `json:"client_id"`
+	Username  string    `json:"username"`
+	Scopes    []string  `json:"scopes"`
+	IsRevoked bool      `json:"is_revoked"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type PasswordReset struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	// sha256 of the token sent to the user, the raw token is never stored
+	TokenHash string    `json:"token_hash"`
+	IsUsed    bool      `json:"is_used"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiredAt time.Time `json:"expired_at"`
+}
+
+type Payee struct {
+	ID        int64  `json:"id"`
+	Owner     string `json:"owner"`
+	Nickname  string `json:"nickname"`
+	AccountID int64  `json:"account_id"`
+	// currency of the account, checked when the payee is added
+	Currency string `json:"currency"`
+	// start of the cooling-off period, large transfers to the payee are refused until it ends
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PaymentRequest struct {
+	ID        int64  `json:"id"`
+	Requester string `json:"requester"`
+	Payer     string `json:"payer"`
+	// account of the requester the payment is transferred to
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Note      string `json:"note"`
+	// pending, accepted, declined, cancelled or expired, a pending request past expires_at can no longer be accepted
+	Status string `json:"status"`
+	// the transfer which paid the request, set once it is accepted
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+type PaymentRequestEvent struct {
+	ID               int64  `json:"id"`
+	PaymentRequestID int64  `json:"payment_request_id"`
+	Status           string `json:"status"`
+	// the user who changed the status, null when the request expired
+	Actor     sql.NullString `json:"actor"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+type Statement struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	PeriodStart time.Time `json:"period_start"`
+	// exclusive
+	PeriodEnd      time.Time `json:"period_end"`
+	OpeningBalance int64     `json:"opening_balance"`
+	ClosingBalance int64     `json:"closing_balance"`
+	// entries of the period with their counterparty and the running balance
+	Lines     json.RawMessage `json:"lines"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 type Transfer struct {
@@ -29,6 +337,38 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// in minor units, charged to from_account_id on top of amount
+	Fee int64 `json:"fee"`
+	// free text of the sender, shown on the statements of both accounts
+	Description string `json:"description"`
+	// identifier of the transfer in the system of the sender, such as an invoice number
+	Reference string `json:"reference"`
+	// object of string values set by the sender, searched by containment
+	Metadata json.RawMessage `json:"metadata"`
+	// the user who made the transfer, null for the interest the bank posts
+	RequestedBy sql.NullString `json:"requested_by"`
+	// the server resolved to_account_id, it is kept from the sender
+	RecipientHidden bool `json:"recipient_hidden"`
+}
+
+type TransferLimit struct {
+	Currency       string        `json:"currency"`
+	PerTransaction sql.NullInt64 `json:"per_transaction"`
+	// in minor units of currency, total of the transfers of a user within a UTC day
+	Daily sql.NullInt64 `json:"daily"`
+	// in minor units of currency, total of the transfers of a user within a UTC month
+	Monthly   sql.NullInt64 `json:"monthly"`
+	UpdatedAt time.Time     `json:"updated_at"`
+}
+
+type TransferUsage struct {
+	Owner    string `json:"owner"`
+	Currency string `json:"currency"`
+	Period   string `json:"period"`
+	// first day of the UTC day or month
+	PeriodStart time.Time `json:"period_start"`
+	// in minor units of currency, total of the transfers of the owner within the period
+	Amount int64 `json:"amount"`
 }
 
 type User struct {
@@ -38,4 +378,33 @@ type User struct {
 	Email             string    `json:"email"`
 	CreatedAt         time.Time `json:"created_at"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
+	IsEmailVerified   bool      `json:"is_email_verified"`
+	// depositor, admin or system, admins are promoted directly in the database
+	Role string `json:"role"`
+}
+
+type UserTransferLimit struct {
+	Username       string        `json:"username"`
+	Currency       string        `json:"currency"`
+	PerTransaction sql.NullInt64 `json:"per_transaction"`
+	Daily          sql.NullInt64 `json:"daily"`
+	Monthly        sql.NullInt64 `json:"monthly"`
+	UpdatedAt      time.Time     `json:"updated_at"`
+}
+
+type VerifyEmail struct {
+	ID       int64  `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	// sha256 of the code sent to the email, the raw code is never stored
+	SecretCodeHash string    `json:"secret_code_hash"`
+	IsUsed         bool      `json:"is_used"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiredAt      time.Time `json:"expired_at"`
+}
+
+type Wallet struct {
+	ID        int64     `json:"id"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"created_at"`
 }
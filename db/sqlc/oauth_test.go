@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomOauthClient(t *testing.T, owner User) OauthClient {
+	secret, err := util.RandomSecret(32)
+	require.NoError(t, err)
+
+	arg := CreateOauthClientParams{
+		ClientID: util.RandomString(16),
+		Owner: owner.Username,
+		Name: util.RandomOwner(),
+		SecretHash: util.HashSecret(secret),
+		RedirectUris: []string{"https://example.com/callback"},
+		Scopes: []string{"accounts:read"},
+	}
+
+	client, err := testQueries.CreateOauthClient(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.ClientID, client.ClientID)
+	require.Equal(t, arg.Owner, client.Owner)
+	require.Equal(t, arg.Name, client.Name)
+	require.Equal(t, arg.SecretHash, client.SecretHash)
+	require.Equal(t, arg.RedirectUris, client.RedirectUris)
+	require.Equal(t, arg.Scopes, client.Scopes)
+	require.NotZero(t, client.CreatedAt)
+
+	return client
+}
+
+func createRandomOauthAuthorizationCode(t *testing.T, client OauthClient, user User, duration time.Duration) OauthAuthorizationCode {
+	arg := CreateOauthAuthorizationCodeParams{
+		CodeHash: util.HashSecret(util.RandomString(32)),
+		ClientID: client.ClientID,
+		Username: user.Username,
+		RedirectUri: client.RedirectUris[0],
+		Scopes: client.Scopes,
+		CodeChallenge: util.RandomString(43),
+		ExpiredAt: time.Now().Add(duration),
+	}
+
+	code, err := testQueries.CreateOauthAuthorizationCode(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.Equal(t, arg.CodeHash, code.CodeHash)
+	require.Equal(t, arg.ClientID, code.ClientID)
+	require.Equal(t, arg.Username, code.Username)
+	require.Equal(t, arg.RedirectUri, code.RedirectUri)
+	require.Equal(t, arg.Scopes, code.Scopes)
+	require.Equal(t, arg.CodeChallenge, code.CodeChallenge)
+	require.False(t, code.IsUsed)
+	require.WithinDuration(t, arg.ExpiredAt, code.ExpiredAt, time.Second)
+
+	return code
+}
+
+func TestGetOauthClient(t *testing.T) {
+	client1 := createRandomOauthClient(t, createRandomUser(t))
+
+	client2, err := testQueries.GetOauthClient(context.Background(), client1.ClientID)
+	require.NoError(t, err)
+	require.Equal(t, client1.ClientID, client2.ClientID)
+	require.Equal(t, client1.RedirectUris, client2.RedirectUris)
+	require.WithinDuration(t, client1.CreatedAt, client2.CreatedAt, time.Second)
+}
+
+func TestUseOauthAuthorizationCode(t *testing.T) {
+	client := createRandomOauthClient(t, createRandomUser(t))
+	code := createRandomOauthAuthorizationCode(t, client, createRandomUser(t), time.Minute)
+
+	// codes are bound to the client they were issued to
+	_, err := testQueries.UseOauthAuthorizationCode(context.Background(), UseOauthAuthorizationCodeParams{
+		CodeHash: code.CodeHash,
+		ClientID: createRandomOauthClient(t, createRandomUser(t)).ClientID,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	arg := UseOauthAuthorizationCodeParams{CodeHash: code.CodeHash, ClientID: client.ClientID}
+	usedCode, err := testQueries.UseOauthAuthorizationCode(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, usedCode.IsUsed)
+
+	_, err = testQueries.UseOauthAuthorizationCode(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestUseExpiredOauthAuthorizationCode(t *testing.T) {
+	client := createRandomOauthClient(t, createRandomUser(t))
+	code := createRandomOauthAuthorizationCode(t, client, createRandomUser(t), -time.Minute)
+
+	_, err := testQueries.UseOauthAuthorizationCode(context.Background(), UseOauthAuthorizationCodeParams{
+		CodeHash: code.CodeHash,
+		ClientID: client.ClientID,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestExchangeOauthCodeTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOauthClient(t, createRandomUser(t))
+	code := createRandomOauthAuthorizationCode(t, client, user, time.Minute)
+
+	arg := ExchangeOauthCodeTxParams{
+		CodeHash: code.CodeHash,
+		ClientID: client.ClientID,
+		RefreshTokenHash: util.HashSecret(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	arg.VerifyCode = func(got OauthAuthorizationCode) error {
+		require.Equal(t, code.ID, got.ID)
+		return nil
+	}
+	refreshToken, err := store.ExchangeOauthCodeTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.RefreshTokenHash, refreshToken.TokenHash)
+	require.Equal(t, client.ClientID, refreshToken.ClientID)
+	require.Equal(t, user.Username, refreshToken.Username)
+	require.Equal(t, code.Scopes, refreshToken.Scopes)
+	require.False(t, refreshToken.IsRevoked)
+
+	arg.RefreshTokenHash = util.HashSecret(util.RandomString(32))
+	_, err = store.ExchangeOauthCodeTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestExchangeOauthCodeTxFailedVerification(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOauthClient(t, createRandomUser(t))
+	code := createRandomOauthAuthorizationCode(t, client, user, time.Minute)
+
+	arg := ExchangeOauthCodeTxParams{
+		CodeHash: code.CodeHash,
+		ClientID: client.ClientID,
+		RefreshTokenHash: util.HashSecret(util.RandomString(32)),
+		RefreshTokenExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	// a failed verification uses up the code
+	verifyErr := errors.New("wrong code verifier")
+	arg.VerifyCode = func(OauthAuthorizationCode) error {
+		return verifyErr
+	}
+	_, err := store.ExchangeOauthCodeTx(context.Background(), arg)
+	require.Equal(t, verifyErr, err)
+
+	arg.VerifyCode = func(OauthAuthorizationCode) error {
+		return nil
+	}
+	_, err = store.ExchangeOauthCodeTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	_, err = testQueries.UseOauthRefreshToken(context.Background(), UseOauthRefreshTokenParams{
+		TokenHash: arg.RefreshTokenHash,
+		ClientID: client.ClientID,
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestRefreshOauthTokenTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	client := createRandomOauthClient(t, createRandomUser(t))
+
+	oldToken, err := testQueries.CreateOauthRefreshToken(context.Background(), CreateOauthRefreshTokenParams{
+		TokenHash: util.HashSecret(util.RandomString(32)),
+		ClientID: client.ClientID,
+		Username: user.Username,
+		Scopes: client.Scopes,
+		ExpiredAt: time.Now().Add(time.Hour),
+	})
+	require.NoError(t, err)
+
+	arg := RefreshOauthTokenTxParams{
+		TokenHash: oldToken.TokenHash,
+		ClientID: client.ClientID,
+		NewTokenHash: util.HashSecret(util.RandomString(32)),
+		NewTokenExpiredAt: time.Now().Add(time.Hour),
+	}
+
+	newToken, err := store.RefreshOauthTokenTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, arg.NewTokenHash, newToken.TokenHash)
+	require.Equal(t, oldToken.Username, newToken.Username)
+	require.Equal(t, oldToken.Scopes, newToken.Scopes)
+
+	// the old token is revoked by the rotation
+	arg.NewTokenHash = util.HashSecret(util.RandomString(32))
+	_, err = store.RefreshOauthTokenTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// changing the password revokes every refresh token of the user
+	_, err = testQueries.UpdateUserPassword(context.Background(), UpdateUserPasswordParams{
+		Username: user.Username,
+		HashedPassword: user.HashedPassword,
+	})
+	require.NoError(t, err)
+
+	arg.TokenHash = newToken.TokenHash
+	_, err = store.RefreshOauthTokenTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
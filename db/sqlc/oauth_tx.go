@@ -0,0 +1,86 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type ExchangeOauthCodeTxParams struct {
+	CodeHash string `json:"code_hash"`
+	ClientID string `json:"client_id"`
+	// VerifyCode checks the redirect uri and the PKCE verifier, an error still uses up the code so a verifier cannot be guessed
+	VerifyCode func(code OauthAuthorizationCode) error `json:"-"`
+	RefreshTokenHash string `json:"refresh_token_hash"`
+	RefreshTokenExpiredAt time.Time `json:"refresh_token_expired_at"`
+}
+
+// ExchangeOauthCodeTx uses an authorization code once and issues a refresh token with the scopes the user consented to
+func (store *SQLStore) ExchangeOauthCodeTx(ctx context.Context, arg ExchangeOauthCodeTxParams) (OauthRefreshToken, error) {
+	var refreshToken OauthRefreshToken
+	var verifyErr error
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		code, err := q.UseOauthAuthorizationCode(ctx, UseOauthAuthorizationCodeParams{
+			CodeHash: arg.CodeHash,
+			ClientID: arg.ClientID,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.VerifyCode != nil {
+			// commit the used code and report the failed verification after the transaction
+			verifyErr = arg.VerifyCode(code)
+			if verifyErr != nil {
+				return nil
+			}
+		}
+
+		refreshToken, err = q.CreateOauthRefreshToken(ctx, CreateOauthRefreshTokenParams{
+			TokenHash: arg.RefreshTokenHash,
+			ClientID: code.ClientID,
+			Username: code.Username,
+			Scopes: code.Scopes,
+			ExpiredAt: arg.RefreshTokenExpiredAt,
+		})
+		return err
+	})
+	if err == nil && verifyErr != nil {
+		err = verifyErr
+	}
+
+	return refreshToken, err
+}
+
+type RefreshOauthTokenTxParams struct {
+	TokenHash string `json:"token_hash"`
+	ClientID string `json:"client_id"`
+	NewTokenHash string `json:"new_token_hash"`
+	NewTokenExpiredAt time.Time `json:"new_token_expired_at"`
+}
+
+// RefreshOauthTokenTx rotates a refresh token, the old one is revoked and the new one keeps its user and scopes
+func (store *SQLStore) RefreshOauthTokenTx(ctx context.Context, arg RefreshOauthTokenTxParams) (OauthRefreshToken, error) {
+	var refreshToken OauthRefreshToken
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		oldToken, err := q.UseOauthRefreshToken(ctx, UseOauthRefreshTokenParams{
+			TokenHash: arg.TokenHash,
+			ClientID: arg.ClientID,
+		})
+		if err != nil {
+			return err
+		}
+
+		refreshToken, err = q.CreateOauthRefreshToken(ctx, CreateOauthRefreshTokenParams{
+			TokenHash: arg.NewTokenHash,
+			ClientID: oldToken.ClientID,
+			Username: oldToken.Username,
+			Scopes: oldToken.Scopes,
+			ExpiredAt: arg.NewTokenExpiredAt,
+		})
+		return err
+	})
+
+	return refreshToken, err
+}
@@ -0,0 +1,59 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPasswordReset(t *testing.T, user User, duration time.Duration) PasswordReset {
+	secret, err := util.RandomSecret(32)
+	require.NoError(t, err)
+
+	arg := CreatePasswordResetParams{
+		Username: user.Username,
+		TokenHash: util.HashSecret(secret),
+		ExpiredAt: time.Now().Add(duration),
+	}
+
+	reset, err := testQueries.CreatePasswordReset(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, reset)
+
+	require.Equal(t, arg.Username, reset.Username)
+	require.Equal(t, arg.TokenHash, reset.TokenHash)
+	require.False(t, reset.IsUsed)
+	require.WithinDuration(t, arg.ExpiredAt, reset.ExpiredAt, time.Second)
+	require.NotZero(t, reset.ID)
+	require.NotZero(t, reset.CreatedAt)
+
+	return reset
+}
+
+func TestCreatePasswordReset(t *testing.T) {
+	createRandomPasswordReset(t, createRandomUser(t), time.Minute)
+}
+
+func TestUsePasswordReset(t *testing.T) {
+	reset := createRandomPasswordReset(t, createRandomUser(t), time.Minute)
+
+	usedReset, err := testQueries.UsePasswordReset(context.Background(), reset.TokenHash)
+	require.NoError(t, err)
+	require.Equal(t, reset.ID, usedReset.ID)
+	require.True(t, usedReset.IsUsed)
+
+	// a token can only be used once
+	_, err = testQueries.UsePasswordReset(context.Background(), reset.TokenHash)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestUseExpiredPasswordReset(t *testing.T) {
+	reset := createRandomPasswordReset(t, createRandomUser(t), -time.Minute)
+
+	_, err := testQueries.UsePasswordReset(context.Background(), reset.TokenHash)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
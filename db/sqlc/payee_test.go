@@ -0,0 +1,90 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPayee(t *testing.T, owner string, account Account) Payee {
+	arg := CreatePayeeParams{
+		Owner: owner,
+		Nickname: util.RandomOwner(),
+		AccountID: account.ID,
+		Currency: account.Currency,
+	}
+
+	payee, err := testQueries.CreatePayee(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, payee.ID)
+	require.Equal(t, arg.Owner, payee.Owner)
+	require.Equal(t, arg.Nickname, payee.Nickname)
+	require.Equal(t, arg.AccountID, payee.AccountID)
+	require.Equal(t, arg.Currency, payee.Currency)
+	require.NotZero(t, payee.CreatedAt)
+
+	return payee
+}
+
+func TestPayees(t *testing.T) {
+	user := createRandomUser(t)
+	account := CreateRandomAccount(t)
+	defer testQueries.DeleteAccount(context.Background(), account.ID)
+
+	payee := createRandomPayee(t, user.Username, account)
+
+	// an account is saved once per user
+	_, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner: user.Username,
+		Nickname: payee.Nickname + " again",
+		AccountID: account.ID,
+		Currency: account.Currency,
+	})
+	require.Error(t, err)
+
+	// only the owner gets the payee
+	_, err = testQueries.GetPayee(context.Background(), GetPayeeParams{ID: payee.ID, Owner: account.Owner})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	updated, err := testQueries.UpdatePayee(context.Background(), UpdatePayeeParams{
+		ID: payee.ID,
+		Owner: user.Username,
+		Nickname: "renamed",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "renamed", updated.Nickname)
+	require.Equal(t, payee.AccountID, updated.AccountID)
+	require.Equal(t, payee.CreatedAt, updated.CreatedAt)
+
+	payees, err := testQueries.ListPayees(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Len(t, payees, 1)
+	require.Equal(t, payee.ID, payees[0].ID)
+
+	deleted, err := testQueries.DeletePayee(context.Background(), DeletePayeeParams{ID: payee.ID, Owner: user.Username})
+	require.NoError(t, err)
+	require.Equal(t, payee.ID, deleted.ID)
+
+	payees, err = testQueries.ListPayees(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, payees)
+}
+
+func TestDeletePayeesByOwner(t *testing.T) {
+	user := createRandomUser(t)
+	accounts := []Account{CreateRandomAccount(t), CreateRandomAccount(t)}
+	for _, account := range accounts {
+		defer testQueries.DeleteAccount(context.Background(), account.ID)
+		createRandomPayee(t, user.Username, account)
+	}
+
+	err := testQueries.DeletePayeesByOwner(context.Background(), user.Username)
+	require.NoError(t, err)
+
+	payees, err := testQueries.ListPayees(context.Background(), user.Username)
+	require.NoError(t, err)
+	require.Empty(t, payees)
+}
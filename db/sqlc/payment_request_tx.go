@@ -0,0 +1,158 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	PAYMENT_REQUEST_PENDING = "pending"
+	PAYMENT_REQUEST_ACCEPTED = "accepted"
+	PAYMENT_REQUEST_DECLINED = "declined"
+	PAYMENT_REQUEST_CANCELLED = "cancelled"
+	PAYMENT_REQUEST_EXPIRED = "expired"
+)
+
+// ErrPaymentRequestNotPending is returned when a payment request is accepted, declined or cancelled once it was settled or expired
+var ErrPaymentRequestNotPending = errors.New("the payment request is no longer pending")
+
+// IsPending tells whether the payment request can still be accepted, a request past its expiry cannot even before it is marked expired
+func (request PaymentRequest) IsPending() bool {
+	return request.Status == PAYMENT_REQUEST_PENDING && time.Now().Before(request.ExpiresAt)
+}
+
+// CreatePaymentRequestTx sends a payment request to the payer, its history starts with the pending event of the requester
+func (store *SQLStore) CreatePaymentRequestTx(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error) {
+	var request PaymentRequest
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		request, err = q.CreatePaymentRequest(ctx, arg)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.CreatePaymentRequestEvent(ctx, CreatePaymentRequestEventParams{
+			PaymentRequestID: request.ID,
+			Status: PAYMENT_REQUEST_PENDING,
+			Actor: sql.NullString{String: request.Requester, Valid: true},
+		})
+		return err
+	})
+
+	return request, err
+}
+
+type AcceptPaymentRequestTxParams struct {
+	ID int64 `json:"id"`
+	// FromAccountID is the account of the payer the request is paid from, in the currency of the request
+	FromAccountID int64 `json:"from_account_id"`
+}
+
+type AcceptPaymentRequestTxResult struct {
+	PaymentRequest PaymentRequest `json:"payment_request"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// AcceptPaymentRequestTx pays a pending request with a transfer to the account of the requester.
+// The request stays locked until the transfer is done, so it cannot be paid twice nor cancelled meanwhile.
+func (store *SQLStore) AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResult, error) {
+	var result AcceptPaymentRequestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		request, err := q.GetPaymentRequestForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+		if !request.IsPending() {
+			return ErrPaymentRequestNotPending
+		}
+
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+		if fromAccount.ID == request.AccountID {
+			return ErrSameAccount
+		}
+		if fromAccount.Currency != request.Currency {
+			return ErrCurrencyMismatch
+		}
+
+		result.Transfer, err = transfer(ctx, q, TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID: request.AccountID,
+			Amount: request.Amount,
+			Description: request.Note,
+			RequestedBy: request.Payer,
+			// the payer pays the request, not an account it chose
+			RecipientHidden: true,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.PaymentRequest, err = finishPaymentRequest(ctx, q, request, PAYMENT_REQUEST_ACCEPTED, request.Payer,
+			sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true})
+		return err
+	})
+
+	return result, err
+}
+
+// DeclinePaymentRequestTx settles a pending request on behalf of the payer, without any transfer
+func (store *SQLStore) DeclinePaymentRequestTx(ctx context.Context, id int64) (PaymentRequest, error) {
+	return store.closePaymentRequest(ctx, id, PAYMENT_REQUEST_DECLINED)
+}
+
+// CancelPaymentRequestTx withdraws a pending request on behalf of the requester
+func (store *SQLStore) CancelPaymentRequestTx(ctx context.Context, id int64) (PaymentRequest, error) {
+	return store.closePaymentRequest(ctx, id, PAYMENT_REQUEST_CANCELLED)
+}
+
+// closePaymentRequest settles a pending request without paying it, the payer declines it and the requester cancels it
+func (store *SQLStore) closePaymentRequest(ctx context.Context, id int64, status string) (PaymentRequest, error) {
+	var request PaymentRequest
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+		request, err = q.GetPaymentRequestForUpdate(ctx, id)
+		if err != nil {
+			return err
+		}
+		if !request.IsPending() {
+			return ErrPaymentRequestNotPending
+		}
+
+		actor := request.Payer
+		if status == PAYMENT_REQUEST_CANCELLED {
+			actor = request.Requester
+		}
+
+		request, err = finishPaymentRequest(ctx, q, request, status, actor, sql.NullInt64{})
+		return err
+	})
+
+	return request, err
+}
+
+// finishPaymentRequest moves a locked request to its final status and records who moved it
+func finishPaymentRequest(ctx context.Context, q *Queries, request PaymentRequest, status string, actor string, transferID sql.NullInt64) (PaymentRequest, error) {
+	request, err := q.SettlePaymentRequest(ctx, SettlePaymentRequestParams{
+		ID: request.ID,
+		Status: status,
+		TransferID: transferID,
+	})
+	if err != nil {
+		return request, err
+	}
+
+	_, err = q.CreatePaymentRequestEvent(ctx, CreatePaymentRequestEventParams{
+		PaymentRequestID: request.ID,
+		Status: status,
+		Actor: sql.NullString{String: actor, Valid: true},
+	})
+	return request, err
+}
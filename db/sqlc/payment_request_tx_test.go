@@ -0,0 +1,185 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createRandomPaymentRequest requests an amount from the owner of the second account, paid into the first one
+func createRandomPaymentRequest(t *testing.T, amount int64, expiresAt time.Time) (PaymentRequest, []Account) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+
+	arg := CreatePaymentRequestParams{
+		Requester: accounts[0].Owner,
+		Payer: accounts[1].Owner,
+		AccountID: accounts[0].ID,
+		Amount: amount,
+		Currency: util.USD,
+		Note: util.RandomString(12),
+		ExpiresAt: expiresAt,
+	}
+
+	request, err := store.CreatePaymentRequestTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, request.ID)
+	require.Equal(t, arg.Requester, request.Requester)
+	require.Equal(t, arg.Payer, request.Payer)
+	require.Equal(t, arg.AccountID, request.AccountID)
+	require.Equal(t, arg.Amount, request.Amount)
+	require.Equal(t, arg.Note, request.Note)
+	require.Equal(t, PAYMENT_REQUEST_PENDING, request.Status)
+	require.False(t, request.TransferID.Valid)
+	return request, accounts
+}
+
+func requirePaymentRequestEvents(t *testing.T, request PaymentRequest, statuses ...string) []PaymentRequestEvent {
+	events, err := testQueries.ListPaymentRequestEvents(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Len(t, events, len(statuses))
+	for i, event := range events {
+		require.Equal(t, statuses[i], event.Status)
+	}
+	return events
+}
+
+func TestCreatePaymentRequestTx(t *testing.T) {
+	request, accounts := createRandomPaymentRequest(t, 300, time.Now().Add(time.Hour))
+
+	events := requirePaymentRequestEvents(t, request, PAYMENT_REQUEST_PENDING)
+	require.Equal(t, sql.NullString{String: request.Requester, Valid: true}, events[0].Actor)
+
+	incoming, err := testQueries.ListIncomingPaymentRequests(context.Background(), ListIncomingPaymentRequestsParams{
+		Payer: accounts[1].Owner,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, incoming, 1)
+	require.Equal(t, request.ID, incoming[0].ID)
+
+	outgoing, err := testQueries.ListOutgoingPaymentRequests(context.Background(), ListOutgoingPaymentRequestsParams{
+		Requester: accounts[0].Owner,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Len(t, outgoing, 1)
+	require.Equal(t, request.ID, outgoing[0].ID)
+}
+
+func TestAcceptPaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+	request, accounts := createRandomPaymentRequest(t, 300, time.Now().Add(time.Hour))
+
+	// the request is paid into the account of the requester, not from it
+	_, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		ID: request.ID,
+		FromAccountID: accounts[0].ID,
+	})
+	require.ErrorIs(t, err, ErrSameAccount)
+
+	result, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		ID: request.ID,
+		FromAccountID: accounts[1].ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_ACCEPTED, result.PaymentRequest.Status)
+	require.Equal(t, sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true}, result.PaymentRequest.TransferID)
+	require.Equal(t, accounts[1].ID, result.Transfer.Transfer.FromAccountID)
+	require.Equal(t, accounts[0].ID, result.Transfer.Transfer.ToAccountID)
+	require.Equal(t, int64(300), result.Transfer.Transfer.Amount)
+	require.Equal(t, accounts[0].Balance+300, result.Transfer.ToAccount.Balance)
+
+	events := requirePaymentRequestEvents(t, request, PAYMENT_REQUEST_PENDING, PAYMENT_REQUEST_ACCEPTED)
+	require.Equal(t, sql.NullString{String: request.Payer, Valid: true}, events[1].Actor)
+
+	// a request is paid once
+	_, err = store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		ID: request.ID,
+		FromAccountID: accounts[1].ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	_, err = store.CancelPaymentRequestTx(context.Background(), request.ID)
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+}
+
+func TestAcceptPaymentRequestTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	request, accounts := createRandomPaymentRequest(t, 5000, time.Now().Add(time.Hour))
+
+	_, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		ID: request.ID,
+		FromAccountID: accounts[1].ID,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the failed payment is rolled back, the request can still be paid or declined
+	request, err = testQueries.GetPaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_PENDING, request.Status)
+	requirePaymentRequestEvents(t, request, PAYMENT_REQUEST_PENDING)
+}
+
+func TestDeclinePaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+	request, _ := createRandomPaymentRequest(t, 300, time.Now().Add(time.Hour))
+
+	declined, err := store.DeclinePaymentRequestTx(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_DECLINED, declined.Status)
+	require.False(t, declined.TransferID.Valid)
+
+	events := requirePaymentRequestEvents(t, request, PAYMENT_REQUEST_PENDING, PAYMENT_REQUEST_DECLINED)
+	require.Equal(t, sql.NullString{String: request.Payer, Valid: true}, events[1].Actor)
+
+	_, err = store.DeclinePaymentRequestTx(context.Background(), request.ID)
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+}
+
+func TestCancelPaymentRequestTx(t *testing.T) {
+	store := NewStore(testDB)
+	request, accounts := createRandomPaymentRequest(t, 300, time.Now().Add(time.Hour))
+
+	cancelled, err := store.CancelPaymentRequestTx(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_CANCELLED, cancelled.Status)
+
+	events := requirePaymentRequestEvents(t, request, PAYMENT_REQUEST_PENDING, PAYMENT_REQUEST_CANCELLED)
+	require.Equal(t, sql.NullString{String: request.Requester, Valid: true}, events[1].Actor)
+
+	// a cancelled request leaves the inbox of the payer
+	incoming, err := testQueries.ListIncomingPaymentRequests(context.Background(), ListIncomingPaymentRequestsParams{
+		Payer: accounts[1].Owner,
+		Limit: 5,
+	})
+	require.NoError(t, err)
+	require.Empty(t, incoming)
+}
+
+func TestExpirePaymentRequests(t *testing.T) {
+	store := NewStore(testDB)
+	request, accounts := createRandomPaymentRequest(t, 300, time.Now().Add(-time.Second))
+
+	// a request past its expiry cannot be paid, even before it is marked expired
+	_, err := store.AcceptPaymentRequestTx(context.Background(), AcceptPaymentRequestTxParams{
+		ID: request.ID,
+		FromAccountID: accounts[1].ID,
+	})
+	require.ErrorIs(t, err, ErrPaymentRequestNotPending)
+
+	n, err := testQueries.ExpirePaymentRequests(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, n, int64(1))
+
+	request, err = testQueries.GetPaymentRequest(context.Background(), request.ID)
+	require.NoError(t, err)
+	require.Equal(t, PAYMENT_REQUEST_EXPIRED, request.Status)
+
+	events := requirePaymentRequestEvents(t, request, PAYMENT_REQUEST_PENDING, PAYMENT_REQUEST_EXPIRED)
+	require.False(t, events[1].Actor.Valid)
+}
@@ -4,26 +4,195 @@ package db
 
 import (
 	"context"
+	"time"
 )
 
 type Querier interface {
+	// Accrues the UTC day on every savings account with a positive balance at its end, at the rate of the currency on
+	// that day. The balance at the end of the day is the balance less the entries posted since, so a late run accrues
+	// on the same balance. An account accrues a day at most once.
+	AccrueInterest(ctx context.Context, accrualDate time.Time) (int64, error)
 	AddAccountBalance(ctx context.Context, arg AddAccountBalanceParams) (Account, error)
+	// Adding to the usage locks its row until the transaction ends, adding 0 reads the usage and locks it
+	AddAccountTransferUsage(ctx context.Context, arg AddAccountTransferUsageParams) (AccountTransferUsage, error)
+	// Adding to the usage locks its row until the transaction ends, adding 0 reads the usage and locks it
+	AddTransferUsage(ctx context.Context, arg AddTransferUsageParams) (TransferUsage, error)
+	CloseAccountsByOwner(ctx context.Context, owner string) error
+	// Counts the transfers identical to a new one, same accounts and same amount
+	CountRepeatedTransfers(ctx context.Context, arg CountRepeatedTransfersParams) (int64, error)
+	CountTransfersFromAccountSince(ctx context.Context, arg CountTransfersFromAccountSinceParams) (int64, error)
+	// Counts the transfers the user made, from its own accounts and from the accounts shared with it
+	CountTransfersRequestedBySince(ctx context.Context, arg CountTransfersRequestedBySinceParams) (int64, error)
+	// The wallet of the owner is created along with its first account, and the owner is made the first owner member
 	CreateAccount(ctx context.Context, arg CreateAccountParams) (Account, error)
+	CreateAccountInvite(ctx context.Context, arg CreateAccountInviteParams) (AccountInvite, error)
+	CreateAccountMember(ctx context.Context, arg CreateAccountMemberParams) (AccountMember, error)
+	CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error)
+	CreateConversion(ctx context.Context, arg CreateConversionParams) (Conversion, error)
+	CreateCurrency(ctx context.Context, arg CreateCurrencyParams) (Currency, error)
 	CreateEntry(ctx context.Context, arg CreateEntryParams) (Entry, error)
+	CreateErasureRequest(ctx context.Context, pseudonym string) (ErasureRequest, error)
+	CreateFraudRule(ctx context.Context, arg CreateFraudRuleParams) (FraudRule, error)
+	CreateHeldTransfer(ctx context.Context, arg CreateHeldTransferParams) (HeldTransfer, error)
+	CreateHold(ctx context.Context, arg CreateHoldParams) (Hold, error)
+	// A day already run is left as is and no row is returned
+	CreateInterestAccrualRun(ctx context.Context, arg CreateInterestAccrualRunParams) (InterestAccrualRun, error)
+	CreateInterestPosting(ctx context.Context, arg CreateInterestPostingParams) (InterestPosting, error)
+	CreateOauthAuthorizationCode(ctx context.Context, arg CreateOauthAuthorizationCodeParams) (OauthAuthorizationCode, error)
+	CreateOauthClient(ctx context.Context, arg CreateOauthClientParams) (OauthClient, error)
+	CreateOauthRefreshToken(ctx context.Context, arg CreateOauthRefreshTokenParams) (OauthRefreshToken, error)
+	CreatePasswordReset(ctx context.Context, arg CreatePasswordResetParams) (PasswordReset, error)
+	CreatePayee(ctx context.Context, arg CreatePayeeParams) (Payee, error)
+	CreatePaymentRequest(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	CreatePaymentRequestEvent(ctx context.Context, arg CreatePaymentRequestEventParams) (PaymentRequestEvent, error)
+	// Concurrent requests for the same closed period compute the same lines, the first one is kept
+	// and sql.ErrNoRows is returned to the others
+	CreateStatement(ctx context.Context, arg CreateStatementParams) (Statement, error)
 	CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error)
 	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	CreateVerifyEmail(ctx context.Context, arg CreateVerifyEmailParams) (VerifyEmail, error)
 	DeleteAccount(ctx context.Context, id int64) error
+	DeleteAccountInvite(ctx context.Context, arg DeleteAccountInviteParams) (AccountInvite, error)
+	// The invites to the user and the invites it sent are dropped with it
+	DeleteAccountInvitesByUser(ctx context.Context, username string) error
+	DeleteAccountMember(ctx context.Context, arg DeleteAccountMemberParams) (AccountMember, error)
+	// The user leaves the accounts held by others, the memberships of the accounts it holds are kept
+	DeleteAccountMembershipsByUser(ctx context.Context, username string) error
+	DeleteAccountTransferLimit(ctx context.Context, accountID int64) (AccountTransferLimit, error)
+	DeleteApiKeysByUser(ctx context.Context, username string) error
 	DeleteEntry(ctx context.Context, id int64) error
+	DeleteFeeSchedule(ctx context.Context, arg DeleteFeeScheduleParams) (FeeSchedule, error)
+	DeleteOauthAuthorizationCodesByUser(ctx context.Context, username string) error
+	DeleteOauthRefreshTokensByUser(ctx context.Context, username string) error
+	DeletePasswordResetsByUser(ctx context.Context, username string) error
+	DeletePayee(ctx context.Context, arg DeletePayeeParams) (Payee, error)
+	DeletePayeesByOwner(ctx context.Context, owner string) error
 	DeleteTransfer(ctx context.Context, id int64) error
+	DeleteUserTransferLimit(ctx context.Context, arg DeleteUserTransferLimitParams) (UserTransferLimit, error)
+	DeleteVerifyEmailsByUser(ctx context.Context, username string) error
+	ExpireHolds(ctx context.Context) (int64, error)
+	// Every expired request gets its expired event, which is what the rows counted are.
+	// A request accepted with a transfer held for review waits for the review instead.
+	ExpirePaymentRequests(ctx context.Context) (int64, error)
 	GetAccount(ctx context.Context, id int64) (Account, error)
+	GetAccountBalanceBefore(ctx context.Context, arg GetAccountBalanceBeforeParams) (int64, error)
 	GetAccountForUpdate(ctx context.Context, id int64) (Account, error)
+	GetAccountMember(ctx context.Context, arg GetAccountMemberParams) (AccountMember, error)
+	GetAccountTransferLimit(ctx context.Context, accountID int64) (AccountTransferLimit, error)
+	GetApiKeyByHash(ctx context.Context, keyHash string) (ApiKey, error)
+	GetConversion(ctx context.Context, id int64) (Conversion, error)
+	GetCurrency(ctx context.Context, code string) (Currency, error)
 	GetEntry(ctx context.Context, id int64) (Entry, error)
+	GetExchangeRate(ctx context.Context, arg GetExchangeRateParams) (ExchangeRate, error)
+	GetFeeSchedule(ctx context.Context, arg GetFeeScheduleParams) (FeeSchedule, error)
+	GetFraudRule(ctx context.Context, id int64) (FraudRule, error)
+	// The funds the active holds reserve on the account, an expired hold reserves nothing even before it is marked
+	GetHeldAmount(ctx context.Context, accountID int64) (int64, error)
+	GetHeldTransfer(ctx context.Context, id int64) (HeldTransfer, error)
+	GetHeldTransferForUpdate(ctx context.Context, id int64) (HeldTransfer, error)
+	GetHold(ctx context.Context, id int64) (Hold, error)
+	GetHoldForUpdate(ctx context.Context, id int64) (Hold, error)
+	// The whole minor units the account accrued before period_end less what was posted to it, the fraction is carried
+	// to the next posting so that rounding never pays more or less than accrued over time
+	GetInterestDue(ctx context.Context, arg GetInterestDueParams) (int64, error)
+	GetLastInterestAccrualRun(ctx context.Context) (InterestAccrualRun, error)
+	GetOauthClient(ctx context.Context, clientID string) (OauthClient, error)
+	// The checking account of the owner in the currency, the one transfers to the owner are paid into
+	GetOwnerAccount(ctx context.Context, arg GetOwnerAccountParams) (Account, error)
+	GetPayee(ctx context.Context, arg GetPayeeParams) (Payee, error)
+	GetPayeeByAccount(ctx context.Context, arg GetPayeeByAccountParams) (Payee, error)
+	GetPaymentRequest(ctx context.Context, id int64) (PaymentRequest, error)
+	GetPaymentRequestForUpdate(ctx context.Context, id int64) (PaymentRequest, error)
+	GetStatement(ctx context.Context, arg GetStatementParams) (Statement, error)
 	GetTransfer(ctx context.Context, id int64) (Transfer, error)
+	// The limits which apply to the user in the currency, its own ones or else the defaults
+	GetTransferLimit(ctx context.Context, arg GetTransferLimitParams) (GetTransferLimitRow, error)
 	GetUser(ctx context.Context, username string) (User, error)
+	GetUserByEmail(ctx context.Context, email string) (User, error)
+	GetWallet(ctx context.Context, id int64) (Wallet, error)
+	// The balances of a wallet are the checking accounts of its owner
+	GetWalletAccount(ctx context.Context, arg GetWalletAccountParams) (Account, error)
+	// Tells whether any account of the owner has ever paid the account
+	HasTransferredTo(ctx context.Context, arg HasTransferredToParams) (bool, error)
+	// Marks the outstanding reset tokens of the user as used, once the password is reset or changed
+	InvalidatePasswordResets(ctx context.Context, username string) error
+	// Marks the outstanding verification codes of the user as used, once a new one replaces them
+	InvalidateVerifyEmails(ctx context.Context, username string) error
+	// The invites to the account its users have not answered yet
+	ListAccountInvites(ctx context.Context, accountID int64) ([]AccountInvite, error)
+	ListAccountMembers(ctx context.Context, accountID int64) ([]AccountMember, error)
+	ListAccountTransferUsage(ctx context.Context, arg ListAccountTransferUsageParams) ([]AccountTransferUsage, error)
+	// The accounts the user is a member of, in any role
 	ListAccounts(ctx context.Context, arg ListAccountsParams) ([]Account, error)
+	ListAccountsByOwner(ctx context.Context, owner string) ([]Account, error)
+	ListActiveHolds(ctx context.Context, accountID int64) ([]Hold, error)
+	ListApiKeysByUser(ctx context.Context, username string) ([]ApiKey, error)
+	ListCurrencies(ctx context.Context) ([]Currency, error)
+	ListDefaultTransferLimits(ctx context.Context) ([]TransferLimit, error)
+	ListEnabledFraudRules(ctx context.Context) ([]FraudRule, error)
 	ListEntries(ctx context.Context, arg ListEntriesParams) ([]Entry, error)
+	ListEntriesByOwner(ctx context.Context, owner string) ([]Entry, error)
+	ListExchangeRates(ctx context.Context) ([]ExchangeRate, error)
+	ListFeeSchedules(ctx context.Context) ([]FeeSchedule, error)
+	ListFraudRules(ctx context.Context) ([]FraudRule, error)
+	ListHeldAmounts(ctx context.Context, accountIds []int64) ([]ListHeldAmountsRow, error)
+	ListHeldTransfers(ctx context.Context, arg ListHeldTransfersParams) ([]HeldTransfer, error)
+	// The inbox of the payer, the requests it can still accept
+	ListIncomingPaymentRequests(ctx context.Context, arg ListIncomingPaymentRequestsParams) ([]PaymentRequest, error)
+	// The accounts with whole minor units accrued before period_end and not posted yet, but for those already posted
+	// for the period
+	ListInterestDue(ctx context.Context, arg ListInterestDueParams) ([]ListInterestDueRow, error)
+	ListInterestPostings(ctx context.Context, accountID int64) ([]InterestPosting, error)
+	ListInterestRates(ctx context.Context) ([]InterestRate, error)
+	// The requests the requester sent, in any status
+	ListOutgoingPaymentRequests(ctx context.Context, arg ListOutgoingPaymentRequestsParams) ([]PaymentRequest, error)
+	ListPayees(ctx context.Context, owner string) ([]Payee, error)
+	ListPaymentRequestEvents(ctx context.Context, paymentRequestID int64) ([]PaymentRequestEvent, error)
+	// The current names of the holders of the counterparty accounts of the entries, the snapshots do not store them
+	// so that an erased user is not named in the statements of others
+	ListStatementCounterpartyNames(ctx context.Context, entryIds []int64) ([]ListStatementCounterpartyNamesRow, error)
+	// The entries of the period with their counterparty, the account of a recipient the server resolved is kept from its sender
+	ListStatementEntries(ctx context.Context, arg ListStatementEntriesParams) ([]ListStatementEntriesRow, error)
+	// The limits which apply to the user in every currency with limits
+	ListTransferLimits(ctx context.Context, username string) ([]ListTransferLimitsRow, error)
+	ListTransferUsage(ctx context.Context, arg ListTransferUsageParams) ([]TransferUsage, error)
 	ListTransfers(ctx context.Context, arg ListTransfersParams) ([]Transfer, error)
+	ListTransfersByOwner(ctx context.Context, owner string) ([]Transfer, error)
+	// The invites the user has not answered yet, newest first
+	ListUserAccountInvites(ctx context.Context, username string) ([]AccountInvite, error)
+	ListUserTransferLimits(ctx context.Context, username string) ([]UserTransferLimit, error)
+	ListWalletAccounts(ctx context.Context, walletID int64) ([]Account, error)
+	// The rows are locked in the order of their ids, as every transaction writing several accounts must do
+	LockAccounts(ctx context.Context, ids []int64) ([]Account, error)
+	PseudonymizeUser(ctx context.Context, arg PseudonymizeUserParams) (User, error)
+	ReviewHeldTransfer(ctx context.Context, arg ReviewHeldTransferParams) (HeldTransfer, error)
+	RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (ApiKey, error)
+	// The transfers from and to the account which match every filter, newest first. The pattern is matched
+	// case-insensitively against the description and the reference, an empty pattern, reference or metadata object
+	// matches every transfer.
+	SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error)
+	SetHoldTransfer(ctx context.Context, arg SetHoldTransferParams) (Hold, error)
+	SettleHold(ctx context.Context, arg SettleHoldParams) (Hold, error)
+	SettlePaymentRequest(ctx context.Context, arg SettlePaymentRequestParams) (PaymentRequest, error)
 	UpdateAccount(ctx context.Context, arg UpdateAccountParams) (Account, error)
+	UpdateCurrency(ctx context.Context, arg UpdateCurrencyParams) (Currency, error)
+	UpdateFraudRule(ctx context.Context, arg UpdateFraudRuleParams) (FraudRule, error)
+	// Only the nickname changes, a payee paying another account is a new payee with its own cooling-off period
+	UpdatePayee(ctx context.Context, arg UpdatePayeeParams) (Payee, error)
+	UpdateUser(ctx context.Context, arg UpdateUserParams) (User, error)
+	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) (User, error)
+	UpsertAccountTransferLimit(ctx context.Context, arg UpsertAccountTransferLimitParams) (AccountTransferLimit, error)
+	UpsertDefaultTransferLimit(ctx context.Context, arg UpsertDefaultTransferLimitParams) (TransferLimit, error)
+	UpsertExchangeRate(ctx context.Context, arg UpsertExchangeRateParams) (ExchangeRate, error)
+	UpsertFeeSchedule(ctx context.Context, arg UpsertFeeScheduleParams) (FeeSchedule, error)
+	UpsertInterestRate(ctx context.Context, arg UpsertInterestRateParams) (InterestRate, error)
+	UpsertUserTransferLimit(ctx context.Context, arg UpsertUserTransferLimitParams) (UserTransferLimit, error)
+	UseOauthAuthorizationCode(ctx context.Context, arg UseOauthAuthorizationCodeParams) (OauthAuthorizationCode, error)
+	// Refresh tokens issued before the last password change of their user can no longer be used
+	UseOauthRefreshToken(ctx context.Context, arg UseOauthRefreshTokenParams) (OauthRefreshToken, error)
+	UsePasswordReset(ctx context.Context, tokenHash string) (PasswordReset, error)
+	UseVerifyEmail(ctx context.Context, arg UseVerifyEmailParams) (VerifyEmail, error)
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (User, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,37 @@
+package db
+
+import "context"
+
+type ResetPasswordTxParams struct {
+	TokenHash string `json:"token_hash"`
+	HashedPassword string `json:"hashed_password"`
+}
+
+// ResetPasswordTx consumes a password reset token and sets the new password of its owner.
+// The token is marked as used in the same transaction, so it can never be redeemed twice.
+// The other outstanding tokens of the user are invalidated with it.
+// sql.ErrNoRows is returned when the token does not exist, has already been used or has expired.
+func (store *SQLStore) ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error) {
+	var user User
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		reset, err := q.UsePasswordReset(ctx, arg.TokenHash)
+		if err != nil {
+			return err
+		}
+
+		err = q.InvalidatePasswordResets(ctx, reset.Username)
+		if err != nil {
+			return err
+		}
+
+		user, err = q.UpdateUserPassword(ctx, UpdateUserPasswordParams{
+			Username: reset.Username,
+			HashedPassword: arg.HashedPassword,
+		})
+
+		return err
+	})
+
+	return user, err
+}
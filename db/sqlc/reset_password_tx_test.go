@@ -0,0 +1,42 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResetPasswordTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	reset := createRandomPasswordReset(t, user, time.Minute)
+	other := createRandomPasswordReset(t, user, time.Minute)
+
+	password := util.RandomString(10)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	arg := ResetPasswordTxParams{
+		TokenHash: reset.TokenHash,
+		HashedPassword: hashedPassword,
+	}
+
+	updatedUser, err := store.ResetPasswordTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, updatedUser.Username)
+	require.NoError(t, util.CheckPassword(updatedUser.HashedPassword, password))
+	require.True(t, updatedUser.PasswordChangedAt.After(user.PasswordChangedAt))
+
+	// the token has been consumed by the first reset
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	// and the other tokens of the user went with it
+	arg.TokenHash = other.TokenHash
+	_, err = store.ResetPasswordTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
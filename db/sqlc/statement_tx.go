@@ -0,0 +1,186 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// StatementLine is an entry of a statement with the balance of the account right after it
+type StatementLine struct {
+	EntryID int64 `json:"entry_id"`
+	Amount int64 `json:"amount"`
+	Balance int64 `json:"balance"`
+	TransferID int64 `json:"transfer_id,omitempty"`
+	ConversionID int64 `json:"conversion_id,omitempty"`
+	// FeeTransferID is the transfer the entry charged the fee of
+	FeeTransferID int64 `json:"fee_transfer_id,omitempty"`
+	CounterpartyAccountID int64 `json:"counterparty_account_id,omitempty"`
+	CounterpartyCurrency string `json:"counterparty_currency,omitempty"`
+	CounterpartyName string `json:"counterparty_name,omitempty"`
+	// Description and Reference are those the sender gave the transfer of the entry
+	Description string `json:"description,omitempty"`
+	Reference string `json:"reference,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AccountStatement struct {
+	Account Account `json:"account"`
+	PeriodStart time.Time `json:"period_start"`
+	PeriodEnd time.Time `json:"period_end"`
+	OpeningBalance int64 `json:"opening_balance"`
+	ClosingBalance int64 `json:"closing_balance"`
+	Lines []StatementLine `json:"lines"`
+	// GeneratedAt is when the lines were computed, which is when the snapshot was taken for closed periods
+	GeneratedAt time.Time `json:"generated_at"`
+	IsSnapshot bool `json:"is_snapshot"`
+}
+
+type StatementTxParams struct {
+	AccountID int64 `json:"account_id"`
+	PeriodStart time.Time `json:"period_start"`
+	// PeriodEnd is exclusive
+	PeriodEnd time.Time `json:"period_end"`
+	// Snapshot is set for closed calendar months, whose statement is stored the first time and served as is afterwards
+	Snapshot bool `json:"snapshot"`
+}
+
+// StatementTx computes the statement of an account from its entries.
+// It runs in a repeatable read transaction, so the opening balance and the lines come from the same snapshot
+// and the closing balance always equals the opening balance plus the entries.
+func (store *SQLStore) StatementTx(ctx context.Context, arg StatementTxParams) (AccountStatement, error) {
+	statement := AccountStatement{
+		PeriodStart: arg.PeriodStart,
+		PeriodEnd: arg.PeriodEnd,
+		IsSnapshot: arg.Snapshot,
+	}
+
+	err := store.execTxWithOptions(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead}, func(q *Queries) error {
+		var err error
+		statement.Account, err = q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if arg.Snapshot {
+			snapshot, err := q.GetStatement(ctx, GetStatementParams{
+				AccountID: arg.AccountID,
+				PeriodStart: arg.PeriodStart,
+				PeriodEnd: arg.PeriodEnd,
+			})
+			if err == nil {
+				statement.OpeningBalance = snapshot.OpeningBalance
+				statement.ClosingBalance = snapshot.ClosingBalance
+				statement.GeneratedAt = snapshot.CreatedAt
+				err = json.Unmarshal(snapshot.Lines, &statement.Lines)
+				if err != nil {
+					return err
+				}
+				return nameCounterparties(ctx, q, statement.Lines)
+			}
+			if !errors.Is(err, sql.ErrNoRows) {
+				return err
+			}
+		}
+
+		statement.OpeningBalance, err = q.GetAccountBalanceBefore(ctx, GetAccountBalanceBeforeParams{
+			AccountID: arg.AccountID,
+			CreatedAt: arg.PeriodStart,
+		})
+		if err != nil {
+			return err
+		}
+
+		entries, err := q.ListStatementEntries(ctx, ListStatementEntriesParams{
+			AccountID: arg.AccountID,
+			PeriodStart: arg.PeriodStart,
+			PeriodEnd: arg.PeriodEnd,
+		})
+		if err != nil {
+			return err
+		}
+
+		balance := statement.OpeningBalance
+		statement.Lines = make([]StatementLine, len(entries))
+		for i, entry := range entries {
+			balance += entry.Amount
+			statement.Lines[i] = StatementLine{
+				EntryID: entry.ID,
+				Amount: entry.Amount,
+				Balance: balance,
+				TransferID: entry.TransferID.Int64,
+				ConversionID: entry.ConversionID.Int64,
+				FeeTransferID: entry.FeeTransferID.Int64,
+				CounterpartyAccountID: entry.CounterpartyAccountID.Int64,
+				CounterpartyCurrency: entry.CounterpartyCurrency.String,
+				CounterpartyName: entry.CounterpartyName.String,
+				Description: entry.TransferDescription.String,
+				Reference: entry.TransferReference.String,
+				CreatedAt: entry.CreatedAt,
+			}
+		}
+		statement.ClosingBalance = balance
+		statement.GeneratedAt = time.Now()
+
+		if !arg.Snapshot {
+			return nil
+		}
+
+		// the names are looked up whenever the snapshot is served, they change when a user is erased
+		unnamed := make([]StatementLine, len(statement.Lines))
+		for i, line := range statement.Lines {
+			line.CounterpartyName = ""
+			unnamed[i] = line
+		}
+
+		lines, err := json.Marshal(unnamed)
+		if err != nil {
+			return err
+		}
+
+		snapshot, err := q.CreateStatement(ctx, CreateStatementParams{
+			AccountID: arg.AccountID,
+			PeriodStart: arg.PeriodStart,
+			PeriodEnd: arg.PeriodEnd,
+			OpeningBalance: statement.OpeningBalance,
+			ClosingBalance: statement.ClosingBalance,
+			Lines: lines,
+		})
+		if errors.Is(err, sql.ErrNoRows) {
+			// a concurrent request stored the same lines first
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		statement.GeneratedAt = snapshot.CreatedAt
+		return nil
+	})
+
+	return statement, err
+}
+
+// nameCounterparties sets the current names of the counterparties on the lines of a snapshot
+func nameCounterparties(ctx context.Context, q *Queries, lines []StatementLine) error {
+	ids := make([]int64, len(lines))
+	for i, line := range lines {
+		ids[i] = line.EntryID
+	}
+
+	rows, err := q.ListStatementCounterpartyNames(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[int64]string, len(rows))
+	for _, row := range rows {
+		names[row.ID] = row.CounterpartyName
+	}
+	for i := range lines {
+		lines[i].CounterpartyName = names[lines[i].EntryID]
+	}
+	return nil
+}
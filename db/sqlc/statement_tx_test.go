@@ -0,0 +1,193 @@
+package db
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatementTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+
+	periodStart := time.Now().Add(-time.Minute)
+	for _, amount := range []int64{100, 50} {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID: account2.ID,
+			Amount: amount,
+		})
+		require.NoError(t, err)
+	}
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID: account1.ID,
+		Amount: 30,
+	})
+	require.NoError(t, err)
+
+	arg := StatementTxParams{
+		AccountID: account1.ID,
+		PeriodStart: periodStart,
+		PeriodEnd: time.Now().Add(time.Minute),
+	}
+
+	statement, err := store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.False(t, statement.IsSnapshot)
+	require.Equal(t, account1.ID, statement.Account.ID)
+	require.Len(t, statement.Lines, 3)
+
+	balance := statement.OpeningBalance
+	for _, line := range statement.Lines {
+		balance += line.Amount
+		require.Equal(t, balance, line.Balance)
+		require.NotZero(t, line.TransferID)
+		require.Equal(t, account2.ID, line.CounterpartyAccountID)
+		require.Equal(t, account2.Currency, line.CounterpartyCurrency)
+	}
+	require.Equal(t, balance, statement.ClosingBalance)
+	require.Equal(t, []int64{-100, -50, 30}, []int64{statement.Lines[0].Amount, statement.Lines[1].Amount, statement.Lines[2].Amount})
+}
+
+func TestStatementTxSnapshot(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+
+	periodStart := time.Now().Add(-time.Minute)
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 100,
+	})
+	require.NoError(t, err)
+
+	arg := StatementTxParams{
+		AccountID: account1.ID,
+		PeriodStart: periodStart,
+		PeriodEnd: time.Now().Add(time.Minute),
+		Snapshot: true,
+	}
+
+	first, err := store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, first.IsSnapshot)
+	require.Len(t, first.Lines, 1)
+
+	// entries posted after the snapshot was taken do not change the statement
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 10,
+	})
+	require.NoError(t, err)
+
+	second, err := store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, first.OpeningBalance, second.OpeningBalance)
+	require.Equal(t, first.ClosingBalance, second.ClosingBalance)
+	require.Equal(t, first.Lines[0].EntryID, second.Lines[0].EntryID)
+	require.Len(t, second.Lines, 1)
+	require.WithinDuration(t, first.GeneratedAt, second.GeneratedAt, time.Millisecond)
+
+	// the current statement has both entries
+	arg.Snapshot = false
+	current, err := store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, current.Lines, 2)
+}
+
+func TestStatementTxSnapshotErasedCounterparty(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := CreateRandomAccount(t)
+	counterparty, err := testQueries.GetUser(context.Background(), account2.Owner)
+	require.NoError(t, err)
+
+	periodStart := time.Now().Add(-time.Minute)
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 100,
+	})
+	require.NoError(t, err)
+
+	arg := StatementTxParams{
+		AccountID: account1.ID,
+		PeriodStart: periodStart,
+		PeriodEnd: time.Now().Add(time.Minute),
+		Snapshot: true,
+	}
+
+	first, err := store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, counterparty.FullName, first.Lines[0].CounterpartyName)
+
+	// the snapshot stores the account of the counterparty but not its name
+	snapshot, err := testQueries.GetStatement(context.Background(), GetStatementParams{
+		AccountID: arg.AccountID,
+		PeriodStart: arg.PeriodStart,
+		PeriodEnd: arg.PeriodEnd,
+	})
+	require.NoError(t, err)
+	require.NotContains(t, string(snapshot.Lines), counterparty.FullName)
+
+	_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account2.ID, Balance: 0})
+	require.NoError(t, err)
+	_, err = store.EraseUserTx(context.Background(), EraseUserTxParams{
+		Username: account2.Owner,
+		Pseudonym: fmt.Sprintf("erased-%s", uuid.New()),
+	})
+	require.NoError(t, err)
+
+	second, err := store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, first.Lines[0].EntryID, second.Lines[0].EntryID)
+	require.Equal(t, account2.ID, second.Lines[0].CounterpartyAccountID)
+	require.Equal(t, ERASED_USER_FULL_NAME, second.Lines[0].CounterpartyName)
+}
+
+func TestStatementTxHiddenRecipient(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
+
+	periodStart := time.Now().Add(-time.Minute)
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 100,
+		RecipientHidden: true,
+	})
+	require.NoError(t, err)
+
+	arg := StatementTxParams{
+		AccountID: account1.ID,
+		PeriodStart: periodStart,
+		PeriodEnd: time.Now().Add(time.Minute),
+	}
+
+	// the sender sees who it paid but not the account
+	statement, err := store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, statement.Lines, 1)
+	require.Zero(t, statement.Lines[0].CounterpartyAccountID)
+	require.NotEmpty(t, statement.Lines[0].CounterpartyName)
+
+	// the recipient still sees the account of the sender
+	arg.AccountID = account2.ID
+	statement, err = store.StatementTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, statement.Lines, 1)
+	require.Equal(t, account1.ID, statement.Lines[0].CounterpartyAccountID)
+}
@@ -3,12 +3,44 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"time"
 )
 
+// ErrInsufficientFunds is returned when a transfer would overdraw the available balance of the account it is debited from
+var ErrInsufficientFunds = errors.New("the balance of the account is insufficient")
+
+// ErrAccountClosed is returned when a transfer is made from or to the account of an erased user
+var ErrAccountClosed = errors.New("the account is closed")
+
 type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
+	BatchTransferTx(ctx context.Context, arg BatchTransferTxParams) (BatchTransferTxResult, error)
+	ReviewHeldTransferTx(ctx context.Context, arg ReviewHeldTransferTxParams) (ReviewHeldTransferTxResult, error)
+	ConvertCurrencyTx(ctx context.Context, arg ConvertCurrencyTxParams) (ConvertCurrencyTxResult, error)
+	StatementTx(ctx context.Context, arg StatementTxParams) (AccountStatement, error)
+	ResetPasswordTx(ctx context.Context, arg ResetPasswordTxParams) (User, error)
+	PlaceHoldTx(ctx context.Context, arg PlaceHoldTxParams) (Hold, error)
+	CaptureHoldTx(ctx context.Context, arg CaptureHoldTxParams) (CaptureHoldTxResult, error)
+	VoidHoldTx(ctx context.Context, id int64) (Hold, error)
+	CreatePaymentRequestTx(ctx context.Context, arg CreatePaymentRequestParams) (PaymentRequest, error)
+	AcceptPaymentRequestTx(ctx context.Context, arg AcceptPaymentRequestTxParams) (AcceptPaymentRequestTxResult, error)
+	DeclinePaymentRequestTx(ctx context.Context, id int64) (PaymentRequest, error)
+	CancelPaymentRequestTx(ctx context.Context, id int64) (PaymentRequest, error)
+	AccrueInterestTx(ctx context.Context, accrualDate time.Time) (InterestAccrualRun, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (InterestPosting, error)
+	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error)
+	ResendVerifyEmailTx(ctx context.Context, arg ResendVerifyEmailTxParams) (VerifyEmail, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error)
+	ExportUserDataTx(ctx context.Context, username string) (UserDataExport, error)
+	EraseUserTx(ctx context.Context, arg EraseUserTxParams) (ErasureRequest, error)
+	ExchangeOauthCodeTx(ctx context.Context, arg ExchangeOauthCodeTxParams) (OauthRefreshToken, error)
+	RefreshOauthTokenTx(ctx context.Context, arg RefreshOauthTokenTxParams) (OauthRefreshToken, error)
+	AcceptAccountInviteTx(ctx context.Context, arg AcceptAccountInviteTxParams) (AccountMember, error)
 }
 
 type SQLStore struct {
@@ -24,7 +56,11 @@ func NewStore(db *sql.DB) Store {
 }
 
 func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error)  error {
-	tx, err := store.db.BeginTx(ctx, nil)
+	return store.execTxWithOptions(ctx, nil, fn)
+}
+
+func (store *SQLStore) execTxWithOptions(ctx context.Context, opts *sql.TxOptions, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, opts)
 	if err != nil {
 		return err
 	}
@@ -46,6 +82,16 @@ type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID int64 `json:"to_account_id"`
 	Amount int64 `json:"amount"`
+	// Type is the operation the transfer is made by, which picks its fee schedule, a plain transfer when empty
+	Type string `json:"type"`
+	Description string `json:"description"`
+	Reference string `json:"reference"`
+	// Metadata is a JSON object, an empty object when nil
+	Metadata json.RawMessage `json:"metadata"`
+	// RequestedBy is the user making the transfer, whose limits it counts against, the holder of the source account when empty
+	RequestedBy string `json:"requested_by"`
+	// RecipientHidden is set when the server resolved the recipient for the sender, who must not learn its account
+	RecipientHidden bool `json:"recipient_hidden"`
 }
 
 type TransferTxResult struct {
@@ -54,6 +100,8 @@ type TransferTxResult struct {
 	ToAccount Account `json:"to_account"`
 	FromEntry Entry `json:"from_entry"`
 	ToEntry Entry `json:"to_entry"`
+	// FeeEntry charged the fee to the source account, it is nil for a transfer without fee
+	FeeEntry *Entry `json:"fee_entry,omitempty"`
 }
 
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
@@ -61,44 +109,133 @@ func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (Tr
 
 	err := store.execTx(ctx, func(q *Queries) error {
 		var err error
+		result, err = transfer(ctx, q, arg)
+		return err
+	})
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID: arg.ToAccountID,
-			Amount: arg.Amount,
-		})
+	return result, err
+}
 
-		if err != nil {
-			return err
-		}
+// transfer moves the money within a transaction, for the transactions which make a transfer among other writes.
+// The source account pays the fee of the transfer on top of its amount, while only the amount counts against
+// the transfer limits of the user making it and those of the account.
+func transfer(ctx context.Context, q *Queries, arg TransferTxParams) (TransferTxResult, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount: -arg.Amount,
-		})
+	if arg.RequestedBy == "" {
+		arg.RequestedBy = fromAccount.Owner
+	}
 
-		if err != nil {
-			return err
-		}
+	transferType := arg.Type
+	if transferType == "" {
+		transferType = TRANSFER_TYPE_TRANSFER
+	}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount: arg.Amount,
-		})
+	fee, err := transferFee(ctx, q, transferType, fromAccount.Currency, arg.Amount)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
 
-		if err != nil {
-			return err
-		}
+	result, err := postTransfer(ctx, q, arg, fee)
+	if err != nil {
+		return result, err
+	}
 
-		result.FromAccount, result.ToAccount, err = TransferMoney(ctx, q, arg.FromAccountID, arg.ToAccountID, arg.Amount)
-		
-		if err != nil {
-			return err
-		}
-		
-		return nil
+	// the updated rows are locked, an account cannot be closed between this check and the commit
+	if result.FromAccount.ClosedAt.Valid || result.ToAccount.ClosedAt.Valid {
+		return result, ErrAccountClosed
+	}
+
+	// the balance is checked after the update, which holds the row lock, so that concurrent transfers cannot overdraw it.
+	// The fee is only charged at the end, what is left must cover it.
+	available, err := availableBalance(ctx, q, result.FromAccount)
+	if err != nil {
+		return result, err
+	}
+	if available < fee {
+		return result, ErrInsufficientFunds
+	}
+
+	allowance, err := lockTransferAllowance(ctx, q, arg.RequestedBy, result.FromAccount)
+	if err != nil {
+		return result, err
+	}
+
+	if err = allowance.check(arg.Amount); err != nil {
+		return result, err
+	}
+
+	allowance.use(arg.Amount)
+	if err = allowance.save(ctx, q); err != nil || fee == 0 {
+		return result, err
+	}
+
+	fromAccount, feeEntry, err := chargeFee(ctx, q, result.Transfer, fromAccount.Currency)
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount = fromAccount
+	result.FeeEntry = &feeEntry
+	return result, nil
+}
+
+// transferMetadata is the metadata stored with a transfer, the transfers made without any get an empty object
+func transferMetadata(metadata json.RawMessage) json.RawMessage {
+	if len(metadata) == 0 {
+		return json.RawMessage("{}")
+	}
+	return metadata
+}
+
+// postTransfer writes the transfer, its entries and the balances without any check, the bank posts its own
+// transfers, such as the interest it pays, with it. The fee is recorded on the transfer, charging it is left to the caller.
+func postTransfer(ctx context.Context, q *Queries, arg TransferTxParams, fee int64) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		Fee: fee,
+		Description: arg.Description,
+		Reference: arg.Reference,
+		Metadata: transferMetadata(arg.Metadata),
+		RequestedBy: sql.NullString{String: arg.RequestedBy, Valid: arg.RequestedBy != ""},
+		RecipientHidden: arg.RecipientHidden,
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.FromAccountID,
+		Amount: -arg.Amount,
+		TransferID: transferID,
 	})
 
+	if err != nil {
+		return result, err
+	}
+
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID: arg.ToAccountID,
+		Amount: arg.Amount,
+		TransferID: transferID,
+	})
+
+	if err != nil {
+		return result, err
+	}
+
+	result.FromAccount, result.ToAccount, err = TransferMoney(ctx, q, arg.FromAccountID, arg.ToAccountID, arg.Amount)
 	return result, err
 }
 
@@ -7,11 +7,24 @@ import (
 	"github.com/stretchr/testify/require"
 )
 
+// createFundedAccount creates an account with enough balance for the transfers of a test
+func createFundedAccount(t *testing.T, balance int64) Account {
+	account := CreateRandomAccount(t)
+
+	account, err := testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID: account.ID,
+		Balance: balance,
+	})
+	require.NoError(t, err)
+
+	return account
+}
+
 func TestFiveConcurrentTranferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := CreateRandomAccount(t)
-	account2 := CreateRandomAccount(t)
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
 
 	t.Logf(">>>>> Before Transaction Account1: %d Account2: %d\n", account1.Balance, account2.Balance)
 
@@ -110,8 +123,8 @@ func TestFiveConcurrentTranferTx(t *testing.T) {
 func TestInconsistentOrderTranferTx(t *testing.T) {
 	store := NewStore(testDB)
 
-	account1 := CreateRandomAccount(t)
-	account2 := CreateRandomAccount(t)
+	account1 := createFundedAccount(t, 1000)
+	account2 := createFundedAccount(t, 1000)
 
 	t.Logf(">>>>> Before Transaction Account1: %d Account2: %d\n", account1.Balance, account2.Balance)
 
@@ -156,4 +169,27 @@ func TestInconsistentOrderTranferTx(t *testing.T) {
 	t.Logf(">>>>> After all %d Transactions Account1: %d Account2: %d\n", n, updatedAccount1.Balance, updatedAccount2.Balance)
 	require.Equal(t, updatedAccount1.Balance, account1.Balance)
 	require.Equal(t, updatedAccount2.Balance, account2.Balance)
-}
\ No newline at end of file
+}
+
+func TestTransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createFundedAccount(t, 10)
+	account2 := createFundedAccount(t, 10)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 11,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the transfer is rolled back as a whole
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
@@ -5,27 +5,115 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
 )
 
+const countRepeatedTransfers = `-- name: CountRepeatedTransfers :one
+SELECT count(*) FROM transfers
+WHERE from_account_id = $1
+  AND to_account_id = $2
+  AND amount = $3
+  AND created_at >= $4
+`
+
+type CountRepeatedTransfersParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// Counts the transfers identical to a new one, same accounts and same amount
+func (q *Queries) CountRepeatedTransfers(ctx context.Context, arg CountRepeatedTransfersParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countRepeatedTransfers,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.CreatedAt,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTransfersFromAccountSince = `-- name: CountTransfersFromAccountSince :one
+SELECT count(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2
+`
+
+type CountTransfersFromAccountSinceParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+func (q *Queries) CountTransfersFromAccountSince(ctx context.Context, arg CountTransfersFromAccountSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersFromAccountSince, arg.FromAccountID, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTransfersRequestedBySince = `-- name: CountTransfersRequestedBySince :one
+SELECT count(*) FROM transfers
+WHERE requested_by = $1 AND created_at >= $2
+`
+
+type CountTransfersRequestedBySinceParams struct {
+	RequestedBy sql.NullString `json:"requested_by"`
+	CreatedAt   time.Time      `json:"created_at"`
+}
+
+// Counts the transfers the user made, from its own accounts and from the accounts shared with it
+func (q *Queries) CountTransfersRequestedBySince(ctx context.Context, arg CountTransfersRequestedBySinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersRequestedBySince, arg.RequestedBy, arg.CreatedAt)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
   from_account_id,
   to_account_id,
-  amount
+  amount,
+  fee,
+  description,
+  reference,
+  metadata,
+  requested_by,
+  recipient_hidden
 ) VALUES (
-  $1, $2, $3
+  $1, $2, $3, $4, $5, $6, $7, $8, $9
 )
-RETURNING id, from_account_id, to_account_id, amount, created_at
+RETURNING id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, requested_by, recipient_hidden
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID   int64           `json:"from_account_id"`
+	ToAccountID     int64           `json:"to_account_id"`
+	Amount          int64           `json:"amount"`
+	Fee             int64           `json:"fee"`
+	Description     string          `json:"description"`
+	Reference       string          `json:"reference"`
+	Metadata        json.RawMessage `json:"metadata"`
+	RequestedBy     sql.NullString  `json:"requested_by"`
+	RecipientHidden bool            `json:"recipient_hidden"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.Fee,
+		arg.Description,
+		arg.Reference,
+		arg.Metadata,
+		arg.RequestedBy,
+		arg.RecipientHidden,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -33,6 +121,12 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.RequestedBy,
+		&i.RecipientHidden,
 	)
 	return i, err
 }
@@ -48,7 +142,7 @@ func (q *Queries) DeleteTransfer(ctx context.Context, id int64) error {
 }
 
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, requested_by, recipient_hidden FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -61,12 +155,39 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.Fee,
+		&i.Description,
+		&i.Reference,
+		&i.Metadata,
+		&i.RequestedBy,
+		&i.RecipientHidden,
 	)
 	return i, err
 }
 
+const hasTransferredTo = `-- name: HasTransferredTo :one
+SELECT EXISTS (
+  SELECT 1 FROM transfers
+  WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+    AND to_account_id = $2
+)
+`
+
+type HasTransferredToParams struct {
+	Owner       string `json:"owner"`
+	ToAccountID int64  `json:"to_account_id"`
+}
+
+// Tells whether any account of the owner has ever paid the account
+func (q *Queries) HasTransferredTo(ctx context.Context, arg HasTransferredToParams) (bool, error) {
+	row := q.db.QueryRowContext(ctx, hasTransferredTo, arg.Owner, arg.ToAccountID)
+	var exists bool
+	err := row.Scan(&exists)
+	return exists, err
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, requested_by, recipient_hidden FROM transfers
 WHERE from_account_id = $1 or to_account_id = $2
 ORDER BY id
 LIMIT $3
@@ -100,6 +221,119 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.RequestedBy,
+			&i.RecipientHidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listTransfersByOwner = `-- name: ListTransfersByOwner :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, requested_by, recipient_hidden FROM transfers
+WHERE from_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+  OR to_account_id IN (SELECT id FROM accounts WHERE owner = $1)
+ORDER BY id
+`
+
+func (q *Queries) ListTransfersByOwner(ctx context.Context, owner string) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, listTransfersByOwner, owner)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.RequestedBy,
+			&i.RecipientHidden,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const searchTransfers = `-- name: SearchTransfers :many
+SELECT id, from_account_id, to_account_id, amount, created_at, fee, description, reference, metadata, requested_by, recipient_hidden FROM transfers
+WHERE (from_account_id = $1 OR to_account_id = $1)
+  AND ($2::text = '' OR description ILIKE $2 OR reference ILIKE $2)
+  AND ($3::text = '' OR reference = $3)
+  AND metadata @> $4::jsonb
+ORDER BY id DESC
+LIMIT $5::int
+OFFSET $6::int
+`
+
+type SearchTransfersParams struct {
+	AccountID int64           `json:"account_id"`
+	Pattern   string          `json:"pattern"`
+	Reference string          `json:"reference"`
+	Metadata  json.RawMessage `json:"metadata"`
+	RowLimit  int32           `json:"row_limit"`
+	RowOffset int32           `json:"row_offset"`
+}
+
+// The transfers from and to the account which match every filter, newest first. The pattern is matched
+// case-insensitively against the description and the reference, an empty pattern, reference or metadata object
+// matches every transfer.
+func (q *Queries) SearchTransfers(ctx context.Context, arg SearchTransfersParams) ([]Transfer, error) {
+	rows, err := q.db.QueryContext(ctx, searchTransfers,
+		arg.AccountID,
+		arg.Pattern,
+		arg.Reference,
+		arg.Metadata,
+		arg.RowLimit,
+		arg.RowOffset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []Transfer{}
+	for rows.Next() {
+		var i Transfer
+		if err := rows.Scan(
+			&i.ID,
+			&i.FromAccountID,
+			&i.ToAccountID,
+			&i.Amount,
+			&i.CreatedAt,
+			&i.Fee,
+			&i.Description,
+			&i.Reference,
+			&i.Metadata,
+			&i.RequestedBy,
+			&i.RecipientHidden,
 		); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,195 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// the periods the usage of the limits is tracked in, both in UTC
+const (
+	TRANSFER_PERIOD_DAY = "day"
+	TRANSFER_PERIOD_MONTH = "month"
+)
+
+// the limits a transfer can exceed
+const (
+	LIMIT_PER_TRANSACTION = "per_transaction"
+	LIMIT_DAILY = "daily"
+	LIMIT_MONTHLY = "monthly"
+)
+
+// ErrTransferLimitExceeded is wrapped by the TransferLimitError of a transfer over a limit of the user making it
+var ErrTransferLimitExceeded = errors.New("the transfer exceeds a transfer limit")
+
+// TransferLimitError tells which limit a transfer exceeds and what the user can still transfer under it
+type TransferLimitError struct {
+	Limit string
+	Currency string
+	Remaining int64
+	// AccountID is set when the limit is one of the source account rather than one of the user
+	AccountID int64
+}
+
+func (e *TransferLimitError) Error() string {
+	if e.AccountID != 0 {
+		return fmt.Sprintf("the transfer exceeds the %s limit of the account", e.Limit)
+	}
+	return fmt.Sprintf("the transfer exceeds the %s limit", e.Limit)
+}
+
+func (e *TransferLimitError) Unwrap() error {
+	return ErrTransferLimitExceeded
+}
+
+// TransferPeriods returns the first days of the UTC day and month of t, the periods the usage is tracked in
+func TransferPeriods(t time.Time) (day time.Time, month time.Time) {
+	t = t.UTC()
+	day = time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	month = time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return
+}
+
+// transferAllowance is what a user may still transfer in a currency, and from the source account, within a transaction
+type transferAllowance struct {
+	username string
+	currency string
+	accountID int64
+	day time.Time
+	month time.Time
+	limit GetTransferLimitRow
+	daily int64
+	monthly int64
+	// accountLimit has no limit set when the account has none
+	accountLimit AccountTransferLimit
+	accountDaily int64
+	accountMonthly int64
+	// used is what the transaction transferred so far, it is added to the usage by save
+	used int64
+}
+
+// lockTransferAllowance reads the limits and the usage of the user making the transfer in the currency of the account,
+// the holder or any member spending from it, and those of the account itself. The rows of the usage stay locked until
+// the transaction ends, so the transfers of a user are checked one after the other and concurrent transfers cannot
+// exceed a limit together. It must be called once the accounts are locked, so every transaction takes the locks in
+// the same order.
+func lockTransferAllowance(ctx context.Context, q *Queries, username string, account Account) (*transferAllowance, error) {
+	allowance := &transferAllowance{username: username, currency: account.Currency, accountID: account.ID}
+	allowance.day, allowance.month = TransferPeriods(time.Now())
+
+	var err error
+	allowance.limit, err = q.GetTransferLimit(ctx, GetTransferLimitParams{
+		Username: username,
+		Currency: account.Currency,
+	})
+	// without limits in the currency the usage is still tracked, for limits set later in the period
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	allowance.accountLimit, err = q.GetAccountTransferLimit(ctx, account.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+
+	allowance.daily, allowance.accountDaily, err = allowance.add(ctx, q, TRANSFER_PERIOD_DAY, allowance.day, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	allowance.monthly, allowance.accountMonthly, err = allowance.add(ctx, q, TRANSFER_PERIOD_MONTH, allowance.month, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return allowance, nil
+}
+
+// add adds the amount to the usage of the user and to the one of the account in the period, the user first
+func (allowance *transferAllowance) add(ctx context.Context, q *Queries, period string, periodStart time.Time, amount int64) (int64, int64, error) {
+	usage, err := q.AddTransferUsage(ctx, AddTransferUsageParams{
+		Owner: allowance.username,
+		Currency: allowance.currency,
+		Period: period,
+		PeriodStart: periodStart,
+		Amount: amount,
+	})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	accountUsage, err := q.AddAccountTransferUsage(ctx, AddAccountTransferUsageParams{
+		AccountID: allowance.accountID,
+		Period: period,
+		PeriodStart: periodStart,
+		Amount: amount,
+	})
+	return usage.Amount, accountUsage.Amount, err
+}
+
+// check tells whether the amount can be transferred on top of what the transaction already transferred,
+// under the limits of the user and then under those of the account
+func (allowance *transferAllowance) check(amount int64) error {
+	limit := allowance.limit
+	exceeded, remaining := exceededLimit(amount, limit.PerTransaction, limit.Daily, limit.Monthly,
+		allowance.daily+allowance.used, allowance.monthly+allowance.used)
+	if len(exceeded) > 0 {
+		return &TransferLimitError{Limit: exceeded, Currency: allowance.currency, Remaining: remaining}
+	}
+
+	accountLimit := allowance.accountLimit
+	exceeded, remaining = exceededLimit(amount, accountLimit.PerTransaction, accountLimit.Daily, accountLimit.Monthly,
+		allowance.accountDaily+allowance.used, allowance.accountMonthly+allowance.used)
+	if len(exceeded) > 0 {
+		return &TransferLimitError{Limit: exceeded, Currency: allowance.currency, Remaining: remaining, AccountID: allowance.accountID}
+	}
+
+	return nil
+}
+
+// exceededLimit names the first limit the amount exceeds on top of the usage and what remains of it,
+// the name is empty when the amount is within every limit
+func exceededLimit(amount int64, perTransaction sql.NullInt64, daily sql.NullInt64, monthly sql.NullInt64, dailyUsed int64, monthlyUsed int64) (string, int64) {
+	if perTransaction.Valid && amount > perTransaction.Int64 {
+		return LIMIT_PER_TRANSACTION, perTransaction.Int64
+	}
+
+	if daily.Valid && dailyUsed+amount > daily.Int64 {
+		return LIMIT_DAILY, RemainingLimit(daily.Int64, dailyUsed)
+	}
+
+	if monthly.Valid && monthlyUsed+amount > monthly.Int64 {
+		return LIMIT_MONTHLY, RemainingLimit(monthly.Int64, monthlyUsed)
+	}
+
+	return "", 0
+}
+
+func (allowance *transferAllowance) use(amount int64) {
+	allowance.used += amount
+}
+
+// save adds what the transaction transferred to the usage of the day and the month, of the user and of the account
+func (allowance *transferAllowance) save(ctx context.Context, q *Queries) error {
+	if allowance.used == 0 {
+		return nil
+	}
+
+	_, _, err := allowance.add(ctx, q, TRANSFER_PERIOD_DAY, allowance.day, allowance.used)
+	if err != nil {
+		return err
+	}
+
+	_, _, err = allowance.add(ctx, q, TRANSFER_PERIOD_MONTH, allowance.month, allowance.used)
+	return err
+}
+
+// RemainingLimit is what is left of a limit, a limit lowered below the usage leaves nothing
+func RemainingLimit(limit int64, used int64) int64 {
+	if used > limit {
+		return 0
+	}
+	return limit - used
+}
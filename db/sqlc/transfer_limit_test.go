@@ -0,0 +1,243 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func setUserTransferLimit(t *testing.T, username string, perTransaction int64, daily int64, monthly int64) {
+	_, err := testQueries.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username: username,
+		Currency: util.USD,
+		PerTransaction: sql.NullInt64{Int64: perTransaction, Valid: true},
+		Daily: sql.NullInt64{Int64: daily, Valid: true},
+		Monthly: sql.NullInt64{Int64: monthly, Valid: true},
+	})
+	require.NoError(t, err)
+}
+
+func TestTransferPeriods(t *testing.T) {
+	at := time.Date(2024, time.February, 29, 23, 30, 0, 0, time.FixedZone("KST", 9*60*60))
+
+	day, month := TransferPeriods(at)
+	require.Equal(t, time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC), day)
+	require.Equal(t, time.Date(2024, time.February, 1, 0, 0, 0, 0, time.UTC), month)
+}
+
+func TestGetTransferLimit(t *testing.T) {
+	user := createRandomUser(t)
+
+	// without limits of its own the user has the defaults of the currency
+	limit, err := testQueries.GetTransferLimit(context.Background(), GetTransferLimitParams{Username: user.Username, Currency: util.USD})
+	require.NoError(t, err)
+	require.Equal(t, int64(2500000), limit.PerTransaction.Int64)
+
+	setUserTransferLimit(t, user.Username, 100, 200, 300)
+
+	limit, err = testQueries.GetTransferLimit(context.Background(), GetTransferLimitParams{Username: user.Username, Currency: util.USD})
+	require.NoError(t, err)
+	require.Equal(t, int64(100), limit.PerTransaction.Int64)
+	require.Equal(t, int64(300), limit.Monthly.Int64)
+
+	limits, err := testQueries.ListTransferLimits(context.Background(), user.Username)
+	require.NoError(t, err)
+	for _, limit := range limits {
+		if limit.Currency == util.USD {
+			require.Equal(t, int64(200), limit.Daily.Int64)
+		}
+	}
+}
+
+func TestTransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+	setUserTransferLimit(t, accounts[0].Owner, 300, 500, 10000)
+
+	arg := TransferTxParams{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 301}
+
+	var limitErr *TransferLimitError
+	_, err := store.TransferTx(context.Background(), arg)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LIMIT_PER_TRANSACTION, limitErr.Limit)
+
+	arg.Amount = 300
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LIMIT_DAILY, limitErr.Limit)
+	require.Equal(t, int64(200), limitErr.Remaining)
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// the rejected transfers are not counted
+	day, month := TransferPeriods(time.Now())
+	usage, err := testQueries.ListTransferUsage(context.Background(), ListTransferUsageParams{
+		Owner: accounts[0].Owner,
+		Day: day,
+		Month: month,
+	})
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	for _, u := range usage {
+		require.Equal(t, int64(300), u.Amount)
+	}
+}
+
+// TestTransferTxLimitsConcurrent makes more transfers at once than the daily limit allows
+func TestTransferTxLimitsConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 3)
+	setUserTransferLimit(t, accounts[0].Owner, 100, 500, 10000)
+
+	n := 10
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: accounts[0].ID,
+				ToAccountID: accounts[i%2+1].ID,
+				Amount: 100,
+			})
+			errs <- err
+		}(i)
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	}
+	require.Equal(t, 5, succeeded)
+
+	account, err := testQueries.GetAccount(context.Background(), accounts[0].ID)
+	require.NoError(t, err)
+	require.Equal(t, int64(500), account.Balance)
+}
+
+func TestBatchTransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 3)
+	setUserTransferLimit(t, accounts[0].Owner, 300, 500, 10000)
+
+	result, err := store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{
+			{ToAccountID: accounts[1].ID, Amount: 400},
+			{ToAccountID: accounts[1].ID, Amount: 300},
+			{ToAccountID: accounts[2].ID, Amount: 300},
+			{ToAccountID: accounts[2].ID, Amount: 200},
+		},
+		BestEffort: true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, 2, result.Completed)
+
+	var limitErr *TransferLimitError
+	require.ErrorAs(t, result.Items[0].Err, &limitErr)
+	require.Equal(t, LIMIT_PER_TRANSACTION, limitErr.Limit)
+	require.NoError(t, result.Items[1].Err)
+	require.ErrorAs(t, result.Items[2].Err, &limitErr)
+	require.Equal(t, LIMIT_DAILY, limitErr.Limit)
+	require.NoError(t, result.Items[3].Err)
+}
+
+func TestTransferTxAccountLimits(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID: accounts[0].ID,
+		Daily: sql.NullInt64{Int64: 500, Valid: true},
+	})
+	require.NoError(t, err)
+
+	arg := TransferTxParams{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 300}
+	_, err = store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	// the owner is far below its own limits, the account caps the transfer
+	var limitErr *TransferLimitError
+	_, err = store.TransferTx(context.Background(), arg)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LIMIT_DAILY, limitErr.Limit)
+	require.Equal(t, accounts[0].ID, limitErr.AccountID)
+	require.Equal(t, int64(200), limitErr.Remaining)
+
+	day, month := TransferPeriods(time.Now())
+	usage, err := testQueries.ListAccountTransferUsage(context.Background(), ListAccountTransferUsageParams{
+		AccountID: accounts[0].ID,
+		Day: day,
+		Month: month,
+	})
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+	for _, u := range usage {
+		require.Equal(t, int64(300), u.Amount)
+	}
+
+	// without limits the usage is still tracked, the other account has received but not sent
+	usage, err = testQueries.ListAccountTransferUsage(context.Background(), ListAccountTransferUsageParams{
+		AccountID: accounts[1].ID,
+		Day: day,
+		Month: month,
+	})
+	require.NoError(t, err)
+	require.Empty(t, usage)
+}
+
+func TestTransferTxMemberLimits(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+	spender := createRandomAccountMember(t, accounts[0], ACCOUNT_ROLE_SPENDER)
+	setUserTransferLimit(t, spender.Username, 300, 500, 10000)
+
+	// the limits of the spender apply to what it spends from the account it shares
+	arg := TransferTxParams{FromAccountID: accounts[0].ID, ToAccountID: accounts[1].ID, Amount: 301, RequestedBy: spender.Username}
+	var limitErr *TransferLimitError
+	_, err := store.TransferTx(context.Background(), arg)
+	require.ErrorAs(t, err, &limitErr)
+	require.Equal(t, LIMIT_PER_TRANSACTION, limitErr.Limit)
+	require.Zero(t, limitErr.AccountID)
+
+	arg.Amount = 300
+	result, err := store.TransferTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, sql.NullString{String: spender.Username, Valid: true}, result.Transfer.RequestedBy)
+
+	day, month := TransferPeriods(time.Now())
+	usage, err := testQueries.ListTransferUsage(context.Background(), ListTransferUsageParams{
+		Owner: spender.Username,
+		Day: day,
+		Month: month,
+	})
+	require.NoError(t, err)
+	require.Len(t, usage, 2)
+
+	// the holder made no transfer
+	usage, err = testQueries.ListTransferUsage(context.Background(), ListTransferUsageParams{
+		Owner: accounts[0].Owner,
+		Day: day,
+		Month: month,
+	})
+	require.NoError(t, err)
+	require.Empty(t, usage)
+
+	count, err := testQueries.CountTransfersRequestedBySince(context.Background(), CountTransfersRequestedBySinceParams{
+		RequestedBy: sql.NullString{String: spender.Username, Valid: true},
+		CreatedAt: time.Now().Add(-time.Minute),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), count)
+}
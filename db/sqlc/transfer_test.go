@@ -3,6 +3,7 @@ package db
 import (
 	"context"
 	"database/sql"
+	"encoding/json"
 	"testing"
 
 	"github.com/sssaang/simplebank/db/util"
@@ -14,6 +15,9 @@ func CreateRandomTransfer(t *testing.T, fromAccount Account, toAccount Account)
 		FromAccountID: fromAccount.ID,
 		ToAccountID: toAccount.ID,
 		Amount: util.RandomMoney(),
+		Description: util.RandomString(20),
+		Reference: util.RandomString(8),
+		Metadata: json.RawMessage(`{"order":"42"}`),
 	}
 
 	transfer, err := testQueries.CreateTransfer(context.Background(), arg)
@@ -23,6 +27,9 @@ func CreateRandomTransfer(t *testing.T, fromAccount Account, toAccount Account)
 	require.Equal(t, transfer.FromAccountID, arg.FromAccountID)
 	require.Equal(t, transfer.ToAccountID, arg.ToAccountID)
 	require.Equal(t, transfer.Amount, arg.Amount)
+	require.Equal(t, arg.Description, transfer.Description)
+	require.Equal(t, arg.Reference, transfer.Reference)
+	require.JSONEq(t, string(arg.Metadata), string(transfer.Metadata))
 	return transfer
 }
 
@@ -103,3 +110,65 @@ func TestListTransfers(t *testing.T) {
 		require.Equal(t, transfer.ToAccountID, toAccount.ID)
 	}
 }
+
+func TestSearchTransfers(t *testing.T) {
+	fromAccount := CreateRandomAccount(t)
+	toAccount := CreateRandomAccount(t)
+
+	var transfers []Transfer
+	for i := 0; i < 3; i++ {
+		transfers = append(transfers, CreateRandomTransfer(t, fromAccount, toAccount))
+	}
+	other, err := testQueries.CreateTransfer(context.Background(), CreateTransferParams{
+		FromAccountID: toAccount.ID,
+		ToAccountID: fromAccount.ID,
+		Amount: 10,
+		Description: "100% refund",
+		Reference: "REFUND_1",
+		Metadata: json.RawMessage(`{"order":"7","channel":"web"}`),
+	})
+	require.NoError(t, err)
+
+	// every transfer of the account, in either direction, newest first
+	found, err := testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		AccountID: toAccount.ID,
+		Metadata: json.RawMessage("{}"),
+		RowLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 4)
+	require.Equal(t, other.ID, found[0].ID)
+	require.Equal(t, transfers[2].ID, found[1].ID)
+
+	// the pattern ignores case and matches the reference too
+	found, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		AccountID: toAccount.ID,
+		Pattern: `%refund\_%`,
+		Metadata: json.RawMessage("{}"),
+		RowLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, other.ID, found[0].ID)
+
+	found, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		AccountID: toAccount.ID,
+		Reference: transfers[1].Reference,
+		Metadata: json.RawMessage("{}"),
+		RowLimit: 10,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, transfers[1].ID, found[0].ID)
+
+	// a transfer matches when its metadata contains every pair
+	found, err = testQueries.SearchTransfers(context.Background(), SearchTransfersParams{
+		AccountID: toAccount.ID,
+		Metadata: json.RawMessage(`{"order":"42"}`),
+		RowLimit: 10,
+		RowOffset: 1,
+	})
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	require.Equal(t, transfers[1].ID, found[0].ID)
+}
@@ -0,0 +1,54 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	// SecretCodeHash is only set when the email changes, the new email must then be verified again
+	SecretCodeHash string `json:"secret_code_hash"`
+	VerifyEmailExpiredAt time.Time `json:"verify_email_expired_at"`
+	// AfterUpdate is called before the transaction commits when a new verification has been created
+	AfterUpdate func(user User, verifyEmail VerifyEmail) error `json:"-"`
+}
+
+type UpdateUserTxResult struct {
+	User User `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.User, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+
+		if len(arg.SecretCodeHash) == 0 {
+			return nil
+		}
+
+		result.VerifyEmail, err = q.CreateVerifyEmail(ctx, CreateVerifyEmailParams{
+			Username: result.User.Username,
+			Email: result.User.Email,
+			SecretCodeHash: arg.SecretCodeHash,
+			ExpiredAt: arg.VerifyEmailExpiredAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterUpdate == nil {
+			return nil
+		}
+		return arg.AfterUpdate(result.User, result.VerifyEmail)
+	})
+
+	return result, err
+}
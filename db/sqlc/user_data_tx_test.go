@@ -0,0 +1,136 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	newEmail := util.RandomEmail()
+
+	var sentTo string
+	result, err := store.UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: user.Username,
+			FullName: util.RandomOwner(),
+			Email: newEmail,
+			IsEmailVerified: false,
+		},
+		SecretCodeHash: util.HashSecret(util.RandomString(32)),
+		VerifyEmailExpiredAt: time.Now().Add(time.Minute),
+		AfterUpdate: func(user User, verifyEmail VerifyEmail) error {
+			sentTo = verifyEmail.Email
+			return nil
+		},
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, newEmail, result.User.Email)
+	require.Equal(t, newEmail, result.VerifyEmail.Email)
+	require.Equal(t, newEmail, sentTo)
+}
+
+func TestExportUserDataTx(t *testing.T) {
+	store := NewStore(testDB)
+	account1 := CreateRandomAccount(t)
+	account2 := CreateRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID: account2.ID,
+		Amount: 1,
+	})
+	require.NoError(t, err)
+
+	export, err := store.ExportUserDataTx(context.Background(), account1.Owner)
+	require.NoError(t, err)
+	require.Equal(t, account1.Owner, export.User.Username)
+	require.Len(t, export.Accounts, 1)
+	require.Len(t, export.Entries, 1)
+	require.Len(t, export.Transfers, 1)
+	require.Equal(t, account2.ID, export.Transfers[0].ToAccountID)
+}
+
+func TestEraseUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := CreateRandomAccount(t)
+
+	pseudonym := fmt.Sprintf("erased-%s", uuid.New())
+	arg := EraseUserTxParams{
+		Username: account.Owner,
+		Pseudonym: pseudonym,
+	}
+
+	// accounts holding money cannot be erased
+	if account.Balance != 0 {
+		_, err := store.EraseUserTx(context.Background(), arg)
+		require.EqualError(t, err, ErrAccountsNotEmpty.Error())
+
+		_, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{ID: account.ID, Balance: 0})
+		require.NoError(t, err)
+	}
+
+	erasure, err := store.EraseUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, pseudonym, erasure.Pseudonym)
+
+	_, err = testQueries.GetUser(context.Background(), account.Owner)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	erasedUser, err := testQueries.GetUser(context.Background(), pseudonym)
+	require.NoError(t, err)
+	require.Equal(t, ERASED_USER_FULL_NAME, erasedUser.FullName)
+	require.NotContains(t, erasedUser.Email, account.Owner)
+
+	// the ledger follows the pseudonym
+	erasedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, pseudonym, erasedAccount.Owner)
+}
+
+func TestEraseUserTxClosesAccounts(t *testing.T) {
+	store := NewStore(testDB)
+	accounts := createBatchAccounts(t, 2)
+	erased := createWalletAccount(t, createRandomUser(t).Username, util.USD, 0)
+
+	_, err := store.EraseUserTx(context.Background(), EraseUserTxParams{
+		Username: erased.Owner,
+		Pseudonym: fmt.Sprintf("erased-%s", uuid.New()),
+	})
+	require.NoError(t, err)
+
+	closed, err := testQueries.GetAccount(context.Background(), erased.ID)
+	require.NoError(t, err)
+	require.True(t, closed.ClosedAt.Valid)
+
+	// nothing can be credited to the account of an erased user, the money could never be withdrawn
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID: erased.ID,
+		Amount: 10,
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	_, err = store.BatchTransferTx(context.Background(), BatchTransferTxParams{
+		FromAccountID: accounts[0].ID,
+		Currency: util.USD,
+		Items: []BatchTransferItem{
+			{ToAccountID: accounts[1].ID, Amount: 10},
+			{ToAccountID: erased.ID, Amount: 10},
+		},
+	})
+	require.ErrorIs(t, err, ErrAccountClosed)
+
+	closed, err = testQueries.GetAccount(context.Background(), erased.ID)
+	require.NoError(t, err)
+	require.Zero(t, closed.Balance)
+}
@@ -52,4 +52,28 @@ func TestGetUser(t *testing.T) {
 	require.Equal(t, userCreated.Email, userFetched.Email)
 	require.WithinDuration(t, userCreated.PasswordChangedAt, userFetched.PasswordChangedAt, 0)
 	require.WithinDuration(t, userCreated.CreatedAt, userFetched.CreatedAt, 0)
-}
\ No newline at end of file
+}
+
+func TestGetUserByEmail(t *testing.T) {
+	userCreated := createRandomUser(t)
+	userFetched, err := testQueries.GetUserByEmail(context.Background(), userCreated.Email)
+
+	require.NoError(t, err)
+	require.Equal(t, userCreated.Username, userFetched.Username)
+	require.Equal(t, userCreated.Email, userFetched.Email)
+}
+
+func TestUpdateUserPassword(t *testing.T) {
+	userCreated := createRandomUser(t)
+	hashedPassword, err := util.HashPassword(util.RandomString(10))
+	require.NoError(t, err)
+
+	userUpdated, err := testQueries.UpdateUserPassword(context.Background(), UpdateUserPasswordParams{
+		Username: userCreated.Username,
+		HashedPassword: hashedPassword,
+	})
+
+	require.NoError(t, err)
+	require.Equal(t, hashedPassword, userUpdated.HashedPassword)
+	require.True(t, userUpdated.PasswordChangedAt.After(userCreated.PasswordChangedAt))
+}
@@ -0,0 +1,157 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomVerifyEmail(t *testing.T, user User, secretCode string) VerifyEmail {
+	arg := CreateVerifyEmailParams{
+		Username: user.Username,
+		Email: user.Email,
+		SecretCodeHash: util.HashSecret(secretCode),
+		ExpiredAt: time.Now().Add(time.Minute),
+	}
+
+	verifyEmail, err := testQueries.CreateVerifyEmail(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, verifyEmail)
+
+	require.Equal(t, arg.Username, verifyEmail.Username)
+	require.Equal(t, arg.Email, verifyEmail.Email)
+	require.Equal(t, arg.SecretCodeHash, verifyEmail.SecretCodeHash)
+	require.False(t, verifyEmail.IsUsed)
+	require.NotZero(t, verifyEmail.ID)
+
+	return verifyEmail
+}
+
+func TestCreateVerifyEmail(t *testing.T) {
+	createRandomVerifyEmail(t, createRandomUser(t), util.RandomString(32))
+}
+
+func TestVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+	require.False(t, user.IsEmailVerified)
+
+	secretCode := util.RandomString(32)
+	verifyEmail := createRandomVerifyEmail(t, user, secretCode)
+
+	// a wrong code does not verify anything
+	_, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID: verifyEmail.ID,
+		SecretCodeHash: util.HashSecret(util.RandomString(32)),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	arg := VerifyEmailTxParams{
+		EmailID: verifyEmail.ID,
+		SecretCodeHash: util.HashSecret(secretCode),
+	}
+
+	result, err := store.VerifyEmailTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.True(t, result.VerifyEmail.IsUsed)
+	require.True(t, result.User.IsEmailVerified)
+
+	_, err = store.VerifyEmailTx(context.Background(), arg)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestCreateUserTx(t *testing.T) {
+	store := NewStore(testDB)
+	username := util.RandomEmail()
+	hashedPassword, err := util.HashPassword(util.RandomString(10))
+	require.NoError(t, err)
+
+	var sentTo string
+	arg := CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username: username,
+			HashedPassword: hashedPassword,
+			FullName: util.RandomOwner(),
+			Email: username,
+		},
+		SecretCodeHash: util.HashSecret(util.RandomString(32)),
+		VerifyEmailExpiredAt: time.Now().Add(time.Minute),
+		AfterCreate: func(user User, verifyEmail VerifyEmail) error {
+			sentTo = verifyEmail.Email
+			return nil
+		},
+	}
+
+	result, err := store.CreateUserTx(context.Background(), arg)
+	require.NoError(t, err)
+	require.Equal(t, username, result.User.Username)
+	require.False(t, result.User.IsEmailVerified)
+	require.Equal(t, result.User.Username, result.VerifyEmail.Username)
+	require.Equal(t, username, sentTo)
+}
+
+func TestCreateUserTxRollback(t *testing.T) {
+	store := NewStore(testDB)
+	username := util.RandomEmail()
+
+	arg := CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username: username,
+			HashedPassword: util.RandomString(10),
+			FullName: util.RandomOwner(),
+			Email: username,
+		},
+		SecretCodeHash: util.HashSecret(util.RandomString(32)),
+		VerifyEmailExpiredAt: time.Now().Add(time.Minute),
+		AfterCreate: func(user User, verifyEmail VerifyEmail) error {
+			return sql.ErrConnDone
+		},
+	}
+
+	_, err := store.CreateUserTx(context.Background(), arg)
+	require.Error(t, err)
+
+	_, err = testQueries.GetUser(context.Background(), username)
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+}
+
+func TestResendVerifyEmailTx(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	oldCode := util.RandomString(32)
+	oldVerifyEmail := createRandomVerifyEmail(t, user, oldCode)
+
+	newCode := util.RandomString(32)
+	var sentTo string
+	verifyEmail, err := store.ResendVerifyEmailTx(context.Background(), ResendVerifyEmailTxParams{
+		User: user,
+		SecretCodeHash: util.HashSecret(newCode),
+		ExpiredAt: time.Now().Add(time.Minute),
+		AfterCreate: func(verifyEmail VerifyEmail) error {
+			sentTo = verifyEmail.Email
+			return nil
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, user.Email, sentTo)
+	require.NotEqual(t, oldVerifyEmail.ID, verifyEmail.ID)
+
+	// the code sent before no longer verifies the email
+	_, err = store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID: oldVerifyEmail.ID,
+		SecretCodeHash: util.HashSecret(oldCode),
+	})
+	require.EqualError(t, err, sql.ErrNoRows.Error())
+
+	result, err := store.VerifyEmailTx(context.Background(), VerifyEmailTxParams{
+		EmailID: verifyEmail.ID,
+		SecretCodeHash: util.HashSecret(newCode),
+	})
+	require.NoError(t, err)
+	require.True(t, result.User.IsEmailVerified)
+}
@@ -0,0 +1,80 @@
+package db
+
+import (
+	"context"
+	"time"
+)
+
+type VerifyEmailTxParams struct {
+	EmailID int64 `json:"email_id"`
+	SecretCodeHash string `json:"secret_code_hash"`
+}
+
+type VerifyEmailTxResult struct {
+	User User `json:"user"`
+	VerifyEmail VerifyEmail `json:"verify_email"`
+}
+
+// VerifyEmailTx consumes a verification code and marks the email of its user as verified.
+// sql.ErrNoRows is returned when the code is invalid, used or expired, or when the user has changed their email since.
+func (store *SQLStore) VerifyEmailTx(ctx context.Context, arg VerifyEmailTxParams) (VerifyEmailTxResult, error) {
+	var result VerifyEmailTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.VerifyEmail, err = q.UseVerifyEmail(ctx, UseVerifyEmailParams{
+			ID: arg.EmailID,
+			SecretCodeHash: arg.SecretCodeHash,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			Username: result.VerifyEmail.Username,
+			Email: result.VerifyEmail.Email,
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type ResendVerifyEmailTxParams struct {
+	User User `json:"user"`
+	SecretCodeHash string `json:"secret_code_hash"`
+	ExpiredAt time.Time `json:"expired_at"`
+	// AfterCreate is called before the transaction commits, an error keeps the earlier codes valid
+	AfterCreate func(verifyEmail VerifyEmail) error `json:"-"`
+}
+
+// ResendVerifyEmailTx replaces the outstanding verification codes of the user with a new one,
+// for the users whose code expired or whose email never arrived
+func (store *SQLStore) ResendVerifyEmailTx(ctx context.Context, arg ResendVerifyEmailTxParams) (VerifyEmail, error) {
+	var verifyEmail VerifyEmail
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		err := q.InvalidateVerifyEmails(ctx, arg.User.Username)
+		if err != nil {
+			return err
+		}
+
+		verifyEmail, err = q.CreateVerifyEmail(ctx, CreateVerifyEmailParams{
+			Username: arg.User.Username,
+			Email: arg.User.Email,
+			SecretCodeHash: arg.SecretCodeHash,
+			ExpiredAt: arg.ExpiredAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.AfterCreate == nil {
+			return nil
+		}
+		return arg.AfterCreate(verifyEmail)
+	})
+
+	return verifyEmail, err
+}
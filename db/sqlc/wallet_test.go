@@ -0,0 +1,141 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+// createWalletAccount opens a balance in currency in the wallet of the owner
+func createWalletAccount(t *testing.T, owner string, currency string, balance int64) Account {
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner: owner,
+		Balance: balance,
+		Currency: currency,
+		Type: ACCOUNT_CHECKING,
+	})
+	require.NoError(t, err)
+	return account
+}
+
+func setExchangeRate(t *testing.T, fromCurrency string, toCurrency string, rate string) {
+	_, err := testQueries.UpsertExchangeRate(context.Background(), UpsertExchangeRateParams{
+		FromCurrency: fromCurrency,
+		ToCurrency: toCurrency,
+		Rate: rate,
+	})
+	require.NoError(t, err)
+}
+
+func TestWalletAccounts(t *testing.T) {
+	user := createRandomUser(t)
+	usd := createWalletAccount(t, user.Username, util.USD, 100)
+	eur := createWalletAccount(t, user.Username, util.EUR, 200)
+
+	// every account of the user is a balance of the same wallet
+	require.Equal(t, usd.WalletID, eur.WalletID)
+
+	wallet, err := testQueries.GetWallet(context.Background(), usd.WalletID)
+	require.NoError(t, err)
+	require.Equal(t, user.Username, wallet.Owner)
+
+	accounts, err := testQueries.ListWalletAccounts(context.Background(), wallet.ID)
+	require.NoError(t, err)
+	require.Equal(t, []Account{eur, usd}, accounts)
+
+	account, err := testQueries.GetWalletAccount(context.Background(), GetWalletAccountParams{
+		WalletID: wallet.ID,
+		Currency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, usd, account)
+}
+
+func TestUpsertExchangeRate(t *testing.T) {
+	setExchangeRate(t, util.EUR, util.USD, "1.25")
+
+	rate, err := testQueries.GetExchangeRate(context.Background(), GetExchangeRateParams{
+		FromCurrency: util.EUR,
+		ToCurrency: util.USD,
+	})
+	require.NoError(t, err)
+	require.Equal(t, "1.25", rate.Rate)
+	require.NotZero(t, rate.UpdatedAt)
+}
+
+func TestConvertCurrencyTx(t *testing.T) {
+	store := NewStore(testDB)
+	setExchangeRate(t, util.USD, util.KRW, "1300")
+
+	user := createRandomUser(t)
+	usd := createWalletAccount(t, user.Username, util.USD, 1000)
+
+	// the KRW balance is opened by the first conversion
+	result, err := store.ConvertCurrencyTx(context.Background(), ConvertCurrencyTxParams{
+		WalletID: usd.WalletID,
+		FromCurrency: util.USD,
+		ToCurrency: util.KRW,
+		Amount: 250,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, usd.ID, result.FromAccount.ID)
+	require.Equal(t, int64(750), result.FromAccount.Balance)
+	require.Equal(t, usd.WalletID, result.ToAccount.WalletID)
+	require.Equal(t, util.KRW, result.ToAccount.Currency)
+	require.Equal(t, int64(3250), result.ToAccount.Balance)
+
+	require.Equal(t, int64(-250), result.FromEntry.Amount)
+	require.Equal(t, int64(3250), result.ToEntry.Amount)
+
+	require.Equal(t, int64(250), result.Conversion.FromAmount)
+	require.Equal(t, int64(3250), result.Conversion.ToAmount)
+	require.Equal(t, "1300", result.Conversion.Rate)
+
+	// the second conversion credits the same balance
+	result, err = store.ConvertCurrencyTx(context.Background(), ConvertCurrencyTxParams{
+		WalletID: usd.WalletID,
+		FromCurrency: util.USD,
+		ToCurrency: util.KRW,
+		Amount: 100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(650), result.FromAccount.Balance)
+	require.Equal(t, int64(4550), result.ToAccount.Balance)
+
+	accounts, err := testQueries.ListWalletAccounts(context.Background(), usd.WalletID)
+	require.NoError(t, err)
+	require.Len(t, accounts, 2)
+}
+
+func TestConvertCurrencyTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+	setExchangeRate(t, util.USD, util.KRW, "1300")
+
+	user := createRandomUser(t)
+	usd := createWalletAccount(t, user.Username, util.USD, 100)
+
+	_, err := store.ConvertCurrencyTx(context.Background(), ConvertCurrencyTxParams{
+		WalletID: usd.WalletID,
+		FromCurrency: util.USD,
+		ToCurrency: util.KRW,
+		Amount: 101,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the conversion is rolled back as a whole, including the KRW balance
+	accounts, err := testQueries.ListWalletAccounts(context.Background(), usd.WalletID)
+	require.NoError(t, err)
+	require.Equal(t, []Account{usd}, accounts)
+
+	// the wallet holds no KRW at all
+	_, err = store.ConvertCurrencyTx(context.Background(), ConvertCurrencyTxParams{
+		WalletID: usd.WalletID,
+		FromCurrency: util.KRW,
+		ToCurrency: util.USD,
+		Amount: 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
@@ -10,8 +10,25 @@ type Config struct {
 	DBDriver string `mapstructure:"DB_DRIVER"`
 	DBSource string `mapstructure:"DB_SOURCE"`
 	ApiAddress string `mapstructure:"API_ADDRESS"`
+	GrpcAddress string `mapstructure:"GRPC_ADDRESS"`
 	PasetoSymmetricKey string `mapstructure:"PASETO_SYMMETRIC_KEY"`
+	TokenFormat string `mapstructure:"TOKEN_FORMAT"`
+	TokenSigningKeyID string `mapstructure:"TOKEN_SIGNING_KEY_ID"`
+	TokenSigningKey string `mapstructure:"TOKEN_SIGNING_KEY"`
+	TokenVerificationKeys string `mapstructure:"TOKEN_VERIFICATION_KEYS"`
 	AccessTokenDuration time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
+	PasswordResetTokenDuration time.Duration `mapstructure:"PASSWORD_RESET_TOKEN_DURATION"`
+	OauthCodeDuration time.Duration `mapstructure:"OAUTH_CODE_DURATION"`
+	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	IntrospectionClients string `mapstructure:"INTROSPECTION_CLIENTS"`
+	VerifyEmailDuration time.Duration `mapstructure:"VERIFY_EMAIL_DURATION"`
+	MailFilePath string `mapstructure:"MAIL_FILE_PATH"`
+	CurrencyRefreshInterval time.Duration `mapstructure:"CURRENCY_REFRESH_INTERVAL"`
+	HoldExpiryInterval time.Duration `mapstructure:"HOLD_EXPIRY_INTERVAL"`
+	InterestJobInterval time.Duration `mapstructure:"INTEREST_JOB_INTERVAL"`
+	PayeeCoolingOffPeriod time.Duration `mapstructure:"PAYEE_COOLING_OFF_PERIOD"`
+	PayeeCoolingOffAmounts string `mapstructure:"PAYEE_COOLING_OFF_AMOUNTS"`
+	PaymentRequestExpiryInterval time.Duration `mapstructure:"PAYMENT_REQUEST_EXPIRY_INTERVAL"`
 }
 
 func LoadConfig(path string) (config Config, err error) {
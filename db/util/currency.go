@@ -0,0 +1,106 @@
+package util
+
+import (
+	"sort"
+	"sync"
+)
+
+// the currencies seeded by the migrations, the registry starts with them until it is loaded from the database
+const (
+	USD = "USD"
+	EUR = "EUR"
+	KRW = "KRW"
+)
+
+type Currency struct {
+	Code string
+	MinorUnits int
+	IsEnabled bool
+}
+
+// CurrencyRegistry caches the currencies table in memory, it is read on every request that carries an amount
+type CurrencyRegistry struct {
+	mutex sync.RWMutex
+	currencies map[string]Currency
+}
+
+func NewCurrencyRegistry(currencies []Currency) *CurrencyRegistry {
+	registry := &CurrencyRegistry{}
+	registry.Load(currencies)
+	return registry
+}
+
+// Load replaces every cached currency
+func (registry *CurrencyRegistry) Load(currencies []Currency) {
+	cache := make(map[string]Currency, len(currencies))
+	for _, currency := range currencies {
+		cache[currency.Code] = currency
+	}
+
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.currencies = cache
+}
+
+// Put adds or replaces a single currency, after an admin changed it
+func (registry *CurrencyRegistry) Put(currency Currency) {
+	registry.mutex.Lock()
+	defer registry.mutex.Unlock()
+	registry.currencies[currency.Code] = currency
+}
+
+// Lookup finds a currency, including disabled ones whose amounts must still be formatted
+func (registry *CurrencyRegistry) Lookup(code string) (Currency, bool) {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+	currency, ok := registry.currencies[code]
+	return currency, ok
+}
+
+// All returns every cached currency, sorted by code
+func (registry *CurrencyRegistry) All() []Currency {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	currencies := make([]Currency, 0, len(registry.currencies))
+	for _, currency := range registry.currencies {
+		currencies = append(currencies, currency)
+	}
+	sort.Slice(currencies, func(i, j int) bool {
+		return currencies[i].Code < currencies[j].Code
+	})
+	return currencies
+}
+
+// Enabled returns the codes of the currencies new accounts and transfers can use, sorted
+func (registry *CurrencyRegistry) Enabled() []string {
+	registry.mutex.RLock()
+	defer registry.mutex.RUnlock()
+
+	var codes []string
+	for code, currency := range registry.currencies {
+		if currency.IsEnabled {
+			codes = append(codes, code)
+		}
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Currencies is the registry of the process, the servers load it from the database on startup
+var Currencies = NewCurrencyRegistry([]Currency{
+	{Code: USD, MinorUnits: 2, IsEnabled: true},
+	{Code: EUR, MinorUnits: 2, IsEnabled: true},
+	{Code: KRW, MinorUnits: 0, IsEnabled: true},
+})
+
+func IsSupportedCurrency(currency string) bool {
+	found, ok := Currencies.Lookup(currency)
+	return ok && found.IsEnabled
+}
+
+// MinorUnits returns the number of decimals of the currency
+func MinorUnits(currency string) (int, bool) {
+	found, ok := Currencies.Lookup(currency)
+	return found.MinorUnits, ok
+}
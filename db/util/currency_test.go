@@ -0,0 +1,55 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCurrencyRegistry(t *testing.T) {
+	registry := NewCurrencyRegistry([]Currency{
+		{Code: USD, MinorUnits: 2, IsEnabled: true},
+		{Code: KRW, MinorUnits: 0, IsEnabled: false},
+	})
+
+	currency, ok := registry.Lookup(KRW)
+	require.True(t, ok)
+	require.Equal(t, 0, currency.MinorUnits)
+	require.False(t, currency.IsEnabled)
+
+	_, ok = registry.Lookup(EUR)
+	require.False(t, ok)
+	require.Equal(t, []string{USD}, registry.Enabled())
+
+	registry.Put(Currency{Code: "JPY", MinorUnits: 0, IsEnabled: true})
+	require.Equal(t, []string{"JPY", USD}, registry.Enabled())
+	require.Len(t, registry.All(), 3)
+
+	registry.Load([]Currency{{Code: EUR, MinorUnits: 2, IsEnabled: true}})
+	require.Equal(t, []Currency{{Code: EUR, MinorUnits: 2, IsEnabled: true}}, registry.All())
+}
+
+func TestSupportedCurrenciesReadTheRegistry(t *testing.T) {
+	defaults := Currencies.All()
+	t.Cleanup(func() {
+		Currencies.Load(defaults)
+	})
+
+	Currencies.Load([]Currency{
+		{Code: "JPY", MinorUnits: 0, IsEnabled: true},
+		{Code: USD, MinorUnits: 2, IsEnabled: false},
+	})
+
+	require.True(t, IsSupportedCurrency("JPY"))
+	require.False(t, IsSupportedCurrency(USD))
+	require.False(t, IsSupportedCurrency(EUR))
+
+	// disabled currencies are still formatted with their decimals
+	minorUnits, ok := MinorUnits(USD)
+	require.True(t, ok)
+	require.Equal(t, 2, minorUnits)
+
+	for i := 0; i < 10; i++ {
+		require.Equal(t, "JPY", RandomCurrency())
+	}
+}
@@ -0,0 +1,82 @@
+package util
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var (
+	ErrInvalidFeeRate = errors.New("the fee rate must be a decimal number from 0 to 1")
+	ErrInvalidFeeTiers = errors.New("the fee tiers must start at a min_amount of 0 and increase, with non negative flat fees")
+)
+
+// FeeTier is the fee of the amounts from MinAmount up to the MinAmount of the next tier
+type FeeTier struct {
+	MinAmount int64 `json:"min_amount"`
+	// Flat is in minor units
+	Flat int64 `json:"flat"`
+	// Rate is the fraction of the amount charged on top of the flat fee, "0.015" for 1.5%, empty charges none
+	Rate string `json:"rate,omitempty"`
+}
+
+// ParseFeeRate reads the rate of a fee tier such as "0.015" for 1.5%, from 0 to 1
+func ParseFeeRate(value string) (*big.Rat, error) {
+	if strings.ContainsAny(value, "/eE") {
+		return nil, ErrInvalidFeeRate
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() < 0 || rate.Cmp(big.NewRat(1, 1)) > 0 {
+		return nil, ErrInvalidFeeRate
+	}
+	return rate, nil
+}
+
+// ValidateFeeTiers checks that the tiers cover every amount once, in the order of their min_amount
+func ValidateFeeTiers(tiers []FeeTier) error {
+	if len(tiers) == 0 || tiers[0].MinAmount != 0 {
+		return ErrInvalidFeeTiers
+	}
+
+	for i, tier := range tiers {
+		if tier.Flat < 0 || (i > 0 && tier.MinAmount <= tiers[i-1].MinAmount) {
+			return ErrInvalidFeeTiers
+		}
+		if tier.Rate == "" {
+			continue
+		}
+		if _, err := ParseFeeRate(tier.Rate); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Fee is the flat fee plus the rate times the amount of the tier the amount falls in,
+// rounded toward zero to the minor unit like every other computed amount
+func Fee(tiers []FeeTier, amount int64) (int64, error) {
+	if err := ValidateFeeTiers(tiers); err != nil {
+		return 0, err
+	}
+
+	tier := tiers[0]
+	for _, t := range tiers[1:] {
+		if t.MinAmount > amount {
+			break
+		}
+		tier = t
+	}
+
+	fee := big.NewInt(tier.Flat)
+	if tier.Rate != "" {
+		rate, _ := ParseFeeRate(tier.Rate)
+		variable := new(big.Int).Mul(big.NewInt(amount), rate.Num())
+		fee.Add(fee, variable.Quo(variable, rate.Denom()))
+	}
+
+	if !fee.IsInt64() {
+		return 0, ErrMoneyOverflow
+	}
+	return fee.Int64(), nil
+}
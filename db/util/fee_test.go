@@ -0,0 +1,66 @@
+package util
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFee(t *testing.T) {
+	tiers := []FeeTier{
+		{MinAmount: 0, Flat: 25},
+		{MinAmount: 10000, Flat: 10, Rate: "0.01"},
+		{MinAmount: 100000, Rate: "0.005"},
+	}
+
+	testCases := []struct {
+		amount int64
+		fee int64
+	}{
+		{amount: 1, fee: 25},
+		{amount: 9999, fee: 25},
+		{amount: 10000, fee: 110},
+		{amount: 10050, fee: 110},
+		{amount: 10099, fee: 110},
+		{amount: 10100, fee: 111},
+		{amount: 100000, fee: 500},
+		{amount: 100199, fee: 500},
+	}
+
+	for _, tc := range testCases {
+		fee, err := Fee(tiers, tc.amount)
+		require.NoError(t, err, tc.amount)
+		require.Equal(t, tc.fee, fee, tc.amount)
+	}
+
+	_, err := Fee([]FeeTier{{Flat: math.MaxInt64, Rate: "1"}}, 1)
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestValidateFeeTiers(t *testing.T) {
+	valid := [][]FeeTier{
+		{{MinAmount: 0}},
+		{{MinAmount: 0, Rate: "1"}},
+		{{MinAmount: 0, Flat: 100}, {MinAmount: 500, Rate: "0.02"}},
+	}
+	for _, tiers := range valid {
+		require.NoError(t, ValidateFeeTiers(tiers), tiers)
+	}
+
+	invalid := [][]FeeTier{
+		{},
+		{{MinAmount: 100}},
+		{{MinAmount: 0, Flat: -1}},
+		{{MinAmount: 0}, {MinAmount: 500}, {MinAmount: 500}},
+		{{MinAmount: 0}, {MinAmount: 500}, {MinAmount: 200}},
+	}
+	for _, tiers := range invalid {
+		require.ErrorIs(t, ValidateFeeTiers(tiers), ErrInvalidFeeTiers, tiers)
+	}
+
+	for _, rate := range []string{"1.01", "-0.01", "1/100", "1e-2", "abc"} {
+		err := ValidateFeeTiers([]FeeTier{{MinAmount: 0, Rate: rate}})
+		require.ErrorIs(t, err, ErrInvalidFeeRate, rate)
+	}
+}
@@ -0,0 +1,234 @@
+package util
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+var (
+	ErrMoneyOverflow = errors.New("the amount is out of range")
+	ErrCurrencyMismatch = errors.New("the amounts are not in the same currency")
+	ErrInvalidMoney = errors.New("the amount must be a decimal number such as 12.34")
+	ErrUnsupportedCurrency = errors.New("the currency is not supported")
+	ErrInvalidRate = errors.New("the exchange rate must be a positive decimal number")
+	ErrInvalidAnnualRate = errors.New("the annual rate must be a decimal number from 0 to less than 1")
+)
+
+// Money is an amount in the minor units of its currency, cents for USD and won for KRW.
+// Amounts are stored as integer minor units, Money only exists to parse, format and compute them safely.
+type Money struct {
+	amount int64
+	currency string
+}
+
+// NewMoney wraps an amount of minor units, as stored in the database
+func NewMoney(amount int64, currency string) Money {
+	return Money{amount: amount, currency: currency}
+}
+
+// ParseMoney reads a decimal amount such as "12.34" in the major units of the currency.
+// It rejects more decimals than the currency has rather than rounding them.
+func ParseMoney(value string, currency string) (Money, error) {
+	exponent, ok := MinorUnits(currency)
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	digits := value
+	negative := strings.HasPrefix(digits, "-")
+	if negative {
+		digits = digits[1:]
+	}
+
+	integer, fraction := digits, ""
+	if i := strings.IndexByte(digits, '.'); i >= 0 {
+		integer, fraction = digits[:i], digits[i+1:]
+		if len(fraction) == 0 {
+			return Money{}, ErrInvalidMoney
+		}
+	}
+
+	if len(integer) == 0 || !isDigits(integer) || !isDigits(fraction) {
+		return Money{}, ErrInvalidMoney
+	}
+
+	if len(fraction) > exponent {
+		return Money{}, fmt.Errorf("%s has at most %d decimals", currency, exponent)
+	}
+
+	minor, err := strconv.ParseUint(integer+fraction+strings.Repeat("0", exponent-len(fraction)), 10, 64)
+	if err != nil {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	if negative {
+		if minor > uint64(math.MaxInt64)+1 {
+			return Money{}, ErrMoneyOverflow
+		}
+		return NewMoney(int64(-minor), currency), nil
+	}
+
+	if minor > math.MaxInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(int64(minor), currency), nil
+}
+
+func isDigits(value string) bool {
+	for _, c := range value {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Amount returns the amount in minor units
+func (money Money) Amount() int64 {
+	return money.amount
+}
+
+func (money Money) Currency() string {
+	return money.currency
+}
+
+func (money Money) IsPositive() bool {
+	return money.amount > 0
+}
+
+func (money Money) IsNegative() bool {
+	return money.amount < 0
+}
+
+// Add returns the sum of the amounts, which must be in the same currency
+func (money Money) Add(other Money) (Money, error) {
+	if money.currency != other.currency {
+		return Money{}, ErrCurrencyMismatch
+	}
+
+	if (other.amount > 0 && money.amount > math.MaxInt64-other.amount) ||
+		(other.amount < 0 && money.amount < math.MinInt64-other.amount) {
+		return Money{}, ErrMoneyOverflow
+	}
+
+	return NewMoney(money.amount+other.amount, money.currency), nil
+}
+
+// Sub returns the difference of the amounts, which must be in the same currency
+func (money Money) Sub(other Money) (Money, error) {
+	negated, err := other.Neg()
+	if err != nil {
+		return Money{}, err
+	}
+	return money.Add(negated)
+}
+
+func (money Money) Neg() (Money, error) {
+	if money.amount == math.MinInt64 {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(-money.amount, money.currency), nil
+}
+
+// Mul multiplies the amount by an integer factor, a quantity for instance
+func (money Money) Mul(factor int64) (Money, error) {
+	if money.amount == 0 || factor == 0 {
+		return NewMoney(0, money.currency), nil
+	}
+
+	// the division does not detect MinInt64 * -1, which wraps around to itself
+	product := money.amount * factor
+	if (factor == -1 && money.amount == math.MinInt64) || product/factor != money.amount {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(product, money.currency), nil
+}
+
+// ParseRate reads an exchange rate such as "0.9215", which must be positive
+func ParseRate(value string) (*big.Rat, error) {
+	if strings.ContainsAny(value, "/eE") {
+		return nil, ErrInvalidRate
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() <= 0 {
+		return nil, ErrInvalidRate
+	}
+	return rate, nil
+}
+
+// ParseAnnualRate reads an annual interest rate such as "0.025" for 2.5%, a rate of 0 pays nothing
+func ParseAnnualRate(value string) (*big.Rat, error) {
+	if strings.ContainsAny(value, "/eE") {
+		return nil, ErrInvalidAnnualRate
+	}
+
+	rate, ok := new(big.Rat).SetString(value)
+	if !ok || rate.Sign() < 0 || rate.Cmp(big.NewRat(1, 1)) >= 0 {
+		return nil, ErrInvalidAnnualRate
+	}
+	return rate, nil
+}
+
+// Convert exchanges the amount into currency at rate, the price of one major unit of the currency of
+// the amount in major units of currency. The result is rounded toward zero to the minor unit of currency.
+func (money Money) Convert(currency string, rate string) (Money, error) {
+	fromExponent, ok := MinorUnits(money.currency)
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	toExponent, ok := MinorUnits(currency)
+	if !ok {
+		return Money{}, ErrUnsupportedCurrency
+	}
+
+	r, err := ParseRate(rate)
+	if err != nil {
+		return Money{}, err
+	}
+
+	// minor units of currency = amount * rate * 10^toExponent / 10^fromExponent
+	numerator := new(big.Int).Mul(big.NewInt(money.amount), r.Num())
+	numerator.Mul(numerator, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(toExponent)), nil))
+	denominator := new(big.Int).Mul(r.Denom(), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(fromExponent)), nil))
+
+	converted := numerator.Quo(numerator, denominator)
+	if !converted.IsInt64() {
+		return Money{}, ErrMoneyOverflow
+	}
+	return NewMoney(converted.Int64(), currency), nil
+}
+
+// String formats the amount in major units with the decimals of the currency, 1234 USD is "12.34"
+func (money Money) String() string {
+	exponent, _ := MinorUnits(money.currency)
+
+	var minor uint64
+	sign := ""
+	if money.amount < 0 {
+		sign = "-"
+		minor = uint64(-(money.amount + 1)) + 1
+	} else {
+		minor = uint64(money.amount)
+	}
+
+	digits := strconv.FormatUint(minor, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	return sign + digits[:len(digits)-exponent] + "." + digits[len(digits)-exponent:]
+}
+
+// MarshalJSON writes the amount as a decimal string, "12.34", so that clients never need floats
+func (money Money) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Quote(money.String())), nil
+}
@@ -0,0 +1,152 @@
+package util
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseMoney(t *testing.T) {
+	testCases := []struct {
+		value string
+		currency string
+		amount int64
+		err bool
+	}{
+		{value: "12.34", currency: USD, amount: 1234},
+		{value: "12.3", currency: USD, amount: 1230},
+		{value: "12", currency: EUR, amount: 1200},
+		{value: "0.05", currency: USD, amount: 5},
+		{value: "-7.50", currency: USD, amount: -750},
+		{value: "1500", currency: KRW, amount: 1500},
+		{value: "92233720368547758.07", currency: USD, amount: math.MaxInt64},
+		{value: "-92233720368547758.08", currency: USD, amount: math.MinInt64},
+		{value: "92233720368547758.08", currency: USD, err: true},
+		{value: "1.5", currency: KRW, err: true},
+		{value: "1.234", currency: USD, err: true},
+		{value: "", currency: USD, err: true},
+		{value: ".5", currency: USD, err: true},
+		{value: "5.", currency: USD, err: true},
+		{value: "+5", currency: USD, err: true},
+		{value: "1e3", currency: USD, err: true},
+		{value: "1,000", currency: USD, err: true},
+		{value: "10", currency: "XYZ", err: true},
+	}
+
+	for _, tc := range testCases {
+		money, err := ParseMoney(tc.value, tc.currency)
+		if tc.err {
+			require.Error(t, err, tc.value)
+			continue
+		}
+
+		require.NoError(t, err, tc.value)
+		require.Equal(t, tc.amount, money.Amount(), tc.value)
+		require.Equal(t, tc.currency, money.Currency())
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	require.Equal(t, "12.34", NewMoney(1234, USD).String())
+	require.Equal(t, "0.05", NewMoney(5, EUR).String())
+	require.Equal(t, "0.00", NewMoney(0, USD).String())
+	require.Equal(t, "-0.50", NewMoney(-50, USD).String())
+	require.Equal(t, "1234", NewMoney(1234, KRW).String())
+	require.Equal(t, "-92233720368547758.08", NewMoney(math.MinInt64, USD).String())
+
+	for i := 0; i < 100; i++ {
+		money := NewMoney(RandomInt(-1000000, 1000000), RandomCurrency())
+		parsed, err := ParseMoney(money.String(), money.Currency())
+		require.NoError(t, err)
+		require.Equal(t, money, parsed)
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	data, err := json.Marshal(struct {
+		Balance Money `json:"balance"`
+	}{NewMoney(1234, USD)})
+	require.NoError(t, err)
+	require.JSONEq(t, `{"balance": "12.34"}`, string(data))
+}
+
+func TestMoneyArithmetic(t *testing.T) {
+	sum, err := NewMoney(150, USD).Add(NewMoney(275, USD))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(425, USD), sum)
+
+	difference, err := NewMoney(150, USD).Sub(NewMoney(275, USD))
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(-125, USD), difference)
+
+	product, err := NewMoney(-150, KRW).Mul(3)
+	require.NoError(t, err)
+	require.Equal(t, NewMoney(-450, KRW), product)
+
+	_, err = NewMoney(1, USD).Add(NewMoney(1, EUR))
+	require.ErrorIs(t, err, ErrCurrencyMismatch)
+
+	_, err = NewMoney(math.MaxInt64, USD).Add(NewMoney(1, USD))
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(math.MinInt64, USD).Sub(NewMoney(1, USD))
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(0, USD).Sub(NewMoney(math.MinInt64, USD))
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(math.MinInt64, USD).Neg()
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(math.MaxInt64/2+1, USD).Mul(2)
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+
+	_, err = NewMoney(math.MinInt64, USD).Mul(-1)
+	require.ErrorIs(t, err, ErrMoneyOverflow)
+}
+
+func TestMoneyConvert(t *testing.T) {
+	testCases := []struct {
+		money Money
+		currency string
+		rate string
+		converted Money
+		err error
+	}{
+		{money: NewMoney(1000, USD), currency: EUR, rate: "0.9215", converted: NewMoney(921, EUR)},
+		{money: NewMoney(1234, USD), currency: KRW, rate: "1350.5", converted: NewMoney(16665, KRW)},
+		{money: NewMoney(16665, KRW), currency: USD, rate: "0.00074", converted: NewMoney(1233, USD)},
+		{money: NewMoney(1, USD), currency: EUR, rate: "0.5", converted: NewMoney(0, EUR)},
+		{money: NewMoney(100, USD), currency: EUR, rate: "0", err: ErrInvalidRate},
+		{money: NewMoney(100, USD), currency: EUR, rate: "-1", err: ErrInvalidRate},
+		{money: NewMoney(100, USD), currency: EUR, rate: "1/3", err: ErrInvalidRate},
+		{money: NewMoney(100, USD), currency: EUR, rate: "1e3", err: ErrInvalidRate},
+		{money: NewMoney(100, USD), currency: "XYZ", rate: "1", err: ErrUnsupportedCurrency},
+		{money: NewMoney(math.MaxInt64, USD), currency: KRW, rate: "1350", err: ErrMoneyOverflow},
+	}
+
+	for _, tc := range testCases {
+		converted, err := tc.money.Convert(tc.currency, tc.rate)
+		if tc.err != nil {
+			require.ErrorIs(t, err, tc.err, tc.rate)
+			continue
+		}
+
+		require.NoError(t, err, tc.rate)
+		require.Equal(t, tc.converted, converted, tc.rate)
+	}
+}
+
+func TestParseAnnualRate(t *testing.T) {
+	for _, value := range []string{"0", "0.025", "0.999"} {
+		_, err := ParseAnnualRate(value)
+		require.NoError(t, err, value)
+	}
+
+	for _, value := range []string{"1", "1.5", "-0.01", "1/40", "2.5e-2", "abc", ""} {
+		_, err := ParseAnnualRate(value)
+		require.ErrorIs(t, err, ErrInvalidAnnualRate, value)
+	}
+}
@@ -33,7 +33,7 @@ func RandomMoney() int64 {
 }
 
 func RandomCurrency() string {
-	currencies := []string{USD, EUR, KRW}
+	currencies := Currencies.Enabled()
 	return currencies[rand.Intn(len(currencies))]
 }
 
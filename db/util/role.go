@@ -0,0 +1,9 @@
+package util
+
+// roles of users, every user signs up as a depositor
+const (
+	ROLE_DEPOSITOR = "depositor"
+	ROLE_ADMIN = "admin"
+	// ROLE_SYSTEM users own the accounts of the bank, they have no password and cannot sign in
+	ROLE_SYSTEM = "system"
+)
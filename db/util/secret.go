@@ -0,0 +1,23 @@
+package util
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+)
+
+// RandomSecret returns a hex encoded, cryptographically secure random string built from numBytes random bytes
+func RandomSecret(numBytes int) (string, error) {
+	b := make([]byte, numBytes)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("Failed to generate secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// HashSecret returns the hex encoded sha256 of the secret so that it can be stored and looked up safely
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,27 @@
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRandomSecret(t *testing.T) {
+	secret, err := RandomSecret(32)
+	require.NoError(t, err)
+	require.Len(t, secret, 64)
+
+	otherSecret, err := RandomSecret(32)
+	require.NoError(t, err)
+	require.NotEqual(t, secret, otherSecret)
+}
+
+func TestHashSecret(t *testing.T) {
+	secret, err := RandomSecret(32)
+	require.NoError(t, err)
+
+	hash := HashSecret(secret)
+	require.Len(t, hash, 64)
+	require.NotEqual(t, secret, hash)
+	require.Equal(t, hash, HashSecret(secret))
+}
@@ -0,0 +1,114 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateAccount(ctx context.Context, req *pb.CreateAccountRequest) (*pb.CreateAccountResponse, error) {
+	if !util.IsSupportedCurrency(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency %s", req.GetCurrency())
+	}
+
+	if err := requireVerifiedEmail(ctx); err != nil {
+		return nil, err
+	}
+
+	arg := db.CreateAccountParams{
+		Owner: authPayload(ctx).Username,
+		Currency: req.GetCurrency(),
+		Balance: 0,
+		Type: db.ACCOUNT_CHECKING,
+	}
+
+	account, err := server.store.CreateAccount(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "foreign_key_violation", "unique_violation":
+				return nil, status.Error(codes.PermissionDenied, err.Error())
+			}
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.CreateAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	if req.GetId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "id must be positive")
+	}
+
+	account, err := server.store.GetAccount(ctx, req.GetId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = server.authorizeAccount(ctx, account, db.ACCOUNT_ROLE_OWNER, db.ACCOUNT_ROLE_SPENDER, db.ACCOUNT_ROLE_VIEWER)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pb.GetAccountResponse{Account: convertAccount(account)}, nil
+}
+
+func (server *Server) ListAccounts(ctx context.Context, req *pb.ListAccountsRequest) (*pb.ListAccountsResponse, error) {
+	if req.GetPageId() < 1 || req.GetPageSize() < 5 || req.GetPageSize() > 10 {
+		return nil, status.Error(codes.InvalidArgument, "page_id must be positive and page_size between 5 and 10")
+	}
+
+	arg := db.ListAccountsParams{
+		Username: authPayload(ctx).Username,
+		Limit: req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+
+	accounts, err := server.store.ListAccounts(ctx, arg)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &pb.ListAccountsResponse{Accounts: make([]*pb.Account, len(accounts))}
+	for i, account := range accounts {
+		res.Accounts[i] = convertAccount(account)
+	}
+	return res, nil
+}
+
+// authorizeAccount fails unless the authenticated user is a member of the account in one of the roles,
+// the holder is an owner without looking the membership up
+func (server *Server) authorizeAccount(ctx context.Context, account db.Account, roles ...string) error {
+	username := authPayload(ctx).Username
+	role := db.ACCOUNT_ROLE_OWNER
+	if account.Owner != username {
+		member, err := server.store.GetAccountMember(ctx, db.GetAccountMemberParams{
+			AccountID: account.ID,
+			Username: username,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return status.Error(codes.PermissionDenied, "the user has no access to the account")
+			}
+			return status.Error(codes.Internal, err.Error())
+		}
+		role = member.Role
+	}
+
+	for _, allowed := range roles {
+		if role == allowed {
+			return nil
+		}
+	}
+	return status.Errorf(codes.PermissionDenied, "the %s role does not allow it on the account", role)
+}
@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateAccountRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	account.Balance = 0
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	arg := db.CreateAccountParams{
+		Owner: user.Username,
+		Currency: account.Currency,
+		Balance: 0,
+		Type: db.ACCOUNT_CHECKING,
+	}
+	store.EXPECT().
+	CreateAccount(gomock.Any(), gomock.Eq(arg)).
+	Times(1).
+	Return(account, nil)
+	stubAuthUsers(store)
+
+	server, client := newTestClient(t, store)
+	ctx := withAuthorization(t, server.tokenManager, user.Username, token.ALL_SCOPES, time.Minute)
+
+	res, err := client.CreateAccount(ctx, &pb.CreateAccountRequest{Currency: account.Currency})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, res.GetAccount().GetId())
+	require.Equal(t, user.Username, res.GetAccount().GetOwner())
+
+	_, err = client.CreateAccount(ctx, &pb.CreateAccountRequest{Currency: "XYZ"})
+	requireStatusCode(t, codes.InvalidArgument, err)
+
+	// read only tokens cannot open accounts
+	ctx = withAuthorization(t, server.tokenManager, user.Username, token.READ_ONLY_SCOPES, time.Minute)
+	_, err = client.CreateAccount(ctx, &pb.CreateAccountRequest{Currency: account.Currency})
+	requireStatusCode(t, codes.PermissionDenied, err)
+}
+
+func TestListAccountsRPC(t *testing.T) {
+	user, _ := randomUser(t)
+	accounts := []db.Account{randomAccount(user.Username), randomAccount(user.Username)}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	arg := db.ListAccountsParams{
+		Username: user.Username,
+		Limit: 5,
+		Offset: 5,
+	}
+	store.EXPECT().
+	ListAccounts(gomock.Any(), gomock.Eq(arg)).
+	Times(1).
+	Return(accounts, nil)
+	stubAuthUsers(store)
+
+	server, client := newTestClient(t, store)
+	ctx := withAuthorization(t, server.tokenManager, user.Username, token.ALL_SCOPES, time.Minute)
+
+	res, err := client.ListAccounts(ctx, &pb.ListAccountsRequest{PageId: 2, PageSize: 5})
+	require.NoError(t, err)
+	require.Len(t, res.GetAccounts(), len(accounts))
+	for i, account := range accounts {
+		require.Equal(t, account.ID, res.GetAccounts()[i].GetId())
+	}
+
+	_, err = client.ListAccounts(ctx, &pb.ListAccountsRequest{PageId: 0, PageSize: 5})
+	requireStatusCode(t, codes.InvalidArgument, err)
+}
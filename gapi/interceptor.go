@@ -0,0 +1,149 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/token"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	AUTHORIZATION_HEADER = "authorization"
+	AUTHORIZATION_TYPE_BEARER = "bearer"
+	AUTHORIZATION_TYPE_API_KEY = "apikey"
+)
+
+type contextKey string
+
+const (
+	authorizationPayloadKey contextKey = "authorization_payload"
+	authorizationUserKey contextKey = "authorization_user"
+)
+
+// publicMethods can be called without credentials
+var publicMethods = map[string]bool{
+	"/pb.UserService/CreateUser": true,
+	"/pb.UserService/LoginUser": true,
+}
+
+// methodScopes is the scope each authenticated method requires, like the routes of the HTTP server
+var methodScopes = map[string]string{
+	"/pb.UserService/GetUser": token.SCOPE_USER_READ,
+	"/pb.AccountService/CreateAccount": token.SCOPE_ACCOUNTS_WRITE,
+	"/pb.AccountService/GetAccount": token.SCOPE_ACCOUNTS_READ,
+	"/pb.AccountService/ListAccounts": token.SCOPE_ACCOUNTS_READ,
+	"/pb.TransferService/CreateTransfer": token.SCOPE_TRANSFERS_WRITE,
+}
+
+// authInterceptor is the gRPC counterpart of the authMiddleware of the HTTP server.
+// It verifies the bearer token or API key of the authorization metadata, loads its user,
+// rejects tokens and API keys issued before the last password change and enforces the scope of the method.
+func (server *Server) authInterceptor(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (interface{}, error) {
+	if publicMethods[info.FullMethod] {
+		return handler(ctx, req)
+	}
+
+	scope, found := methodScopes[info.FullMethod]
+	if !found {
+		return nil, status.Errorf(codes.PermissionDenied, "no scope grants access to %s", info.FullMethod)
+	}
+
+	payload, user, err := server.authorize(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !payload.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, "the %s scope is required", scope)
+	}
+
+	ctx = context.WithValue(ctx, authorizationPayloadKey, payload)
+	ctx = context.WithValue(ctx, authorizationUserKey, user)
+	return handler(ctx, req)
+}
+
+func (server *Server) authorize(ctx context.Context) (*token.Payload, db.User, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return nil, db.User{}, status.Error(codes.Unauthenticated, "missing metadata")
+	}
+
+	values := md.Get(AUTHORIZATION_HEADER)
+	if len(values) == 0 {
+		return nil, db.User{}, status.Error(codes.Unauthenticated, "authorization header is not provided")
+	}
+
+	fields := strings.Fields(values[0])
+	if len(fields) < 2 {
+		return nil, db.User{}, status.Error(codes.Unauthenticated, "invalid authorization header format")
+	}
+
+	var payload *token.Payload
+	var err error
+
+	authorizationType := strings.ToLower(fields[0])
+	switch authorizationType {
+	case AUTHORIZATION_TYPE_BEARER:
+		payload, err = server.tokenManager.VerifyToken(fields[1])
+		if err != nil {
+			return nil, db.User{}, status.Error(codes.Unauthenticated, err.Error())
+		}
+	case AUTHORIZATION_TYPE_API_KEY:
+		apiKey, err := server.store.GetApiKeyByHash(ctx, util.HashSecret(fields[1]))
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return nil, db.User{}, status.Error(codes.Unauthenticated, "api key is invalid or revoked")
+			}
+			return nil, db.User{}, status.Error(codes.Internal, err.Error())
+		}
+
+		payload = &token.Payload{
+			Username: apiKey.Username,
+			Scopes: apiKey.Scopes,
+			IssuedAt: apiKey.CreatedAt,
+		}
+	default:
+		return nil, db.User{}, status.Error(codes.Unauthenticated, "unsupported authorization type")
+	}
+
+	user, err := server.store.GetUser(ctx, payload.Username)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, db.User{}, status.Error(codes.Unauthenticated, "the user of the token does not exist")
+		}
+		return nil, db.User{}, status.Error(codes.Internal, err.Error())
+	}
+
+	if payload.IssuedAt.Before(user.PasswordChangedAt) {
+		return nil, db.User{}, status.Error(codes.Unauthenticated, "token or api key was issued before the last password change")
+	}
+
+	return payload, user, nil
+}
+
+func authPayload(ctx context.Context) *token.Payload {
+	return ctx.Value(authorizationPayloadKey).(*token.Payload)
+}
+
+func authUser(ctx context.Context) db.User {
+	return ctx.Value(authorizationUserKey).(db.User)
+}
+
+func requireVerifiedEmail(ctx context.Context) error {
+	if !authUser(ctx).IsEmailVerified {
+		return status.Error(codes.PermissionDenied, "the email of the user has not been verified yet")
+	}
+	return nil
+}
@@ -0,0 +1,230 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestAuthInterceptor(t *testing.T) {
+	user, _ := randomUser(t)
+	account := randomAccount(user.Username)
+	apiKey := "sbk_0123456789abcdef"
+
+	testCases := []struct {
+		name string
+		setupAuth func(t *testing.T, tokenManager token.TokenManager) context.Context
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.GetAccountResponse, err error)
+	}{
+		{
+			name: "OK",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				return withAuthorization(t, tokenManager, user.Username, token.ALL_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAuthUsers(store)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+				require.Equal(t, account.Balance, res.GetAccount().GetBalance())
+			},
+		},
+		{
+			name: "No Authorization",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				return context.Background()
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatusCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			name: "Expired Token",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				return withAuthorization(t, tokenManager, user.Username, token.ALL_SCOPES, -time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatusCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			name: "Token Issued Before Password Change",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				return withAuthorization(t, tokenManager, user.Username, token.ALL_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.User{Username: user.Username, PasswordChangedAt: time.Now().Add(time.Minute)}, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatusCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			name: "Missing Scope",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				scopes := []string{token.SCOPE_TRANSFERS_WRITE}
+				return withAuthorization(t, tokenManager, user.Username, scopes, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+				stubAuthUsers(store)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatusCode(t, codes.PermissionDenied, err)
+			},
+		},
+		{
+			name: "API Key",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				authorization := AUTHORIZATION_TYPE_API_KEY + " " + apiKey
+				return metadata.AppendToOutgoingContext(context.Background(), AUTHORIZATION_HEADER, authorization)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Eq(util.HashSecret(apiKey))).
+				Times(1).
+				Return(db.ApiKey{Username: user.Username, Scopes: []string{token.SCOPE_ACCOUNTS_READ}}, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				stubAuthUsers(store)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+			},
+		},
+		{
+			name: "API Key Created Before Password Change",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				authorization := AUTHORIZATION_TYPE_API_KEY + " " + apiKey
+				return metadata.AppendToOutgoingContext(context.Background(), AUTHORIZATION_HEADER, authorization)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Eq(util.HashSecret(apiKey))).
+				Times(1).
+				Return(db.ApiKey{Username: user.Username, Scopes: []string{token.SCOPE_ACCOUNTS_READ}, CreatedAt: time.Now().Add(-time.Hour)}, nil)
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(db.User{Username: user.Username, PasswordChangedAt: time.Now()}, nil)
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatusCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			name: "Revoked API Key",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				authorization := AUTHORIZATION_TYPE_API_KEY + " " + apiKey
+				return metadata.AppendToOutgoingContext(context.Background(), AUTHORIZATION_HEADER, authorization)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetApiKeyByHash(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.ApiKey{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatusCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			name: "Viewer Member",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				return withAuthorization(t, tokenManager, "other_user", token.ALL_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{AccountID: account.ID, Username: "other_user", Role: db.ACCOUNT_ROLE_VIEWER}, nil)
+				stubAuthUsers(store)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, account.ID, res.GetAccount().GetId())
+			},
+		},
+		{
+			name: "Other Owner",
+			setupAuth: func(t *testing.T, tokenManager token.TokenManager) context.Context {
+				return withAuthorization(t, tokenManager, "other_user", token.ALL_SCOPES, time.Minute)
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetAccount(gomock.Any(), gomock.Eq(account.ID)).
+				Times(1).
+				Return(account, nil)
+				store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account.ID, Username: "other_user"})).
+				Times(1).
+				Return(db.AccountMember{}, sql.ErrNoRows)
+				stubAuthUsers(store)
+			},
+			checkResponse: func(t *testing.T, res *pb.GetAccountResponse, err error) {
+				requireStatusCode(t, codes.PermissionDenied, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, client := newTestClient(t, store)
+			ctx := tc.setupAuth(t, server.tokenManager)
+
+			res, err := client.GetAccount(ctx, &pb.GetAccountRequest{Id: account.ID})
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/mail"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+type testClient struct {
+	pb.UserServiceClient
+	pb.AccountServiceClient
+	pb.TransferServiceClient
+}
+
+// newTestClient serves the gRPC API over an in-memory connection
+func newTestClient(t *testing.T, store db.Store) (*Server, testClient) {
+	config := util.Config{
+		PasetoSymmetricKey: util.RandomString(32),
+		AccessTokenDuration: time.Minute,
+		VerifyEmailDuration: time.Minute,
+		PayeeCoolingOffPeriod: time.Hour,
+		PayeeCoolingOffAmounts: "USD:1000",
+	}
+
+	server, err := NewServer(config, store, mail.NewLogMailer(ioutil.Discard))
+	require.NoError(t, err)
+
+	listener := bufconn.Listen(1024 * 1024)
+	grpcServer := server.NewGRPCServer()
+	go grpcServer.Serve(listener)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(
+		context.Background(),
+		"bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.Dial()
+		}),
+		grpc.WithInsecure(),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { conn.Close() })
+
+	client := testClient{
+		UserServiceClient: pb.NewUserServiceClient(conn),
+		AccountServiceClient: pb.NewAccountServiceClient(conn),
+		TransferServiceClient: pb.NewTransferServiceClient(conn),
+	}
+	return server, client
+}
+
+func withAuthorization(
+	t *testing.T,
+	tokenManager token.TokenManager,
+	username string,
+	scopes []string,
+	duration time.Duration,
+) context.Context {
+	accessToken, err := tokenManager.CreateToken(username, scopes, duration)
+	require.NoError(t, err)
+
+	authorization := fmt.Sprintf("%s %s", AUTHORIZATION_TYPE_BEARER, accessToken)
+	return metadata.AppendToOutgoingContext(context.Background(), AUTHORIZATION_HEADER, authorization)
+}
+
+// stubAuthUsers lets the interceptor load any user whose token is presented.
+// It should be called after the test case stubs so that their own GetUser expectations take precedence.
+func stubAuthUsers(store *testdb.MockStore) {
+	store.EXPECT().
+	GetUser(gomock.Any(), gomock.Any()).
+	AnyTimes().
+	DoAndReturn(func(ctx context.Context, username string) (db.User, error) {
+		return db.User{Username: username, IsEmailVerified: true}, nil
+	})
+}
+
+func requireStatusCode(t *testing.T, code codes.Code, err error) {
+	st, ok := status.FromError(err)
+	require.True(t, ok)
+	require.Equal(t, code, st.Code(), st.Message())
+}
+
+func randomUser(t *testing.T) (user db.User, password string) {
+	username := util.RandomEmail()
+	password = util.RandomString(10)
+	hashedPassword, err := util.HashPassword(password)
+	require.NoError(t, err)
+
+	user = db.User{
+		Username: username,
+		HashedPassword: hashedPassword,
+		FullName: util.RandomOwner(),
+		Email: username,
+		IsEmailVerified: true,
+	}
+	return
+}
+
+func randomAccount(username string) db.Account {
+	return db.Account{
+		ID: util.RandomInt(1, 10000),
+		Owner: username,
+		Balance: util.RandomMoney(),
+		Currency: util.RandomCurrency(),
+	}
+}
@@ -0,0 +1,59 @@
+package gapi
+
+import (
+	"fmt"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/mail"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/sssaang/simplebank/token"
+	"google.golang.org/grpc"
+)
+
+// Server serves the gRPC API on top of the same store and tokens as the gin HTTP server
+type Server struct {
+	pb.UnimplementedUserServiceServer
+	pb.UnimplementedAccountServiceServer
+	pb.UnimplementedTransferServiceServer
+	config util.Config
+	store db.Store
+	tokenManager token.TokenManager
+	mailer mail.Mailer
+	riskEngine *risk.Engine
+	coolingOff *risk.CoolingOff
+}
+
+// NewServer creates a new gRPC server
+func NewServer(config util.Config, store db.Store, mailer mail.Mailer) (*Server, error) {
+	tokenManager, err := token.NewTokenManagerFromConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token manager %w", err)
+	}
+
+	coolingOff, err := risk.NewCoolingOff(store, config.PayeeCoolingOffPeriod, config.PayeeCoolingOffAmounts)
+	if err != nil {
+		return nil, fmt.Errorf("cannot parse payee cooling-off amounts %w", err)
+	}
+
+	server := &Server{
+		config: config,
+		store: store,
+		tokenManager: tokenManager,
+		mailer: mailer,
+		riskEngine: risk.NewEngine(store),
+		coolingOff: coolingOff,
+	}
+
+	return server, nil
+}
+
+// NewGRPCServer creates a grpc.Server that authenticates every call and registers the services on it
+func (server *Server) NewGRPCServer() *grpc.Server {
+	grpcServer := grpc.NewServer(grpc.UnaryInterceptor(server.authInterceptor))
+	pb.RegisterUserServiceServer(grpcServer, server)
+	pb.RegisterAccountServiceServer(grpcServer, server)
+	pb.RegisterTransferServiceServer(grpcServer, server)
+	return grpcServer
+}
@@ -0,0 +1,158 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/risk"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (server *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	if req.GetFromAccountId() < 1 || req.GetToAccountId() < 1 {
+		return nil, status.Error(codes.InvalidArgument, "account ids must be positive")
+	}
+
+	if req.GetAmount() <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be positive")
+	}
+
+	if !util.IsSupportedCurrency(req.GetCurrency()) {
+		return nil, status.Errorf(codes.InvalidArgument, "unsupported currency %s", req.GetCurrency())
+	}
+
+	if err := requireVerifiedEmail(ctx); err != nil {
+		return nil, err
+	}
+
+	fromAccount, err := server.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.authorizeAccount(ctx, fromAccount, db.ACCOUNT_ROLE_OWNER, db.ACCOUNT_ROLE_SPENDER)
+	if err != nil {
+		return nil, err
+	}
+
+	toAccount, err := server.validAccount(ctx, req.GetToAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.checkCoolingOff(ctx, toAccount, req.GetAmount())
+	if err != nil {
+		return nil, err
+	}
+
+	err = server.screenTransfer(ctx, fromAccount, toAccount, req.GetAmount())
+	if err != nil {
+		return nil, err
+	}
+
+	arg := db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID: req.GetToAccountId(),
+		Amount: req.GetAmount(),
+		RequestedBy: authPayload(ctx).Username,
+	}
+
+	result, err := server.store.TransferTx(ctx, arg)
+	switch {
+	case err == nil:
+	case errors.Is(err, db.ErrInsufficientFunds), errors.Is(err, db.ErrAccountClosed):
+		return nil, status.Error(codes.FailedPrecondition, err.Error())
+	case errors.Is(err, db.ErrTransferLimitExceeded):
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	case errors.Is(err, db.ErrSameAccount):
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, sql.ErrNoRows):
+		return nil, status.Error(codes.NotFound, err.Error())
+	default:
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &pb.CreateTransferResponse{
+		Transfer: convertTransfer(result.Transfer),
+		FromAccount: convertAccount(result.FromAccount),
+		ToAccount: convertAccount(result.ToAccount),
+		FromEntry: convertEntry(result.FromEntry),
+		ToEntry: convertEntry(result.ToEntry),
+	}
+	return res, nil
+}
+
+func (server *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := server.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, status.Error(codes.NotFound, err.Error())
+		}
+		return account, status.Error(codes.Internal, err.Error())
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(
+			codes.InvalidArgument,
+			"account [%d] currency mismatch: the currency of the account is %s while the currency of the transfer is %s",
+			accountID, account.Currency, currency,
+		)
+	}
+
+	return account, nil
+}
+
+// checkCoolingOff holds back a first large transfer to an account the user has not paid before, as the HTTP API does
+func (server *Server) checkCoolingOff(ctx context.Context, toAccount db.Account, amount int64) error {
+	err := server.coolingOff.Check(ctx, authPayload(ctx).Username, toAccount, amount)
+	var coolingOffErr *risk.CoolingOffError
+	if errors.As(err, &coolingOffErr) {
+		return status.Error(codes.FailedPrecondition, coolingOffErr.Error())
+	}
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+	return nil
+}
+
+// screenTransfer runs the fraud rules as the HTTP API does, a transfer flagged for review is held
+// and the caller is told so with its id, since the response of the RPC can only describe a transfer made
+func (server *Server) screenTransfer(ctx context.Context, fromAccount db.Account, toAccount db.Account, amount int64) error {
+	assessment, err := server.riskEngine.Screen(ctx, risk.Transfer{
+		FromAccount: fromAccount,
+		ToAccount: toAccount,
+		Amount: amount,
+		RequestedBy: authPayload(ctx).Username,
+	})
+	if err != nil {
+		return status.Error(codes.Internal, err.Error())
+	}
+
+	switch assessment.Outcome {
+	case risk.OUTCOME_DENY:
+		return status.Error(codes.PermissionDenied, "the transfer was denied by the fraud screening")
+	case risk.OUTCOME_REVIEW:
+		held, err := server.store.CreateHeldTransfer(ctx, db.CreateHeldTransferParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID: toAccount.ID,
+			Amount: amount,
+			Currency: fromAccount.Currency,
+			RequestedBy: authPayload(ctx).Username,
+			Reasons: assessment.Reasons(),
+			// the gRPC API takes no transfer details yet
+			Metadata: json.RawMessage("{}"),
+		})
+		if err != nil {
+			return status.Error(codes.Internal, err.Error())
+		}
+		return status.Errorf(codes.FailedPrecondition, "the transfer is held for review as held transfer %d", held.ID)
+	}
+
+	return nil
+}
@@ -0,0 +1,328 @@
+package gapi
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/risk"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateTransferRPC(t *testing.T) {
+	user1, _ := randomUser(t)
+	user2, _ := randomUser(t)
+	account1 := randomAccount(user1.Username)
+	account2 := randomAccount(user2.Username)
+	account3 := randomAccount(user2.Username)
+	account1.Currency = util.USD
+	account2.Currency = util.USD
+	account3.Currency = util.EUR
+	amount := int64(10)
+
+	testCases := []struct {
+		name string
+		username string
+		req *pb.CreateTransferRequest
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, res *pb.CreateTransferResponse, err error)
+	}{
+		{
+			name: "OK",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+
+				arg := db.TransferTxParams{
+					FromAccountID: account1.ID,
+					ToAccountID: account2.ID,
+					Amount: amount,
+					RequestedBy: user1.Username,
+				}
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Eq(arg)).
+				Times(1).
+				Return(db.TransferTxResult{
+					Transfer: db.Transfer{ID: 1, FromAccountID: account1.ID, ToAccountID: account2.ID, Amount: amount},
+					FromAccount: account1,
+					ToAccount: account2,
+				}, nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, amount, res.GetTransfer().GetAmount())
+				require.Equal(t, account1.ID, res.GetFromAccount().GetId())
+			},
+		},
+		{
+			name: "Not The Owner",
+			username: user2.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Eq(db.GetAccountMemberParams{AccountID: account1.ID, Username: user2.Username})).
+				Times(1).
+				Return(db.AccountMember{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.PermissionDenied, err)
+			},
+		},
+		{
+			name: "Viewer",
+			username: user2.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().
+				GetAccountMember(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.AccountMember{AccountID: account1.ID, Username: user2.Username, Role: db.ACCOUNT_ROLE_VIEWER}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.PermissionDenied, err)
+			},
+		},
+		{
+			name: "Currency Mismatch",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account3.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account3.ID)).Times(1).Return(account3, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			name: "Held For Review",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+				ListEnabledFraudRules(gomock.Any()).
+				Times(1).
+				Return([]db.FraudRule{{ID: 1, Name: "new recipient", Kind: risk.RULE_NEW_RECIPIENT, Action: risk.OUTCOME_REVIEW}}, nil)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Eq(db.HasTransferredToParams{Owner: user1.Username, ToAccountID: account2.ID})).
+				Times(1).
+				Return(false, nil)
+				store.EXPECT().
+				CreateHeldTransfer(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.HeldTransfer{ID: 7, Status: db.HELD_TRANSFER_PENDING}, nil)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.FailedPrecondition, err)
+				require.Contains(t, err.Error(), "held transfer 7")
+			},
+		},
+		{
+			name: "Cooling Off",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: 100000,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Eq(db.HasTransferredToParams{Owner: user1.Username, ToAccountID: account2.ID})).
+				Times(1).
+				Return(false, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Eq(db.GetPayeeByAccountParams{Owner: user1.Username, AccountID: account2.ID})).
+				Times(1).
+				Return(db.Payee{}, sql.ErrNoRows)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.FailedPrecondition, err)
+			},
+		},
+		{
+			name: "Zero Amount",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: 0,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().TransferTx(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			name: "Account Closed",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.TransferTxResult{}, db.ErrAccountClosed)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.FailedPrecondition, err)
+			},
+		},
+		{
+			name: "Same Account",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.TransferTxResult{}, db.ErrSameAccount)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			name: "Account Not Found",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: util.USD,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account1.ID)).Times(1).Return(account1, nil)
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Eq(account2.ID)).Times(1).Return(account2, nil)
+				store.EXPECT().
+				TransferTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.TransferTxResult{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.NotFound, err)
+			},
+		},
+		{
+			name: "Unsupported Currency",
+			username: user1.Username,
+			req: &pb.CreateTransferRequest{
+				FromAccountId: account1.ID,
+				ToAccountId: account2.ID,
+				Amount: amount,
+				Currency: "XYZ",
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateTransferResponse, err error) {
+				requireStatusCode(t, codes.InvalidArgument, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+			stubAuthUsers(store)
+			store.EXPECT().ListEnabledFraudRules(gomock.Any()).AnyTimes().Return([]db.FraudRule{}, nil)
+
+			server, client := newTestClient(t, store)
+			ctx := withAuthorization(t, server.tokenManager, tc.username, token.ALL_SCOPES, time.Minute)
+
+			res, err := client.CreateTransfer(ctx, tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestCreateTransferRPCUnverifiedEmail(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	GetUser(gomock.Any(), gomock.Eq(user.Username)).
+	Times(1).
+	Return(db.User{Username: user.Username, IsEmailVerified: false}, nil)
+	store.EXPECT().GetAccount(gomock.Any(), gomock.Any()).Times(0)
+
+	server, client := newTestClient(t, store)
+	ctx := withAuthorization(t, server.tokenManager, user.Username, token.ALL_SCOPES, time.Minute)
+
+	_, err := client.CreateTransfer(ctx, &pb.CreateTransferRequest{
+		FromAccountId: 1,
+		ToAccountId: 2,
+		Amount: 10,
+		Currency: util.USD,
+	})
+	requireStatusCode(t, codes.PermissionDenied, err)
+}
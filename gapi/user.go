@@ -0,0 +1,131 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/token"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const VERIFY_EMAIL_SECRET_CODE_BYTES = 16
+
+// validate checks the fields with the rules of the binding tags of the HTTP API
+var validate = validator.New()
+
+// CreateUser registers a user like POST /user, the verification link is mailed before the user is committed
+func (server *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
+	switch {
+	case validate.Var(req.GetUsername(), "required,email") != nil:
+		return nil, status.Error(codes.InvalidArgument, "username must be an email")
+	case validate.Var(req.GetEmail(), "required,email") != nil:
+		return nil, status.Error(codes.InvalidArgument, "email must be a valid email")
+	case len(req.GetFullName()) == 0:
+		return nil, status.Error(codes.InvalidArgument, "full name is required")
+	case len(req.GetPassword()) < 8:
+		return nil, status.Error(codes.InvalidArgument, "password must have at least 8 characters")
+	}
+
+	hashedPassword, err := util.HashPassword(req.GetPassword())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	secretCode, err := util.RandomSecret(VERIFY_EMAIL_SECRET_CODE_BYTES)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	arg := db.CreateUserTxParams{
+		CreateUserParams: db.CreateUserParams{
+			Username: req.GetUsername(),
+			HashedPassword: hashedPassword,
+			FullName: req.GetFullName(),
+			Email: req.GetEmail(),
+		},
+		SecretCodeHash: util.HashSecret(secretCode),
+		VerifyEmailExpiredAt: time.Now().Add(server.config.VerifyEmailDuration),
+		AfterCreate: func(user db.User, verifyEmail db.VerifyEmail) error {
+			return server.sendVerifyEmail(user, verifyEmail, secretCode)
+		},
+	}
+
+	result, err := server.store.CreateUserTx(ctx, arg)
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok && pqErr.Code.Name() == "unique_violation" {
+			return nil, status.Error(codes.AlreadyExists, "the username or email is already taken")
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &pb.CreateUserResponse{User: convertUser(result.User)}, nil
+}
+
+// sendVerifyEmail mails the link of the HTTP API which verifies the email
+func (server *Server) sendVerifyEmail(user db.User, verifyEmail db.VerifyEmail, secretCode string) error {
+	verifyURL := fmt.Sprintf(
+		"http://%s/verify_email?email_id=%d&secret_code=%s",
+		server.config.ApiAddress,
+		verifyEmail.ID,
+		secretCode,
+	)
+	content := fmt.Sprintf(
+		"Hello %s,\n\nplease confirm your email address by visiting %s\nThe link expires in %s.",
+		user.FullName,
+		verifyURL,
+		server.config.VerifyEmailDuration,
+	)
+
+	return server.mailer.SendEmail(verifyEmail.Email, "Verify your email address", content)
+}
+
+func (server *Server) LoginUser(ctx context.Context, req *pb.LoginUserRequest) (*pb.LoginUserResponse, error) {
+	if len(req.GetUsername()) == 0 || len(req.GetPassword()) < 8 {
+		return nil, status.Error(codes.InvalidArgument, "username and a password of at least 8 characters are required")
+	}
+
+	user, err := server.store.GetUser(ctx, req.GetUsername())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = util.CheckPassword(user.HashedPassword, req.GetPassword())
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+
+	accessToken, err := server.tokenManager.CreateToken(
+		user.Username,
+		token.ALL_SCOPES,
+		server.config.AccessTokenDuration,
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	res := &pb.LoginUserResponse{
+		AccessToken: accessToken,
+		User: convertUser(user),
+	}
+	return res, nil
+}
+
+func (server *Server) GetUser(ctx context.Context, req *pb.GetUserRequest) (*pb.GetUserResponse, error) {
+	if req.GetUsername() != authPayload(ctx).Username {
+		return nil, status.Error(codes.PermissionDenied, "the user has no access to the information of the requested user")
+	}
+
+	// the user has already been loaded by the interceptor
+	return &pb.GetUserResponse{User: convertUser(authUser(ctx))}, nil
+}
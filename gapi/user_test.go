@@ -0,0 +1,251 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/lib/pq"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	mockmail "github.com/sssaang/simplebank/mail/mock"
+	"github.com/sssaang/simplebank/pb"
+	"github.com/sssaang/simplebank/token"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+)
+
+func TestCreateUserRPC(t *testing.T) {
+	user, password := randomUser(t)
+	req := &pb.CreateUserRequest{
+		Username: user.Username,
+		FullName: user.FullName,
+		Email: user.Email,
+		Password: password,
+	}
+
+	// createUser runs the callback as the store would before committing
+	createUser := func(_ interface{}, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+		verifyEmail := db.VerifyEmail{ID: 1, Username: arg.Username, Email: arg.Email}
+		if err := arg.AfterCreate(user, verifyEmail); err != nil {
+			return db.CreateUserTxResult{}, err
+		}
+		return db.CreateUserTxResult{User: user, VerifyEmail: verifyEmail}, nil
+	}
+
+	testCases := []struct {
+		name string
+		req *pb.CreateUserRequest
+		buildStubs func(store *testdb.MockStore, mailer *mockmail.MockMailer)
+		checkResponse func(t *testing.T, res *pb.CreateUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: req,
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				CreateUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(ctx interface{}, arg db.CreateUserTxParams) (db.CreateUserTxResult, error) {
+					require.Equal(t, user.Username, arg.Username)
+					require.Equal(t, user.Email, arg.Email)
+					require.NotEqual(t, password, arg.HashedPassword)
+					require.NotEmpty(t, arg.SecretCodeHash)
+					return createUser(ctx, arg)
+				})
+
+				mailer.EXPECT().
+				SendEmail(gomock.Eq(user.Email), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(nil)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
+				require.Equal(t, user.Email, res.GetUser().GetEmail())
+			},
+		},
+		{
+			name: "Invalid Email",
+			req: &pb.CreateUserRequest{Username: user.Username, FullName: user.FullName, Email: "invalid", Password: password},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				CreateUserTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireStatusCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			name: "Short Password",
+			req: &pb.CreateUserRequest{Username: user.Username, FullName: user.FullName, Email: user.Email, Password: "short"},
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				CreateUserTx(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireStatusCode(t, codes.InvalidArgument, err)
+			},
+		},
+		{
+			name: "Duplicate Username",
+			req: req,
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				CreateUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.CreateUserTxResult{}, &pq.Error{Code: "23505"})
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireStatusCode(t, codes.AlreadyExists, err)
+			},
+		},
+		{
+			name: "Mailer Error",
+			req: req,
+			buildStubs: func(store *testdb.MockStore, mailer *mockmail.MockMailer) {
+				store.EXPECT().
+				CreateUserTx(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(createUser)
+
+				mailer.EXPECT().
+				SendEmail(gomock.Any(), gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(errors.New("smtp unavailable"))
+			},
+			checkResponse: func(t *testing.T, res *pb.CreateUserResponse, err error) {
+				requireStatusCode(t, codes.Internal, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			mailer := mockmail.NewMockMailer(ctrl)
+			tc.buildStubs(store, mailer)
+
+			server, client := newTestClient(t, store)
+			server.mailer = mailer
+
+			res, err := client.CreateUser(context.Background(), tc.req)
+			tc.checkResponse(t, res, err)
+		})
+	}
+}
+
+func TestLoginUserRPC(t *testing.T) {
+	user, password := randomUser(t)
+
+	testCases := []struct {
+		name string
+		req *pb.LoginUserRequest
+		buildStubs func(store *testdb.MockStore)
+		checkResponse func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error)
+	}{
+		{
+			name: "OK",
+			req: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error) {
+				require.NoError(t, err)
+				require.Equal(t, user.Username, res.GetUser().GetUsername())
+
+				payload, err := server.tokenManager.VerifyToken(res.GetAccessToken())
+				require.NoError(t, err)
+				require.Equal(t, user.Username, payload.Username)
+				require.Equal(t, token.ALL_SCOPES, payload.Scopes)
+			},
+		},
+		{
+			name: "Wrong Password",
+			req: &pb.LoginUserRequest{Username: user.Username, Password: "wrong password"},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Eq(user.Username)).
+				Times(1).
+				Return(user, nil)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error) {
+				requireStatusCode(t, codes.Unauthenticated, err)
+			},
+		},
+		{
+			name: "User Not Found",
+			req: &pb.LoginUserRequest{Username: user.Username, Password: password},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(1).
+				Return(db.User{}, sql.ErrNoRows)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error) {
+				requireStatusCode(t, codes.NotFound, err)
+			},
+		},
+		{
+			name: "Short Password",
+			req: &pb.LoginUserRequest{Username: user.Username, Password: "short"},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				GetUser(gomock.Any(), gomock.Any()).
+				Times(0)
+			},
+			checkResponse: func(t *testing.T, server *Server, res *pb.LoginUserResponse, err error) {
+				requireStatusCode(t, codes.InvalidArgument, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			server, client := newTestClient(t, store)
+			res, err := client.LoginUser(context.Background(), tc.req)
+			tc.checkResponse(t, server, res, err)
+		})
+	}
+}
+
+func TestGetUserRPC(t *testing.T) {
+	user, _ := randomUser(t)
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	store := testdb.NewMockStore(ctrl)
+	store.EXPECT().
+	GetUser(gomock.Any(), gomock.Eq(user.Username)).
+	AnyTimes().
+	Return(user, nil)
+
+	server, client := newTestClient(t, store)
+	ctx := withAuthorization(t, server.tokenManager, user.Username, token.ALL_SCOPES, time.Minute)
+
+	res, err := client.GetUser(ctx, &pb.GetUserRequest{Username: user.Username})
+	require.NoError(t, err)
+	require.Equal(t, user.Username, res.GetUser().GetUsername())
+	require.Equal(t, user.FullName, res.GetUser().GetFullName())
+
+	_, err = client.GetUser(ctx, &pb.GetUserRequest{Username: "other_user"})
+	requireStatusCode(t, codes.PermissionDenied, err)
+}
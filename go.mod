@@ -9,8 +9,10 @@ require (
 	github.com/golang/mock v1.3.1
 	github.com/google/uuid v1.2.0
 	github.com/lib/pq v1.10.1
-	github.com/o1egl/paseto v1.0.0 // indirect
+	github.com/o1egl/paseto v1.0.0
 	github.com/spf13/viper v1.7.1
 	github.com/stretchr/testify v1.7.0
 	golang.org/x/crypto v0.0.0-20210513164829-c07d793c2f9a
+	google.golang.org/grpc v1.38.0
+	google.golang.org/protobuf v1.26.0
 )
@@ -0,0 +1,34 @@
+package mail
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"os"
+)
+
+// LogMailer writes emails to a log instead of delivering them. It is meant for development.
+type LogMailer struct {
+	logger *log.Logger
+}
+
+func NewLogMailer(writer io.Writer) Mailer {
+	return &LogMailer{
+		logger: log.New(writer, "[mail] ", log.LstdFlags),
+	}
+}
+
+// NewFileMailer creates a LogMailer appending every email to the file at path
+func NewFileMailer(path string) (Mailer, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("cannot open mail file %w", err)
+	}
+
+	return NewLogMailer(file), nil
+}
+
+func (mailer *LogMailer) SendEmail(to string, subject string, content string) error {
+	mailer.logger.Printf("to: %s\nsubject: %s\n\n%s\n", to, subject, content)
+	return nil
+}
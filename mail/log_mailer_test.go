@@ -0,0 +1,25 @@
+package mail
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogMailer(t *testing.T) {
+	var buffer bytes.Buffer
+	mailer := NewLogMailer(&buffer)
+
+	to := util.RandomEmail()
+	subject := util.RandomString(10)
+	content := util.RandomString(30)
+
+	err := mailer.SendEmail(to, subject, content)
+	require.NoError(t, err)
+
+	require.Contains(t, buffer.String(), to)
+	require.Contains(t, buffer.String(), subject)
+	require.Contains(t, buffer.String(), content)
+}
@@ -0,0 +1,7 @@
+package mail
+
+// Mailer delivers emails to the users of the bank.
+// Implementations can be swapped without touching the handlers that send emails.
+type Mailer interface {
+	SendEmail(to string, subject string, content string) error
+}
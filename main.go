@@ -1,13 +1,19 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"log"
+	"net"
+	"os"
+	"time"
 
 	_ "github.com/lib/pq"
 	"github.com/sssaang/simplebank/api"
 	db "github.com/sssaang/simplebank/db/sqlc"
 	"github.com/sssaang/simplebank/db/util"
+	"github.com/sssaang/simplebank/gapi"
+	"github.com/sssaang/simplebank/mail"
 )
 
 func main() {
@@ -21,8 +27,28 @@ func main() {
 		log.Fatal("cannot connect to db", err)
 	}
 
+	mailer := mail.NewLogMailer(os.Stdout)
+	if config.MailFilePath != "" {
+		mailer, err = mail.NewFileMailer(config.MailFilePath)
+		if err != nil {
+			log.Fatal("cannot create mailer", err)
+		}
+	}
+
 	store := db.NewStore(conn)
-	server, err := api.NewServer(config, store)
+
+	err = db.LoadCurrencies(context.Background(), store, util.Currencies)
+	if err != nil {
+		log.Fatal("cannot load currencies", err)
+	}
+	go refreshCurrencies(store, config.CurrencyRefreshInterval)
+	go expireHolds(store, config.HoldExpiryInterval)
+	go expirePaymentRequests(store, config.PaymentRequestExpiryInterval)
+	go payInterest(store, config.InterestJobInterval)
+
+	go runGrpcServer(config, store, mailer)
+
+	server, err := api.NewServer(config, store, mailer)
 	if err != nil {
 		log.Fatal("cannot instantiate server", err)
 	}
@@ -31,4 +57,94 @@ func main() {
 	if err != nil {
 		log.Fatal("cannot start server", err)
 	}
-}  
\ No newline at end of file
+}
+
+// refreshCurrencies reloads the currency registry periodically,
+// so that the changes made by an admin on another instance are picked up
+func refreshCurrencies(store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	for range time.Tick(interval) {
+		err := db.LoadCurrencies(context.Background(), store, util.Currencies)
+		if err != nil {
+			log.Printf("cannot refresh currencies: %v", err)
+		}
+	}
+}
+
+// expireHolds marks the holds past their expiry periodically, they stop reserving funds at expiry regardless,
+// so the interval only bounds how long their status lags behind
+func expireHolds(store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	for range time.Tick(interval) {
+		expired, err := store.ExpireHolds(context.Background())
+		if err != nil {
+			log.Printf("cannot expire holds: %v", err)
+			continue
+		}
+		if expired > 0 {
+			log.Printf("expired %d holds", expired)
+		}
+	}
+}
+
+// expirePaymentRequests marks the payment requests past their expiry periodically, they cannot be accepted
+// from expiry regardless, so the interval only bounds how long they linger in the history as pending
+func expirePaymentRequests(store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	for range time.Tick(interval) {
+		expired, err := store.ExpirePaymentRequests(context.Background())
+		if err != nil {
+			log.Printf("cannot expire payment requests: %v", err)
+			continue
+		}
+		if expired > 0 {
+			log.Printf("expired %d payment requests", expired)
+		}
+	}
+}
+
+// payInterest accrues the interest of the days which ended and posts the interest of the month which ended,
+// every run after the first of a day finds nothing left to do
+func payInterest(store db.Store, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	for range time.Tick(interval) {
+		result, err := db.PayInterest(context.Background(), store, time.Now())
+		if err != nil {
+			log.Printf("cannot pay interest: %v", err)
+		}
+		if result.Days > 0 || result.Postings > 0 {
+			log.Printf("accrued %d days of interest, paid interest to %d accounts", result.Days, result.Postings)
+		}
+	}
+}
+
+// runGrpcServer serves the gRPC API on its own address next to the HTTP server
+func runGrpcServer(config util.Config, store db.Store, mailer mail.Mailer) {
+	server, err := gapi.NewServer(config, store, mailer)
+	if err != nil {
+		log.Fatal("cannot instantiate grpc server", err)
+	}
+
+	listener, err := net.Listen("tcp", config.GrpcAddress)
+	if err != nil {
+		log.Fatal("cannot listen on grpc address", err)
+	}
+
+	log.Printf("start gRPC server at %s", listener.Addr().String())
+	err = server.NewGRPCServer().Serve(listener)
+	if err != nil {
+		log.Fatal("cannot start grpc server", err)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,116 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+// CoolingOffError tells that a transfer is too large for a recipient the user has not paid before
+type CoolingOffError struct {
+	Amount int64
+	Currency string
+	// Until is when the payee of the recipient leaves its cooling-off period, zero when the recipient is no payee
+	Until time.Time
+	Period time.Duration
+}
+
+func (e *CoolingOffError) Error() string {
+	amount := util.NewMoney(e.Amount, e.Currency)
+	if e.Until.IsZero() {
+		return fmt.Sprintf("a first transfer of %s %s or more to an account is allowed once it has been a payee for %s",
+			amount, e.Currency, e.Period)
+	}
+	return fmt.Sprintf("transfers of %s %s or more to the payee are allowed from %s",
+		amount, e.Currency, e.Until.UTC().Format(time.RFC3339))
+}
+
+// CoolingOff holds back the large transfers of a user to the accounts it has never paid, until the account has been
+// one of its payees for the cooling-off period, so that whoever takes over a login cannot empty the accounts of the
+// user to their own at once, whichever way they name the recipient
+type CoolingOff struct {
+	store db.Querier
+	period time.Duration
+	amounts map[string]int64
+}
+
+func NewCoolingOff(store db.Querier, period time.Duration, amounts string) (*CoolingOff, error) {
+	parsed, err := ParseCoolingOffAmounts(amounts)
+	if err != nil {
+		return nil, err
+	}
+	return &CoolingOff{store: store, period: period, amounts: parsed}, nil
+}
+
+// ParseCoolingOffAmounts reads the amounts from which a transfer to a new recipient waits for the cooling-off period,
+// formatted as currency:amount pairs such as "USD:1000,KRW:1000000". A currency without amount has no cooling-off.
+func ParseCoolingOffAmounts(value string) (map[string]int64, error) {
+	amounts := make(map[string]int64)
+
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid cooling-off amount %q: must be formatted as currency:amount", entry)
+		}
+
+		amount, err := util.ParseMoney(fields[1], fields[0])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cooling-off amount %q: %w", entry, err)
+		}
+		amounts[fields[0]] = amount.Amount()
+	}
+
+	return amounts, nil
+}
+
+// Until is the end of the cooling-off period of the payee
+func (coolingOff *CoolingOff) Until(payee db.Payee) time.Time {
+	return payee.CreatedAt.Add(coolingOff.period)
+}
+
+// Check returns a CoolingOffError when the user may not transfer the amount to the account yet.
+// The accounts of the user and the accounts it has paid before are never held back.
+func (coolingOff *CoolingOff) Check(ctx context.Context, username string, toAccount db.Account, amount int64) error {
+	maxAmount, ok := coolingOff.amounts[toAccount.Currency]
+	if !ok || amount < maxAmount || toAccount.Owner == username {
+		return nil
+	}
+
+	known, err := coolingOff.store.HasTransferredTo(ctx, db.HasTransferredToParams{
+		Owner: username,
+		ToAccountID: toAccount.ID,
+	})
+	if err != nil || known {
+		return err
+	}
+
+	coolingOffErr := &CoolingOffError{Amount: maxAmount, Currency: toAccount.Currency, Period: coolingOff.period}
+
+	payee, err := coolingOff.store.GetPayeeByAccount(ctx, db.GetPayeeByAccountParams{
+		Owner: username,
+		AccountID: toAccount.ID,
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return coolingOffErr
+	}
+	if err != nil {
+		return err
+	}
+
+	coolingOffErr.Until = coolingOff.Until(payee)
+	if !time.Now().Before(coolingOffErr.Until) {
+		return nil
+	}
+	return coolingOffErr
+}
@@ -0,0 +1,152 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCoolingOffAmounts(t *testing.T) {
+	amounts, err := ParseCoolingOffAmounts("USD:1000, KRW:1000000,")
+	require.NoError(t, err)
+	require.Equal(t, map[string]int64{util.USD: 100000, util.KRW: 1000000}, amounts)
+
+	for _, value := range []string{"USD", "USD:abc", "XYZ:10", "KRW:10.5"} {
+		_, err := ParseCoolingOffAmounts(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestCoolingOffCheck(t *testing.T) {
+	username := util.RandomOwner()
+	toAccount := db.Account{ID: util.RandomInt(1, 1000), Owner: util.RandomOwner(), Currency: util.USD}
+	payee := db.Payee{ID: 1, Owner: username, AccountID: toAccount.ID, Currency: util.USD}
+
+	testCases := []struct {
+		name string
+		toAccount db.Account
+		amount int64
+		buildStubs func(store *testdb.MockStore)
+		checkResult func(t *testing.T, err error)
+	}{
+		{
+			name: "Small Amount",
+			toAccount: toAccount,
+			amount: 99999,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Own Account",
+			toAccount: db.Account{ID: toAccount.ID, Owner: username, Currency: util.USD},
+			amount: 100000,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Paid Before",
+			toAccount: toAccount,
+			amount: 100000,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Eq(db.HasTransferredToParams{Owner: username, ToAccountID: toAccount.ID})).
+				Times(1).
+				Return(true, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "No Payee",
+			toAccount: toAccount,
+			amount: 100000,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+				store.EXPECT().
+				GetPayeeByAccount(gomock.Any(), gomock.Eq(db.GetPayeeByAccountParams{Owner: username, AccountID: toAccount.ID})).
+				Times(1).
+				Return(db.Payee{}, sql.ErrNoRows)
+			},
+			checkResult: func(t *testing.T, err error) {
+				var coolingOffErr *CoolingOffError
+				require.ErrorAs(t, err, &coolingOffErr)
+				require.True(t, coolingOffErr.Until.IsZero())
+				require.Contains(t, err.Error(), "once it has been a payee for 1h0m0s")
+			},
+		},
+		{
+			name: "New Payee",
+			toAccount: toAccount,
+			amount: 100000,
+			buildStubs: func(store *testdb.MockStore) {
+				newPayee := payee
+				newPayee.CreatedAt = time.Now()
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(1).Return(newPayee, nil)
+			},
+			checkResult: func(t *testing.T, err error) {
+				var coolingOffErr *CoolingOffError
+				require.ErrorAs(t, err, &coolingOffErr)
+				require.WithinDuration(t, time.Now().Add(time.Hour), coolingOffErr.Until, time.Minute)
+			},
+		},
+		{
+			name: "Payee Cooled Off",
+			toAccount: toAccount,
+			amount: 100000,
+			buildStubs: func(store *testdb.MockStore) {
+				oldPayee := payee
+				oldPayee.CreatedAt = time.Now().Add(-2 * time.Hour)
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+				store.EXPECT().GetPayeeByAccount(gomock.Any(), gomock.Any()).Times(1).Return(oldPayee, nil)
+			},
+			checkResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+		{
+			name: "Currency Without Amount",
+			toAccount: db.Account{ID: toAccount.ID, Owner: toAccount.Owner, Currency: util.EUR},
+			amount: 100000000,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkResult: func(t *testing.T, err error) {
+				require.NoError(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			coolingOff, err := NewCoolingOff(store, time.Hour, "USD:1000")
+			require.NoError(t, err)
+
+			err = coolingOff.Check(context.Background(), username, tc.toAccount, tc.amount)
+			tc.checkResult(t, err)
+		})
+	}
+}
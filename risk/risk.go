@@ -0,0 +1,172 @@
+// Package risk screens transfers against the fraud rules before they are made.
+// The rules are read from the database on every screening, so the changes staff make through the admin API
+// apply to the next transfer without a redeploy.
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+)
+
+// the outcomes of a screening, review and deny are also the actions of the rules
+const (
+	OUTCOME_ALLOW = "allow"
+	OUTCOME_REVIEW = "review"
+	OUTCOME_DENY = "deny"
+)
+
+const (
+	// RULE_AMOUNT matches every transfer from the amount of the rule
+	RULE_AMOUNT = "amount"
+	// RULE_ACCOUNT_VELOCITY matches once the account made max_count transfers within the window
+	RULE_ACCOUNT_VELOCITY = "account_velocity"
+	// RULE_USER_VELOCITY matches once the user made max_count transfers within the window, from any account it spends from
+	RULE_USER_VELOCITY = "user_velocity"
+	// RULE_NEW_RECIPIENT matches the first transfer of the user to an account of someone else
+	RULE_NEW_RECIPIENT = "new_recipient"
+	// RULE_REPEATED_TRANSFER matches once max_count identical transfers were made within the window
+	RULE_REPEATED_TRANSFER = "repeated_transfer"
+)
+
+var RULE_KINDS = []string{RULE_AMOUNT, RULE_ACCOUNT_VELOCITY, RULE_USER_VELOCITY, RULE_NEW_RECIPIENT, RULE_REPEATED_TRANSFER}
+
+// HasWindow tells whether the rules of the kind count transfers, which requires max_count and window_seconds
+func HasWindow(kind string) bool {
+	return kind == RULE_ACCOUNT_VELOCITY || kind == RULE_USER_VELOCITY || kind == RULE_REPEATED_TRANSFER
+}
+
+// Transfer is a transfer about to be made, its accounts are already checked to exist and to share the currency
+type Transfer struct {
+	FromAccount db.Account
+	ToAccount db.Account
+	Amount int64
+	// RequestedBy is the user making the transfer, the holder of the source account or one of its members
+	RequestedBy string
+	// Batched are the transfers of the same batch screened before this one, they count as made already
+	Batched []Transfer
+}
+
+// countBatched counts the earlier transfers of the batch which match
+func (transfer Transfer) countBatched(match func(batched Transfer) bool) int64 {
+	count := int64(0)
+	for _, batched := range transfer.Batched {
+		if match(batched) {
+			count++
+		}
+	}
+	return count
+}
+
+type Assessment struct {
+	Outcome string
+	// Rules are the rules the transfer matched, in the order of their ids
+	Rules []db.FraudRule
+}
+
+// Reasons names the matched rules, for the staff reviewing the transfer
+func (assessment Assessment) Reasons() []string {
+	reasons := make([]string, len(assessment.Rules))
+	for i, rule := range assessment.Rules {
+		reasons[i] = rule.Name
+	}
+	return reasons
+}
+
+type Engine struct {
+	store db.Querier
+}
+
+func NewEngine(store db.Querier) *Engine {
+	return &Engine{store: store}
+}
+
+// Screen evaluates every enabled rule, the outcome is the strictest action of the matched rules
+func (engine *Engine) Screen(ctx context.Context, transfer Transfer) (Assessment, error) {
+	assessment := Assessment{Outcome: OUTCOME_ALLOW}
+
+	rules, err := engine.store.ListEnabledFraudRules(ctx)
+	if err != nil {
+		return assessment, err
+	}
+
+	for _, rule := range rules {
+		matched, err := engine.matches(ctx, rule, transfer)
+		if err != nil {
+			return assessment, fmt.Errorf("cannot evaluate fraud rule %d: %w", rule.ID, err)
+		}
+		if !matched {
+			continue
+		}
+
+		assessment.Rules = append(assessment.Rules, rule)
+		if rule.Action == OUTCOME_DENY || assessment.Outcome == OUTCOME_ALLOW {
+			assessment.Outcome = rule.Action
+		}
+	}
+
+	return assessment, nil
+}
+
+func (engine *Engine) matches(ctx context.Context, rule db.FraudRule, transfer Transfer) (bool, error) {
+	// an amount restricts any kind of rule to the large transfers of its currency
+	if rule.Currency.Valid {
+		if rule.Currency.String != transfer.FromAccount.Currency || transfer.Amount < rule.MinAmount.Int64 {
+			return false, nil
+		}
+	}
+
+	since := time.Now().Add(-time.Duration(rule.WindowSeconds.Int32) * time.Second)
+
+	switch rule.Kind {
+	case RULE_AMOUNT:
+		return true, nil
+
+	case RULE_ACCOUNT_VELOCITY:
+		count, err := engine.store.CountTransfersFromAccountSince(ctx, db.CountTransfersFromAccountSinceParams{
+			FromAccountID: transfer.FromAccount.ID,
+			CreatedAt: since,
+		})
+		count += transfer.countBatched(func(batched Transfer) bool {
+			return batched.FromAccount.ID == transfer.FromAccount.ID
+		})
+		return count >= int64(rule.MaxCount.Int32), err
+
+	case RULE_USER_VELOCITY:
+		count, err := engine.store.CountTransfersRequestedBySince(ctx, db.CountTransfersRequestedBySinceParams{
+			RequestedBy: sql.NullString{String: transfer.RequestedBy, Valid: true},
+			CreatedAt: since,
+		})
+		count += transfer.countBatched(func(batched Transfer) bool {
+			return batched.RequestedBy == transfer.RequestedBy
+		})
+		return count >= int64(rule.MaxCount.Int32), err
+
+	case RULE_NEW_RECIPIENT:
+		if transfer.ToAccount.Owner == transfer.FromAccount.Owner {
+			return false, nil
+		}
+		known, err := engine.store.HasTransferredTo(ctx, db.HasTransferredToParams{
+			Owner: transfer.FromAccount.Owner,
+			ToAccountID: transfer.ToAccount.ID,
+		})
+		return !known, err
+
+	case RULE_REPEATED_TRANSFER:
+		count, err := engine.store.CountRepeatedTransfers(ctx, db.CountRepeatedTransfersParams{
+			FromAccountID: transfer.FromAccount.ID,
+			ToAccountID: transfer.ToAccount.ID,
+			Amount: transfer.Amount,
+			CreatedAt: since,
+		})
+		count += transfer.countBatched(func(batched Transfer) bool {
+			return batched.FromAccount.ID == transfer.FromAccount.ID && batched.ToAccount.ID == transfer.ToAccount.ID && batched.Amount == transfer.Amount
+		})
+		return count >= int64(rule.MaxCount.Int32), err
+	}
+
+	return false, fmt.Errorf("unknown kind %s", rule.Kind)
+}
@@ -0,0 +1,215 @@
+package risk
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	db "github.com/sssaang/simplebank/db/sqlc"
+	testdb "github.com/sssaang/simplebank/db/test"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomTransfer() Transfer {
+	return Transfer{
+		FromAccount: db.Account{ID: util.RandomInt(1, 1000), Owner: util.RandomOwner(), Currency: util.USD},
+		ToAccount: db.Account{ID: util.RandomInt(1001, 2000), Owner: util.RandomOwner(), Currency: util.USD},
+		Amount: 1000,
+		// a member of the source account rather than its holder
+		RequestedBy: util.RandomOwner(),
+	}
+}
+
+func window(maxCount int32, seconds int32) (sql.NullInt32, sql.NullInt32) {
+	return sql.NullInt32{Int32: maxCount, Valid: true}, sql.NullInt32{Int32: seconds, Valid: true}
+}
+
+func TestScreen(t *testing.T) {
+	transfer := randomTransfer()
+	maxCount, windowSeconds := window(3, 3600)
+
+	largeUSD := db.FraudRule{
+		ID: 1,
+		Name: "large usd",
+		Kind: RULE_AMOUNT,
+		Action: OUTCOME_REVIEW,
+		Currency: sql.NullString{String: util.USD, Valid: true},
+		MinAmount: sql.NullInt64{Int64: 1000, Valid: true},
+	}
+	largeEUR := largeUSD
+	largeEUR.ID = 2
+	largeEUR.Currency.String = util.EUR
+	busyAccount := db.FraudRule{ID: 3, Name: "busy account", Kind: RULE_ACCOUNT_VELOCITY, Action: OUTCOME_DENY, MaxCount: maxCount, WindowSeconds: windowSeconds}
+	busyUser := db.FraudRule{ID: 4, Name: "busy user", Kind: RULE_USER_VELOCITY, Action: OUTCOME_REVIEW, MaxCount: maxCount, WindowSeconds: windowSeconds}
+	newRecipient := db.FraudRule{ID: 5, Name: "new recipient", Kind: RULE_NEW_RECIPIENT, Action: OUTCOME_REVIEW}
+	repeated := db.FraudRule{ID: 6, Name: "repeated", Kind: RULE_REPEATED_TRANSFER, Action: OUTCOME_DENY, MaxCount: maxCount, WindowSeconds: windowSeconds}
+
+	testCases := []struct {
+		name string
+		transfer Transfer
+		buildStubs func(store *testdb.MockStore)
+		checkAssessment func(t *testing.T, assessment Assessment, err error)
+	}{
+		{
+			name: "No Rules",
+			transfer: transfer,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{}, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_ALLOW, assessment.Outcome)
+				require.Empty(t, assessment.Rules)
+			},
+		},
+		{
+			name: "Amount Of The Currency",
+			transfer: transfer,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{largeUSD, largeEUR}, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_REVIEW, assessment.Outcome)
+				require.Equal(t, []string{largeUSD.Name}, assessment.Reasons())
+			},
+		},
+		{
+			name: "Below The Amount",
+			transfer: Transfer{FromAccount: transfer.FromAccount, ToAccount: transfer.ToAccount, Amount: 999},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{largeUSD}, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_ALLOW, assessment.Outcome)
+			},
+		},
+		{
+			name: "Velocity",
+			transfer: transfer,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{busyAccount, busyUser}, nil)
+				store.EXPECT().
+				CountTransfersFromAccountSince(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CountTransfersFromAccountSinceParams) (int64, error) {
+					require.Equal(t, transfer.FromAccount.ID, arg.FromAccountID)
+					return 2, nil
+				})
+				store.EXPECT().
+				CountTransfersRequestedBySince(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CountTransfersRequestedBySinceParams) (int64, error) {
+					require.Equal(t, transfer.RequestedBy, arg.RequestedBy.String)
+					return 3, nil
+				})
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_REVIEW, assessment.Outcome)
+				require.Equal(t, []string{busyUser.Name}, assessment.Reasons())
+			},
+		},
+		{
+			name: "Velocity Within A Batch",
+			transfer: Transfer{
+				FromAccount: transfer.FromAccount,
+				ToAccount: transfer.ToAccount,
+				Amount: transfer.Amount,
+				RequestedBy: transfer.RequestedBy,
+				// the earlier items of the batch are not transfers yet
+				Batched: []Transfer{transfer, transfer, transfer},
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{busyAccount, busyUser, repeated}, nil)
+				store.EXPECT().CountTransfersFromAccountSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CountTransfersRequestedBySince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CountRepeatedTransfers(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_DENY, assessment.Outcome)
+				require.Equal(t, []string{busyAccount.Name, busyUser.Name, repeated.Name}, assessment.Reasons())
+			},
+		},
+		{
+			name: "Deny Wins Over Review",
+			transfer: transfer,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{largeUSD, repeated, newRecipient}, nil)
+				store.EXPECT().
+				CountRepeatedTransfers(gomock.Any(), gomock.Any()).
+				Times(1).
+				DoAndReturn(func(_ context.Context, arg db.CountRepeatedTransfersParams) (int64, error) {
+					require.Equal(t, transfer.ToAccount.ID, arg.ToAccountID)
+					require.Equal(t, transfer.Amount, arg.Amount)
+					return 5, nil
+				})
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(1).Return(false, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_DENY, assessment.Outcome)
+				require.Equal(t, []string{largeUSD.Name, repeated.Name, newRecipient.Name}, assessment.Reasons())
+			},
+		},
+		{
+			name: "Known Recipient",
+			transfer: transfer,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{newRecipient}, nil)
+				store.EXPECT().
+				HasTransferredTo(gomock.Any(), gomock.Eq(db.HasTransferredToParams{Owner: transfer.FromAccount.Owner, ToAccountID: transfer.ToAccount.ID})).
+				Times(1).
+				Return(true, nil)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_ALLOW, assessment.Outcome)
+			},
+		},
+		{
+			name: "Own Account",
+			transfer: Transfer{
+				FromAccount: transfer.FromAccount,
+				ToAccount: db.Account{ID: transfer.ToAccount.ID, Owner: transfer.FromAccount.Owner, Currency: util.USD},
+				Amount: transfer.Amount,
+			},
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{newRecipient}, nil)
+				store.EXPECT().HasTransferredTo(gomock.Any(), gomock.Any()).Times(0)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.NoError(t, err)
+				require.Equal(t, OUTCOME_ALLOW, assessment.Outcome)
+			},
+		},
+		{
+			name: "Store Error",
+			transfer: transfer,
+			buildStubs: func(store *testdb.MockStore) {
+				store.EXPECT().ListEnabledFraudRules(gomock.Any()).Times(1).Return([]db.FraudRule{busyAccount}, nil)
+				store.EXPECT().CountTransfersFromAccountSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), sql.ErrConnDone)
+			},
+			checkAssessment: func(t *testing.T, assessment Assessment, err error) {
+				require.ErrorIs(t, err, sql.ErrConnDone)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := testdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			assessment, err := NewEngine(store).Screen(context.Background(), tc.transfer)
+			tc.checkAssessment(t, assessment, err)
+		})
+	}
+}
@@ -0,0 +1,60 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+var csvHeader = []string{"date", "entry_id", "description", "counterparty_account_id", "amount", "balance", "currency", "reference"}
+
+// WriteCSV writes a row per entry, between a row for the opening balance and a row for the closing balance
+func WriteCSV(w io.Writer, statement db.AccountStatement) error {
+	currency := statement.Account.Currency
+	writer := csv.NewWriter(w)
+
+	rows := [][]string{
+		csvHeader,
+		{
+			statement.PeriodStart.UTC().Format(timeLayout), "", "opening balance", "", "",
+			util.NewMoney(statement.OpeningBalance, currency).String(), currency, "",
+		},
+	}
+
+	for _, line := range statement.Lines {
+		counterpartyAccountID := ""
+		if line.CounterpartyAccountID != 0 {
+			counterpartyAccountID = strconv.FormatInt(line.CounterpartyAccountID, 10)
+		}
+
+		rows = append(rows, []string{
+			line.CreatedAt.UTC().Format(timeLayout),
+			strconv.FormatInt(line.EntryID, 10),
+			csvText(Describe(line)),
+			counterpartyAccountID,
+			util.NewMoney(line.Amount, currency).String(),
+			util.NewMoney(line.Balance, currency).String(),
+			currency,
+			csvText(line.Reference),
+		})
+	}
+
+	rows = append(rows, []string{
+		statement.PeriodEnd.UTC().Format(timeLayout), "", "closing balance", "", "",
+		util.NewMoney(statement.ClosingBalance, currency).String(), currency, "",
+	})
+
+	return writer.WriteAll(rows)
+}
+
+// csvText keeps free text chosen by other users from being read as a formula by a spreadsheet, by quoting a leading formula character
+func csvText(text string) string {
+	if len(text) > 0 && strings.ContainsRune("=+-@\t\r", rune(text[0])) {
+		return "'" + text
+	}
+	return text
+}
@@ -0,0 +1,144 @@
+package statement
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+)
+
+// A4 pages of monospaced text, which is all a statement needs, so no PDF library is required
+const (
+	pdfPageWidth = 595
+	pdfPageHeight = 842
+	pdfMargin = 50
+	pdfFontSize = 8
+	pdfLeading = 11
+	pdfLinesPerPage = (pdfPageHeight - 2*pdfMargin) / pdfLeading
+)
+
+// WritePDF lays the statement out as a table, page after page
+func WritePDF(w io.Writer, statement db.AccountStatement) error {
+	currency := statement.Account.Currency
+	money := func(amount int64) string {
+		return util.NewMoney(amount, currency).String()
+	}
+
+	lines := []string{
+		fmt.Sprintf("Statement of account %d (%s)", statement.Account.ID, currency),
+		fmt.Sprintf("Owner: %s", statement.Account.Owner),
+		fmt.Sprintf("Period: %s to %s",
+			statement.PeriodStart.UTC().Format(DATE_LAYOUT),
+			LastDay(statement).UTC().Format(DATE_LAYOUT),
+		),
+		fmt.Sprintf("Generated: %s UTC", statement.GeneratedAt.UTC().Format(timeLayout)),
+		"",
+		fmt.Sprintf("Opening balance: %s %s", money(statement.OpeningBalance), currency),
+		"",
+		fmt.Sprintf("%-19s  %-40s  %14s  %14s", "Date", "Description", "Amount", "Balance"),
+		strings.Repeat("-", 91),
+	}
+
+	for _, line := range statement.Lines {
+		lines = append(lines, fmt.Sprintf("%-19s  %-40s  %14s  %14s",
+			line.CreatedAt.UTC().Format(timeLayout),
+			truncate(Describe(line), 40),
+			money(line.Amount),
+			money(line.Balance),
+		))
+	}
+
+	lines = append(lines,
+		strings.Repeat("-", 91),
+		fmt.Sprintf("Closing balance: %s %s", money(statement.ClosingBalance), currency),
+	)
+
+	_, err := w.Write(renderPDF(lines))
+	return err
+}
+
+func truncate(value string, length int) string {
+	runes := []rune(value)
+	if len(runes) <= length {
+		return value
+	}
+	return string(runes[:length-3]) + "..."
+}
+
+// renderPDF writes a PDF 1.4 document with the lines in the Courier standard font.
+// Objects 1 to 3 are the catalog, the page tree and the font, then every page is followed by its content stream.
+func renderPDF(lines []string) []byte {
+	var pages [][]string
+	for len(lines) > pdfLinesPerPage {
+		pages = append(pages, lines[:pdfLinesPerPage])
+		lines = lines[pdfLinesPerPage:]
+	}
+	pages = append(pages, lines)
+
+	var objects []string
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 4+2*i)
+	}
+
+	objects = append(objects,
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)),
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Courier /Encoding /WinAnsiEncoding >>",
+	)
+
+	for i, page := range pages {
+		var content bytes.Buffer
+		fmt.Fprintf(&content, "BT /F1 %d Tf %d TL %d %d Td\n", pdfFontSize, pdfLeading, pdfMargin, pdfPageHeight-pdfMargin)
+		for _, line := range page {
+			fmt.Fprintf(&content, "(%s) '\n", pdfString(line))
+		}
+		content.WriteString("ET")
+
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %d %d] /Resources << /Font << /F1 3 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, 5+2*i),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%s\nendstream", content.Len(), content.String()),
+		)
+	}
+
+	var document bytes.Buffer
+	document.WriteString("%PDF-1.4\n")
+
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = document.Len()
+		fmt.Fprintf(&document, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+
+	xref := document.Len()
+	fmt.Fprintf(&document, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&document, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&document, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+
+	return document.Bytes()
+}
+
+// pdfString escapes a literal string, characters outside of Latin-1 cannot be drawn with WinAnsiEncoding
+func pdfString(value string) string {
+	var escaped strings.Builder
+	for _, r := range value {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			escaped.WriteByte('\\')
+			escaped.WriteRune(r)
+		case r >= 0x20 && r < 0x7f:
+			escaped.WriteRune(r)
+		case r >= 0xa0 && r <= 0xff:
+			fmt.Fprintf(&escaped, "\\%03o", r)
+		default:
+			escaped.WriteByte('?')
+		}
+	}
+	return escaped.String()
+}
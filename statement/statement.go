@@ -0,0 +1,71 @@
+// Package statement renders account statements for customers, as CSV for spreadsheets and as PDF for printing.
+// Rendering only depends on the statement, so a snapshot of a closed period always renders to the same document.
+package statement
+
+import (
+	"fmt"
+	"time"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+)
+
+const (
+	FORMAT_CSV = "csv"
+	FORMAT_PDF = "pdf"
+)
+
+// DATE_LAYOUT is how the periods of statements are written, and read from the requests
+const DATE_LAYOUT = "2006-01-02"
+
+const timeLayout = "2006-01-02 15:04:05"
+
+// LastDay is the last day included in the statement, the period end is exclusive
+func LastDay(statement db.AccountStatement) time.Time {
+	return statement.PeriodEnd.AddDate(0, 0, -1)
+}
+
+// Filename names the document of the statement for downloads
+func Filename(statement db.AccountStatement, format string) string {
+	return fmt.Sprintf("statement-%d-%s-%s.%s",
+		statement.Account.ID,
+		statement.PeriodStart.UTC().Format(DATE_LAYOUT),
+		LastDay(statement).UTC().Format(DATE_LAYOUT),
+		format,
+	)
+}
+
+// Describe tells what an entry was posted for, with its counterparty and the description the sender gave the transfer
+func Describe(line db.StatementLine) string {
+	description := describeEntry(line)
+	if len(line.Description) > 0 {
+		return fmt.Sprintf("%s: %s", description, line.Description)
+	}
+	return description
+}
+
+func describeEntry(line db.StatementLine) string {
+	switch {
+	case line.FeeTransferID != 0:
+		return fmt.Sprintf("fee for transfer %d", line.FeeTransferID)
+	case line.TransferID != 0 && line.Amount < 0:
+		return fmt.Sprintf("transfer to %s", counterparty(line))
+	case line.TransferID != 0:
+		return fmt.Sprintf("transfer from %s", counterparty(line))
+	case line.ConversionID != 0 && line.Amount < 0:
+		return fmt.Sprintf("conversion to %s", line.CounterpartyCurrency)
+	case line.ConversionID != 0:
+		return fmt.Sprintf("conversion from %s", line.CounterpartyCurrency)
+	}
+	return "entry"
+}
+
+// counterparty names the other account of a transfer, only by its holder when the server resolved it for the sender
+func counterparty(line db.StatementLine) string {
+	if line.CounterpartyAccountID == 0 {
+		return line.CounterpartyName
+	}
+	if len(line.CounterpartyName) == 0 {
+		return fmt.Sprintf("account %d", line.CounterpartyAccountID)
+	}
+	return fmt.Sprintf("account %d (%s)", line.CounterpartyAccountID, line.CounterpartyName)
+}
@@ -0,0 +1,180 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+	"time"
+
+	db "github.com/sssaang/simplebank/db/sqlc"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func testStatement(lines int) db.AccountStatement {
+	start := time.Date(2026, time.September, 1, 0, 0, 0, 0, time.UTC)
+	statement := db.AccountStatement{
+		Account: db.Account{ID: 12, Owner: "owner@example.com", Currency: util.USD},
+		PeriodStart: start,
+		PeriodEnd: start.AddDate(0, 1, 0),
+		OpeningBalance: 1000,
+		GeneratedAt: start.AddDate(0, 1, 1),
+	}
+
+	balance := statement.OpeningBalance
+	for i := 0; i < lines; i++ {
+		amount := int64(250)
+		line := db.StatementLine{
+			EntryID: int64(i + 1),
+			TransferID: int64(i + 1),
+			CounterpartyAccountID: 34,
+			CounterpartyName: "Jane (Doe)",
+			CreatedAt: start.Add(time.Duration(i) * time.Hour),
+		}
+		if i%2 == 1 {
+			amount = -100
+			line.Description = "rent"
+			line.Reference = fmt.Sprintf("INV-%d", i)
+		}
+
+		balance += amount
+		line.Amount = amount
+		line.Balance = balance
+		statement.Lines = append(statement.Lines, line)
+	}
+	statement.ClosingBalance = balance
+
+	return statement
+}
+
+func TestDescribe(t *testing.T) {
+	require.Equal(t, "transfer to account 34 (Jane)", Describe(db.StatementLine{TransferID: 1, Amount: -1, CounterpartyAccountID: 34, CounterpartyName: "Jane"}))
+	require.Equal(t, "transfer from account 34", Describe(db.StatementLine{TransferID: 1, Amount: 1, CounterpartyAccountID: 34}))
+	require.Equal(t, "transfer to Jane", Describe(db.StatementLine{TransferID: 1, Amount: -1, CounterpartyName: "Jane"}))
+	require.Equal(t, "transfer from account 34: invoice 7", Describe(db.StatementLine{TransferID: 1, Amount: 1, CounterpartyAccountID: 34, Description: "invoice 7"}))
+	require.Equal(t, "conversion to EUR", Describe(db.StatementLine{ConversionID: 1, Amount: -1, CounterpartyCurrency: util.EUR}))
+	require.Equal(t, "conversion from KRW", Describe(db.StatementLine{ConversionID: 1, Amount: 1, CounterpartyCurrency: util.KRW}))
+	require.Equal(t, "fee for transfer 7", Describe(db.StatementLine{FeeTransferID: 7, Amount: -1}))
+	require.Equal(t, "entry", Describe(db.StatementLine{Amount: 1}))
+}
+
+func TestFilename(t *testing.T) {
+	require.Equal(t, "statement-12-2026-09-01-2026-09-30.pdf", Filename(testStatement(0), FORMAT_PDF))
+}
+
+func TestWriteCSV(t *testing.T) {
+	statement := testStatement(2)
+
+	var buffer bytes.Buffer
+	err := WriteCSV(&buffer, statement)
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, [][]string{
+		csvHeader,
+		{"2026-09-01 00:00:00", "", "opening balance", "", "", "10.00", "USD", ""},
+		{"2026-09-01 00:00:00", "1", "transfer from account 34 (Jane (Doe))", "34", "2.50", "12.50", "USD", ""},
+		{"2026-09-01 01:00:00", "2", "transfer to account 34 (Jane (Doe)): rent", "34", "-1.00", "11.50", "USD", "INV-1"},
+		{"2026-10-01 00:00:00", "", "closing balance", "", "", "11.50", "USD", ""},
+	}, rows)
+}
+
+func TestWriteCSVHiddenRecipient(t *testing.T) {
+	statement := testStatement(2)
+	// the server resolved the recipient of the transfer for the sender
+	statement.Lines[1].CounterpartyAccountID = 0
+
+	var buffer bytes.Buffer
+	err := WriteCSV(&buffer, statement)
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, []string{"2026-09-01 01:00:00", "2", "transfer to Jane (Doe): rent", "", "-1.00", "11.50", "USD", "INV-1"}, rows[3])
+}
+
+func TestWriteCSVFormula(t *testing.T) {
+	statement := testStatement(2)
+	// the sender of a transfer picks its reference and description
+	statement.Lines[1].Reference = `=HYPERLINK("http://example.com","INV-1")`
+	statement.Lines[0].Reference = "+cmd|' /C calc'!A0"
+	statement.Lines[0].Description = "@SUM(1+1)"
+
+	var buffer bytes.Buffer
+	err := WriteCSV(&buffer, statement)
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(&buffer).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, `'=HYPERLINK("http://example.com","INV-1")`, rows[3][7])
+	require.Equal(t, "'+cmd|' /C calc'!A0", rows[2][7])
+	require.Equal(t, "transfer from account 34 (Jane (Doe)): @SUM(1+1)", rows[2][2])
+	// amounts are numbers, their sign is kept
+	require.Equal(t, "-1.00", rows[3][4])
+
+	for _, text := range []string{"-1", "@a", "\tx", "\rx", "=1"} {
+		require.Equal(t, "'"+text, csvText(text))
+	}
+	require.Equal(t, "INV-1", csvText("INV-1"))
+	require.Empty(t, csvText(""))
+}
+
+func TestWritePDF(t *testing.T) {
+	statement := testStatement(3)
+
+	var buffer bytes.Buffer
+	err := WritePDF(&buffer, statement)
+	require.NoError(t, err)
+
+	document := buffer.String()
+	require.Regexp(t, `^%PDF-1\.4\n`, document)
+	require.Regexp(t, `%%EOF\n$`, document)
+	require.Contains(t, document, `(Statement of account 12 \(USD\)) '`)
+	require.Contains(t, document, `(Closing balance: 14.00 USD) '`)
+	requireValidXref(t, document)
+
+	// the same statement always renders to the same document
+	var again bytes.Buffer
+	err = WritePDF(&again, statement)
+	require.NoError(t, err)
+	require.Equal(t, buffer.Bytes(), again.Bytes())
+}
+
+func TestWritePDFPages(t *testing.T) {
+	var buffer bytes.Buffer
+	err := WritePDF(&buffer, testStatement(3*pdfLinesPerPage))
+	require.NoError(t, err)
+
+	document := buffer.String()
+	require.Contains(t, document, "/Count 4")
+	requireValidXref(t, document)
+}
+
+func TestPDFString(t *testing.T) {
+	require.Equal(t, `a\(b\)\\c`, pdfString(`a(b)\c`))
+	require.Equal(t, `Jos\351`, pdfString("José"))
+	require.Equal(t, `?`, pdfString("한"))
+}
+
+// requireValidXref checks that the cross-reference table points at every object
+func requireValidXref(t *testing.T, document string) {
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindStringSubmatch(document)
+	require.NotNil(t, startxref)
+
+	xref, err := strconv.Atoi(startxref[1])
+	require.NoError(t, err)
+	require.True(t, len(document) > xref)
+	require.Regexp(t, `^xref\n`, document[xref:])
+
+	offsets := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllStringSubmatch(document[xref:], -1)
+	require.NotEmpty(t, offsets)
+	for i, offset := range offsets {
+		at, err := strconv.Atoi(offset[1])
+		require.NoError(t, err)
+		require.Regexp(t, fmt.Sprintf(`^%d 0 obj\n`, i+1), document[at:])
+	}
+}
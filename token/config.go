@@ -0,0 +1,29 @@
+package token
+
+import (
+	"fmt"
+
+	"github.com/sssaang/simplebank/db/util"
+)
+
+// NewTokenManagerFromConfig signs tokens with the Ed25519 keyring when a signing key is configured
+// and falls back to the shared PASETO symmetric key otherwise
+func NewTokenManagerFromConfig(config util.Config) (TokenManager, error) {
+	if len(config.TokenSigningKey) == 0 {
+		return NewPasetoManager(config.PasetoSymmetricKey)
+	}
+
+	keyring, err := ParseKeyring(config.TokenSigningKeyID, config.TokenSigningKey, config.TokenVerificationKeys)
+	if err != nil {
+		return nil, err
+	}
+
+	switch config.TokenFormat {
+	case "jwt":
+		return NewJWTEdDSAManager(keyring)
+	case "", "paseto":
+		return NewPasetoPublicManager(keyring)
+	}
+
+	return nil, fmt.Errorf("unsupported token format %s", config.TokenFormat)
+}
\ No newline at end of file
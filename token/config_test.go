@@ -0,0 +1,72 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenManagerFromConfig(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+	signingKey := base64.StdEncoding.EncodeToString(seed)
+
+	testCases := []struct {
+		name string
+		config util.Config
+		checkManager func(t *testing.T, manager TokenManager, err error)
+	}{
+		{
+			name: "Symmetric Paseto",
+			config: util.Config{PasetoSymmetricKey: util.RandomString(32)},
+			checkManager: func(t *testing.T, manager TokenManager, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &PasetoManager{}, manager)
+			},
+		},
+		{
+			name: "Public Paseto",
+			config: util.Config{TokenSigningKeyID: "k1", TokenSigningKey: signingKey},
+			checkManager: func(t *testing.T, manager TokenManager, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &PasetoPublicManager{}, manager)
+			},
+		},
+		{
+			name: "EdDSA JWT",
+			config: util.Config{TokenFormat: "jwt", TokenSigningKeyID: "k1", TokenSigningKey: signingKey},
+			checkManager: func(t *testing.T, manager TokenManager, err error) {
+				require.NoError(t, err)
+				require.IsType(t, &JWTEdDSAManager{}, manager)
+			},
+		},
+		{
+			name: "Unsupported Format",
+			config: util.Config{TokenFormat: "saml", TokenSigningKeyID: "k1", TokenSigningKey: signingKey},
+			checkManager: func(t *testing.T, manager TokenManager, err error) {
+				require.Error(t, err)
+			},
+		},
+		{
+			name: "Missing Key ID",
+			config: util.Config{TokenSigningKey: signingKey},
+			checkManager: func(t *testing.T, manager TokenManager, err error) {
+				require.Error(t, err)
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.config.AccessTokenDuration = time.Minute
+			manager, err := NewTokenManagerFromConfig(tc.config)
+			tc.checkManager(t, manager, err)
+		})
+	}
+}
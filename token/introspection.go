@@ -0,0 +1,172 @@
+package token
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrIntrospectionOnly = errors.New("tokens cannot be created through introspection")
+
+// IntrospectionResponse is the body returned by the introspection endpoint, following RFC 7662.
+// The claims of the payload are only set for active tokens.
+type IntrospectionResponse struct {
+	Active bool `json:"active"`
+	*Payload
+}
+
+type introspectionCacheEntry struct {
+	payload *Payload
+	cachedUntil time.Time
+}
+
+// IntrospectionClient verifies tokens by asking the introspection endpoint of the bank,
+// so that services can validate tokens without holding any key. Answers are cached for cacheTTL,
+// but an active token is never cached past its expiry.
+type IntrospectionClient struct {
+	endpoint string
+	clientID string
+	clientSecret string
+	cacheTTL time.Duration
+	httpClient *http.Client
+
+	mu sync.Mutex
+	cache map[string]introspectionCacheEntry
+}
+
+func NewIntrospectionClient(endpoint string, clientID string, clientSecret string, cacheTTL time.Duration, httpClient *http.Client) (TokenManager, error) {
+	if len(endpoint) == 0 {
+		return nil, errors.New("introspection endpoint must not be empty")
+	}
+
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 5 * time.Second}
+	}
+
+	return &IntrospectionClient{
+		endpoint: endpoint,
+		clientID: clientID,
+		clientSecret: clientSecret,
+		cacheTTL: cacheTTL,
+		httpClient: httpClient,
+		cache: make(map[string]introspectionCacheEntry),
+	}, nil
+}
+
+func (client *IntrospectionClient) CreateToken(username string, scopes []string, duration time.Duration) (string, error) {
+	return "", ErrIntrospectionOnly
+}
+
+func (client *IntrospectionClient) VerifyToken(token string) (*Payload, error) {
+	key := cacheKey(token)
+
+	payload, cached := client.lookup(key)
+	if !cached {
+		var err error
+		payload, err = client.introspect(token)
+		if err != nil {
+			return nil, err
+		}
+		client.store(key, payload)
+	}
+
+	if payload == nil {
+		return nil, ErrInvalidToken
+	}
+
+	err := payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
+
+func (client *IntrospectionClient) introspect(token string) (*Payload, error) {
+	form := url.Values{"token": {token}}
+	request, err := http.NewRequest(http.MethodPost, client.endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(client.clientID, client.clientSecret)
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("cannot reach introspection endpoint %w", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("introspection failed with status %d", response.StatusCode)
+	}
+
+	var res IntrospectionResponse
+	err = json.NewDecoder(response.Body).Decode(&res)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode introspection response %w", err)
+	}
+
+	if !res.Active || res.Payload == nil {
+		return nil, nil
+	}
+
+	return res.Payload, nil
+}
+
+func (client *IntrospectionClient) lookup(key string) (*Payload, bool) {
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	entry, ok := client.cache[key]
+	if !ok {
+		return nil, false
+	}
+
+	if time.Now().After(entry.cachedUntil) {
+		delete(client.cache, key)
+		return nil, false
+	}
+
+	return entry.payload, true
+}
+
+func (client *IntrospectionClient) store(key string, payload *Payload) {
+	if client.cacheTTL <= 0 {
+		return
+	}
+
+	cachedUntil := time.Now().Add(client.cacheTTL)
+	if payload != nil && payload.ExpiredAt.Before(cachedUntil) {
+		cachedUntil = payload.ExpiredAt
+	}
+
+	client.mu.Lock()
+	defer client.mu.Unlock()
+
+	now := time.Now()
+	for k, entry := range client.cache {
+		if now.After(entry.cachedUntil) {
+			delete(client.cache, k)
+		}
+	}
+
+	client.cache[key] = introspectionCacheEntry{
+		payload: payload,
+		cachedUntil: cachedUntil,
+	}
+}
+
+// cacheKey avoids keeping raw bearer tokens in memory longer than needed
+func cacheKey(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
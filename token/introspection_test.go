@@ -0,0 +1,114 @@
+package token
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// newIntrospectionServer answers like the bank would, verifying tokens with the given manager
+func newIntrospectionServer(t *testing.T, manager TokenManager, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(calls, 1)
+
+		clientID, clientSecret, ok := r.BasicAuth()
+		if !ok || clientID != "service" || clientSecret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		res := IntrospectionResponse{}
+		payload, err := manager.VerifyToken(r.FormValue("token"))
+		if err == nil {
+			res.Active = true
+			res.Payload = payload
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		require.NoError(t, json.NewEncoder(w).Encode(res))
+	}))
+}
+
+func TestIntrospectionClient(t *testing.T) {
+	manager, err := NewPasetoManager(util.RandomString(chacha20poly1305.KeySize))
+	require.NoError(t, err)
+
+	var calls int32
+	server := newIntrospectionServer(t, manager, &calls)
+	defer server.Close()
+
+	client, err := NewIntrospectionClient(server.URL, "service", "secret", time.Minute, server.Client())
+	require.NoError(t, err)
+
+	username := util.RandomEmail()
+	token, err := manager.CreateToken(username, ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+
+	payload, err := client.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, username, payload.Username)
+
+	// the second verification is answered from the cache
+	payload, err = client.VerifyToken(token)
+	require.NoError(t, err)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	_, err = client.VerifyToken("invalid token")
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	_, err = client.VerifyToken("invalid token")
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Equal(t, int32(2), atomic.LoadInt32(&calls))
+
+	_, err = client.CreateToken(username, ALL_SCOPES, time.Minute)
+	require.EqualError(t, err, ErrIntrospectionOnly.Error())
+}
+
+func TestIntrospectionClientCacheExpiry(t *testing.T) {
+	manager, err := NewPasetoManager(util.RandomString(chacha20poly1305.KeySize))
+	require.NoError(t, err)
+
+	var calls int32
+	server := newIntrospectionServer(t, manager, &calls)
+	defer server.Close()
+
+	client, err := NewIntrospectionClient(server.URL, "service", "secret", time.Hour, server.Client())
+	require.NoError(t, err)
+
+	token, err := manager.CreateToken(util.RandomEmail(), ALL_SCOPES, 50*time.Millisecond)
+	require.NoError(t, err)
+
+	_, err = client.VerifyToken(token)
+	require.NoError(t, err)
+
+	// a cached token still expires at its own expiry
+	time.Sleep(100 * time.Millisecond)
+	_, err = client.VerifyToken(token)
+	require.Error(t, err)
+}
+
+func TestIntrospectionClientWrongCredentials(t *testing.T) {
+	manager, err := NewPasetoManager(util.RandomString(chacha20poly1305.KeySize))
+	require.NoError(t, err)
+
+	var calls int32
+	server := newIntrospectionServer(t, manager, &calls)
+	defer server.Close()
+
+	client, err := NewIntrospectionClient(server.URL, "service", "wrong", time.Minute, server.Client())
+	require.NoError(t, err)
+
+	token, err := manager.CreateToken(util.RandomEmail(), ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+
+	_, err = client.VerifyToken(token)
+	require.Error(t, err)
+	require.NotEqual(t, ErrInvalidToken, err)
+}
@@ -0,0 +1,112 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+)
+
+// SigningMethodEdDSA implements the EdDSA (Ed25519) JWS algorithm, which jwt-go v3 lacks
+type SigningMethodEdDSA struct{}
+
+var signingMethodEdDSA = &SigningMethodEdDSA{}
+
+func init() {
+	jwt.RegisterSigningMethod(signingMethodEdDSA.Alg(), func() jwt.SigningMethod {
+		return signingMethodEdDSA
+	})
+}
+
+func (method *SigningMethodEdDSA) Alg() string {
+	return "EdDSA"
+}
+
+func (method *SigningMethodEdDSA) Sign(signingString string, key interface{}) (string, error) {
+	privateKey, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return "", jwt.ErrInvalidKeyType
+	}
+
+	signature := ed25519.Sign(privateKey, []byte(signingString))
+	return jwt.EncodeSegment(signature), nil
+}
+
+func (method *SigningMethodEdDSA) Verify(signingString string, signature string, key interface{}) error {
+	publicKey, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return jwt.ErrInvalidKeyType
+	}
+
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+
+	if !ed25519.Verify(publicKey, []byte(signingString), sig) {
+		return jwt.ErrSignatureInvalid
+	}
+
+	return nil
+}
+
+// JWTEdDSAManager issues JWTs signed with the Ed25519 keys of a keyring, the key id is carried in the kid header
+type JWTEdDSAManager struct {
+	keyring *Keyring
+}
+
+func NewJWTEdDSAManager(keyring *Keyring) (TokenManager, error) {
+	return &JWTEdDSAManager{
+		keyring: keyring,
+	}, nil
+}
+
+func (manager *JWTEdDSAManager) CreateToken(username string, scopes []string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, scopes, duration)
+	if err != nil {
+		return "", err
+	}
+
+	keyID, signingKey := manager.keyring.SigningKey()
+	jwtToken := jwt.NewWithClaims(signingMethodEdDSA, payload)
+	jwtToken.Header["kid"] = keyID
+	return jwtToken.SignedString(signingKey)
+}
+
+func (manager *JWTEdDSAManager) VerifyToken(token string) (*Payload, error) {
+	keyFunc := func(token *jwt.Token) (interface{}, error) {
+		_, ok := token.Method.(*SigningMethodEdDSA)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		keyID, ok := token.Header["kid"].(string)
+		if !ok {
+			return nil, ErrInvalidToken
+		}
+
+		return manager.keyring.VerificationKey(keyID)
+	}
+
+	jwtToken, err := jwt.ParseWithClaims(token, &Payload{}, keyFunc)
+	if err != nil {
+		verr, ok := err.(*jwt.ValidationError)
+		if ok && errors.Is(verr.Inner, ErrExpiredToken) {
+			return nil, ErrExpiredToken
+		}
+
+		if ok && errors.Is(verr.Inner, ErrUnknownKey) {
+			return nil, ErrUnknownKey
+		}
+
+		return nil, ErrInvalidToken
+	}
+
+	payload, ok := jwtToken.Claims.(*Payload)
+	if !ok {
+		return nil, ErrInvalidToken
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,87 @@
+package token
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dgrijalva/jwt-go"
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJWTEdDSAManager(t *testing.T) {
+	manager, err := NewJWTEdDSAManager(randomKeyring(t))
+	require.NoError(t, err)
+
+	username := util.RandomEmail()
+	duration := time.Minute
+	issuedAt := time.Now()
+	expiredAt := time.Now().Add(duration)
+
+	token, err := manager.CreateToken(username, ALL_SCOPES, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	payload, err := manager.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, ALL_SCOPES, payload.Scopes)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredJWTEdDSAToken(t *testing.T) {
+	manager, err := NewJWTEdDSAManager(randomKeyring(t))
+	require.NoError(t, err)
+
+	token, err := manager.CreateToken(util.RandomEmail(), ALL_SCOPES, -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := manager.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestJWTEdDSAKeyRotation(t *testing.T) {
+	keyring := randomKeyring(t)
+	manager, err := NewJWTEdDSAManager(keyring)
+	require.NoError(t, err)
+
+	oldToken, err := manager.CreateToken(util.RandomEmail(), ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+	oldKeyID, _ := keyring.SigningKey()
+
+	err = keyring.Rotate(util.RandomString(8), randomSigningKey(t))
+	require.NoError(t, err)
+
+	_, err = manager.VerifyToken(oldToken)
+	require.NoError(t, err)
+
+	err = keyring.RemoveVerificationKey(oldKeyID)
+	require.NoError(t, err)
+	_, err = manager.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrUnknownKey.Error())
+}
+
+func TestInvalidJWTEdDSATokenHMAC(t *testing.T) {
+	keyring := randomKeyring(t)
+	keyID, _ := keyring.SigningKey()
+
+	// an HMAC token reusing a known kid must not be accepted
+	payload, err := NewPayload(util.RandomEmail(), ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+	jwtToken := jwt.NewWithClaims(jwt.SigningMethodHS256, payload)
+	jwtToken.Header["kid"] = keyID
+	token, err := jwtToken.SignedString([]byte(util.RandomString(32)))
+	require.NoError(t, err)
+
+	manager, err := NewJWTEdDSAManager(keyring)
+	require.NoError(t, err)
+
+	payload, err = manager.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
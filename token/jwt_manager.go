@@ -24,8 +24,8 @@ func NewJWTManager(secret string) (TokenManager, error) {
 	}, nil
 }
 
-func (manager *JWTManager) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (manager *JWTManager) CreateToken(username string, scopes []string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, scopes, duration)
 	if err != nil {
 		return "", err
 	}
@@ -18,7 +18,7 @@ func TestJWTManager(t *testing.T) {
 	issuedAt := time.Now()
 	expiredAt := time.Now().Add(duration)
 
-	token, err := manager.CreateToken(username, duration)
+	token, err := manager.CreateToken(username, ALL_SCOPES, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -28,6 +28,7 @@ func TestJWTManager(t *testing.T) {
 
 	require.NotZero(t, payload.ID)
 	require.Equal(t, username, payload.Username)
+	require.Equal(t, ALL_SCOPES, payload.Scopes)
 	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
 	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
 }
@@ -37,7 +38,7 @@ func TestExpiredJWTToken(t *testing.T) {
 	require.NoError(t, err)
 	
 	username := util.RandomEmail()
-	token, err := manager.CreateToken(username, -time.Minute)
+	token, err := manager.CreateToken(username, ALL_SCOPES, -time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, token)
 
@@ -48,7 +49,7 @@ func TestExpiredJWTToken(t *testing.T) {
 }
 
 func TestInvalidJWTTokenAlgNone(t *testing.T) {
-	payload, err := NewPayload(util.RandomEmail(), time.Minute)
+	payload, err := NewPayload(util.RandomEmail(), ALL_SCOPES, time.Minute)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -0,0 +1,147 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownKey = errors.New("token is signed by an unknown key")
+
+// Keyring holds the Ed25519 key used to sign new tokens and every public key still accepted for verification.
+// Rotating the signing key keeps the previous public key, so tokens issued before the rotation stay valid
+// until the key is explicitly removed.
+type Keyring struct {
+	mu sync.RWMutex
+	signingKeyID string
+	signingKey ed25519.PrivateKey
+	verificationKeys map[string]ed25519.PublicKey
+}
+
+func NewKeyring(signingKeyID string, signingKey ed25519.PrivateKey) (*Keyring, error) {
+	keyring := &Keyring{
+		verificationKeys: make(map[string]ed25519.PublicKey),
+	}
+
+	err := keyring.Rotate(signingKeyID, signingKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return keyring, nil
+}
+
+// Rotate makes the given key the signing key. The public keys of the previous signing keys remain valid for verification.
+func (keyring *Keyring) Rotate(signingKeyID string, signingKey ed25519.PrivateKey) error {
+	if len(signingKeyID) == 0 {
+		return errors.New("signing key id must not be empty")
+	}
+
+	if len(signingKey) != ed25519.PrivateKeySize {
+		return fmt.Errorf("invalid signing key size: must be exactly %d bytes", ed25519.PrivateKeySize)
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	keyring.signingKeyID = signingKeyID
+	keyring.signingKey = signingKey
+	keyring.verificationKeys[signingKeyID] = signingKey.Public().(ed25519.PublicKey)
+	return nil
+}
+
+// AddVerificationKey accepts tokens signed by the private part of the given public key
+func (keyring *Keyring) AddVerificationKey(keyID string, key ed25519.PublicKey) error {
+	if len(keyID) == 0 {
+		return errors.New("verification key id must not be empty")
+	}
+
+	if len(key) != ed25519.PublicKeySize {
+		return fmt.Errorf("invalid verification key size: must be exactly %d bytes", ed25519.PublicKeySize)
+	}
+
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	keyring.verificationKeys[keyID] = key
+	return nil
+}
+
+// RemoveVerificationKey stops accepting tokens of the key. The current signing key cannot be removed.
+func (keyring *Keyring) RemoveVerificationKey(keyID string) error {
+	keyring.mu.Lock()
+	defer keyring.mu.Unlock()
+
+	if keyID == keyring.signingKeyID {
+		return errors.New("cannot remove the key currently used for signing")
+	}
+
+	delete(keyring.verificationKeys, keyID)
+	return nil
+}
+
+func (keyring *Keyring) SigningKey() (string, ed25519.PrivateKey) {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	return keyring.signingKeyID, keyring.signingKey
+}
+
+func (keyring *Keyring) VerificationKey(keyID string) (ed25519.PublicKey, error) {
+	keyring.mu.RLock()
+	defer keyring.mu.RUnlock()
+
+	key, ok := keyring.verificationKeys[keyID]
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	return key, nil
+}
+
+// ParseKeyring builds a keyring from its configuration.
+// signingKey is the base64 encoded 32 bytes seed of the Ed25519 signing key and verificationKeys is a comma
+// separated list of id:key pairs, where key is the base64 encoded raw 32-byte public key, not a DER or PEM one,
+// e.g. "2021-05:11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=,2021-06:...".
+func ParseKeyring(signingKeyID string, signingKey string, verificationKeys string) (*Keyring, error) {
+	seed, err := base64.StdEncoding.DecodeString(signingKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode signing key %w", err)
+	}
+
+	if len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("invalid signing key size: seed must be exactly %d bytes", ed25519.SeedSize)
+	}
+
+	keyring, err := NewKeyring(signingKeyID, ed25519.NewKeyFromSeed(seed))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, entry := range strings.Split(verificationKeys, ",") {
+		entry = strings.TrimSpace(entry)
+		if len(entry) == 0 {
+			continue
+		}
+
+		fields := strings.SplitN(entry, ":", 2)
+		if len(fields) != 2 {
+			return nil, fmt.Errorf("invalid verification key %q: must be formatted as id:key", entry)
+		}
+
+		key, err := base64.StdEncoding.DecodeString(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("cannot decode verification key %s %w", fields[0], err)
+		}
+
+		err = keyring.AddVerificationKey(fields[0], ed25519.PublicKey(key))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return keyring, nil
+}
@@ -0,0 +1,84 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+)
+
+func randomSigningKey(t *testing.T) ed25519.PrivateKey {
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return privateKey
+}
+
+func randomKeyring(t *testing.T) *Keyring {
+	keyring, err := NewKeyring(util.RandomString(8), randomSigningKey(t))
+	require.NoError(t, err)
+	return keyring
+}
+
+func TestKeyringRotation(t *testing.T) {
+	keyring := randomKeyring(t)
+	oldKeyID, oldKey := keyring.SigningKey()
+
+	newKeyID := util.RandomString(8)
+	newKey := randomSigningKey(t)
+	err := keyring.Rotate(newKeyID, newKey)
+	require.NoError(t, err)
+
+	keyID, signingKey := keyring.SigningKey()
+	require.Equal(t, newKeyID, keyID)
+	require.Equal(t, newKey, signingKey)
+
+	// the previous key is still accepted for verification
+	publicKey, err := keyring.VerificationKey(oldKeyID)
+	require.NoError(t, err)
+	require.Equal(t, oldKey.Public(), publicKey)
+
+	err = keyring.RemoveVerificationKey(oldKeyID)
+	require.NoError(t, err)
+	_, err = keyring.VerificationKey(oldKeyID)
+	require.EqualError(t, err, ErrUnknownKey.Error())
+
+	err = keyring.RemoveVerificationKey(newKeyID)
+	require.Error(t, err)
+}
+
+func TestParseKeyring(t *testing.T) {
+	seed := make([]byte, ed25519.SeedSize)
+	_, err := rand.Read(seed)
+	require.NoError(t, err)
+
+	otherKey := randomSigningKey(t)
+	verificationKeys := fmt.Sprintf("old:%s", base64.StdEncoding.EncodeToString(otherKey.Public().(ed25519.PublicKey)))
+
+	keyring, err := ParseKeyring("current", base64.StdEncoding.EncodeToString(seed), verificationKeys)
+	require.NoError(t, err)
+
+	keyID, signingKey := keyring.SigningKey()
+	require.Equal(t, "current", keyID)
+	require.Equal(t, ed25519.NewKeyFromSeed(seed), signingKey)
+
+	publicKey, err := keyring.VerificationKey("old")
+	require.NoError(t, err)
+	require.Equal(t, otherKey.Public(), publicKey)
+
+	_, err = ParseKeyring("current", "invalid key", "")
+	require.Error(t, err)
+
+	_, err = ParseKeyring("current", base64.StdEncoding.EncodeToString(seed), "missing-separator")
+	require.Error(t, err)
+
+	// the example of the documentation is a raw key, a DER encoded one is refused
+	_, err = ParseKeyring("current", base64.StdEncoding.EncodeToString(seed), "2021-05:11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=")
+	require.NoError(t, err)
+
+	_, err = ParseKeyring("current", base64.StdEncoding.EncodeToString(seed), "2021-05:MCowBQYDK2VwAyEA11qYAYKxCrfVS/7TyWQHOg7hcvPapiMlrwIaaPcHURo=")
+	require.Error(t, err)
+}
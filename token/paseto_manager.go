@@ -26,8 +26,8 @@ func NewPasetoManager(symmetricKey string) (TokenManager, error) {
 	return manager, nil
 }
 
-func (manager *PasetoManager) CreateToken(username string, duration time.Duration) (string, error) {
-	payload, err := NewPayload(username, duration)
+func (manager *PasetoManager) CreateToken(username string, scopes []string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, scopes, duration)
 	if err != nil {
 		return "", err
 	}
@@ -0,0 +1,69 @@
+package token
+
+import (
+	"time"
+
+	"github.com/o1egl/paseto"
+)
+
+// keyFooter is stored in clear in the token footer so that the verification key can be looked up
+type keyFooter struct {
+	KeyID string `json:"kid"`
+}
+
+// PasetoPublicManager issues PASETO v2.public tokens signed with the Ed25519 keys of a keyring.
+// Unlike PasetoManager, services only holding the public keys can verify tokens without being able to mint them.
+type PasetoPublicManager struct {
+	paseto *paseto.V2
+	keyring *Keyring
+}
+
+func NewPasetoPublicManager(keyring *Keyring) (TokenManager, error) {
+	manager := &PasetoPublicManager{
+		paseto: paseto.NewV2(),
+		keyring: keyring,
+	}
+
+	return manager, nil
+}
+
+func (manager *PasetoPublicManager) CreateToken(username string, scopes []string, duration time.Duration) (string, error) {
+	payload, err := NewPayload(username, scopes, duration)
+	if err != nil {
+		return "", err
+	}
+
+	keyID, signingKey := manager.keyring.SigningKey()
+	return manager.paseto.Sign(signingKey, payload, keyFooter{KeyID: keyID})
+}
+
+func (manager *PasetoPublicManager) VerifyToken(token string) (*Payload, error) {
+	version, purpose, err := paseto.GetTokenInfo(token)
+	if err != nil || version != paseto.Version2 || purpose != paseto.PUBLIC {
+		return nil, ErrInvalidToken
+	}
+
+	var footer keyFooter
+	err = paseto.ParseFooter(token, &footer)
+	if err != nil || len(footer.KeyID) == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	publicKey, err := manager.keyring.VerificationKey(footer.KeyID)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := &Payload{}
+	err = manager.paseto.Verify(token, publicKey, payload, nil)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	err = payload.Valid()
+	if err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
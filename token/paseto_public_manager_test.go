@@ -0,0 +1,109 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"testing"
+	"time"
+
+	"github.com/sssaang/simplebank/db/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+func TestPasetoPublicManager(t *testing.T) {
+	manager, err := NewPasetoPublicManager(randomKeyring(t))
+	require.NoError(t, err)
+
+	username := util.RandomEmail()
+	duration := time.Minute
+	issuedAt := time.Now()
+	expiredAt := time.Now().Add(duration)
+
+	token, err := manager.CreateToken(username, ALL_SCOPES, duration)
+	require.NoError(t, err)
+	require.NotEmpty(t, token)
+
+	payload, err := manager.VerifyToken(token)
+	require.NoError(t, err)
+	require.NotEmpty(t, payload)
+
+	require.NotZero(t, payload.ID)
+	require.Equal(t, username, payload.Username)
+	require.Equal(t, ALL_SCOPES, payload.Scopes)
+	require.WithinDuration(t, issuedAt, payload.IssuedAt, time.Second)
+	require.WithinDuration(t, expiredAt, payload.ExpiredAt, time.Second)
+}
+
+func TestExpiredPasetoPublicToken(t *testing.T) {
+	manager, err := NewPasetoPublicManager(randomKeyring(t))
+	require.NoError(t, err)
+
+	token, err := manager.CreateToken(util.RandomEmail(), ALL_SCOPES, -time.Minute)
+	require.NoError(t, err)
+
+	payload, err := manager.VerifyToken(token)
+	require.EqualError(t, err, ErrExpiredToken.Error())
+	require.Nil(t, payload)
+}
+
+func TestPasetoPublicKeyRotation(t *testing.T) {
+	keyring := randomKeyring(t)
+	manager, err := NewPasetoPublicManager(keyring)
+	require.NoError(t, err)
+
+	oldToken, err := manager.CreateToken(util.RandomEmail(), ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+	oldKeyID, _ := keyring.SigningKey()
+
+	err = keyring.Rotate(util.RandomString(8), randomSigningKey(t))
+	require.NoError(t, err)
+
+	newToken, err := manager.CreateToken(util.RandomEmail(), ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+
+	// tokens issued before the rotation keep working
+	_, err = manager.VerifyToken(oldToken)
+	require.NoError(t, err)
+	_, err = manager.VerifyToken(newToken)
+	require.NoError(t, err)
+
+	err = keyring.RemoveVerificationKey(oldKeyID)
+	require.NoError(t, err)
+	_, err = manager.VerifyToken(oldToken)
+	require.EqualError(t, err, ErrUnknownKey.Error())
+}
+
+func TestPasetoPublicVerifyOnly(t *testing.T) {
+	signingKeyring := randomKeyring(t)
+	signer, err := NewPasetoPublicManager(signingKeyring)
+	require.NoError(t, err)
+
+	token, err := signer.CreateToken(util.RandomEmail(), ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+
+	// a service only knowing the public key can verify the token
+	keyID, signingKey := signingKeyring.SigningKey()
+	verifyingKeyring := randomKeyring(t)
+	err = verifyingKeyring.AddVerificationKey(keyID, signingKey.Public().(ed25519.PublicKey))
+	require.NoError(t, err)
+
+	verifier, err := NewPasetoPublicManager(verifyingKeyring)
+	require.NoError(t, err)
+	_, err = verifier.VerifyToken(token)
+	require.NoError(t, err)
+}
+
+func TestInvalidPasetoPublicToken(t *testing.T) {
+	symmetricManager, err := NewPasetoManager(util.RandomString(chacha20poly1305.KeySize))
+	require.NoError(t, err)
+
+	token, err := symmetricManager.CreateToken(util.RandomEmail(), ALL_SCOPES, time.Minute)
+	require.NoError(t, err)
+
+	manager, err := NewPasetoPublicManager(randomKeyring(t))
+	require.NoError(t, err)
+
+	payload, err := manager.VerifyToken(token)
+	require.EqualError(t, err, ErrInvalidToken.Error())
+	require.Nil(t, payload)
+}
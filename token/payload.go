@@ -15,11 +15,12 @@ var (
 type Payload struct {
 	ID uuid.UUID `json:"id"`
 	Username string `json:"username"`
+	Scopes []string `json:"scopes"`
 	IssuedAt time.Time `json:"issued_at"`
 	ExpiredAt time.Time `json:"expired_at"`
 }
 
-func NewPayload(username string, duration time.Duration) (*Payload, error) {
+func NewPayload(username string, scopes []string, duration time.Duration) (*Payload, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -28,6 +29,7 @@ func NewPayload(username string, duration time.Duration) (*Payload, error) {
 	payload := &Payload{
 		ID: tokenID,
 		Username: username,
+		Scopes: scopes,
 		IssuedAt: time.Now(),
 		ExpiredAt: time.Now().Add(duration),
 	}
@@ -0,0 +1,62 @@
+package token
+
+const (
+	SCOPE_USER_READ = "user:read"
+	SCOPE_USER_WRITE = "user:write"
+	SCOPE_ACCOUNTS_READ = "accounts:read"
+	SCOPE_ACCOUNTS_WRITE = "accounts:write"
+	SCOPE_TRANSFERS_READ = "transfers:read"
+	SCOPE_TRANSFERS_WRITE = "transfers:write"
+	SCOPE_API_KEYS_READ = "api_keys:read"
+	SCOPE_API_KEYS_WRITE = "api_keys:write"
+	// SCOPE_ADMIN is only of use to users with the admin role
+	SCOPE_ADMIN = "admin"
+)
+
+// ALL_SCOPES are granted to the access tokens issued on login
+var ALL_SCOPES = []string{
+	SCOPE_USER_READ,
+	SCOPE_USER_WRITE,
+	SCOPE_ACCOUNTS_READ,
+	SCOPE_ACCOUNTS_WRITE,
+	SCOPE_TRANSFERS_READ,
+	SCOPE_TRANSFERS_WRITE,
+	SCOPE_API_KEYS_READ,
+	SCOPE_API_KEYS_WRITE,
+	SCOPE_ADMIN,
+}
+
+// READ_ONLY_SCOPES are the only scopes third-party OAuth clients can be granted
+var READ_ONLY_SCOPES = []string{
+	SCOPE_USER_READ,
+	SCOPE_ACCOUNTS_READ,
+	SCOPE_TRANSFERS_READ,
+}
+
+func IsSupportedScope(scope string) bool {
+	for _, supported := range ALL_SCOPES {
+		if scope == supported {
+			return true
+		}
+	}
+	return false
+}
+
+func IsReadOnlyScope(scope string) bool {
+	for _, readOnly := range READ_ONLY_SCOPES {
+		if scope == readOnly {
+			return true
+		}
+	}
+	return false
+}
+
+// HasScope reports whether the payload grants the scope
+func (payload *Payload) HasScope(scope string) bool {
+	for _, granted := range payload.Scopes {
+		if granted == scope {
+			return true
+		}
+	}
+	return false
+}
@@ -3,6 +3,6 @@ package token
 import "time"
 
 type TokenManager interface {
-	CreateToken(username string, duration time.Duration) (string, error)
+	CreateToken(username string, scopes []string, duration time.Duration) (string, error)
 	VerifyToken(token string) (*Payload, error)
 }
\ No newline at end of file